@@ -1,35 +1,73 @@
 package emailverifier
 
 import (
+	"net/mail"
 	"regexp"
 	"strings"
+
+	"golang.org/x/net/idna"
 )
 
 var emailRegex = regexp.MustCompile(emailRegexString)
 
+// defaultVerifier provides the default configuration to the package level helpers, it is never modified
+var defaultVerifier = NewVerifier()
+
 // Syntax stores all information about an email Syntax
 type Syntax struct {
-	Username string `json:"username"`
-	Domain   string `json:"domain"`
-	Valid    bool   `json:"valid"`
+	Username      string   `json:"username"`
+	Domain        string   `json:"domain"`
+	DomainASCII   string   `json:"domain_ascii"`   // the ASCII (punycode) form of the domain, used for DNS and SMTP
+	DomainUnicode string   `json:"domain_unicode"` // the Unicode form of the domain
+	Valid         bool     `json:"valid"`
+	HasSubAddress bool     `json:"has_sub_address"` // whether the local part carries a sub-address tag, e.g. user+tag
+	Tag           string   `json:"tag"`             // the sub-address tag without its separator
+	Reasons       []string `json:"reasons"`         // reasons why the syntax is invalid, e.g. "consecutive_dots"
+}
+
+// ParseAddress parses and validates an email address exactly as Verify does with the default configuration,
+// `"Name" <address>` mailboxes included. It performs no network I/O and is safe for concurrent use.
+// The returned error is a *SyntaxError if the syntax is invalid, the Syntax then carries the reasons as well.
+func ParseAddress(email string) (*Syntax, error) {
+	_, _, syntax := defaultVerifier.parseEmail(email)
+	if !syntax.Valid {
+		return &syntax, &SyntaxError{Email: email, Reasons: syntax.Reasons}
+	}
+	return &syntax, nil
 }
 
-// ParseAddress attempts to parse an email address and return it in the form of an Syntax
+// parseEmail parses email as passed to Verify, returns its display name, its bare address and its syntax
+func (v *Verifier) parseEmail(email string) (string, string, Syntax) {
+	name, address := v.parseMailbox(email)
+	return name, address, v.ParseAddress(address)
+}
+
+// ParseAddress attempts to parse an email address and return it in the form of an Syntax.
+// Comments are stripped before validation and a quoted local part is reported in its unquoted form,
+// e.g. `"john..doe"@example.com` has the username `john..doe`
 func (v *Verifier) ParseAddress(email string) Syntax {
+	v = v.snapshot()
+	email = stripComments(email)
 
-	isAddressValid := IsAddressValid(email)
+	isAddressValid, reasons := checkSyntax(email, v.syntaxMode, v.utf8LocalPartEnabled)
 	if !isAddressValid {
-		return Syntax{Valid: false}
+		return Syntax{Valid: false, Reasons: reasons}
 	}
 
 	index := strings.LastIndex(email, "@")
-	username := email[:index]
+	local := email[:index]
 	domain := strings.ToLower(email[index+1:])
+	asciiDomain, unicodeDomain, _ := idnForms(domain)
+	_, tag, hasSubAddress := v.splitSubAddress(local, domain)
 
 	return Syntax{
-		Username: username,
-		Domain:   domain,
-		Valid:    isAddressValid,
+		Username:      unquoteLocalPart(local),
+		Domain:        domain,
+		DomainASCII:   asciiDomain,
+		DomainUnicode: unicodeDomain,
+		Valid:         isAddressValid,
+		HasSubAddress: hasSubAddress,
+		Tag:           tag,
 	}
 }
 
@@ -37,3 +75,142 @@ func (v *Verifier) ParseAddress(email string) Syntax {
 func IsAddressValid(email string) bool {
 	return emailRegex.MatchString(email)
 }
+
+// idnForms returns the ASCII (punycode) and the Unicode form of domain,
+// reports whether domain is a valid internationalized domain name
+func idnForms(domain string) (string, string, bool) {
+	if isASCII(domain) && !strings.Contains(domain, "xn--") {
+		return domain, domain, true
+	}
+
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", "", false
+	}
+	unicodeDomain, err := idna.Lookup.ToUnicode(asciiDomain)
+	if err != nil {
+		return "", "", false
+	}
+	return asciiDomain, unicodeDomain, true
+}
+
+// parseMailbox extracts the display name and the bare address (addr-spec) from an RFC 5322 mailbox
+// such as `"John Smith" <john@example.com>`, surrounding whitespace, comments and group syntax are tolerated.
+// If email can't be parsed it is returned as it is, so the syntax check reports it as invalid.
+func (v *Verifier) parseMailbox(email string) (string, string) {
+	address := strings.TrimSpace(email)
+	if valid, _ := checkSyntax(stripComments(address), SyntaxLenient, v.utf8LocalPartEnabled); valid {
+		return "", address
+	}
+
+	mailbox, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", email
+	}
+
+	// net/mail reports the local part unquoted, restore the quotes it may need
+	index := strings.LastIndex(mailbox.Address, "@")
+	return mailbox.Name, quoteLocalPart(mailbox.Address[:index]) + mailbox.Address[index:]
+}
+
+// stripComments removes RFC 5322 comments such as "(comment)" outside of quoted strings,
+// email is returned as it is if its parentheses are unbalanced
+func stripComments(email string) string {
+	if !strings.Contains(email, "(") {
+		return email
+	}
+
+	var b strings.Builder
+	var depth int
+	var quoted, escaped bool
+	for _, r := range email {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && (quoted || depth > 0):
+			escaped = true
+		case r == '"' && depth == 0:
+			quoted = !quoted
+		case r == '(' && !quoted:
+			depth++
+			continue
+		case r == ')' && !quoted && depth > 0:
+			depth--
+			continue
+		}
+		if depth == 0 {
+			b.WriteRune(r)
+		}
+	}
+
+	if depth != 0 {
+		return email
+	}
+	return b.String()
+}
+
+// unquoteLocalPart returns the content of a quoted local part with its quoted-pairs unescaped
+func unquoteLocalPart(local string) string {
+	if !isQuoted(local) {
+		return local
+	}
+
+	var b strings.Builder
+	var escaped bool
+	for _, r := range local[1 : len(local)-1] {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// quoteLocalPart returns the local part in the form to be used in an address:
+// as it is if it's a dot-atom or already a valid quoted string, otherwise as an escaped quoted string
+func quoteLocalPart(local string) string {
+	if isDotAtom(local) || isValidQuotedString(local) {
+		return local
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range local {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// isDotAtom checks if local is a valid unquoted local part
+func isDotAtom(local string) bool {
+	if local == "" || len(dotReasons(local)) > 0 {
+		return false
+	}
+	return strings.IndexFunc(local, func(r rune) bool { return !isAtext(r) && r != '.' }) < 0
+}
+
+// isValidQuotedString checks if local is a quoted string whose inner quotes and backslashes are escaped
+func isValidQuotedString(local string) bool {
+	if !isQuoted(local) {
+		return false
+	}
+
+	var escaped bool
+	for _, r := range local[1 : len(local)-1] {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			return false
+		}
+	}
+	return !escaped
+}
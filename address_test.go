@@ -1,7 +1,10 @@
 package emailverifier
 
 import (
+	"sync"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -31,3 +34,175 @@ func TestCheckAddressSyntax(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAddress_SubAddress(t *testing.T) {
+	address := verifier.ParseAddress("user+spam@domain.com")
+	assert.True(t, address.Valid)
+	assert.True(t, address.HasSubAddress)
+	assert.Equal(t, "user+spam", address.Username)
+	assert.Equal(t, "spam", address.Tag)
+}
+
+func TestParseMailbox(t *testing.T) {
+	cases := []struct {
+		email   string
+		name    string
+		address string
+	}{
+		{email: "john@example.com", name: "", address: "john@example.com"},
+		{email: "  john@example.com\t", name: "", address: "john@example.com"},
+		{email: `"John Smith" <john@example.com>`, name: "John Smith", address: "john@example.com"},
+		{email: "John Smith <john@example.com>", name: "John Smith", address: "john@example.com"},
+		{email: " <john@example.com> ", name: "", address: "john@example.com"},
+		{email: "john@example.com (John Smith)", name: "John Smith", address: "john@example.com"},
+		{email: "Team: John <john@example.com>;", name: "John", address: "john@example.com"},
+		{email: "John Smith <john@example.com", name: "", address: "John Smith <john@example.com"},
+		{email: "not an address", name: "", address: "not an address"},
+	}
+
+	for _, c := range cases {
+		name, address := verifier.parseMailbox(c.email)
+		assert.Equal(t, c.name, name, c.email)
+		assert.Equal(t, c.address, address, c.email)
+	}
+}
+
+func TestCheckEmail_DisplayName(t *testing.T) {
+	email := `"John Smith" <john@zzjbfwqi.shop>`
+
+	ret, err := verifier.Verify(email)
+	assert.NoError(t, err)
+	assert.Equal(t, email, ret.Email)
+	assert.Equal(t, "John Smith", ret.Name)
+	assert.True(t, ret.Syntax.Valid)
+	assert.Equal(t, "john", ret.Syntax.Username)
+	assert.Equal(t, "zzjbfwqi.shop", ret.Syntax.Domain)
+}
+
+func TestCheckEmail_MalformedDisplayName(t *testing.T) {
+	ret, err := verifier.Verify(`"John Smith <john@example.com>`)
+	assert.NoError(t, err)
+	assert.False(t, ret.Syntax.Valid)
+}
+
+func TestParseAddress_QuotedLocalPart(t *testing.T) {
+	cases := []struct {
+		email    string
+		valid    bool
+		username string
+	}{
+		{email: `"john..doe"@example.com`, valid: true, username: "john..doe"},
+		{email: `"john doe"@example.com`, valid: true, username: "john doe"},
+		{email: `"john \"jd\" doe"@example.com`, valid: true, username: `john "jd" doe`},
+		{email: `"back\\slash"@example.com`, valid: true, username: `back\slash`},
+		{email: `"a@b"@example.com`, valid: true, username: "a@b"},
+		{email: `"john"doe"@example.com`, valid: false},
+		{email: `"john\"@example.com`, valid: false},
+		{email: "john doe@example.com", valid: false},
+	}
+
+	for _, c := range cases {
+		address := verifier.ParseAddress(c.email)
+		assert.Equal(t, c.valid, address.Valid, c.email)
+		assert.Equal(t, c.username, address.Username, c.email)
+	}
+}
+
+func TestParseAddress_Comments(t *testing.T) {
+	address := verifier.ParseAddress("odd.but.legal(comment)@example.com")
+	assert.True(t, address.Valid)
+	assert.Equal(t, "odd.but.legal", address.Username)
+	assert.Equal(t, "example.com", address.Domain)
+
+	address = verifier.ParseAddress("(a (nested) comment)john@(domain comment)example.com")
+	assert.True(t, address.Valid)
+	assert.Equal(t, "john", address.Username)
+	assert.Equal(t, "example.com", address.Domain)
+
+	address = verifier.ParseAddress(`"(not a comment)"@example.com`)
+	assert.True(t, address.Valid)
+	assert.Equal(t, "(not a comment)", address.Username)
+
+	assert.False(t, verifier.ParseAddress("john(unbalanced@example.com").Valid)
+}
+
+func TestStripComments(t *testing.T) {
+	assert.Equal(t, "john@example.com", stripComments("john(x)@example.com"))
+	assert.Equal(t, "john@example.com", stripComments(`john(x \) y)@example.com`))
+	assert.Equal(t, `"a(b)"@example.com`, stripComments(`"a(b)"@example.com`))
+	assert.Equal(t, "john(@example.com", stripComments("john(@example.com"))
+}
+
+func TestQuoteLocalPart(t *testing.T) {
+	assert.Equal(t, "john.doe", quoteLocalPart("john.doe"))
+	assert.Equal(t, `"john..doe"`, quoteLocalPart("john..doe"))
+	assert.Equal(t, `"john doe"`, quoteLocalPart("john doe"))
+	assert.Equal(t, `"john \"jd\" doe"`, quoteLocalPart(`john "jd" doe`))
+	assert.Equal(t, `"john..doe"`, quoteLocalPart(`"john..doe"`))
+	assert.Equal(t, `"back\\slash"`, quoteLocalPart(`back\slash`))
+}
+
+func TestParseMailbox_QuotedLocalPart(t *testing.T) {
+	name, address := verifier.parseMailbox(`"John" <"john..doe"@example.com>`)
+	assert.Equal(t, "John", name)
+	assert.Equal(t, `"john..doe"@example.com`, address)
+}
+
+func TestParseAddress_IDNForms(t *testing.T) {
+	address := verifier.ParseAddress("user@München.de")
+	assert.True(t, address.Valid)
+	assert.Equal(t, "münchen.de", address.Domain)
+	assert.Equal(t, "xn--mnchen-3ya.de", address.DomainASCII)
+	assert.Equal(t, "münchen.de", address.DomainUnicode)
+
+	address = verifier.ParseAddress("user@xn--mnchen-3ya.de")
+	assert.True(t, address.Valid)
+	assert.Equal(t, "xn--mnchen-3ya.de", address.Domain)
+	assert.Equal(t, "xn--mnchen-3ya.de", address.DomainASCII)
+	assert.Equal(t, "münchen.de", address.DomainUnicode)
+
+	address = verifier.ParseAddress("user@example.com")
+	assert.Equal(t, "example.com", address.DomainASCII)
+	assert.Equal(t, "example.com", address.DomainUnicode)
+}
+
+func TestParseAddress_InvalidIDN(t *testing.T) {
+	address := verifier.ParseAddress("user@xn--zz.com")
+	assert.False(t, address.Valid)
+	assert.Equal(t, []string{ReasonInvalidIDN}, address.Reasons)
+}
+
+func TestParseAddressFunc_Valid(t *testing.T) {
+	syntax, err := ParseAddress(`"John Smith" <John.Smith+promo@Example.com>`)
+	assert.NoError(t, err)
+	assert.True(t, syntax.Valid)
+	assert.Equal(t, "John.Smith+promo", syntax.Username)
+	assert.Equal(t, "example.com", syntax.Domain)
+	assert.Equal(t, "promo", syntax.Tag)
+}
+
+func TestParseAddressFunc_Invalid(t *testing.T) {
+	syntax, err := ParseAddress("user@@example.com")
+	assert.False(t, syntax.Valid)
+	assert.Equal(t, []string{ReasonInvalidCharacter}, syntax.Reasons)
+
+	syntaxErr, ok := err.(*SyntaxError)
+	assert.True(t, ok)
+	assert.Equal(t, "user@@example.com", syntaxErr.Email)
+	assert.Equal(t, []string{ReasonInvalidCharacter}, syntaxErr.Reasons)
+	assert.Equal(t, `invalid email address syntax "user@@example.com" : invalid_character`, err.Error())
+}
+
+func TestParseAddressFunc_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			syntax, err := ParseAddress("user@example.com")
+			assert.NoError(t, err)
+			assert.True(t, syntax.Valid)
+		}()
+	}
+	wg.Wait()
+}
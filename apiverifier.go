@@ -0,0 +1,123 @@
+package emailverifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Providers of the API verifiers, see EnableAPIVerifier
+const (
+	APIVerifierYahoo   = "yahoo"
+	APIVerifierOutlook = "outlook"
+)
+
+// apiVerifier checks the existence of the addresses of a mailbox provider through its web endpoints,
+// for providers whose SMTP servers accept RCPT for nonexistent users
+type apiVerifier interface {
+	// isSupported reports whether the addresses of domain are checked by the verifier
+	isSupported(domain string) bool
+	// check checks the existence of the address of username at domain like CheckSMTP does until ctx is done,
+	// an answer which doesn't tell whether the address exists yields an error
+	check(ctx context.Context, domain, username string) (*SMTP, error)
+}
+
+// newAPIVerifiers creates the API verifiers by provider name
+var newAPIVerifiers = map[string]func() apiVerifier{
+	APIVerifierYahoo:   func() apiVerifier { return newYahooVerifier() },
+	APIVerifierOutlook: func() apiVerifier { return newOutlookVerifier() },
+}
+
+// EnableAPIVerifier enables the API verifier of the provider name, e.g. APIVerifierYahoo or APIVerifierOutlook.
+// When the smtp check is enabled, the addresses of the provider are checked through its API instead of SMTP.
+func (v *Verifier) EnableAPIVerifier(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	newVerifier, ok := newAPIVerifiers[name]
+	if !ok {
+		return fmt.Errorf("unsupported API verifier %q", name)
+	}
+	v.setAPIVerifier(name, newVerifier())
+	return nil
+}
+
+// DisableAPIVerifier disables the API verifier of the provider name
+func (v *Verifier) DisableAPIVerifier(name string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.setAPIVerifier(name, nil)
+	return v
+}
+
+// setAPIVerifier sets the API verifier of the provider name, a nil api removes it.
+// The map is replaced rather than modified, since snapshots share it.
+func (v *Verifier) setAPIVerifier(name string, api apiVerifier) {
+	apiVerifiers := make(map[string]apiVerifier, len(v.apiVerifiers)+1)
+	for n, a := range v.apiVerifiers {
+		apiVerifiers[n] = a
+	}
+	if api != nil {
+		apiVerifiers[name] = api
+	} else {
+		delete(apiVerifiers, name)
+	}
+	v.apiVerifiers = apiVerifiers
+}
+
+// apiVerifierFor returns the enabled API verifier checking the addresses of domain
+func (v *Verifier) apiVerifierFor(domain string) (apiVerifier, bool) {
+	for _, api := range v.apiVerifiers {
+		if api.isSupported(domain) {
+			return api, true
+		}
+	}
+	return nil, false
+}
+
+// checkDomainAPI performs the checks of usernames at domain with api like checkDomainSMTP does
+func checkDomainAPI(ctx context.Context, api apiVerifier, domain string, usernames []string) []smtpCheck {
+	checks := make([]smtpCheck, len(usernames))
+	for i, username := range usernames {
+		start := time.Now()
+		smtp, err := api.check(ctx, domain, username)
+		if smtp != nil {
+			smtp.ProbeReliability = ProbeReliabilityHigh
+		}
+		checks[i] = smtpCheck{smtp: smtp, err: err, deliverable: time.Since(start)}
+	}
+	return checks
+}
+
+// rateLimiter spaces out the calls of wait by interval
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // earliest time of the next call
+}
+
+// wait blocks until the interval since the previous call has passed. It returns the error of ctx once ctx is done,
+// without taking the turn of a call.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		if !l.next.After(now) {
+			l.next = now.Add(l.interval)
+			l.mu.Unlock()
+			return nil
+		}
+		delay := l.next.Sub(now)
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticAPIVerifier checks the addresses of domain, which exist if listed in users
+type staticAPIVerifier struct {
+	domain string
+	users  map[string]bool
+	err    error
+}
+
+func (s staticAPIVerifier) isSupported(domain string) bool {
+	return domain == s.domain
+}
+
+func (s staticAPIVerifier) check(ctx context.Context, domain, username string) (*SMTP, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &SMTP{HostExists: true, Deliverable: s.users[username]}, nil
+}
+
+func TestEnableAPIVerifier(t *testing.T) {
+	v := NewVerifier()
+
+	assert.NoError(t, v.EnableAPIVerifier(APIVerifierYahoo))
+	_, ok := v.apiVerifierFor("ymail.com")
+	assert.True(t, ok)
+	_, ok = v.apiVerifierFor("gmail.com")
+	assert.False(t, ok)
+
+	v.DisableAPIVerifier(APIVerifierYahoo)
+	_, ok = v.apiVerifierFor("ymail.com")
+	assert.False(t, ok)
+
+	assert.Error(t, v.EnableAPIVerifier("unknown"))
+}
+
+func TestCheckSMTP_APIVerifier(t *testing.T) {
+	v := NewVerifier().EnableSMTPCheck()
+	v.apiVerifiers = map[string]apiVerifier{
+		"static": staticAPIVerifier{domain: "api.test", users: map[string]bool{"alice": true}},
+	}
+
+	smtp, err := v.checkSMTP("api.test", "alice", nil)
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+	assert.Equal(t, reachableYes, v.calculateReachable(smtp))
+
+	smtp, err = v.checkSMTP("api.test", "bob", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, reachableNo, v.calculateReachable(smtp))
+}
+
+func TestCheckDomainAPI(t *testing.T) {
+	api := staticAPIVerifier{domain: "api.test", users: map[string]bool{"alice": true}}
+
+	checks := checkDomainAPI(context.Background(), api, "api.test", []string{"alice", "bob"})
+	assert.True(t, checks[0].smtp.Deliverable)
+	assert.False(t, checks[1].smtp.Deliverable)
+
+	checks = checkDomainAPI(context.Background(), staticAPIVerifier{err: errors.New("blocked")}, "api.test", []string{"alice"})
+	assert.Error(t, checks[0].err)
+	assert.Nil(t, checks[0].smtp)
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := &rateLimiter{interval: 20 * time.Millisecond}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.wait(context.Background()))
+	}
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestRateLimiter_Canceled(t *testing.T) {
+	l := &rateLimiter{interval: time.Hour}
+	assert.NoError(t, l.wait(context.Background()))
+
+	// a call waiting for its turn returns once its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, l.wait(ctx))
+	assert.True(t, time.Since(start) < time.Second)
+
+	// and doesn't take a turn, the next call waits for the same one
+	next := l.next
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Error(t, l.wait(ctx))
+	assert.Equal(t, next, l.next)
+}
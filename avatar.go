@@ -0,0 +1,183 @@
+package emailverifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Avatar providers answering the avatar check
+const (
+	AvatarProviderGravatar   = "gravatar"
+	AvatarProviderLibravatar = "libravatar"
+)
+
+// Avatar is detail about the avatar of an email
+type Avatar struct {
+	Provider  string `json:"provider"`   // name of the provider which answered
+	HasAvatar bool   `json:"has_avatar"` // whether has avatar
+	Hash      string `json:"hash"`       // hash of the normalized email, which identifies the avatar
+	Url       string `json:"url"`        // avatar url, empty if no avatar
+}
+
+// AvatarProvider checks whether an email has an avatar, Check returns an Avatar with HasAvatar false
+// if the email has none and an error if the check failed
+type AvatarProvider interface {
+	Check(ctx context.Context, email string) (*Avatar, error)
+}
+
+// SetAvatarProvider sets the provider of the avatar check, which is enabled by EnableGravatarCheck.
+// A nil provider restores Gravatar, the default provider.
+func (v *Verifier) SetAvatarProvider(p AvatarProvider) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.avatarProvider = p
+	return v
+}
+
+// CheckAvatar will return the avatar of the given email from the avatar provider of the verifier
+func (v *Verifier) CheckAvatar(email string) (*Avatar, error) {
+	v = v.snapshot()
+	p := v.avatarProvider
+	if p == nil {
+		p = &GravatarProvider{Client: v.gravatarClient}
+	}
+	return p.Check(context.Background(), email)
+}
+
+// GravatarProvider is the AvatarProvider of Gravatar
+type GravatarProvider struct {
+	Client *http.Client // http client of the requests, http.DefaultClient with a timeout of 10 seconds if nil
+}
+
+// Check returns the Gravatar avatar of email
+func (p *GravatarProvider) Check(ctx context.Context, email string) (*Avatar, error) {
+	gravatar, err := checkGravatar(ctx, p.Client, email)
+	if err != nil {
+		return nil, err
+	}
+	return gravatar.avatar(), nil
+}
+
+// LibravatarProvider is the AvatarProvider of Libravatar, which looks up the avatar server of the email domain
+// by the _avatars-sec and _avatars SRV records, and falls back to the Libravatar server without them
+type LibravatarProvider struct {
+	Client *http.Client // http client of the requests, http.DefaultClient with a timeout of 10 seconds if nil
+
+	lookupSRV func(service, proto, name string) (string, []*net.SRV, error) // net.LookupSRV if nil
+}
+
+// Check returns the Libravatar avatar of email
+func (p *LibravatarProvider) Check(ctx context.Context, email string) (*Avatar, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil, fmt.Errorf("invalid email %q", email)
+	}
+
+	sum := sha256.Sum256([]byte(email))
+	hash := hex.EncodeToString(sum[:])
+	avatarUrl := p.baseUrl(domainToASCII(email[at+1:])) + hash
+
+	_, found, err := getAvatar(ctx, p.Client, avatarUrl+"?d=404")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &Avatar{Provider: AvatarProviderLibravatar, Hash: hash}, nil
+	}
+	return &Avatar{Provider: AvatarProviderLibravatar, HasAvatar: true, Hash: hash, Url: avatarUrl}, nil
+}
+
+// baseUrl returns the avatar url prefix of the avatar server of domain
+func (p *LibravatarProvider) baseUrl(domain string) string {
+	lookupSRV := p.lookupSRV
+	if lookupSRV == nil {
+		lookupSRV = net.LookupSRV
+	}
+
+	services := []struct {
+		name   string
+		scheme string
+		port   uint16
+	}{
+		{"avatars-sec", "https", 443},
+		{"avatars", "http", 80},
+	}
+	for _, service := range services {
+		_, records, err := lookupSRV(service.name, "tcp", domain)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+
+		// records are sorted by priority and randomized by weight
+		target := strings.TrimSuffix(records[0].Target, ".")
+		if !isAvatarHost(target) {
+			continue
+		}
+		host := target
+		if records[0].Port != service.port {
+			host = net.JoinHostPort(target, strconv.Itoa(int(records[0].Port)))
+		}
+		return service.scheme + "://" + host + "/avatar/"
+	}
+	return libravatarBaseUrl
+}
+
+// isAvatarHost reports whether the SRV target host is a plain host name
+func isAvatarHost(host string) bool {
+	if host == "" {
+		return false
+	}
+	for _, r := range host {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// getAvatar requests the avatar at url with client, or http.DefaultClient with a timeout of 10 seconds if nil.
+// It returns the body of the avatar and whether it was found, a status other than 200 and 404 is an error.
+func getAvatar(ctx context.Context, client *http.Client, url string) ([]byte, bool, error) {
+	if client == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, avatarTimeout)
+		defer cancel()
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("get avatar with status_code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return body, true, nil
+}
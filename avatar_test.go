@@ -0,0 +1,131 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// staticAvatarProvider answers every check with its avatar and error
+type staticAvatarProvider struct {
+	avatar *Avatar
+	err    error
+}
+
+func (p staticAvatarProvider) Check(ctx context.Context, email string) (*Avatar, error) {
+	return p.avatar, p.err
+}
+
+// srvLookup returns a lookupSRV func answering with the records of each service
+func srvLookup(records map[string][]*net.SRV) func(service, proto, name string) (string, []*net.SRV, error) {
+	return func(service, proto, name string) (string, []*net.SRV, error) {
+		if r, ok := records[service]; ok {
+			return "", r, nil
+		}
+		return "", nil, errors.New("no such host")
+	}
+}
+
+func TestCheckAvatar_DefaultsToGravatar(t *testing.T) {
+	defer gock.Off()
+	_, hash := getMD5Hash("somebody@example.com")
+	gock.New("https://www.gravatar.com").
+		Get("/avatar/" + hash).
+		Reply(200).
+		BodyString("avatar")
+
+	avatar, err := NewVerifier().CheckAvatar("somebody@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &Avatar{
+		Provider:  AvatarProviderGravatar,
+		HasAvatar: true,
+		Hash:      hash,
+		Url:       "https://www.gravatar.com/avatar/" + hash,
+	}, avatar)
+}
+
+func TestCheckAvatar_CustomProvider(t *testing.T) {
+	want := &Avatar{Provider: "intranet", HasAvatar: true, Url: "https://avatars.corp.test/alice"}
+	v := NewVerifier().SetAvatarProvider(staticAvatarProvider{avatar: want})
+
+	avatar, err := v.CheckAvatar("alice@corp.test")
+	assert.NoError(t, err)
+	assert.Equal(t, want, avatar)
+
+	_, err = NewVerifier().SetAvatarProvider(staticAvatarProvider{err: errors.New("unavailable")}).CheckAvatar("alice@corp.test")
+	assert.Error(t, err)
+}
+
+func TestLibravatarProvider_BaseUrl(t *testing.T) {
+	cases := []struct {
+		name    string
+		records map[string][]*net.SRV
+		want    string
+	}{
+		{
+			name: "secure",
+			records: map[string][]*net.SRV{
+				"avatars-sec": {{Target: "avatars.example.com.", Port: 443}},
+				"avatars":     {{Target: "plain.example.com.", Port: 80}},
+			},
+			want: "https://avatars.example.com/avatar/",
+		},
+		{
+			name: "plain with port",
+			records: map[string][]*net.SRV{
+				"avatars": {{Target: "avatars.example.com.", Port: 8080}},
+			},
+			want: "http://avatars.example.com:8080/avatar/",
+		},
+		{
+			name: "invalid target",
+			records: map[string][]*net.SRV{
+				"avatars-sec": {{Target: "evil.example.com/x?", Port: 443}},
+			},
+			want: libravatarBaseUrl,
+		},
+		{
+			name: "no records",
+			want: libravatarBaseUrl,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := &LibravatarProvider{lookupSRV: srvLookup(c.records)}
+			assert.Equal(t, c.want, p.baseUrl("example.com"))
+		})
+	}
+}
+
+func TestLibravatarProvider_Check(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://avatars.example.com").
+		Reply(200).
+		BodyString("avatar")
+
+	p := &LibravatarProvider{lookupSRV: srvLookup(map[string][]*net.SRV{
+		"avatars-sec": {{Target: "avatars.example.com.", Port: 443}},
+	})}
+	avatar, err := p.Check(context.Background(), "Somebody@Example.com")
+	assert.NoError(t, err)
+	assert.True(t, avatar.HasAvatar)
+	assert.Equal(t, AvatarProviderLibravatar, avatar.Provider)
+	assert.Len(t, avatar.Hash, 64)
+	assert.Equal(t, "https://avatars.example.com/avatar/"+avatar.Hash, avatar.Url)
+}
+
+func TestLibravatarProvider_CheckNotFound(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://seccdn.libravatar.org").
+		Reply(404)
+
+	p := &LibravatarProvider{lookupSRV: srvLookup(nil)}
+	avatar, err := p.Check(context.Background(), "somebody@example.com")
+	assert.NoError(t, err)
+	assert.False(t, avatar.HasAvatar)
+	assert.Empty(t, avatar.Url)
+}
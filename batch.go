@@ -0,0 +1,469 @@
+package emailverifier
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchOptions configures the verification of a batch of addresses by VerifyBatch
+type BatchOptions struct {
+	Concurrency       int  // number of addresses, or of domains with GroupByDomain, verified concurrently, defaults to 10
+	GroupByDomain     bool // whether the addresses of a domain are verified together, sharing the mx and catch-all checks
+	DomainConnections int  // number of concurrent SMTP connections per domain with GroupByDomain, 1 (default) or 2
+	Deduplicate       bool // whether an address listed several times, ignoring the case of its domain and surrounding spaces, is verified once
+
+	// OnResult is called with the index in the batch and the result of each address as soon as its verification
+	// completes, in the order of completion. The calls are serialized, and VerifyBatch returns after the last one.
+	OnResult func(index int, result BatchResult)
+}
+
+// BatchResult is the verification result of one address of a batch
+type BatchResult struct {
+	Email  string  // passed email address
+	Result *Result // result of the verification, as returned by Verify
+	Err    error   // error of the verification, as returned by Verify
+}
+
+// concurrency returns the number of addresses or domains verified concurrently
+func (o BatchOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return defaultBatchConcurrency
+	}
+	return o.Concurrency
+}
+
+// domainConnections returns the number of concurrent SMTP connections per domain, bounded to 1 or 2
+func (o BatchOptions) domainConnections() int {
+	switch {
+	case o.DomainConnections <= 1:
+		return 1
+	case o.DomainConnections > maxBatchDomainConnections:
+		return maxBatchDomainConnections
+	}
+	return o.DomainConnections
+}
+
+// VerifyBatch verifies emails concurrently and returns their results in the order of emails.
+//
+// With GroupByDomain the addresses are partitioned by domain: the mx and the catch-all checks run once per domain,
+// and the addresses of the domain are then checked over reused SMTP connections, at most DomainConnections at a time.
+// On typical lists, where many addresses share few domains, this opens far fewer SMTP connections than Verify.
+func (v *Verifier) VerifyBatch(emails []string, opts BatchOptions) []BatchResult {
+	return v.VerifyBatchWithContext(context.Background(), emails, opts)
+}
+
+// VerifyBatchWithContext performs VerifyBatch until ctx is done, which interrupts the pending verifications
+// like VerifyWithContext does. The addresses whose verification ctx interrupted or prevented have the error of ctx.
+func (v *Verifier) VerifyBatchWithContext(ctx context.Context, emails []string, opts BatchOptions) []BatchResult {
+	v = v.snapshot()
+	if opts.Deduplicate {
+		return v.verifyUnique(ctx, emails, opts)
+	}
+	results := make([]BatchResult, len(emails))
+	done := opts.onResult(results)
+	if opts.GroupByDomain {
+		if ctx.Done() != nil {
+			v = v.withContext(ctx)
+		}
+		v.verifyBatchByDomain(emails, opts, results, done)
+		return results
+	}
+
+	runConcurrently(len(emails), opts.concurrency(), func(i int) {
+		ret, err := v.VerifyWithContext(ctx, emails[i])
+		results[i] = BatchResult{Email: emails[i], Result: ret, Err: err}
+		done(i)
+	})
+	return results
+}
+
+// VerifyBulk verifies a large list of emails, like VerifyBatch with GroupByDomain and Deduplicate:
+// each distinct address is verified once, and the addresses of a domain share its mx and catch-all checks
+// and reuse its SMTP connections, on at most the default concurrency of domains at a time
+func (v *Verifier) VerifyBulk(emails []string) []BatchResult {
+	return v.VerifyBatch(emails, BatchOptions{GroupByDomain: true, Deduplicate: true})
+}
+
+// verifyUnique performs VerifyBatch of the distinct addresses of emails, whose results are copied to their duplicates.
+// The result of a duplicate is a copy of the result of the first occurrence, with its own Email and address fields.
+// Addresses differing in the case of the local part are distinct, see resultCacheKey.
+func (v *Verifier) verifyUnique(ctx context.Context, emails []string, opts BatchOptions) []BatchResult {
+	var unique []string
+	var positions [][]int // indexes in emails of each address of unique
+	seen := make(map[string]int)
+	for i, email := range emails {
+		key := resultCacheKey(email)
+		n, found := seen[key]
+		if !found {
+			n = len(unique)
+			seen[key] = n
+			unique = append(unique, email)
+			positions = append(positions, nil)
+		}
+		positions[n] = append(positions[n], i)
+	}
+
+	results := make([]BatchResult, len(emails))
+	inner := opts
+	inner.Deduplicate = false
+	// the calls are serialized, so each result is stored and emitted at once
+	inner.OnResult = func(n int, result BatchResult) {
+		for _, i := range positions[n] {
+			results[i] = v.duplicateResult(result, emails[i])
+			if opts.OnResult != nil {
+				opts.OnResult(i, results[i])
+			}
+		}
+	}
+	v.VerifyBatchWithContext(ctx, unique, inner)
+	return results
+}
+
+// duplicateResult returns a copy of result as the result of email, with the address fields of email,
+// whose sections are copied too, so the positions of an address don't share a result
+func (v *Verifier) duplicateResult(result BatchResult, email string) BatchResult {
+	result.Email = email
+	if result.Result == nil {
+		return result
+	}
+	ret := *result.Result
+	ret.Email = email
+	if ret.Syntax.Valid {
+		v.readdress(&ret, email)
+	}
+	if ret.SMTP != nil {
+		smtp := *ret.SMTP
+		ret.SMTP = &smtp
+	}
+	if ret.Gravatar != nil {
+		gravatar := *ret.Gravatar
+		ret.Gravatar = &gravatar
+	}
+	if ret.Avatar != nil {
+		avatar := *ret.Avatar
+		ret.Avatar = &avatar
+	}
+	if ret.DomainAuth != nil {
+		ret.DomainAuth = copyDomainAuth(ret.DomainAuth)
+	}
+	result.Result = &ret
+	return result
+}
+
+// copyDomainAuth returns a copy of auth whose sections are copied too
+func copyDomainAuth(auth *DomainAuth) *DomainAuth {
+	ret := *auth
+	if ret.MTASTS != nil {
+		mtasts := *ret.MTASTS
+		ret.MTASTS = &mtasts
+	}
+	if ret.SPF != nil {
+		spf := *ret.SPF
+		ret.SPF = &spf
+	}
+	if ret.DKIM != nil {
+		dkim := *ret.DKIM
+		ret.DKIM = &dkim
+	}
+	if ret.DMARC != nil {
+		dmarc := *ret.DMARC
+		ret.DMARC = &dmarc
+	}
+	return &ret
+}
+
+// onResult returns the function called once the result of index is stored in results, which calls OnResult serialized
+func (o BatchOptions) onResult(results []BatchResult) func(index int) {
+	if o.OnResult == nil {
+		return func(int) {}
+	}
+	var mu sync.Mutex
+	return func(index int) {
+		mu.Lock()
+		defer mu.Unlock()
+		o.OnResult(index, results[index])
+	}
+}
+
+// batchItem is an address of a batch which still needs the mx and smtp checks
+type batchItem struct {
+	index   int     // index of the address in the batch
+	ret     *Result // result of the address and misc checks
+	address string  // parsed address
+	syntax  Syntax  // syntax of the address
+}
+
+// verifyBatchByDomain verifies emails grouped by domain, stores their results in results and calls done
+// with the index of each result once it's stored
+func (v *Verifier) verifyBatchByDomain(emails []string, opts BatchOptions, results []BatchResult, done func(int)) {
+	var domains []string
+	groups := make(map[string][]batchItem)
+	// the verified results are cached as they complete, a verification the context interrupted fails with its error
+	completed := func(i int) {
+		if v.expired() && (results[i].Err != nil || results[i].Result.TimedOut) {
+			results[i].Err = contextError(v.context())
+		}
+		v.cacheResult(results[i].Email, results[i].Result, results[i].Err)
+		done(i)
+	}
+	for i, email := range emails {
+		if ret, ok := v.cachedResult(email); ok {
+			results[i] = BatchResult{Email: email, Result: ret}
+			done(i)
+			continue
+		}
+		ret, address, syntax, verified := v.verifyAddress(email)
+		results[i] = BatchResult{Email: email, Result: ret}
+		if verified {
+			ret.Timings.Total = ret.Timings.Syntax
+			ret.assess(nil)
+			v.observeVerification(ret, nil)
+			completed(i)
+			continue
+		}
+
+		domain := strings.ToLower(syntax.DomainASCII)
+		if _, found := groups[domain]; !found {
+			domains = append(domains, domain)
+		}
+		groups[domain] = append(groups[domain], batchItem{index: i, ret: ret, address: address, syntax: syntax})
+	}
+
+	runConcurrently(len(domains), opts.concurrency(), func(i int) {
+		v.verifyDomainGroup(domains[i], groups[domains[i]], opts.domainConnections(), results, completed)
+	})
+}
+
+// verifyDomainGroup performs the mx and smtp checks of the addresses items of domain, and calls completed with the index
+// of each address once its result is stored in results.
+// The timings of the shared checks are recorded for each address, its total is the sum of its stages.
+func (v *Verifier) verifyDomainGroup(domain string, items []batchItem, connections int, results []BatchResult, completed func(int)) {
+	start := time.Now()
+	mx, mxErr := v.CheckMX(domain)
+	mxDuration := time.Since(start)
+
+	// The domain auth check is shared by the addresses of the domain
+	var auth *DomainAuth
+	var authErr error
+	if v.domainAuthCheckEnabled && mxErr == nil {
+		auth, authErr = v.checkDomainAuth(domain)
+	}
+
+	var pending []batchItem
+	for _, item := range items {
+		item.ret.Timings.MX = mxDuration
+		item.ret.Timings.Total = item.ret.Timings.Syntax + mxDuration
+		done, err := v.applyMX(item.ret, item.syntax, mx, mxErr)
+		if !done && v.domainAuthCheckEnabled {
+			if authErr != nil {
+				done, err = true, authErr
+			} else {
+				item.ret.DomainAuth = auth
+			}
+		}
+		if !done && !v.smtpCheckEnabled {
+			done, err = true, v.applySMTP(item.ret, item.address, nil)
+		}
+		if !done {
+			pending = append(pending, item)
+			continue
+		}
+		results[item.index].Err = err
+		item.ret.assess(err)
+		v.observeVerification(item.ret, err)
+		completed(item.index)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	usernames := make([]string, len(pending))
+	for i, item := range pending {
+		usernames[i] = item.syntax.Username
+	}
+
+	var checks []smtpCheck
+	if v.smtpDryRun {
+		checks = make([]smtpCheck, len(usernames))
+		for i, username := range usernames {
+			checks[i].smtp, checks[i].err = v.planSMTP(domain, username)
+		}
+	} else if api, ok := v.apiVerifierFor(domain); ok {
+		checks = checkDomainAPI(v.context(), api, domain, usernames)
+	} else {
+		var retries int32
+		checks = v.checkDomainSMTP(domain, usernames, connections, func() (*smtp.Client, error) {
+			client, n, err := v.acquireClient(domain)
+			atomic.AddInt32(&retries, int32(n))
+			return client, err
+		})
+		// the reconnections of the connections of the domain are shared by its addresses
+		reliability := v.probeReliability(domain)
+		for _, check := range checks {
+			if check.smtp != nil {
+				check.smtp.ConnectionRetries = int(atomic.LoadInt32(&retries))
+				check.smtp.ProbeReliability = reliability
+			}
+		}
+	}
+	for i, item := range pending {
+		check := checks[i]
+		item.ret.Timings.CatchAll = check.catchAll
+		item.ret.Timings.Deliverable = check.deliverable
+		item.ret.Timings.Total += check.catchAll + check.deliverable
+		switch {
+		case check.err == nil:
+			results[item.index].Err = v.applySMTP(item.ret, item.address, check.smtp)
+		case v.smtpSoftFailEnabled:
+			results[item.index].Err = v.applySMTP(item.ret, item.address, smtpFailure(check.smtp, check.err))
+		default:
+			results[item.index].Err = check.err
+		}
+		item.ret.assess(results[item.index].Err)
+		v.observeVerification(item.ret, results[item.index].Err)
+		completed(item.index)
+	}
+}
+
+// smtpCheck is the outcome of the smtp checks of an address by checkDomainSMTP
+type smtpCheck struct {
+	smtp        *SMTP         // result of the smtp checks, without HostExists if they failed to connect
+	err         error         // error of the smtp checks
+	catchAll    time.Duration // duration of the catch-all check shared by all addresses of the domain
+	deliverable time.Duration // duration of the deliverability check of the address
+}
+
+// checkDomainSMTP performs the smtp checks of usernames at domain like CheckSMTP does for each of them,
+// but checks the catch-all once and the usernames over at most connections clients created by dial
+func (v *Verifier) checkDomainSMTP(domain string, usernames []string, connections int, dial func() (*smtp.Client, error)) []smtpCheck {
+	checks := make([]smtpCheck, len(usernames))
+
+	start := time.Now()
+	client, err := dial()
+	if err != nil {
+		for i := range checks {
+			ret := SMTP{HostUnreachableReason: hostUnreachableReason(err)}
+			recordFailedStage(&ret, err)
+			checks[i] = smtpCheck{smtp: &ret, err: ParseSMTPError(err), catchAll: time.Since(start)}
+		}
+		return checks
+	}
+
+	var catchAll SMTP
+	v.checkCatchAll(client, domain, &catchAll)
+	catchAllDuration := time.Since(start)
+
+	// If the email server is a catch-all email server, no need to calibrate deliverable on a specific user
+	if catchAll.CatchAll {
+		v.releaseClient(client)
+		for i := range checks {
+			ret := catchAll
+			checks[i] = smtpCheck{smtp: &ret, catchAll: catchAllDuration}
+		}
+		return checks
+	}
+
+	next := make(chan int)
+	go func() {
+		for i := range usernames {
+			next <- i
+		}
+		close(next)
+	}()
+
+	var wg sync.WaitGroup
+	for c := 0; c < connections && c < len(usernames); c++ {
+		// the first connection is the one the catch-all was checked over
+		s := &smtpSession{dial: dial}
+		if c == 0 {
+			s.client, s.used = client, true
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer v.closeSession(s)
+			for i := range next {
+				ret := catchAll
+				start := time.Now()
+				err := v.checkSessionPresence(s, domain, usernames[i], &ret)
+				checks[i] = smtpCheck{smtp: &ret, err: err, catchAll: catchAllDuration, deliverable: time.Since(start)}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return checks
+}
+
+// smtpSession is an SMTP connection reused for several checks
+type smtpSession struct {
+	dial   func() (*smtp.Client, error) // creates a new client awaiting RCPT
+	client *smtp.Client                 // current client, nil if none is connected
+	used   bool                         // whether client needs a new mail transaction before RCPT
+}
+
+// checkSessionPresence checks the deliver ability of the address of username at domain over s,
+// a broken connection is replaced by a new one
+func (v *Verifier) checkSessionPresence(s *smtpSession, domain, username string, ret *SMTP) error {
+	// A pooled connection which sent enough RCPT commands is replaced
+	if s.client != nil && s.used && v.exhaustedClient(s.client) {
+		v.closeSession(s)
+	}
+	if s.client != nil && s.used {
+		if err := v.resetClient(s.client); err != nil {
+			v.debug(domain, "reconnecting after failed reset", "error", err)
+			v.discardClient(s.client)
+			s.client = nil
+		} else {
+			v.stats.countSMTPReuse()
+		}
+	}
+	if s.client == nil {
+		client, err := s.dial()
+		if err != nil {
+			recordFailedStage(ret, err)
+			return ParseSMTPError(err)
+		}
+		s.client = client
+	}
+
+	s.used = true
+	v.checkPresence(s.client, domain, username, ret)
+	return nil
+}
+
+// closeSession releases the connection of s
+func (v *Verifier) closeSession(s *smtpSession) {
+	if s.client != nil {
+		v.releaseClient(s.client)
+		s.client = nil
+	}
+}
+
+// runConcurrently calls f for every index below n on at most concurrency goroutines
+func runConcurrently(n, concurrency int, f func(int)) {
+	next := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			next <- i
+		}
+		close(next)
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,354 @@
+package emailverifier
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// newScriptedSMTPDial returns a dial func connecting to in-memory SMTP servers,
+// which reply to RCPT with the reply returned by rcpt, and the number of dials made
+func newScriptedSMTPDial(t *testing.T, rcpt func(address string) string) (func() (*smtp.Client, error), *int32) {
+	var dials int32
+	dial := func() (*smtp.Client, error) {
+		atomic.AddInt32(&dials, 1)
+		server, conn := net.Pipe()
+		go func() {
+			defer server.Close()
+			r := bufio.NewReader(server)
+			_, _ = server.Write([]byte("220 test ESMTP\r\n"))
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				line = strings.TrimSpace(line)
+				switch cmd := strings.ToUpper(line); {
+				case strings.HasPrefix(cmd, "EHLO"):
+					_, _ = server.Write([]byte("250-test\r\n250 HELP\r\n"))
+				case strings.HasPrefix(cmd, "RCPT TO:"):
+					address := strings.Trim(line[len("RCPT TO:"):], "<>")
+					_, _ = server.Write([]byte(rcpt(address) + "\r\n"))
+				case strings.HasPrefix(cmd, "QUIT"):
+					_, _ = server.Write([]byte("221 bye\r\n"))
+					return
+				default:
+					_, _ = server.Write([]byte("250 OK\r\n"))
+				}
+			}
+		}()
+
+		client, err := smtp.NewClient(conn, "test")
+		if err != nil {
+			return nil, err
+		}
+		if err := client.Hello("localhost"); err != nil {
+			return nil, err
+		}
+		if err := client.Mail(defaultFromEmail); err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+	return dial, &dials
+}
+
+// existingUsers returns a rcpt reply func accepting only the addresses of users
+func existingUsers(users ...string) func(string) string {
+	return func(address string) string {
+		for _, user := range users {
+			if strings.HasPrefix(address, user+"@") {
+				return "250 OK"
+			}
+		}
+		return "550 5.1.1 user unknown"
+	}
+}
+
+func TestCheckDomainSMTP_ReusesConnection(t *testing.T) {
+	dial, dials := newScriptedSMTPDial(t, existingUsers("alice", "bob"))
+	v := NewVerifier().EnableSMTPCheck()
+
+	usernames := []string{"alice", "nobody", "bob", "carol", "alice"}
+	checks := v.checkDomainSMTP("example.com", usernames, 1, dial)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(dials))
+	for i, expected := range []bool{true, false, true, false, true} {
+		assert.NoError(t, checks[i].err)
+		expectedSMTP := &SMTP{HostExists: true, Deliverable: expected}
+		if !expected {
+			expectedSMTP.RejectReason = RejectUserUnknown
+			expectedSMTP.FailedStage, expectedSMTP.ReplyCode = SMTPStageRcpt, 550
+		}
+		assert.Equal(t, expectedSMTP, checks[i].smtp, usernames[i])
+		assert.True(t, checks[i].catchAll > 0)
+		assert.True(t, checks[i].deliverable > 0)
+	}
+}
+
+func TestCheckDomainSMTP_TwoConnections(t *testing.T) {
+	dial, dials := newScriptedSMTPDial(t, existingUsers("user1", "user3"))
+	v := NewVerifier().EnableSMTPCheck()
+
+	var usernames []string
+	for i := 0; i < 20; i++ {
+		usernames = append(usernames, "user"+string(rune('0'+i%10)))
+	}
+	checks := v.checkDomainSMTP("example.com", usernames, 2, dial)
+
+	assert.LessOrEqual(t, atomic.LoadInt32(dials), int32(2))
+	for i, username := range usernames {
+		assert.NoError(t, checks[i].err)
+		assert.Equal(t, username == "user1" || username == "user3", checks[i].smtp.Deliverable, username)
+	}
+}
+
+func TestCheckDomainSMTP_CatchAll(t *testing.T) {
+	dial, dials := newScriptedSMTPDial(t, func(string) string { return "250 OK" })
+	v := NewVerifier().EnableSMTPCheck()
+
+	checks := v.checkDomainSMTP("example.com", []string{"alice", "bob"}, 2, dial)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(dials))
+	for _, check := range checks {
+		assert.NoError(t, check.err)
+		assert.Equal(t, &SMTP{HostExists: true, CatchAll: true}, check.smtp)
+		assert.Zero(t, check.deliverable)
+	}
+}
+
+func TestCheckDomainSMTP_DialError(t *testing.T) {
+	v := NewVerifier().EnableSMTPCheck()
+	dial := func() (*smtp.Client, error) {
+		return nil, unreachable(UnreachableConnectTimeout, errors.New("dial tcp: i/o timeout"))
+	}
+
+	checks := v.checkDomainSMTP("example.com", []string{"alice", "bob"}, 1, dial)
+	for _, check := range checks {
+		assert.Equal(t, &SMTP{HostUnreachableReason: UnreachableConnectTimeout}, check.smtp)
+		assert.Error(t, check.err)
+	}
+}
+
+func TestCheckSessionPresence_ReplacesBrokenConnection(t *testing.T) {
+	dial, dials := newScriptedSMTPDial(t, existingUsers("alice"))
+	v := NewVerifier()
+
+	s := &smtpSession{dial: dial}
+	defer v.closeSession(s)
+
+	var ret SMTP
+	assert.NoError(t, v.checkSessionPresence(s, "example.com", "alice", &ret))
+	assert.True(t, ret.Deliverable)
+
+	// a closed connection fails the reset and is replaced
+	_ = s.client.Close()
+	ret = SMTP{}
+	assert.NoError(t, v.checkSessionPresence(s, "example.com", "alice", &ret))
+	assert.True(t, ret.Deliverable)
+	assert.Equal(t, int32(2), atomic.LoadInt32(dials))
+}
+
+func TestVerifyBatch_Order(t *testing.T) {
+	v := NewVerifier().
+		EnableSMTPCheck().
+		SetDomainAllowlist([]string{"trusted.test"}, reachableYes).
+		SetDomainBlocklist([]string{"blocked.test"})
+
+	emails := []string{
+		"invalid-address",
+		"a@trusted.test",
+		"b@blocked.test",
+		"c@zzjbfwqi.shop",
+		"d@trusted.test",
+	}
+
+	for _, groupByDomain := range []bool{false, true} {
+		results := v.VerifyBatch(emails, BatchOptions{GroupByDomain: groupByDomain, Concurrency: 3})
+		assert.Len(t, results, len(emails))
+		for i, email := range emails {
+			assert.Equal(t, email, results[i].Email)
+			assert.Equal(t, email, results[i].Result.Email)
+			assert.NoError(t, results[i].Err)
+		}
+
+		assert.False(t, results[0].Result.Syntax.Valid)
+		assert.Equal(t, SkipReasonAllowlisted, results[1].Result.SkipReason)
+		assert.Equal(t, SkipReasonBlocklisted, results[2].Result.SkipReason)
+		assert.True(t, results[3].Result.Disposable)
+		assert.Equal(t, reachableYes, results[4].Result.Reachable)
+	}
+}
+
+func TestVerifyBatch_Empty(t *testing.T) {
+	assert.Empty(t, NewVerifier().VerifyBatch(nil, BatchOptions{GroupByDomain: true}))
+}
+
+func TestBatchOptions(t *testing.T) {
+	assert.Equal(t, defaultBatchConcurrency, BatchOptions{}.concurrency())
+	assert.Equal(t, 4, BatchOptions{Concurrency: 4}.concurrency())
+	assert.Equal(t, 1, BatchOptions{}.domainConnections())
+	assert.Equal(t, 2, BatchOptions{DomainConnections: 2}.domainConnections())
+	assert.Equal(t, maxBatchDomainConnections, BatchOptions{DomainConnections: 10}.domainConnections())
+}
+
+func TestRunConcurrently(t *testing.T) {
+	var (
+		sum     int64
+		running int32
+		maxSeen int32
+	)
+	runConcurrently(100, 4, func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxSeen)
+			if n <= m || atomic.CompareAndSwapInt32(&maxSeen, m, n) {
+				break
+			}
+		}
+		atomic.AddInt64(&sum, int64(i))
+		atomic.AddInt32(&running, -1)
+	})
+	assert.Equal(t, int64(4950), sum)
+	assert.LessOrEqual(t, maxSeen, int32(4))
+}
+
+func TestVerifyBatch_SMTPSoftFail(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DropOn: "MAIL"}, []string{"example.com"}, WithSMTPSoftFail())
+
+	emails := []string{"alice@example.com", "bob@example.com"}
+	for _, groupByDomain := range []bool{false, true} {
+		results := v.VerifyBatch(emails, BatchOptions{GroupByDomain: groupByDomain})
+		for i, email := range emails {
+			assert.NoError(t, results[i].Err, email)
+			assert.Equal(t, reachableUnknown, results[i].Result.Reachable, email)
+			if assert.NotNil(t, results[i].Result.SMTP, email) {
+				assert.NotNil(t, results[i].Result.SMTP.Error, email)
+			}
+		}
+	}
+}
+
+func TestVerifyBatch_OnResult(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com", "example.org"})
+
+	emails := []string{"invalid-address", "alice@example.com", "bob@example.org", "carol@example.com"}
+	for _, groupByDomain := range []bool{false, true} {
+		var running int32
+		emitted := map[int]BatchResult{}
+		results := v.VerifyBatch(emails, BatchOptions{GroupByDomain: groupByDomain, Concurrency: 4, OnResult: func(i int, result BatchResult) {
+			assert.Equal(t, int32(1), atomic.AddInt32(&running, 1), "the calls are serialized")
+			defer atomic.AddInt32(&running, -1)
+			_, found := emitted[i]
+			assert.False(t, found, "each result is emitted once")
+			emitted[i] = result
+		}})
+		assert.Len(t, emitted, len(emails))
+		for i, result := range results {
+			assert.Equal(t, result, emitted[i])
+		}
+	}
+}
+
+func TestVerifyBatch_Deduplicate(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown",
+		Rcpt: map[string]string{"alice@example.com": "250 2.1.5 OK"}}, []string{"example.com"})
+
+	emails := []string{"alice@example.com", "bob@example.com", " alice@Example.com", "alice@example.com", "Alice@example.com"}
+	emitted := map[int]BatchResult{}
+	results := v.VerifyBatch(emails, BatchOptions{GroupByDomain: true, Deduplicate: true, OnResult: func(i int, result BatchResult) {
+		emitted[i] = result
+	}})
+	assert.Len(t, emitted, len(emails))
+	for i, email := range emails {
+		assert.Equal(t, emitted[i], results[i])
+		assert.Equal(t, email, results[i].Email)
+		if assert.NoError(t, results[i].Err) {
+			assert.Equal(t, email, results[i].Result.Email)
+		}
+	}
+	assert.Equal(t, reachableYes, results[2].Result.Reachable)
+	assert.Equal(t, reachableNo, results[1].Result.Reachable)
+
+	// The positions of an address have their own results
+	assert.Equal(t, results[0].Result, results[3].Result)
+	assert.False(t, results[0].Result == results[3].Result)
+	assert.False(t, results[0].Result.SMTP == results[2].Result.SMTP)
+	results[0].Result.SMTP.Deliverable = false
+	assert.True(t, results[3].Result.SMTP.Deliverable)
+
+	// The local part is case-sensitive, so another case is verified on its own
+	assert.Equal(t, "Alice", results[4].Result.Syntax.Username)
+	assert.Equal(t, "alice", results[2].Result.Syntax.Username)
+
+	var rcpts int
+	for _, command := range srv.Commands() {
+		if strings.HasPrefix(strings.ToLower(command), "rcpt to:<alice@") {
+			rcpts++
+		}
+	}
+	assert.Equal(t, 2, rcpts)
+}
+
+func TestVerifyBatchWithContext_Canceled(t *testing.T) {
+	for _, opts := range []BatchOptions{{}, {GroupByDomain: true}, {GroupByDomain: true, Deduplicate: true}} {
+		v, _ := newSMTPTestVerifier(t, smtptest.Behavior{BannerDelay: 10 * time.Second}, []string{"example.com"})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		start := time.Now()
+		results := v.VerifyBatchWithContext(ctx, []string{"a@example.com", "b@example.com", "invalid"}, opts)
+		assert.True(t, time.Since(start) < 5*time.Second)
+		assert.Equal(t, context.Canceled, results[0].Err)
+		assert.Equal(t, context.Canceled, results[1].Err)
+		assert.True(t, results[0].Result.HasMxRecords)
+		// a verification which completed before ctx was done keeps its result
+		assert.NoError(t, results[2].Err)
+		assert.False(t, results[2].Result.Syntax.Valid)
+	}
+}
+
+func TestDuplicateResult(t *testing.T) {
+	v := NewVerifier()
+	ret := NewResult("user@example.com")
+	ret.Syntax = v.ParseAddress("user@example.com")
+	ret.SMTP = &SMTP{HostExists: true}
+	ret.Gravatar = &Gravatar{HasGravatar: true}
+	ret.Avatar = &Avatar{HasAvatar: true}
+	ret.DomainAuth = &DomainAuth{MTASTS: &MTASTS{}, SPF: &SPF{}, DKIM: &DKIM{}, DMARC: &DMARC{}}
+	result := BatchResult{Email: "user@example.com", Result: ret}
+
+	duplicate := v.duplicateResult(result, " user@Example.com")
+	assert.Equal(t, " user@Example.com", duplicate.Email)
+	assert.Equal(t, " user@Example.com", duplicate.Result.Email)
+	assert.Equal(t, ret.Syntax, duplicate.Result.Syntax)
+	assert.False(t, ret.SMTP == duplicate.Result.SMTP)
+	assert.False(t, ret.Gravatar == duplicate.Result.Gravatar)
+	assert.False(t, ret.Avatar == duplicate.Result.Avatar)
+	assert.False(t, ret.DomainAuth == duplicate.Result.DomainAuth)
+	assert.False(t, ret.DomainAuth.MTASTS == duplicate.Result.DomainAuth.MTASTS)
+	assert.False(t, ret.DomainAuth.SPF == duplicate.Result.DomainAuth.SPF)
+	assert.False(t, ret.DomainAuth.DKIM == duplicate.Result.DomainAuth.DKIM)
+	assert.False(t, ret.DomainAuth.DMARC == duplicate.Result.DomainAuth.DMARC)
+	assert.Equal(t, *ret.DomainAuth.SPF, *duplicate.Result.DomainAuth.SPF)
+}
+
+func TestVerifyBulk(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"})
+
+	emails := []string{"a@example.com", "b@example.com", "a@example.com", "invalid-address"}
+	results := v.VerifyBulk(emails)
+	assert.Len(t, results, len(emails))
+	assert.Equal(t, 1, srv.Connections())
+	assert.Equal(t, results[0].Result.Reachable, results[2].Result.Reachable)
+	assert.False(t, results[3].Result.Syntax.Valid)
+}
@@ -0,0 +1,62 @@
+package emailverifier
+
+import "time"
+
+// Stages of a verification which the verify timeout interrupts, see Result.TimedOutStages
+const (
+	StageMX          = "mx"          // MX records lookup
+	StageDomainAuth  = "domain_auth" // domain auth check
+	StageCatchAll    = "catch_all"   // SMTP catch-all check
+	StageDeliverable = "deliverable" // SMTP deliverability check of the address
+	StageAvatar      = "avatar"      // gravatar or avatar check
+)
+
+// SetVerifyTimeout sets the budget of each verification of Verify, zero, the default, means none.
+// When it expires, the in-flight DNS lookups, connections and SMTP commands are interrupted and Verify returns
+// the result of the completed stages without an error, with TimedOut set and the unfinished stages in TimedOutStages.
+func (v *Verifier) SetVerifyTimeout(d time.Duration) *Verifier {
+	return v.apply(WithVerifyTimeout(d))
+}
+
+// stageTimeoutError is the interruption of the stages of a verification by the verify timeout
+type stageTimeoutError struct {
+	stages []string
+	err    error
+}
+
+func (e *stageTimeoutError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stageTimeoutError) Unwrap() error {
+	return e.err
+}
+
+// timeOut records in ret that the verify timeout interrupted stages
+func timeOut(ret *Result, stages ...string) {
+	ret.TimedOut = true
+	ret.TimedOutStages = append(ret.TimedOutStages, stages...)
+}
+
+// timedOut reports whether the verify timeout interrupted stage of the verification ret
+func (r *Result) timedOut(stage string) bool {
+	for _, s := range r.TimedOutStages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// remainingStages returns the stages of a verification following the MX lookup by the enabled checks,
+// but the avatar check, which doesn't depend on the MX lookup
+func (v *Verifier) remainingStages() []string {
+	var stages []string
+	if v.domainAuthCheckEnabled {
+		stages = append(stages, StageDomainAuth)
+	}
+	if v.smtpCheckEnabled {
+		stages = append(stages, StageCatchAll, StageDeliverable)
+	}
+	return stages
+}
@@ -0,0 +1,176 @@
+package emailverifier
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestVerify_TimeoutDuringDeliverable(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithVerifyTimeout(200*time.Millisecond))
+
+	// The dial of the deliverability check hangs after the catch-all check
+	var dials int32
+	v.dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if atomic.AddInt32(&dials, 1) > 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return srv.Dial(ctx, network, addr)
+	}
+
+	start := time.Now()
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, ret.TimedOut)
+	assert.Equal(t, []string{StageDeliverable}, ret.TimedOutStages)
+	assert.True(t, ret.HasMxRecords)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	if assert.NotNil(t, ret.SMTP) {
+		assert.True(t, ret.SMTP.HostExists)
+		assert.False(t, ret.SMTP.CatchAll)
+		assert.False(t, ret.SMTP.Deliverable)
+	}
+}
+
+func TestVerify_TimeoutDuringCatchAll(t *testing.T) {
+	for _, sequential := range []bool{true, false} {
+		v, _ := newSMTPTestVerifier(t, smtptest.Behavior{BannerDelay: 10 * time.Second}, []string{"example.com"},
+			WithVerifyTimeout(100*time.Millisecond))
+		v.SetAvatarProvider(slowAvatarProvider{delay: 10 * time.Second}).EnableGravatarCheck()
+		if sequential {
+			v.EnableSequentialChecks()
+		}
+
+		start := time.Now()
+		ret, err := v.Verify("user@example.com")
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) < 5*time.Second)
+		assert.True(t, ret.TimedOut)
+		assert.Equal(t, []string{StageCatchAll, StageDeliverable, StageAvatar}, ret.TimedOutStages)
+		assert.True(t, ret.HasMxRecords)
+		assert.Nil(t, ret.SMTP)
+		assert.False(t, ret.SMTPChecked)
+		assert.False(t, ret.GravatarChecked)
+		assert.Equal(t, reachableUnknown, ret.Reachable)
+	}
+}
+
+func TestVerify_TimeoutDuringMX(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DNSDelay: 10 * time.Second}, []string{"example.com"},
+		WithVerifyTimeout(100*time.Millisecond), WithDomainAuthCheck())
+
+	start := time.Now()
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, ret.TimedOut)
+	assert.Equal(t, []string{StageMX, StageDomainAuth, StageCatchAll, StageDeliverable}, ret.TimedOutStages)
+	assert.False(t, ret.HasMxRecords)
+	assert.Nil(t, ret.DomainAuth)
+	assert.Empty(t, ret.Suggestion)
+}
+
+func TestVerify_TimeoutKeepsCompletedAvatar(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DNSDelay: 10 * time.Second}, []string{"example.com"},
+		WithVerifyTimeout(100*time.Millisecond))
+	v.SetAvatarProvider(staticAvatarProvider{avatar: &Avatar{Provider: "intranet"}}).EnableGravatarCheck()
+
+	// The avatar check runs alongside the MX lookup, so it completes within the budget
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{StageMX, StageCatchAll, StageDeliverable}, ret.TimedOutStages)
+	assert.True(t, ret.GravatarChecked)
+	assert.NotNil(t, ret.Avatar)
+}
+
+func TestVerify_WithinTimeout(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithVerifyTimeout(5*time.Second))
+
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.TimedOut)
+	assert.Empty(t, ret.TimedOutStages)
+	assert.True(t, ret.SMTP.HostExists)
+}
+
+func TestSetVerifyTimeout(t *testing.T) {
+	v := NewVerifier().SetVerifyTimeout(time.Second)
+	assert.Equal(t, time.Second, v.verifyTimeout)
+	v.SetVerifyTimeout(0)
+	assert.Zero(t, v.verifyTimeout)
+}
+
+func TestVerifyWithContext_Canceled(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{BannerDelay: 10 * time.Second}, []string{"example.com"})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	ret, err := v.VerifyWithContext(ctx, "user@example.com")
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, ret.TimedOut)
+	assert.Equal(t, []string{StageCatchAll, StageDeliverable}, ret.TimedOutStages)
+	assert.True(t, ret.HasMxRecords)
+}
+
+func TestVerifyWithContext_HungServer(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	// The server greets and answers EHLO, then never replies to MAIL
+	v.dialer = hangingDialer("MAIL")
+	// A canceled context has no deadline, the pending command fails once it's done
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	ret, err := v.VerifyWithContext(ctx, "user@example.com")
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, ret.TimedOut)
+}
+
+func TestVerifyWithContext_Completed(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithVerifyTimeout(time.Minute))
+
+	ret, err := v.VerifyWithContext(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.TimedOut)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.True(t, ret.SMTP.CatchAll)
+}
+
+func TestCheckSMTPWithContext(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{BannerDelay: 10 * time.Second}, []string{"example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := v.CheckSMTPWithContext(ctx, "example.com", "user")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestCheckMXWithContext(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DNSDelay: 10 * time.Second}, []string{"example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	mx, err := v.CheckMXWithContext(ctx, "example.com")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, mx)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	v, _ = newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	mx, err = v.CheckMXWithContext(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+}
@@ -0,0 +1,249 @@
+package emailverifier
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kinds of the cached lookups, passed to Observer.ObserveCacheHit
+const (
+	CacheKindMX       = "mx"        // MX records of a domain
+	CacheKindCatchAll = "catch_all" // catch-all check of a domain
+	CacheKindResult   = "result"    // verification of an address
+)
+
+// cacheVersion is the version of the encoding of the cached values. It's bumped whenever the encoding changes,
+// so a verifier ignores the entries another version of the package stored in a shared cache.
+const cacheVersion = 1
+
+// cacheKeyPrefix prefixes the keys of the cached values, so a cache can be shared with other data
+const cacheKeyPrefix = "emailverifier:"
+
+// Cache stores the lookups of a Verifier, like the MX records and the catch-all checks of the domains,
+// so they are shared by its verifications. An implementation backed by a shared store, like Redis,
+// shares them across processes. Its methods are called concurrently.
+// A failing cache doesn't fail the verifications, its errors only count as misses.
+type Cache interface {
+	// Get returns the value of key and whether it was found and hasn't expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key
+	Delete(ctx context.Context, key string) error
+}
+
+// cacheEnvelope is the encoding of a cached value
+type cacheEnvelope struct {
+	Version int             `json:"v"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// SetCache sets the cache of the MX records and the catch-all checks of the domains, and of the verifications
+// when the result cache TTL is set, nil disables the cache
+func (v *Verifier) SetCache(c Cache) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.cache = c
+	return v
+}
+
+// newDomainCache returns the cache of the MX records and the catch-all checks of a verifier without a cache
+func newDomainCache() *MemoryCache {
+	cache, _ := NewMemoryCache(domainCacheSize)
+	return cache
+}
+
+// cacheOf returns the cache of the values of kind, nil if none: the cache of the verifier,
+// or without one its own cache of the MX records and the catch-all checks of the domains
+func (v *Verifier) cacheOf(kind string) Cache {
+	if v.cache != nil {
+		return v.cache
+	}
+	if (kind == CacheKindMX || kind == CacheKindCatchAll) && v.domainCache != nil {
+		return v.domainCache
+	}
+	return nil
+}
+
+// cacheGet looks up the value of key of kind in the cache and decodes it into value.
+// It reports whether the value was found, an undecodable or failed lookup is a miss.
+func (v *Verifier) cacheGet(kind, key string, value interface{}) bool {
+	cache := v.cacheOf(kind)
+	if cache == nil {
+		return false
+	}
+	data, found, err := cache.Get(context.Background(), cacheKeyPrefix+kind+":"+key)
+	if err != nil {
+		v.debug(key, "cache lookup failed", "kind", kind, "error", err)
+	}
+	hit := found && err == nil && decodeCacheValue(data, value)
+	v.stats.countCacheLookup(kind, hit)
+	if v.observer != nil {
+		v.observer.ObserveCacheHit(kind, hit)
+	}
+	return hit
+}
+
+// cacheSet stores value as the value of key of kind in the cache for ttl
+func (v *Verifier) cacheSet(kind, key string, value interface{}, ttl time.Duration) {
+	cache := v.cacheOf(kind)
+	if cache == nil || ttl <= 0 {
+		return
+	}
+	data, err := encodeCacheValue(value)
+	if err == nil {
+		err = cache.Set(context.Background(), cacheKeyPrefix+kind+":"+key, data, ttl)
+	}
+	if err != nil {
+		v.debug(key, "cache store failed", "kind", kind, "error", err)
+	}
+}
+
+// encodeCacheValue encodes value with the current cache version
+func encodeCacheValue(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cacheEnvelope{Version: cacheVersion, Data: data})
+}
+
+// decodeCacheValue decodes data into value, it reports false if data isn't of the current cache version
+func decodeCacheValue(data []byte, value interface{}) bool {
+	var envelope cacheEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Version != cacheVersion {
+		return false
+	}
+	return json.Unmarshal(envelope.Data, value) == nil
+}
+
+// resultCacheKey returns the key of the cached verification of email, which is the same for the forms
+// of an address differing only by the case of the domain or surrounding spaces.
+// The local part keeps its case, which the mail server may distinguish (RFC 5321).
+func resultCacheKey(email string) string {
+	email = strings.TrimSpace(email)
+	index := strings.LastIndex(email, "@")
+	if index < 0 {
+		return email
+	}
+	return email[:index] + strings.ToLower(email[index:])
+}
+
+// MemoryCache is an in-memory LRU Cache, the default choice of a single process
+type MemoryCache struct {
+	evictions  uint64 // entries evicted when the cache was full, first so it's 64-bit aligned for the atomic operations
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element // elements of lru by key
+	lru        *list.List               // entries, the most recently used first
+	now        func() time.Time
+}
+
+// memoryCacheEntry is an entry of a MemoryCache
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an in-memory cache of at most maxEntries values,
+// which evicts the least recently used value when it's full
+func NewMemoryCache(maxEntries int) (*MemoryCache, error) {
+	if maxEntries <= 0 {
+		return nil, errors.New("the max entries of a memory cache must be positive")
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		now:        time.Now,
+	}, nil
+}
+
+// Get implements Cache
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.lru.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set implements Cache
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value, expires: c.now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+		atomic.AddUint64(&c.evictions, 1)
+	}
+	return nil
+}
+
+// Delete implements Cache
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	return nil
+}
+
+// Len returns the number of values in the cache, including the expired ones not evicted yet
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Count implements CacheCounter, the expired values not evicted yet are counted
+func (c *MemoryCache) Count(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// Evictions implements CacheCounter
+func (c *MemoryCache) Evictions() uint64 {
+	return atomic.LoadUint64(&c.evictions)
+}
+
+// remove removes the element elem of an entry, the caller holds mu
+func (c *MemoryCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryCacheEntry).key)
+}
@@ -0,0 +1,178 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// failingCache fails every operation
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (failingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func (failingCache) Delete(ctx context.Context, key string) error {
+	return errors.New("unavailable")
+}
+
+func TestMemoryCache(t *testing.T) {
+	c, err := NewMemoryCache(2)
+	assert.NoError(t, err)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(t, c.Set(ctx, "b", []byte("2"), time.Hour))
+	value, found, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+
+	// b is the least recently used entry
+	assert.NoError(t, c.Set(ctx, "c", []byte("3"), time.Hour))
+	_, found, _ = c.Get(ctx, "b")
+	assert.False(t, found)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, uint64(1), c.Evictions())
+	assert.Equal(t, 1, c.Count("c"))
+	assert.Equal(t, 2, c.Count(""))
+
+	now = now.Add(time.Minute)
+	_, found, _ = c.Get(ctx, "a")
+	assert.False(t, found)
+
+	assert.NoError(t, c.Delete(ctx, "c"))
+	_, found, _ = c.Get(ctx, "c")
+	assert.False(t, found)
+	assert.Equal(t, 0, c.Len())
+
+	_, err = NewMemoryCache(0)
+	assert.Error(t, err)
+}
+
+func TestDecodeCacheValue(t *testing.T) {
+	data, err := encodeCacheValue(&SMTP{HostExists: true, CatchAll: true})
+	assert.NoError(t, err)
+	var smtp SMTP
+	assert.True(t, decodeCacheValue(data, &smtp))
+	assert.Equal(t, SMTP{HostExists: true, CatchAll: true}, smtp)
+
+	// The entries of another version, or another encoding, are ignored
+	assert.False(t, decodeCacheValue([]byte(`{"v":2,"data":{"catch_all":true}}`), &smtp))
+	assert.False(t, decodeCacheValue([]byte(`{"catch_all":true}`), &smtp))
+	assert.False(t, decodeCacheValue([]byte(`gob`), &smtp))
+}
+
+func TestVerify_CacheMXAndCatchAll(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	o := &recordingObserver{}
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithCache(cache))
+	v.SetObserver(o)
+
+	_, err = v.Verify("user@example.com")
+	assert.NoError(t, err)
+	first := len(srv.Commands())
+
+	// The mx records and the catch-all check are cached, only the address is checked again
+	ret, err := v.Verify("other@example.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.HostExists)
+	assert.False(t, ret.SMTP.CatchAll)
+	assert.Equal(t, reachableNo, ret.Reachable)
+	var rcpts int
+	for _, command := range srv.Commands()[first:] {
+		if strings.HasPrefix(command, "RCPT") {
+			rcpts++
+		}
+	}
+	assert.Equal(t, 1, rcpts)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Contains(t, o.cacheLookups, cacheLookup{kind: CacheKindMX, hit: true})
+	assert.Contains(t, o.cacheLookups, cacheLookup{kind: CacheKindCatchAll, hit: true})
+	assert.NotContains(t, o.cacheLookups, cacheLookup{kind: CacheKindResult, hit: true})
+}
+
+func TestVerify_CacheResult(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithCache(cache), WithResultCacheTTL(time.Hour))
+
+	want, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	commands := len(srv.Commands())
+
+	ret, err := v.Verify(" user@Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, " user@Example.com", ret.Email)
+	assert.Equal(t, want.SMTP, ret.SMTP)
+	assert.Equal(t, want.Timings, ret.Timings)
+	assert.Len(t, srv.Commands(), commands)
+
+	results := v.VerifyBatch([]string{"user@example.com", "new@example.com"}, BatchOptions{GroupByDomain: true})
+	assert.Equal(t, want.SMTP, results[0].Result.SMTP)
+	assert.NoError(t, results[1].Err)
+	ret, err = v.Verify("new@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, results[1].Result.SMTP, ret.SMTP)
+}
+
+func TestVerify_CacheResultLocalPartCase(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown",
+		Rcpt: map[string]string{"john.doe@example.com": "250 2.1.5 OK"}}, []string{"example.com"},
+		WithCache(cache), WithResultCacheTTL(time.Hour))
+
+	_, err = v.Verify("john.doe@example.com")
+	assert.NoError(t, err)
+	commands := len(srv.Commands())
+
+	// The local part is case-sensitive, so another case is verified on its own
+	ret, err := v.Verify("John.Doe@example.com")
+	assert.NoError(t, err)
+	assert.Greater(t, len(srv.Commands()), commands)
+	assert.Equal(t, "John.Doe", ret.Syntax.Username)
+	commands = len(srv.Commands())
+
+	// A hit takes the address fields from the passed form
+	ret, err = v.Verify(" john.doe@EXAMPLE.com")
+	assert.NoError(t, err)
+	assert.Len(t, srv.Commands(), commands)
+	assert.Equal(t, " john.doe@EXAMPLE.com", ret.Email)
+	assert.Equal(t, "john.doe", ret.Syntax.Username)
+	assert.Equal(t, "john.doe@example.com", ret.CanonicalEmail)
+}
+
+func TestVerify_FailingCache(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithCache(failingCache{}), WithResultCacheTTL(time.Hour))
+
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.HostExists)
+	assert.True(t, ret.SMTP.CatchAll)
+}
+
+func TestWithCache_Invalid(t *testing.T) {
+	_, err := NewVerifierWithOptions(WithCache(nil))
+	assert.Error(t, err)
+	_, err = NewVerifierWithOptions(WithResultCacheTTL(-time.Second))
+	assert.Error(t, err)
+}
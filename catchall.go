@@ -0,0 +1,69 @@
+package emailverifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CatchAllError is the failure of IsCatchAll to determine whether a domain is catch-all,
+// as opposed to a domain determined not to be
+type CatchAllError struct {
+	Domain                string       // domain whose catch-all check failed
+	SkipReason            string       // why the check was skipped, see the SkipReason constants, empty if it ran
+	HostUnreachableReason string       // why the mail servers couldn't be reached, see the Unreachable constants
+	Err                   *LookupError // failure of the check, nil if it was skipped
+}
+
+func (e *CatchAllError) Error() string {
+	if e.SkipReason != "" {
+		return fmt.Sprintf("catch-all check of %s skipped: %s", e.Domain, e.SkipReason)
+	}
+	return fmt.Sprintf("catch-all check of %s failed: %v", e.Domain, e.Err)
+}
+
+func (e *CatchAllError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// IsCatchAll checks whether the mail servers of domain accept any address, until ctx is done.
+// It runs the catch-all check of CheckSMTP and shares its cache, but skips the domains of the allowlist
+// and the blocklist. It fails with a *CatchAllError when it can't tell, e.g. the mail servers are unreachable.
+func (v *Verifier) IsCatchAll(ctx context.Context, domain string) (bool, error) {
+	v = v.withContext(ctx)
+	ascii := strings.ToLower(domainToASCII(domain))
+
+	// The allowlist and blocklist are checked before any network access
+	if _, ok := v.allowlisted(ascii); ok {
+		return false, &CatchAllError{Domain: domain, SkipReason: SkipReasonAllowlisted}
+	}
+	if v.blocklisted(ascii) {
+		return false, &CatchAllError{Domain: domain, SkipReason: SkipReasonBlocklisted}
+	}
+
+	var ret SMTP
+	if err := v.catchAll(ascii, &ret); err != nil {
+		return false, &CatchAllError{Domain: domain, HostUnreachableReason: ret.HostUnreachableReason, Err: ParseSMTPError(err)}
+	}
+	return ret.CatchAll, nil
+}
+
+// catchAll performs CheckCatchAll on domain, reusing the check while it's cached.
+// IsCatchAll, VerifyDomain and the smtp check of Verify all go through it.
+func (v *Verifier) catchAll(domain string, ret *SMTP) error {
+	key := strings.ToLower(domainToASCII(domain))
+	if v.cacheGet(CacheKindCatchAll, key, ret) {
+		return nil
+	}
+	if err := v.CheckCatchAll(domain, ret); err != nil {
+		return err
+	}
+	// the reconnections belong to this check, not to the later ones reusing it
+	cached := *ret
+	cached.ConnectionRetries = 0
+	v.cacheSet(CacheKindCatchAll, key, cached, v.catchAllCacheTTL)
+	return nil
+}
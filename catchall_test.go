@@ -0,0 +1,146 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestIsCatchAll(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+
+	catchAll, err := v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.True(t, catchAll)
+}
+
+func TestIsCatchAll_NotCatchAll(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"})
+
+	catchAll, err := v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.False(t, catchAll)
+}
+
+func TestIsCatchAll_Cache(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	o := &recordingObserver{}
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithCache(cache))
+	v.SetObserver(o)
+
+	// The catch-all check of IsCatchAll is reused by CheckSMTP and the other way around
+	catchAll, err := v.IsCatchAll(context.Background(), "Example.com")
+	assert.NoError(t, err)
+	assert.False(t, catchAll)
+	first := len(srv.Commands())
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, ProbeReliability: ProbeReliabilityUnknown, FailedStage: SMTPStageRcpt, ReplyCode: 550}, smtp)
+	catchAll, err = v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.False(t, catchAll)
+	assert.Len(t, srv.Commands(), first)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Contains(t, o.cacheLookups, cacheLookup{kind: CacheKindCatchAll, hit: true})
+}
+
+func TestIsCatchAll_CacheTTL(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithCatchAllCacheTTL(time.Minute))
+	now := time.Now()
+	v.domainCache.now = func() time.Time { return now }
+
+	// Without a cache, the catch-all check is cached in memory for its TTL
+	_, err := v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	first := srv.Connections()
+	_, err = v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, first, srv.Connections())
+	assert.Equal(t, 1, v.Stats().Caches[CacheKindCatchAll].Entries)
+
+	now = now.Add(time.Minute)
+	_, err = v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, first+1, srv.Connections())
+
+	// A zero TTL disables caching the checks
+	v, srv = newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithCatchAllCacheTTL(0))
+	for i := 1; i <= 2; i++ {
+		_, err = v.IsCatchAll(context.Background(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, i, srv.Connections())
+	}
+}
+
+func TestIsCatchAll_SharedWithVerifyDomain(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"})
+
+	ret, err := v.VerifyDomain("example.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.SMTP.CatchAll)
+	catchAll, err := v.IsCatchAll(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.False(t, catchAll)
+	assert.Equal(t, 1, srv.Connections())
+}
+
+func TestIsCatchAll_Skipped(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com", "example.org"})
+	v.SetDomainAllowlist([]string{"example.com"}, reachableYes).SetDomainBlocklist([]string{"*.example.org", "example.org"})
+
+	for domain, reason := range map[string]string{
+		"example.com":      SkipReasonAllowlisted,
+		"mail.example.org": SkipReasonBlocklisted,
+		"EXAMPLE.ORG":      SkipReasonBlocklisted,
+	} {
+		catchAll, err := v.IsCatchAll(context.Background(), domain)
+		assert.False(t, catchAll)
+		var e *CatchAllError
+		if assert.True(t, errors.As(err, &e), domain) {
+			assert.Equal(t, &CatchAllError{Domain: domain, SkipReason: reason}, e)
+		}
+	}
+	assert.Empty(t, srv.Commands())
+}
+
+func TestIsCatchAll_Unreachable(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{Banner: "554 5.3.2 Service unavailable"}, []string{"example.com"})
+
+	catchAll, err := v.IsCatchAll(context.Background(), "example.com")
+	assert.False(t, catchAll)
+	var e *CatchAllError
+	if assert.True(t, errors.As(err, &e)) {
+		assert.Equal(t, "example.com", e.Domain)
+		assert.Empty(t, e.SkipReason)
+		assert.Equal(t, UnreachableBannerError, e.HostUnreachableReason)
+		assert.NotNil(t, e.Err)
+	}
+	var lookupErr *LookupError
+	assert.True(t, errors.As(err, &lookupErr))
+	assert.True(t, strings.HasPrefix(err.Error(), "catch-all check of example.com failed: "))
+}
+
+func TestIsCatchAll_Canceled(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	catchAll, err := v.IsCatchAll(ctx, "example.com")
+	assert.False(t, catchAll)
+	var e *CatchAllError
+	assert.True(t, errors.As(err, &e))
+	assert.Empty(t, srv.Commands())
+}
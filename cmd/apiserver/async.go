@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+const (
+	defaultAsyncWorkers = 10
+	defaultJobTTL       = time.Hour
+
+	// asyncQueueSize bounds the jobs waiting for a worker, further jobs are rejected with 503
+	asyncQueueSize = 1000
+
+	callbackTimeout  = 10 * time.Second // timeout of a callback request
+	callbackAttempts = 5                // attempts to deliver a callback before giving up
+	callbackBackoff  = time.Second      // delay before the second attempt, doubled for each next one
+
+	// signatureHeader is the header of the HMAC-SHA256 signature of a callback body, like "sha256=<hex>"
+	signatureHeader = "X-Verifier-Signature"
+)
+
+// Statuses of a job
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobCompleted = "completed"
+)
+
+// Statuses of the delivery of the callback of a job
+const (
+	callbackPending   = "pending"
+	callbackDelivered = "delivered"
+	callbackFailed    = "failed"
+)
+
+// errQueueFull is returned when a job is submitted while the queue is full
+var errQueueFull = errors.New("too many verifications in progress")
+
+// jobResult is the outcome of a job, the body of its callback
+type jobResult struct {
+	ID          string                `json:"id"`
+	Email       string                `json:"email"`
+	Status      string                `json:"status"`
+	Result      *emailVerifier.Result `json:"result,omitempty"`
+	Error       *apiError             `json:"error,omitempty"` // error of the verification, if it failed
+	CreatedAt   time.Time             `json:"created_at"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+}
+
+// callback is the delivery of the result of a job to its callback URL
+type callback struct {
+	URL      string `json:"url"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"` // error of the last attempt, if it failed
+}
+
+// job is an asynchronous verification of an email, polled by its id
+type job struct {
+	jobResult
+	Callback *callback `json:"callback,omitempty"` // nil without a callback URL
+	expires  time.Time // time a completed job is forgotten
+}
+
+// jobStore stores the jobs in memory, a completed job is kept for the TTL
+type jobStore struct {
+	mu        sync.Mutex
+	jobs      map[string]*job
+	ttl       time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newJobStore returns a store keeping the completed jobs for ttl
+func newJobStore(ttl time.Duration) *jobStore {
+	return &jobStore{jobs: make(map[string]*job), ttl: ttl, now: time.Now}
+}
+
+// add stores j, and forgets the expired jobs at most once per TTL
+func (s *jobStore) add(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= s.ttl {
+		for id, j := range s.jobs {
+			if s.expired(j, now) {
+				delete(s.jobs, id)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.jobs[j.ID] = j
+}
+
+// remove forgets the job id
+func (s *jobStore) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+}
+
+// get returns a copy of the unexpired job id
+func (s *jobStore) get(id string) (job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok || s.expired(j, s.now()) {
+		return job{}, false
+	}
+	return j.copy(), true
+}
+
+// update applies f to the job id and returns a copy of the updated job
+func (s *jobStore) update(id string, f func(j *job, now time.Time)) job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j := s.jobs[id]
+	f(j, s.now())
+	return j.copy()
+}
+
+// expired returns whether the completed job j has expired at now
+func (s *jobStore) expired(j *job, now time.Time) bool {
+	return j.Status == jobCompleted && !now.Before(j.expires)
+}
+
+// copy returns a copy of j which doesn't share its callback
+func (j *job) copy() job {
+	c := *j
+	if j.Callback != nil {
+		cb := *j.Callback
+		c.Callback = &cb
+	}
+	return c
+}
+
+// newJobID returns a random job id
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// asyncJobs verifies the emails of the jobs with a pool of workers, and delivers their results to their callbacks
+type asyncJobs struct {
+	store   *jobStore
+	queue   chan string // ids of the jobs waiting for a worker
+	workers int
+	secret  []byte // key of the signatures of the callbacks, unsigned if empty
+	client  *http.Client
+	backoff time.Duration // delay before the second attempt of a callback
+	verify  func(ctx context.Context, email string) verification
+}
+
+// newAsyncJobs returns the jobs of a server configured by c, verifying with verify
+func newAsyncJobs(c config, verify func(ctx context.Context, email string) verification) *asyncJobs {
+	a := &asyncJobs{
+		queue:   make(chan string, asyncQueueSize),
+		workers: c.asyncWorkers,
+		secret:  []byte(c.webhookSecret),
+		client:  &http.Client{Timeout: callbackTimeout},
+		backoff: callbackBackoff,
+		verify:  verify,
+	}
+	if a.workers <= 0 {
+		a.workers = defaultAsyncWorkers
+	}
+	ttl := c.jobTTL
+	if ttl <= 0 {
+		ttl = defaultJobTTL
+	}
+	a.store = newJobStore(ttl)
+	return a
+}
+
+// run runs the workers until ctx is done, the jobs still queued then are never verified
+func (a *asyncJobs) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < a.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case id := <-a.queue:
+					a.process(ctx, id)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// submit queues the verification of email, whose result is posted to callbackURL if not empty
+func (a *asyncJobs) submit(email, callbackURL string) (job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return job{}, err
+	}
+	j := &job{jobResult: jobResult{ID: id, Email: email, Status: jobQueued, CreatedAt: a.store.now()}}
+	if callbackURL != "" {
+		j.Callback = &callback{URL: callbackURL, Status: callbackPending}
+	}
+	a.store.add(j)
+	// The job is copied before it's queued, since a worker may update it right away
+	accepted := j.copy()
+	select {
+	case a.queue <- id:
+		return accepted, nil
+	default:
+		a.store.remove(id)
+		return job{}, errQueueFull
+	}
+}
+
+// process verifies the email of the job id, then delivers its callback
+func (a *asyncJobs) process(ctx context.Context, id string) {
+	j := a.store.update(id, func(j *job, _ time.Time) { j.Status = jobRunning })
+	v := a.verify(ctx, j.Email)
+	if ctx.Err() != nil {
+		return
+	}
+	j = a.store.update(id, func(j *job, now time.Time) {
+		j.Status = jobCompleted
+		j.Result = v.result
+		j.CompletedAt = &now
+		j.expires = now.Add(a.store.ttl)
+		switch {
+		case v.err != nil:
+			_, apiErr := newVerificationError(v.err)
+			j.Error = &apiErr
+		case !v.result.Syntax.Valid:
+			j.Error = &apiError{Code: codeInvalidSyntax, Message: "email address syntax is invalid"}
+		}
+	})
+	if j.Callback != nil {
+		a.deliver(ctx, j)
+	}
+}
+
+// deliver posts the result of the job j to its callback URL, retrying with an exponential backoff
+// after a network error, a 429 or a 5xx status
+func (a *asyncJobs) deliver(ctx context.Context, j job) {
+	body, err := json.Marshal(j.jobResult)
+	if err != nil {
+		a.store.update(j.ID, func(j *job, _ time.Time) {
+			j.Callback.Status = callbackFailed
+			j.Callback.Error = err.Error()
+		})
+		return
+	}
+
+	delay := a.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := a.post(ctx, j.Callback.URL, body)
+		last := err == nil || !retry || attempt == callbackAttempts
+		a.store.update(j.ID, func(j *job, _ time.Time) {
+			j.Callback.Attempts = attempt
+			j.Callback.Error = ""
+			if err != nil {
+				j.Callback.Error = err.Error()
+			}
+			switch {
+			case err == nil:
+				j.Callback.Status = callbackDelivered
+			case last:
+				j.Callback.Status = callbackFailed
+			}
+		})
+		if last {
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// post posts the callback body to callbackURL, it returns whether a failed delivery may succeed on a retry
+func (a *asyncJobs) post(ctx context.Context, callbackURL string, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if len(a.secret) > 0 {
+		req.Header.Set(signatureHeader, sign(a.secret, body))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return true, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5
+	return retry, fmt.Errorf("callback answered %s", resp.Status)
+}
+
+// sign returns the signature header value of body with the secret
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validCallbackURL returns an error unless s is an absolute HTTP or HTTPS URL
+func validCallbackURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("invalid callback_url, it must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// asyncVerificationRequest is the body of an asynchronous verification request
+type asyncVerificationRequest struct {
+	Email       string `json:"email"`
+	CallbackURL string `json:"callback_url"`
+}
+
+// PostAsyncVerification queues the verification of the email of the JSON body {"email": "...", "callback_url": "..."}
+// and answers 202 with its job right away. The result is posted to the optional callback URL and can be polled.
+func (s *server) PostAsyncVerification(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req asyncVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid request body", nil)
+		return
+	}
+	if req.Email == "" {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "missing email", nil)
+		return
+	}
+	if req.CallbackURL != "" {
+		if err := validCallbackURL(req.CallbackURL); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error(), nil)
+			return
+		}
+	}
+
+	j, err := s.async.submit(req.Email, req.CallbackURL)
+	if errors.Is(err, errQueueFull) {
+		writeError(w, http.StatusServiceUnavailable, codeQueueFull, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error(), nil)
+		return
+	}
+	w.Header().Set("Location", "/v1/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// GetJob answers the job of the id parameter, 404 once it expired
+func (s *server) GetJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	j, ok := s.async.store.get(ps.ByName("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, codeNotFound, "no such job", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, j)
+}
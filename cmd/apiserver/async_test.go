@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// callbackRecorder records the callbacks it receives, answering them with its statuses in turn, then 200
+type callbackRecorder struct {
+	mu         sync.Mutex
+	statuses   []int
+	bodies     []string
+	signatures []string
+	received   chan struct{}
+}
+
+func newCallbackRecorder(statuses ...int) *callbackRecorder {
+	return &callbackRecorder{statuses: statuses, received: make(chan struct{}, 10)}
+}
+
+func (c *callbackRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	c.mu.Lock()
+	c.bodies = append(c.bodies, string(body))
+	c.signatures = append(c.signatures, r.Header.Get(signatureHeader))
+	status := http.StatusOK
+	if len(c.statuses) > 0 {
+		status, c.statuses = c.statuses[0], c.statuses[1:]
+	}
+	c.mu.Unlock()
+	w.WriteHeader(status)
+	c.received <- struct{}{}
+}
+
+// startAsync returns the routes of a server verifying with v whose async workers run until the test ends
+func startAsync(t *testing.T, v verifier, c config) (*server, http.Handler) {
+	s := newServer(v, c)
+	s.async.backoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go s.async.run(ctx)
+	return s, s.routes()
+}
+
+// waitJob polls the job id until its callback is no longer pending, or it completed without a callback
+func waitJob(t *testing.T, s *server, id string) job {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		j, ok := s.async.store.get(id)
+		if ok && j.Status == jobCompleted && (j.Callback == nil || j.Callback.Status != callbackPending) {
+			return j
+		}
+	}
+	t.Fatalf("job %s didn't complete", id)
+	return job{}
+}
+
+func TestPostAsyncVerification(t *testing.T) {
+	callbacks := newCallbackRecorder()
+	ts := httptest.NewServer(callbacks)
+	defer ts.Close()
+
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Reachable: "yes", Syntax: emailVerifier.Syntax{Valid: true}}}
+	s, routes := startAsync(t, v, config{webhookSecret: "secret"})
+
+	rec := httptest.NewRecorder()
+	body := `{"email": "user@example.com", "callback_url": "` + ts.URL + `"}`
+	routes.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/verifications/async", strings.NewReader(body)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var accepted job
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &accepted))
+	assert.Equal(t, "/v1/jobs/"+accepted.ID, rec.Header().Get("Location"))
+	assert.Equal(t, "user@example.com", accepted.Email)
+
+	j := waitJob(t, s, accepted.ID)
+	assert.Equal(t, callbackDelivered, j.Callback.Status)
+	assert.Equal(t, 1, j.Callback.Attempts)
+
+	// the callback has the result and the signature of its body
+	callbacks.mu.Lock()
+	defer callbacks.mu.Unlock()
+	assert.Len(t, callbacks.bodies, 1)
+	assert.Equal(t, sign([]byte("secret"), []byte(callbacks.bodies[0])), callbacks.signatures[0])
+	var result jobResult
+	assert.NoError(t, json.Unmarshal([]byte(callbacks.bodies[0]), &result))
+	assert.Equal(t, accepted.ID, result.ID)
+	assert.Equal(t, jobCompleted, result.Status)
+	assert.Equal(t, "yes", result.Result.Reachable)
+
+	// the job can be polled as well
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/jobs/"+accepted.ID, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"completed"`)
+	assert.Contains(t, rec.Body.String(), `"reachable":"yes"`)
+}
+
+func TestPostAsyncVerification_CallbackRetries(t *testing.T) {
+	callbacks := newCallbackRecorder(http.StatusInternalServerError, http.StatusTooManyRequests)
+	ts := httptest.NewServer(callbacks)
+	defer ts.Close()
+
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	s, _ := startAsync(t, v, config{})
+	accepted, err := s.async.submit("user@example.com", ts.URL)
+	assert.NoError(t, err)
+
+	j := waitJob(t, s, accepted.ID)
+	assert.Equal(t, callbackDelivered, j.Callback.Status)
+	assert.Equal(t, 3, j.Callback.Attempts)
+	assert.Empty(t, j.Callback.Error)
+
+	// the callbacks are unsigned without a secret
+	callbacks.mu.Lock()
+	defer callbacks.mu.Unlock()
+	assert.Equal(t, []string{"", "", ""}, callbacks.signatures)
+}
+
+func TestPostAsyncVerification_CallbackFails(t *testing.T) {
+	callbacks := newCallbackRecorder(http.StatusBadRequest)
+	ts := httptest.NewServer(callbacks)
+	defer ts.Close()
+
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "invalid"}}
+	s, _ := startAsync(t, v, config{})
+	accepted, err := s.async.submit("invalid", ts.URL)
+	assert.NoError(t, err)
+
+	// a 4xx status isn't retried
+	j := waitJob(t, s, accepted.ID)
+	assert.Equal(t, callbackFailed, j.Callback.Status)
+	assert.Equal(t, 1, j.Callback.Attempts)
+	assert.Equal(t, "callback answered 400 Bad Request", j.Callback.Error)
+	assert.Equal(t, codeInvalidSyntax, j.Error.Code)
+}
+
+func TestPostAsyncVerification_BadRequest(t *testing.T) {
+	v := &stubVerifier{}
+	for _, body := range []string{
+		`[]`,
+		`{"callback_url": "https://example.com/hook"}`,
+		`{"email": "user@example.com", "callback_url": "example.com/hook"}`,
+		`{"email": "user@example.com", "callback_url": "ftp://example.com/hook"}`,
+	} {
+		code, resp := post(t, v, config{}, "/v1/verifications/async", body)
+		assert.Equal(t, http.StatusBadRequest, code, body)
+		assert.Equal(t, codeInvalidRequest, decodeError(t, resp).Error.Code, body)
+	}
+}
+
+func TestPostAsyncVerification_QueueFull(t *testing.T) {
+	s := newServer(&stubVerifier{}, config{})
+	s.async.queue = make(chan string)
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest("POST", "/v1/verifications/async", strings.NewReader(`{"email": "user@example.com"}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, codeQueueFull, decodeError(t, rec.Body.String()).Error.Code)
+	assert.Empty(t, s.async.store.jobs)
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	code, body := get(t, &stubVerifier{}, "/v1/jobs/unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, codeNotFound, decodeError(t, body).Error.Code)
+}
+
+func TestJobStore_TTL(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	store := newJobStore(time.Minute)
+	store.now = clock.now
+
+	store.add(&job{jobResult: jobResult{ID: "queued", Status: jobQueued}})
+	store.add(&job{jobResult: jobResult{ID: "completed", Status: jobQueued}})
+	store.update("completed", func(j *job, now time.Time) {
+		j.Status = jobCompleted
+		j.expires = now.Add(store.ttl)
+	})
+
+	clock.t = clock.t.Add(time.Minute)
+	_, ok := store.get("completed")
+	assert.False(t, ok)
+
+	// a job which didn't complete doesn't expire, the expired ones are forgotten on the next add
+	_, ok = store.get("queued")
+	assert.True(t, ok)
+	store.add(&job{jobResult: jobResult{ID: "next", Status: jobQueued}})
+	assert.Len(t, store.jobs, 2)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// loadAPIKeys returns the API keys of the comma separated list keys and of the file path,
+// which has a key per line, ignoring blank lines and lines starting with '#'
+func loadAPIKeys(keys, path string) ([]string, error) {
+	var apiKeys []string
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	if path == "" {
+		return apiKeys, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read api keys file: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		apiKeys = append(apiKeys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read api keys file: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// requestAPIKey returns the API key of the `Authorization: Bearer` or the `X-API-Key` header of r
+func requestAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		const prefix = "bearer "
+		if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+			return strings.TrimSpace(auth[len(prefix):])
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// requireAPIKey returns a handler calling next only for requests with one of the API keys,
+// other requests are answered with 401. Every request is allowed when there are no keys.
+func requireAPIKey(keys []string, next http.Handler) http.Handler {
+	if len(keys) == 0 {
+		return next
+	}
+
+	// the keys are compared by their digests, which have the same length whatever the key,
+	// and all of them are compared, so the time taken doesn't depend on the matching key
+	digests := make([][sha256.Size]byte, len(keys))
+	for i, key := range keys {
+		digests[i] = sha256.Sum256([]byte(key))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := requestAPIKey(r)
+		if key == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "missing api key", nil)
+			return
+		}
+
+		digest := sha256.Sum256([]byte(key))
+		match := 0
+		for i := range digests {
+			match |= subtle.ConstantTimeCompare(digest[:], digests[i][:])
+		}
+		if match != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "invalid api key", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
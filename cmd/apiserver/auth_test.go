@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// tempDir returns a temporary directory removed at the end of the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "apiserver")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	path := filepath.Join(tempDir(t), "keys")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("# rotated on 2026-10-01\nfile-key-1\n\n  file-key-2  \n"), 0600))
+
+	keys, err := loadAPIKeys(" env-key-1, ,env-key-2", path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"env-key-1", "env-key-2", "file-key-1", "file-key-2"}, keys)
+
+	keys, err = loadAPIKeys("", "")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = loadAPIKeys("", filepath.Join(tempDir(t), "missing"))
+	assert.Error(t, err)
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	routes := newServer(v, config{apiKeys: []string{"old-key", "new-key"}}).routes()
+
+	cases := []struct {
+		name   string
+		path   string
+		header http.Header
+		code   int
+	}{
+		{"bearer", "/v1/user@example.com/verification", http.Header{"Authorization": {"Bearer new-key"}}, http.StatusOK},
+		{"bearer rotated key", "/v1/user@example.com/verification", http.Header{"Authorization": {"bearer old-key"}}, http.StatusOK},
+		{"x-api-key", "/v1/verification?email=user@example.com", http.Header{"X-Api-Key": {"old-key"}}, http.StatusOK},
+		{"missing", "/v1/user@example.com/verification", nil, http.StatusUnauthorized},
+		{"invalid", "/v1/user@example.com/verification", http.Header{"Authorization": {"Bearer other-key"}}, http.StatusUnauthorized},
+		{"prefix of a key", "/v1/user@example.com/verification", http.Header{"X-Api-Key": {"new"}}, http.StatusUnauthorized},
+		{"basic", "/v1/user@example.com/verification", http.Header{"Authorization": {"Basic bmV3LWtleQ=="}}, http.StatusUnauthorized},
+		{"domain", "/v1/domain/example.com/verification", nil, http.StatusUnauthorized},
+		{"stats", "/v1/stats", nil, http.StatusUnauthorized},
+		{"stats with key", "/v1/stats", http.Header{"X-Api-Key": {"new-key"}}, http.StatusOK},
+		{"unknown route", "/v2/unknown", nil, http.StatusUnauthorized},
+		{"health", "/health", nil, http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", c.path, nil)
+		for key, values := range c.header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+
+		assert.Equal(t, c.code, rec.Code, c.name)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), c.name)
+		if c.code == http.StatusUnauthorized {
+			assert.Equal(t, codeUnauthorized, decodeError(t, rec.Body.String()).Error.Code, c.name)
+			assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer", c.name)
+		}
+	}
+}
+
+func TestRequireAPIKey_NoKeys(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+
+	code, _ := get(t, v, "/v1/user@example.com/verification")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"container/list"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// cacheEntry is a cached verification of an email
+type cacheEntry struct {
+	key     string
+	result  *emailVerifier.Result
+	err     error
+	stored  time.Time // time the verification was cached
+	expires time.Time
+}
+
+// resultCache is an LRU cache of verifications by normalized email, whose entries expire after a TTL
+type resultCache struct {
+	mu           sync.Mutex
+	ttl          time.Duration // TTL of a definitive verification
+	transientTTL time.Duration // TTL of a verification which failed transiently
+	maxEntries   int
+	entries      map[string]*list.Element // elements of lru by key
+	lru          *list.List               // entries, the most recently used first
+	now          func() time.Time
+}
+
+// newResultCache returns a cache of at most maxEntries verifications
+func newResultCache(ttl, transientTTL time.Duration, maxEntries int) *resultCache {
+	if transientTTL > ttl {
+		transientTTL = ttl
+	}
+	return &resultCache{
+		ttl:          ttl,
+		transientTTL: transientTTL,
+		maxEntries:   maxEntries,
+		entries:      make(map[string]*list.Element),
+		lru:          list.New(),
+		now:          time.Now,
+	}
+}
+
+// cacheKey returns the key of email, which is the same for the forms of an address differing
+// only by case or surrounding spaces
+func cacheKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// get returns the unexpired cached verification of email
+func (c *resultCache) get(email string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[cacheKey(email)]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry, true
+}
+
+// add caches the verification of email, evicting the least recently used entry when the cache is full.
+// An invalid address isn't cached, since its verification doesn't reach the network.
+// A result with the error of its smtp check is cached like the error.
+func (c *resultCache) add(email string, result *emailVerifier.Result, err error) {
+	if err == nil && (result == nil || !result.Syntax.Valid) {
+		return
+	}
+	ttl := c.ttl
+	if isTransient(err) || err == nil && result.SMTP != nil && result.SMTP.Error != nil && isTransient(result.SMTP.Error) {
+		ttl = c.transientTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	entry := &cacheEntry{key: cacheKey(email), result: result, err: err, stored: now, expires: now.Add(ttl)}
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes the element elem of an entry
+func (c *resultCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+// transientErrors are the messages of the lookup errors which may not recur on a later verification
+var transientErrors = map[string]bool{
+	emailVerifier.ErrTimeout:                 true,
+	emailVerifier.ErrTryAgainLater:           true,
+	emailVerifier.ErrMailboxBusy:             true,
+	emailVerifier.ErrExceededMessagingLimits: true,
+	emailVerifier.ErrTooManyRCPT:             true,
+}
+
+// isTransient returns whether err is a verification failure which may not recur, like a timeout
+// or a 4xx reply of the mail server. An unexpected error is considered transient.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var lookupErr *emailVerifier.LookupError
+	if !errors.As(err, &lookupErr) {
+		return true
+	}
+	return transientErrors[lookupErr.Message] || strings.HasPrefix(lookupErr.Details, "4")
+}
+
+// CacheStatus tells a response comes from the cache, it's exported since
+// the JSON encoding ignores the embedded pointers of unexported types
+type CacheStatus struct {
+	Cached bool  `json:"cached"`
+	Age    int64 `json:"age"` // seconds since the verification was cached
+}
+
+// status returns the cache status of a response with the entry
+func (c *resultCache) status(entry *cacheEntry) *CacheStatus {
+	return &CacheStatus{Cached: true, Age: int64(c.now().Sub(entry.stored) / time.Second)}
+}
+
+// resultFor returns the cached result as the result of email, which may differ from the cached email by case
+func (e *cacheEntry) resultFor(email string) *emailVerifier.Result {
+	if e.result == nil {
+		return nil
+	}
+	ret := *e.result
+	ret.Email = email
+	return &ret
+}
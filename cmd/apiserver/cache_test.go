@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+func TestResultCache_TTL(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	c := newResultCache(time.Hour, time.Minute, 10)
+	c.now = clock.now
+
+	valid := &emailVerifier.Result{Email: "a@example.com", Syntax: emailVerifier.Syntax{Valid: true}}
+	c.add("a@example.com", valid, nil)
+	c.add("b@example.com", nil, emailVerifier.ParseSMTPError(errors.New("i/o timeout")))
+	c.add("c@example.com", nil, emailVerifier.ParseSMTPError(errors.New("421 service not available")))
+	c.add("d@example.com", nil, emailVerifier.ParseSMTPError(errors.New("lookup example.com: no such host")))
+	c.add("invalid", &emailVerifier.Result{Email: "invalid"}, nil)
+	softFailed := &emailVerifier.Result{Email: "e@example.com", Syntax: emailVerifier.Syntax{Valid: true},
+		SMTP: &emailVerifier.SMTP{Error: emailVerifier.ParseSMTPError(errors.New("i/o timeout"))}}
+	c.add("e@example.com", softFailed, nil)
+
+	entry, ok := c.get(" A@Example.com")
+	assert.True(t, ok)
+	assert.Equal(t, valid, entry.result)
+	assert.Equal(t, " A@Example.com", entry.resultFor(" A@Example.com").Email)
+	_, ok = c.get("invalid")
+	assert.False(t, ok)
+
+	// the transient failures expire first
+	clock.t = clock.t.Add(time.Minute)
+	for email, cached := range map[string]bool{"a@example.com": true, "b@example.com": false, "c@example.com": false, "d@example.com": true, "e@example.com": false} {
+		_, ok := c.get(email)
+		assert.Equal(t, cached, ok, email)
+	}
+
+	clock.t = clock.t.Add(time.Hour)
+	for _, email := range []string{"a@example.com", "d@example.com"} {
+		_, ok := c.get(email)
+		assert.False(t, ok, email)
+	}
+	assert.Empty(t, c.entries)
+}
+
+func TestResultCache_LRU(t *testing.T) {
+	c := newResultCache(time.Hour, time.Minute, 2)
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		c.add(email, &emailVerifier.Result{Email: email, Syntax: emailVerifier.Syntax{Valid: true}}, nil)
+	}
+
+	// a is used, so b is the least recently used entry
+	_, ok := c.get("a@example.com")
+	assert.True(t, ok)
+	c.add("c@example.com", &emailVerifier.Result{Email: "c@example.com", Syntax: emailVerifier.Syntax{Valid: true}}, nil)
+
+	for email, cached := range map[string]bool{"a@example.com": true, "b@example.com": false, "c@example.com": true} {
+		_, ok := c.get(email)
+		assert.Equal(t, cached, ok, email)
+	}
+	assert.Equal(t, 2, c.lru.Len())
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, isTransient(nil))
+	assert.True(t, isTransient(errors.New("unexpected")))
+	assert.True(t, isTransient(emailVerifier.ParseSMTPError(errors.New("i/o timeout"))))
+	assert.True(t, isTransient(emailVerifier.ParseSMTPError(errors.New("450 mailbox busy"))))
+	assert.True(t, isTransient(fmt.Errorf("verify: %w", emailVerifier.ParseSMTPError(errors.New("452 too many recipients")))))
+	assert.False(t, isTransient(emailVerifier.ParseSMTPError(errors.New("550 blocked by spamhaus"))))
+	assert.False(t, isTransient(emailVerifier.ParseSMTPError(errors.New("lookup example.com: no such host"))))
+}
+
+func TestGetEmailVerification_Cached(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	s := newServer(v, config{cacheTTL: time.Hour, cacheTransientTTL: time.Minute, cacheSize: 10})
+	s.cache.now = clock.now
+	routes := s.routes()
+
+	do := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec, body
+	}
+
+	rec, body := do("/v1/user@example.com/verification")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, body, "cached")
+	assert.Empty(t, rec.Header().Get("Age"))
+
+	clock.t = clock.t.Add(90 * time.Second)
+	rec, body = do("/v1/verification?email=User@Example.com")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, true, body["cached"])
+	assert.Equal(t, float64(90), body["age"])
+	assert.Equal(t, "90", rec.Header().Get("Age"))
+	assert.Equal(t, "User@Example.com", body["email"])
+	assert.Equal(t, []string{"user@example.com"}, v.verified)
+
+	// a refresh verifies again and replaces the cached verification
+	rec, body = do("/v1/user@example.com/verification?refresh=true")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, body, "cached")
+	assert.Len(t, v.verified, 2)
+	_, body = do("/v1/user@example.com/verification")
+	assert.Equal(t, float64(0), body["age"])
+
+	rec, _ = do("/v1/user@example.com/verification?refresh=maybe")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetEmailVerification_CachedError(t *testing.T) {
+	v := &stubVerifier{
+		result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}},
+		err:    emailVerifier.ParseSMTPError(errors.New("i/o timeout")),
+	}
+	routes := newServer(v, config{cacheTTL: time.Hour, cacheTransientTTL: time.Minute, cacheSize: 10}).routes()
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/user@example.com/verification", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Nil(t, decodeError(t, rec.Body.String()).CacheStatus)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/user@example.com/verification", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	resp := decodeError(t, rec.Body.String())
+	assert.Equal(t, codeUpstreamTimeout, resp.Error.Code)
+	assert.True(t, resp.CacheStatus.Cached)
+	assert.Len(t, v.verified, 1)
+}
+
+func TestPostVerifications_Cached(t *testing.T) {
+	v := &stubVerifier{err: errors.New("lookup failed")}
+	c := config{cacheTTL: time.Hour, cacheTransientTTL: time.Minute, cacheSize: 10}
+
+	routes := newServer(v, c).routes()
+	post := func(path, body string) []map[string]interface{} {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var verifications []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &verifications))
+		return verifications
+	}
+
+	post("/v1/verifications", `["a@example.com","b@example.com"]`)
+	verifications := post("/v1/verifications", `["c@example.com","b@example.com","a@example.com"]`)
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, v.verified)
+	assert.Len(t, verifications, 3)
+	for i, email := range []string{"c@example.com", "b@example.com", "a@example.com"} {
+		assert.Equal(t, email, verifications[i]["email"])
+		assert.Equal(t, i > 0, verifications[i]["cached"] == true, email)
+	}
+
+	post("/v1/verifications?refresh=true", `["a@example.com"]`)
+	assert.Len(t, v.verified, 4)
+}
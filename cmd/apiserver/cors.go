@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	corsAllowedMethods = "GET, POST"
+	corsAllowedHeaders = "Authorization, Content-Type, X-API-Key"
+	// corsExposedHeaders are the response headers readable by a browser client besides the basic ones
+	corsExposedHeaders = "Age, Retry-After"
+	// corsMaxAge is how long a browser caches the outcome of a preflight request
+	corsMaxAge = 10 * time.Minute
+)
+
+// parseOrigins returns the origins of the comma separated list s
+func parseOrigins(s string) []string {
+	var origins []string
+	for _, origin := range strings.Split(s, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+	return origins
+}
+
+// originAllowed returns whether origin matches one of the allowed origins: "*" matches any origin,
+// an origin with a "*." host like "https://*.example.com" matches the subdomains of its domain
+// with the same scheme and port, other origins match exactly
+func originAllowed(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		i := strings.Index(pattern, "://*.")
+		if i < 0 || !strings.HasPrefix(origin, pattern[:i+3]) {
+			continue
+		}
+		host, suffix := origin[i+3:], pattern[i+4:]
+		if len(host) > len(suffix) && strings.HasSuffix(strings.ToLower(host), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cors returns a handler adding the CORS headers to the responses of next to the requests of the allowed origins,
+// and answering their preflight requests. Requests of other origins get no CORS headers, so browsers block them.
+// No CORS headers are added when there are no allowed origins.
+func cors(allowed []string, next http.Handler) http.Handler {
+	if len(allowed) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// the responses depend on the origin, so caches must not share them between origins
+		w.Header().Add("Vary", "Origin")
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" || !originAllowed(allowed, origin) {
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge/time.Second)))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
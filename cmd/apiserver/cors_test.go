@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+func TestParseOrigins(t *testing.T) {
+	assert.Equal(t, []string{"https://app.example.com", "https://*.example.org"}, parseOrigins(" https://app.example.com/, ,https://*.example.org"))
+	assert.Empty(t, parseOrigins(""))
+}
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"https://app.example.com", "https://*.example.org"}
+	cases := map[string]bool{
+		"https://app.example.com":      true,
+		"https://APP.example.com":      true,
+		"http://app.example.com":       false,
+		"https://app.example.com:8443": false,
+		"https://evil.com":             false,
+		"https://a.example.org":        true,
+		"https://a.b.example.org":      true,
+		"https://example.org":          false,
+		"https://evilexample.org":      false,
+		"http://a.example.org":         false,
+		"https://a.example.org.evil":   false,
+	}
+	for origin, ok := range cases {
+		assert.Equal(t, ok, originAllowed(allowed, origin), origin)
+	}
+	assert.True(t, originAllowed([]string{"*"}, "https://anything.test"))
+}
+
+func TestCORS(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	routes := newServer(v, config{apiKeys: []string{"key"}, corsOrigins: []string{"https://app.example.com"}}).routes()
+
+	do := func(method, origin string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/user@example.com/verification", nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+	preflight := http.Header{
+		"Access-Control-Request-Method":  {"GET"},
+		"Access-Control-Request-Headers": {"authorization"},
+	}
+
+	// the preflight of an allowed origin is answered without an api key
+	rec := do("OPTIONS", "https://app.example.com", preflight)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), "GET")
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, rec.Header()["Vary"])
+
+	rec = do("OPTIONS", "https://evil.com", preflight)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+
+	// a simple request of an allowed origin gets the CORS headers, and still requires an api key
+	rec = do("GET", "https://app.example.com", http.Header{"Authorization": {"Bearer key"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Retry-After")
+	assert.Equal(t, []string{"Origin"}, rec.Header()["Vary"])
+
+	rec = do("GET", "https://app.example.com", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = do("GET", "https://evil.com", http.Header{"Authorization": {"Bearer key"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, []string{"Origin"}, rec.Header()["Vary"])
+}
+
+func TestCORS_Disabled(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	routes := newServer(v, config{}).routes()
+
+	req := httptest.NewRequest("GET", "/v1/user@example.com/verification", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Vary"))
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// Codes of the API errors
+const (
+	codeInvalidSyntax    = "invalid_syntax"
+	codeInvalidRequest   = "invalid_request"
+	codeTooManyEmails    = "too_many_emails"
+	codeUpstreamError    = "upstream_error"
+	codeUpstreamTimeout  = "upstream_timeout"
+	codeTimeout          = "timeout"
+	codeRequestTimeout   = "request_timeout"
+	codeInternal         = "internal_error"
+	codeUnauthorized     = "unauthorized"
+	codeRateLimited      = "rate_limited"
+	codeQueueFull        = "queue_full"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+)
+
+// timeoutBody is the body of a request exceeding its timeout
+const timeoutBody = `{"error":{"code":"` + codeTimeout + `","message":"verification timed out"}}`
+
+// apiError is an error of the API
+type apiError struct {
+	Code    string `json:"code"`    // machine readable kind of the error, see the code constants
+	Message string `json:"message"` // human readable description of the error
+}
+
+// errorResponse is the body of an error response
+type errorResponse struct {
+	Error  apiError    `json:"error"`
+	Result interface{} `json:"result,omitempty"` // partial verification result, if any
+	*CacheStatus
+}
+
+// writeJSON writes v as the JSON body of a response with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		bytes, _ = json.Marshal(errorResponse{Error: apiError{Code: codeInternal, Message: err.Error()}})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(bytes)
+}
+
+// writeError writes an error response with status, result is the partial verification result or nil
+func writeError(w http.ResponseWriter, status int, code, message string, result interface{}) {
+	writeJSON(w, status, errorResponse{Error: apiError{Code: code, Message: message}, Result: result})
+}
+
+// newVerificationError returns the status and the API error of an error returned by a verification:
+// 504 for a mail server timeout or the request timeout, 502 for other SMTP and DNS failures and 500 for anything else
+func newVerificationError(err error) (int, apiError) {
+	var lookupErr *emailVerifier.LookupError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, apiError{Code: codeRequestTimeout, Message: "verification exceeded the request timeout"}
+	case errors.As(err, &lookupErr) && lookupErr.Message == emailVerifier.ErrTimeout:
+		return http.StatusGatewayTimeout, apiError{Code: codeUpstreamTimeout, Message: err.Error()}
+	case errors.As(err, &lookupErr):
+		return http.StatusBadGateway, apiError{Code: codeUpstreamError, Message: err.Error()}
+	default:
+		return http.StatusInternalServerError, apiError{Code: codeInternal, Message: err.Error()}
+	}
+}
+
+// writeVerificationError writes the error response of an error returned by a verification with its partial result,
+// cache is the cache status of a cached verification or nil
+func writeVerificationError(w http.ResponseWriter, err error, result interface{}, cache *CacheStatus) {
+	status, apiErr := newVerificationError(err)
+	writeJSON(w, status, errorResponse{Error: apiErr, Result: result, CacheStatus: cache})
+}
+
+// newRouter returns a router answering unknown routes with JSON errors
+func newRouter() *httprouter.Router {
+	router := httprouter.New()
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, codeNotFound, "no such route", nil)
+	})
+	router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed", nil)
+	})
+	return router
+}
@@ -1,41 +1,443 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/julienschmidt/httprouter"
 	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/rediscache"
 )
 
-func GetEmailVerification(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	verifier := emailVerifier.NewVerifier().EnableSMTPCheck()
-	ret, err := verifier.Verify(ps.ByName("email"))
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+// config is the configuration of the apiserver, set by flags which default to environment variables
+type config struct {
+	listenAddr string        // address the server listens on, or "unix:" and the path of a Unix socket
+	tlsCert    string        // certificate file of HTTPS, plain HTTP if empty
+	tlsKey     string        // key file of the certificate
+	smtpCheck  bool          // whether the smtp check is enabled
+	softFail   bool          // whether an error of the smtp check is answered in the result instead of failing
+	domainAuth bool          // whether the domain auth check is enabled
+	proxy      string        // SOCKS5 proxy URI of the smtp check, none if empty
+	dns        string        // comma separated nameservers of the DNS lookups, the system ones if empty
+	helloName  string        // name of the `EHLO:` SMTP command, the verifier default if empty
+	fromEmail  string        // email of the `MAIL FROM:` SMTP command, the verifier default if empty
+	timeout    time.Duration // timeout of connecting to a mail server, the verifier default if zero
+
+	maxBatch     int           // maximum number of emails of a bulk verification
+	batchTimeout time.Duration // timeout of a bulk verification request
+
+	requestTimeout time.Duration // timeout of the verification of an email, none if zero
+
+	apiKeys []string // keys required by the /v1 routes, none if empty
+
+	rateLimit float64 // requests per second of a client, unlimited if zero
+	rateBurst int     // requests of a client in a burst
+	rateBy    string  // what identifies a client: rateByIP or rateByKey, the API key when keys are required if empty
+
+	cacheTTL          time.Duration // TTL of a cached verification, the cache is disabled if zero
+	cacheTransientTTL time.Duration // TTL of a cached verification which failed transiently
+	cacheSize         int           // maximum number of cached verifications
+
+	redisAddr     string // address of the Redis server caching the lookups of the verifier, none if empty
+	redisPassword string // password of the Redis server, none if empty
+
+	asyncWorkers  int           // workers of the asynchronous verifications
+	jobTTL        time.Duration // time a completed asynchronous verification can be polled
+	webhookSecret string        // key of the signatures of the callbacks, unsigned if empty
+
+	readyDomain   string        // domain whose mail server is reached by the readiness checks
+	readyInterval time.Duration // interval of the readiness checks
+
+	writeTimeout    time.Duration // timeout of writing a response, except for streaming verifications
+	shutdownTimeout time.Duration // grace period of the requests in flight at shutdown
+
+	corsOrigins []string // origins allowed to call the API from a browser, no CORS headers if empty
+
+	metrics     bool   // whether the metrics are recorded and served
+	metricsAddr string // address of the listener of the metrics, the routes of the API if empty
+}
+
+const (
+	defaultMaxBatch     = 1000
+	defaultBatchTimeout = time.Minute
+
+	defaultWriteTimeout    = 2 * time.Minute
+	defaultShutdownTimeout = 30 * time.Second
+
+	defaultCacheTransientTTL = 30 * time.Second
+	defaultCacheSize         = 10000
+
+	// maxEmailBytes bounds the bytes of an email within the JSON body of a bulk verification
+	maxEmailBytes = 512
+
+	// streamConcurrency is the number of emails of a streaming verification verified concurrently
+	streamConcurrency = 10
+
+	// senderIdentityTimeout bounds the validation of the sender identity at startup
+	senderIdentityTimeout = 10 * time.Second
+)
+
+// parseConfig parses the config from the command line arguments args,
+// the flags default to the environment variables looked up by getenv
+func parseConfig(args []string, getenv func(string) string) (config, error) {
+	var c config
+	smtpCheck := true
+	if s := getenv("VERIFIER_SMTP_CHECK"); s != "" {
+		var err error
+		if smtpCheck, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_SMTP_CHECK %q", s)
+		}
 	}
-	if !ret.Syntax.Valid {
-		_, _ = fmt.Fprint(w, "email address syntax is invalid")
-		return
+	softFail := true
+	if s := getenv("VERIFIER_SMTP_SOFT_FAIL"); s != "" {
+		var err error
+		if softFail, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_SMTP_SOFT_FAIL %q", s)
+		}
+	}
+	var domainAuth bool
+	if s := getenv("VERIFIER_DOMAIN_AUTH_CHECK"); s != "" {
+		var err error
+		if domainAuth, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_DOMAIN_AUTH_CHECK %q", s)
+		}
+	}
+	var timeout time.Duration
+	if s := getenv("VERIFIER_TIMEOUT"); s != "" {
+		var err error
+		if timeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_TIMEOUT %q", s)
+		}
+	}
+	maxBatch := defaultMaxBatch
+	if s := getenv("VERIFIER_MAX_BATCH"); s != "" {
+		var err error
+		if maxBatch, err = strconv.Atoi(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_MAX_BATCH %q", s)
+		}
+	}
+	batchTimeout := defaultBatchTimeout
+	if s := getenv("VERIFIER_BATCH_TIMEOUT"); s != "" {
+		var err error
+		if batchTimeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_BATCH_TIMEOUT %q", s)
+		}
+	}
+	var requestTimeout time.Duration
+	if s := getenv("VERIFIER_REQUEST_TIMEOUT"); s != "" {
+		var err error
+		if requestTimeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_REQUEST_TIMEOUT %q", s)
+		}
+	}
+	rateBurst := 0
+	if s := getenv("VERIFIER_RATE_BURST"); s != "" {
+		var err error
+		if rateBurst, err = strconv.Atoi(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_RATE_BURST %q", s)
+		}
+	}
+	var cacheTTL time.Duration
+	if s := getenv("VERIFIER_CACHE_TTL"); s != "" {
+		var err error
+		if cacheTTL, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_CACHE_TTL %q", s)
+		}
+	}
+	cacheTransientTTL := defaultCacheTransientTTL
+	if s := getenv("VERIFIER_CACHE_TRANSIENT_TTL"); s != "" {
+		var err error
+		if cacheTransientTTL, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_CACHE_TRANSIENT_TTL %q", s)
+		}
+	}
+	cacheSize := defaultCacheSize
+	if s := getenv("VERIFIER_CACHE_SIZE"); s != "" {
+		var err error
+		if cacheSize, err = strconv.Atoi(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_CACHE_SIZE %q", s)
+		}
+	}
+	readyInterval := defaultReadyInterval
+	if s := getenv("VERIFIER_READY_INTERVAL"); s != "" {
+		var err error
+		if readyInterval, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_READY_INTERVAL %q", s)
+		}
+	}
+	asyncWorkers := defaultAsyncWorkers
+	if s := getenv("VERIFIER_ASYNC_WORKERS"); s != "" {
+		var err error
+		if asyncWorkers, err = strconv.Atoi(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_ASYNC_WORKERS %q", s)
+		}
+	}
+	jobTTL := defaultJobTTL
+	if s := getenv("VERIFIER_JOB_TTL"); s != "" {
+		var err error
+		if jobTTL, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_JOB_TTL %q", s)
+		}
+	}
+	readyDomain := getenv("VERIFIER_READY_DOMAIN")
+	if readyDomain == "" {
+		readyDomain = defaultReadyDomain
+	}
+	writeTimeout := defaultWriteTimeout
+	if s := getenv("VERIFIER_WRITE_TIMEOUT"); s != "" {
+		var err error
+		if writeTimeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_WRITE_TIMEOUT %q", s)
+		}
+	}
+	shutdownTimeout := defaultShutdownTimeout
+	if s := getenv("VERIFIER_SHUTDOWN_TIMEOUT"); s != "" {
+		var err error
+		if shutdownTimeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_SHUTDOWN_TIMEOUT %q", s)
+		}
+	}
+	metrics := true
+	if s := getenv("VERIFIER_METRICS"); s != "" {
+		var err error
+		if metrics, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_METRICS %q", s)
+		}
+	}
+	listenAddr := getenv("VERIFIER_LISTEN_ADDR")
+	if listenAddr == "" {
+		listenAddr = ":8080"
 	}
 
-	bytes, err := json.Marshal(ret)
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+	fs := flag.NewFlagSet("apiserver", flag.ContinueOnError)
+	fs.StringVar(&c.listenAddr, "listen", listenAddr, "listen address, or unix:path of a Unix socket (VERIFIER_LISTEN_ADDR)")
+	fs.StringVar(&c.tlsCert, "tls-cert", getenv("VERIFIER_TLS_CERT"), "certificate file of HTTPS, plain HTTP if empty (VERIFIER_TLS_CERT)")
+	fs.StringVar(&c.tlsKey, "tls-key", getenv("VERIFIER_TLS_KEY"), "key file of the certificate (VERIFIER_TLS_KEY)")
+	fs.BoolVar(&c.smtpCheck, "smtp-check", smtpCheck, "enable the smtp check (VERIFIER_SMTP_CHECK)")
+	fs.BoolVar(&c.softFail, "smtp-soft-fail", softFail, "answer an error of the smtp check in the result (VERIFIER_SMTP_SOFT_FAIL)")
+	fs.BoolVar(&c.domainAuth, "domain-auth-check", domainAuth, "enable the MTA-STS check of the domains (VERIFIER_DOMAIN_AUTH_CHECK)")
+	fs.StringVar(&c.proxy, "proxy", getenv("VERIFIER_PROXY"), "comma separated SOCKS5 or HTTP proxy URIs of the smtp check, used in turn (VERIFIER_PROXY)")
+	fs.StringVar(&c.dns, "dns", getenv("VERIFIER_DNS"), "comma separated nameservers of the DNS lookups, e.g. 8.8.8.8:53, the system ones if empty (VERIFIER_DNS)")
+	fs.StringVar(&c.helloName, "hello-name", getenv("VERIFIER_HELLO_NAME"), "name of the EHLO command (VERIFIER_HELLO_NAME)")
+	fs.StringVar(&c.fromEmail, "from-email", getenv("VERIFIER_FROM_EMAIL"), "email of the MAIL FROM command (VERIFIER_FROM_EMAIL)")
+	fs.DurationVar(&c.timeout, "timeout", timeout, "timeout of connecting to a mail server (VERIFIER_TIMEOUT)")
+	fs.IntVar(&c.maxBatch, "max-batch", maxBatch, "maximum number of emails of a bulk verification (VERIFIER_MAX_BATCH)")
+	fs.DurationVar(&c.batchTimeout, "batch-timeout", batchTimeout, "timeout of a bulk verification request (VERIFIER_BATCH_TIMEOUT)")
+	fs.DurationVar(&c.requestTimeout, "request-timeout", requestTimeout, "timeout of the verification of an email, none if zero (VERIFIER_REQUEST_TIMEOUT)")
+	// the keys themselves aren't a flag, which would expose them in the process list
+	apiKeysFile := fs.String("api-keys-file", getenv("VERIFIER_API_KEYS_FILE"), "file of the API keys, one per line (VERIFIER_API_KEYS_FILE)")
+	rateLimit := fs.String("rate-limit", getenv("VERIFIER_RATE_LIMIT"), "requests of a client per unit like 10/s, 600/m or 1000/h, unlimited if empty (VERIFIER_RATE_LIMIT)")
+	fs.IntVar(&c.rateBurst, "rate-burst", rateBurst, "requests of a client in a burst, the rate per second rounded up if zero (VERIFIER_RATE_BURST)")
+	fs.StringVar(&c.rateBy, "rate-limit-by", getenv("VERIFIER_RATE_LIMIT_BY"), "what identifies a client of the rate limit: ip or key, the API key when keys are required if empty (VERIFIER_RATE_LIMIT_BY)")
+	fs.DurationVar(&c.cacheTTL, "cache-ttl", cacheTTL, "TTL of a cached verification, no cache if zero (VERIFIER_CACHE_TTL)")
+	fs.DurationVar(&c.cacheTransientTTL, "cache-transient-ttl", cacheTransientTTL, "TTL of a cached verification which failed transiently (VERIFIER_CACHE_TRANSIENT_TTL)")
+	fs.IntVar(&c.cacheSize, "cache-size", cacheSize, "maximum number of cached verifications (VERIFIER_CACHE_SIZE)")
+	fs.StringVar(&c.redisAddr, "redis-addr", getenv("VERIFIER_REDIS_ADDR"), "address of a Redis server caching the lookups and the verifications for the servers sharing it, like localhost:6379 (VERIFIER_REDIS_ADDR)")
+	fs.IntVar(&c.asyncWorkers, "async-workers", asyncWorkers, "workers of the asynchronous verifications (VERIFIER_ASYNC_WORKERS)")
+	fs.DurationVar(&c.jobTTL, "job-ttl", jobTTL, "time a completed asynchronous verification can be polled (VERIFIER_JOB_TTL)")
+	fs.StringVar(&c.readyDomain, "ready-domain", readyDomain, "domain whose mail server is reached by the readiness checks (VERIFIER_READY_DOMAIN)")
+	fs.DurationVar(&c.readyInterval, "ready-interval", readyInterval, "interval of the readiness checks (VERIFIER_READY_INTERVAL)")
+	fs.DurationVar(&c.writeTimeout, "write-timeout", writeTimeout, "timeout of writing a response, none if zero (VERIFIER_WRITE_TIMEOUT)")
+	fs.DurationVar(&c.shutdownTimeout, "shutdown-timeout", shutdownTimeout, "grace period of the requests in flight at shutdown (VERIFIER_SHUTDOWN_TIMEOUT)")
+	corsOrigins := fs.String("cors-origins", getenv("VERIFIER_CORS_ORIGINS"), "comma separated origins allowed to call the API from a browser, like https://*.example.com (VERIFIER_CORS_ORIGINS)")
+	fs.BoolVar(&c.metrics, "metrics", metrics, "record the metrics and serve them at /metrics (VERIFIER_METRICS)")
+	fs.StringVar(&c.metricsAddr, "metrics-listen", getenv("VERIFIER_METRICS_LISTEN_ADDR"), "listen address of the metrics, the API listener if empty (VERIFIER_METRICS_LISTEN_ADDR)")
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+	c.corsOrigins = parseOrigins(*corsOrigins)
+	// the secrets aren't flags either
+	c.webhookSecret = getenv("VERIFIER_WEBHOOK_SECRET")
+	c.redisPassword = getenv("VERIFIER_REDIS_PASSWORD")
+	var err error
+	if c.apiKeys, err = loadAPIKeys(getenv("VERIFIER_API_KEYS"), *apiKeysFile); err != nil {
+		return c, err
+	}
+	if (c.tlsCert == "") != (c.tlsKey == "") {
+		return c, errors.New("the TLS certificate and key must be set together")
 	}
+	if c.maxBatch <= 0 {
+		return c, fmt.Errorf("invalid max batch %d", c.maxBatch)
+	}
+	if c.batchTimeout <= 0 {
+		return c, fmt.Errorf("invalid batch timeout %s", c.batchTimeout)
+	}
+	// a verification exceeding the request timeout must still be able to write its 504
+	if c.requestTimeout < 0 || (c.requestTimeout > 0 && c.writeTimeout > 0 && c.requestTimeout >= c.writeTimeout) {
+		return c, fmt.Errorf("invalid request timeout %s, it must be below the write timeout %s", c.requestTimeout, c.writeTimeout)
+	}
+	if *rateLimit != "" {
+		if c.rateLimit, err = parseRate(*rateLimit); err != nil {
+			return c, err
+		}
+		if c.rateBurst == 0 {
+			c.rateBurst = int(math.Ceil(c.rateLimit))
+		}
+	}
+	if c.rateBurst < 0 {
+		return c, fmt.Errorf("invalid rate burst %d", c.rateBurst)
+	}
+	switch c.rateBy {
+	case "", rateByIP:
+	case rateByKey:
+		if len(c.apiKeys) == 0 {
+			return c, errors.New("the rate limit by API key requires API keys")
+		}
+	default:
+		return c, fmt.Errorf("invalid rate limit by %q, expected ip or key", c.rateBy)
+	}
+	if c.cacheTTL < 0 || c.cacheTransientTTL < 0 {
+		return c, fmt.Errorf("invalid cache ttl %s, transient %s", c.cacheTTL, c.cacheTransientTTL)
+	}
+	if c.cacheSize <= 0 {
+		return c, fmt.Errorf("invalid cache size %d", c.cacheSize)
+	}
+	if c.asyncWorkers <= 0 {
+		return c, fmt.Errorf("invalid async workers %d", c.asyncWorkers)
+	}
+	if c.jobTTL <= 0 {
+		return c, fmt.Errorf("invalid job ttl %s", c.jobTTL)
+	}
+	if c.readyInterval <= 0 {
+		return c, fmt.Errorf("invalid ready interval %s", c.readyInterval)
+	}
+	// a bulk verification exceeding the batch timeout must still be able to write its 503
+	if c.writeTimeout < 0 || (c.writeTimeout > 0 && c.writeTimeout <= c.batchTimeout) {
+		return c, fmt.Errorf("invalid write timeout %s, it must exceed the batch timeout %s", c.writeTimeout, c.batchTimeout)
+	}
+	if c.metricsAddr != "" && !c.metrics {
+		return c, errors.New("the metrics listen address is set while the metrics are disabled")
+	}
+	if c.shutdownTimeout < 0 {
+		return c, fmt.Errorf("invalid shutdown timeout %s", c.shutdownTimeout)
+	}
+	return c, nil
+}
 
-	_, _ = fmt.Fprint(w, string(bytes))
+// newVerifier creates the verifier configured by c, with the additional options opts
+func (c config) newVerifier(opts ...emailVerifier.Option) (*emailVerifier.Verifier, error) {
+	if c.smtpCheck {
+		opts = append(opts, emailVerifier.WithSMTPCheck())
+	}
+	if c.softFail {
+		opts = append(opts, emailVerifier.WithSMTPSoftFail())
+	}
+	if c.domainAuth {
+		opts = append(opts, emailVerifier.WithDomainAuthCheck())
+	}
+	if c.proxy != "" {
+		opts = append(opts, emailVerifier.WithProxies(strings.Split(c.proxy, ",")...))
+	}
+	if c.dns != "" {
+		opts = append(opts, emailVerifier.WithNameservers(strings.Split(c.dns, ",")...))
+	}
+	if c.helloName != "" {
+		opts = append(opts, emailVerifier.WithHelloName(c.helloName))
+	}
+	if c.fromEmail != "" {
+		opts = append(opts, emailVerifier.WithFromEmail(c.fromEmail))
+	}
+	if c.timeout != 0 {
+		opts = append(opts, emailVerifier.WithTimeout(c.timeout))
+	}
+	if c.redisAddr != "" {
+		opts = append(opts, emailVerifier.WithCache(rediscache.New(c.redisAddr, rediscache.Options{Password: c.redisPassword})))
+		if c.cacheTTL > 0 {
+			opts = append(opts, emailVerifier.WithResultCacheTTL(c.cacheTTL))
+		}
+	}
+	return emailVerifier.NewVerifierWithOptions(opts...)
+}
+
+// warnSenderIdentity logs the problems of the identity the smtp check of v presents to the mail servers,
+// which make them reject every check, without refusing to start
+func warnSenderIdentity(v *emailVerifier.Verifier, logf func(format string, a ...interface{})) {
+	ctx, cancel := context.WithTimeout(context.Background(), senderIdentityTimeout)
+	defer cancel()
 
+	var identityErr *emailVerifier.SenderIdentityError
+	switch err := v.ValidateSenderIdentity(ctx); {
+	case errors.As(err, &identityErr):
+		for _, problem := range identityErr.Problems {
+			logf("warning: %s, mail servers may reject the smtp checks", problem.Message)
+		}
+	case err != nil:
+		logf("warning: validate the sender identity: %v", err)
+	}
 }
 
 func main() {
-	router := httprouter.New()
+	c, err := parseConfig(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	}
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		os.Exit(exitInvalidConfig)
+	}
+	verifier, err := c.newVerifier()
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		os.Exit(exitInvalidConfig)
+	}
+	if c.smtpCheck {
+		warnSenderIdentity(verifier, log.Printf)
+	}
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		os.Exit(exitInvalidConfig)
+	}
+	ln, err := listen(c.listenAddr)
+	if err != nil {
+		log.Printf("listen: %v", err)
+		os.Exit(exitServeError)
+	}
 
-	router.GET("/v1/:email/verification", GetEmailVerification)
+	ctx := signalContext()
+	s := newServer(verifier, c)
+	if s.dryRunVerifier, err = c.newVerifier(emailVerifier.WithSMTPDryRun()); err != nil {
+		log.Printf("invalid configuration: %v", err)
+		os.Exit(exitInvalidConfig)
+	}
+	go s.readiness.run(ctx)
+	go s.async.run(ctx)
+	if s.metrics != nil {
+		verifier.SetObserver(s.metrics)
+	}
+	if c.metricsAddr != "" {
+		metricsLn, err := listen(c.metricsAddr)
+		if err != nil {
+			log.Printf("listen metrics: %v", err)
+			os.Exit(exitServeError)
+		}
+		log.Printf("serving metrics on %s", metricsLn.Addr())
+		go func() {
+			metricsServer := &http.Server{Handler: s.metrics, ReadHeaderTimeout: readHeaderTimeout}
+			if err := serve(ctx, metricsServer, metricsLn, c.shutdownTimeout); err != nil {
+				log.Printf("serve metrics: %v", err)
+			}
+		}()
+	}
 
-	log.Fatal(http.ListenAndServe(":8080", router))
+	log.Printf("listening on %s", ln.Addr())
+	switch err := serve(ctx, newHTTPServer(s.routes(), c, tlsConfig), ln, c.shutdownTimeout); {
+	case err == nil:
+		log.Print("shut down")
+		os.Exit(exitOK)
+	case errors.Is(err, errShutdownTimeout):
+		log.Printf("shut down: %v", err)
+		os.Exit(exitShutdownTimeout)
+	default:
+		log.Printf("serve: %v", err)
+		os.Exit(exitServeError)
+	}
 }
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// env returns a getenv func of the variables vars
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestParseConfig_Defaults(t *testing.T) {
+	c, err := parseConfig(nil, env(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, config{
+		listenAddr:   ":8080",
+		smtpCheck:    true,
+		softFail:     true,
+		maxBatch:     defaultMaxBatch,
+		batchTimeout: defaultBatchTimeout,
+
+		cacheTransientTTL: defaultCacheTransientTTL,
+		cacheSize:         defaultCacheSize,
+
+		asyncWorkers: defaultAsyncWorkers,
+		jobTTL:       defaultJobTTL,
+
+		readyDomain:   defaultReadyDomain,
+		readyInterval: defaultReadyInterval,
+
+		writeTimeout:    defaultWriteTimeout,
+		shutdownTimeout: defaultShutdownTimeout,
+
+		metrics: true,
+	}, c)
+
+	_, err = c.newVerifier()
+	assert.NoError(t, err)
+}
+
+func TestParseConfig_EnvAndFlags(t *testing.T) {
+	c, err := parseConfig(
+		[]string{"-listen", ":9090", "-timeout", "5s", "-max-batch", "10"},
+		env(map[string]string{
+			"VERIFIER_LISTEN_ADDR":         ":8081",
+			"VERIFIER_SMTP_CHECK":          "false",
+			"VERIFIER_SMTP_SOFT_FAIL":      "false",
+			"VERIFIER_DOMAIN_AUTH_CHECK":   "true",
+			"VERIFIER_PROXY":               "socks5://127.0.0.1:1080",
+			"VERIFIER_DNS":                 "8.8.8.8:53,1.1.1.1",
+			"VERIFIER_HELLO_NAME":          "mail.example.com",
+			"VERIFIER_FROM_EMAIL":          "probe@example.com",
+			"VERIFIER_TIMEOUT":             "10s",
+			"VERIFIER_MAX_BATCH":           "100",
+			"VERIFIER_BATCH_TIMEOUT":       "2m",
+			"VERIFIER_REQUEST_TIMEOUT":     "15s",
+			"VERIFIER_API_KEYS":            "key-1,key-2",
+			"VERIFIER_RATE_LIMIT":          "600/m",
+			"VERIFIER_CACHE_TTL":           "1h",
+			"VERIFIER_CACHE_SIZE":          "100",
+			"VERIFIER_REDIS_ADDR":          "localhost:6379",
+			"VERIFIER_REDIS_PASSWORD":      "password",
+			"VERIFIER_ASYNC_WORKERS":       "4",
+			"VERIFIER_JOB_TTL":             "10m",
+			"VERIFIER_WEBHOOK_SECRET":      "secret",
+			"VERIFIER_READY_DOMAIN":        "example.com",
+			"VERIFIER_WRITE_TIMEOUT":       "3m",
+			"VERIFIER_SHUTDOWN_TIMEOUT":    "5s",
+			"VERIFIER_CORS_ORIGINS":        "https://app.example.com",
+			"VERIFIER_METRICS_LISTEN_ADDR": ":9100",
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, config{
+		listenAddr: ":9090",
+		domainAuth: true,
+		proxy:      "socks5://127.0.0.1:1080",
+		dns:        "8.8.8.8:53,1.1.1.1",
+		helloName:  "mail.example.com",
+		fromEmail:  "probe@example.com",
+		timeout:    5 * time.Second,
+
+		maxBatch:     10,
+		batchTimeout: 2 * time.Minute,
+
+		requestTimeout: 15 * time.Second,
+
+		apiKeys: []string{"key-1", "key-2"},
+
+		rateLimit: 10,
+		rateBurst: 10,
+
+		cacheTTL:          time.Hour,
+		cacheTransientTTL: defaultCacheTransientTTL,
+		cacheSize:         100,
+
+		redisAddr:     "localhost:6379",
+		redisPassword: "password",
+
+		asyncWorkers:  4,
+		jobTTL:        10 * time.Minute,
+		webhookSecret: "secret",
+
+		readyDomain:   "example.com",
+		readyInterval: defaultReadyInterval,
+
+		writeTimeout:    3 * time.Minute,
+		shutdownTimeout: 5 * time.Second,
+
+		corsOrigins: []string{"https://app.example.com"},
+
+		metrics:     true,
+		metricsAddr: ":9100",
+	}, c)
+
+	c, err = parseConfig([]string{"-rate-limit", "0.5/s", "-rate-burst", "3"}, env(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, c.rateLimit)
+	assert.Equal(t, 3, c.rateBurst)
+
+	c, err = parseConfig([]string{"-rate-limit", "1/s", "-rate-limit-by", "ip"}, env(map[string]string{"VERIFIER_API_KEYS": "key"}))
+	assert.NoError(t, err)
+	assert.Equal(t, rateByIP, c.rateBy)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	_, err := parseConfig(nil, env(map[string]string{"VERIFIER_SMTP_CHECK": "maybe"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_SMTP_SOFT_FAIL": "maybe"}))
+	assert.Error(t, err)
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_DOMAIN_AUTH_CHECK": "maybe"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_TIMEOUT": "soon"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_MAX_BATCH": "many"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-max-batch", "0"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-api-keys-file", "/nonexistent/keys"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_RATE_LIMIT": "fast"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-rate-burst", "-1"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-rate-limit-by", "user"}, env(nil))
+	assert.Error(t, err)
+
+	// there is no key to limit without API keys
+	_, err = parseConfig([]string{"-rate-limit-by", "key"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_CACHE_TTL": "long"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-cache-size", "0"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_ASYNC_WORKERS": "some"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-async-workers", "0"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-job-ttl", "0s"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-ready-interval", "0s"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-write-timeout", "30s"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_REQUEST_TIMEOUT": "soon"}))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-request-timeout", "3m"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-tls-cert", "cert.pem"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-metrics=false", "-metrics-listen", ":9100"}, env(nil))
+	assert.Error(t, err)
+
+	_, err = parseConfig([]string{"-unknown"}, env(nil))
+	assert.Error(t, err)
+}
+
+func TestConfig_NewVerifierInvalid(t *testing.T) {
+	_, err := config{proxy: "ftp://127.0.0.1:8080"}.newVerifier()
+	assert.Error(t, err)
+
+	_, err = config{dns: "dns.google"}.newVerifier()
+	assert.Error(t, err)
+
+	_, err = config{fromEmail: "not an email"}.newVerifier()
+	assert.Error(t, err)
+}
+
+func TestWarnSenderIdentity(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	newVerifier := func(fromEmail, helloName string) *emailVerifier.Verifier {
+		v, err := emailVerifier.NewVerifierWithOptions(emailVerifier.WithSMTPCheck(), emailVerifier.WithResolver(srv.Resolver()),
+			emailVerifier.WithFromEmail(fromEmail), emailVerifier.WithHelloName(helloName))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return v
+	}
+	var logs []string
+	logf := func(format string, a ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, a...))
+	}
+
+	warnSenderIdentity(newVerifier("verify@example.com", "mx.example.com"), logf)
+	assert.Empty(t, logs)
+
+	warnSenderIdentity(newVerifier("verify@example.org", "localhost"), logf)
+	if assert.Len(t, logs, 2) {
+		assert.Contains(t, logs[0], "example.org")
+		assert.Contains(t, logs[1], `"localhost"`)
+	}
+}
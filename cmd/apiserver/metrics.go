@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// Metrics of the apiserver, in the Prometheus text exposition format.
+// The names and labels are stable, dashboards and alerts depend on them.
+//
+//	emailverifier_http_requests_total{method, route, status}        requests answered by the server
+//	emailverifier_http_request_duration_seconds{method, route}      histogram of the durations of the requests
+//	emailverifier_verifications_total{outcome}                      verifications by outcome: yes, no, unknown, invalid, skipped or error
+//	emailverifier_verification_stage_duration_seconds{stage}        histogram of the durations of the stages of the verifications:
+//	                                                                syntax, mx, catch_all, deliverable or total
+//	emailverifier_smtp_dials_total{result}                          connection attempts to mail servers by result:
+//	                                                                ok, timeout, refused, no_such_host, blocked or other
+//	emailverifier_smtp_dial_duration_seconds                        histogram of the durations of the connection attempts
+//	emailverifier_cache_lookups_total{cache, result}                lookups in a cache by result: hit or miss
+//	emailverifier_smtp_unreachable_total{reason}                    verifications whose mail servers were unreachable by reason,
+//	                                                                e.g. connect_timeout or banner_error
+//	emailverifier_smtp_connections_total{result}                    connections of the verifier to mail servers by result:
+//	                                                                opened, reused or failed
+//	emailverifier_cache_entries{cache}                              values in the cache of the verifier by kind, if it counts them
+//	emailverifier_list_entries{list}                                entries of the disposable, free and role lists
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// durationBuckets are the upper bounds in seconds of the buckets of the duration histograms
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metric is a family of series written in the exposition format
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// labelKey joins the values of labels into the key of a series
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// formatLabels returns the label set of names and values, extra is appended as is
+func formatLabels(names, values []string, extra string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeLabel escapes a label value of the exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats a sample value of the exposition format
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// counterVec is a family of counters partitioned by labels
+type counterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*counter
+}
+
+type counter struct {
+	values []string
+	value  float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, series: make(map[string]*counter)}
+}
+
+// inc increments the counter of the label values
+func (c *counterVec) inc(values ...string) {
+	key := labelKey(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counter{values: values}
+		c.series[key] = s
+	}
+	s.value++
+}
+
+func (c *counterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, s.values, ""), formatFloat(s.value))
+	}
+}
+
+// histogramVec is a family of histograms partitioned by labels
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	values []string
+	counts []uint64 // observations per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogram)}
+}
+
+// observe adds the duration d to the histogram of the label values
+func (h *histogramVec) observe(d time.Duration, values ...string) {
+	seconds := d.Seconds()
+	key := labelKey(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, seconds); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += seconds
+}
+
+func (h *histogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			le := `le="` + formatFloat(bound) + `"`
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.values, le), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.values, `le="+Inf"`), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, s.values, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, s.values, ""), s.count)
+	}
+}
+
+// sortedKeys returns the keys of series in order, so the output is stable
+func sortedKeys(series interface{}) []string {
+	var keys []string
+	switch m := series.(type) {
+	case map[string]*counter:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]*histogram:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metrics are the metrics of the apiserver, it observes the verifier as its Observer
+type metrics struct {
+	requests         *counterVec
+	requestDuration  *histogramVec
+	verifications    *counterVec
+	stageDuration    *histogramVec
+	smtpDials        *counterVec
+	smtpDialDuration *histogramVec
+	cacheLookups     *counterVec
+	smtpUnreachable  *counterVec
+	all              []metric
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		requests: newCounterVec("emailverifier_http_requests_total",
+			"Requests answered by the server.", "method", "route", "status"),
+		requestDuration: newHistogramVec("emailverifier_http_request_duration_seconds",
+			"Durations of the requests.", durationBuckets, "method", "route"),
+		verifications: newCounterVec("emailverifier_verifications_total",
+			"Verifications by outcome.", "outcome"),
+		stageDuration: newHistogramVec("emailverifier_verification_stage_duration_seconds",
+			"Durations of the stages of the verifications.", durationBuckets, "stage"),
+		smtpDials: newCounterVec("emailverifier_smtp_dials_total",
+			"Connection attempts to mail servers by result.", "result"),
+		smtpDialDuration: newHistogramVec("emailverifier_smtp_dial_duration_seconds",
+			"Durations of the connection attempts to mail servers.", durationBuckets),
+		cacheLookups: newCounterVec("emailverifier_cache_lookups_total",
+			"Lookups in a cache by result.", "cache", "result"),
+		smtpUnreachable: newCounterVec("emailverifier_smtp_unreachable_total",
+			"Verifications whose mail servers were unreachable by reason.", "reason"),
+	}
+	m.all = []metric{m.requests, m.requestDuration, m.verifications, m.stageDuration, m.smtpDials, m.smtpDialDuration,
+		m.cacheLookups, m.smtpUnreachable}
+	return m
+}
+
+// observeStats adds the statistics returned by stats at each scrape to the metrics
+func (m *metrics) observeStats(stats func() emailVerifier.Stats) {
+	m.all = append(m.all, statsMetric(stats))
+}
+
+// statsMetric writes the statistics of a verifier, which it counts itself, at each scrape
+type statsMetric func() emailVerifier.Stats
+
+func (f statsMetric) write(w *bufio.Writer) {
+	stats := f()
+
+	fmt.Fprintf(w, "# HELP emailverifier_smtp_connections_total Connections of the verifier to mail servers by result.\n"+
+		"# TYPE emailverifier_smtp_connections_total counter\n")
+	for _, c := range []struct {
+		result string
+		value  uint64
+	}{
+		{"failed", stats.SMTP.ConnectionsFailed},
+		{"opened", stats.SMTP.ConnectionsOpened},
+		{"reused", stats.SMTP.ConnectionsReused},
+	} {
+		fmt.Fprintf(w, "emailverifier_smtp_connections_total{result=\"%s\"} %d\n", escapeLabel(c.result), c.value)
+	}
+
+	fmt.Fprintf(w, "# HELP emailverifier_cache_entries Values in the cache of the verifier by kind.\n"+
+		"# TYPE emailverifier_cache_entries gauge\n")
+	kinds := make([]string, 0, len(stats.Caches))
+	for kind := range stats.Caches {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		// a cache which doesn't count its values has no series
+		if entries := stats.Caches[kind].Entries; entries >= 0 {
+			fmt.Fprintf(w, "emailverifier_cache_entries{cache=\"%s\"} %d\n", escapeLabel(kind), entries)
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP emailverifier_list_entries Entries of the domain lists.\n"+
+		"# TYPE emailverifier_list_entries gauge\n")
+	names := make([]string, 0, len(stats.Lists))
+	for name := range stats.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "emailverifier_list_entries{list=\"%s\"} %d\n", escapeLabel(name), stats.Lists[name].Entries)
+	}
+}
+
+// ObserveVerification implements emailVerifier.Observer, the domain isn't a label since it's unbounded
+func (m *metrics) ObserveVerification(domain string, outcome string, d time.Duration) {
+	m.verifications.inc(outcome)
+}
+
+// ObserveSMTPDial implements emailVerifier.Observer
+func (m *metrics) ObserveSMTPDial(host string, err error, d time.Duration) {
+	m.smtpDials.inc(dialResult(err))
+	m.smtpDialDuration.observe(d)
+}
+
+// ObserveCacheHit implements emailVerifier.Observer
+func (m *metrics) ObserveCacheHit(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.inc(kind, result)
+}
+
+// observeResult adds the timings of a verification to the stage histograms, and counts its unreachable mail servers
+func (m *metrics) observeResult(ret *emailVerifier.Result) {
+	m.observeTimings(ret.Timings)
+	if ret.SMTP != nil && ret.SMTP.HostUnreachableReason != "" {
+		m.smtpUnreachable.inc(ret.SMTP.HostUnreachableReason)
+	}
+}
+
+// observeTimings adds the durations of the stages of a verification which ran to the stage histograms
+func (m *metrics) observeTimings(t emailVerifier.Timings) {
+	stages := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"syntax", t.Syntax},
+		{"mx", t.MX},
+		{"catch_all", t.CatchAll},
+		{"deliverable", t.Deliverable},
+		{"total", t.Total},
+	}
+	for _, stage := range stages {
+		if stage.d > 0 {
+			m.stageDuration.observe(stage.d, stage.name)
+		}
+	}
+}
+
+// dialResult returns the result label of a connection attempt which failed with err, "ok" if nil
+func dialResult(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	switch lookupErr := emailVerifier.ParseSMTPError(err); {
+	case lookupErr == nil:
+		return "other"
+	case lookupErr.Message == emailVerifier.ErrTimeout:
+		return "timeout"
+	case lookupErr.Message == emailVerifier.ErrNoSuchHost:
+		return "no_such_host"
+	case lookupErr.Message == emailVerifier.ErrBlocked:
+		return "blocked"
+	case strings.Contains(err.Error(), "connection refused"):
+		return "refused"
+	default:
+		return "other"
+	}
+}
+
+// writeTo writes the metrics in the exposition format
+func (m *metrics) writeTo(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, metric := range m.all {
+		metric.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics to a Prometheus scrape
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	_ = m.writeTo(w)
+}
+
+// statusRecorder records the status of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush lets the streaming verifications flush through the recorder
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.status == 0 {
+			r.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// EnableFullDuplex lets the streaming verifications read the body while writing the response
+func (r *statusRecorder) EnableFullDuplex() error {
+	if d, ok := r.ResponseWriter.(interface{ EnableFullDuplex() error }); ok {
+		return d.EnableFullDuplex()
+	}
+	return http.ErrNotSupported
+}
+
+// SetWriteDeadline lets the streaming verifications lift the write timeout
+func (r *statusRecorder) SetWriteDeadline(deadline time.Time) error {
+	if d, ok := r.ResponseWriter.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return d.SetWriteDeadline(deadline)
+	}
+	return http.ErrNotSupported
+}
+
+// instrument returns a handler recording the requests of next in the metrics,
+// with their route among patterns, "other" for a path matching none of them
+func (m *metrics) instrument(patterns []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		method, route := methodLabel(r.Method), matchRoute(patterns, r.URL.Path)
+		m.requests.inc(method, route, strconv.Itoa(rec.status))
+		m.requestDuration.observe(time.Since(start), method, route)
+	})
+}
+
+// matchRoute returns the pattern among patterns matched by path, whose ":name" segments match any segment.
+// The route of a request is a label, so it must not be the unbounded path itself.
+func matchRoute(patterns []string, path string) string {
+	segments := strings.Split(path, "/")
+	for _, pattern := range patterns {
+		patternSegments := strings.Split(pattern, "/")
+		if len(patternSegments) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, segment := range patternSegments {
+			if segment != segments[i] && !(strings.HasPrefix(segment, ":") && segments[i] != "") {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return pattern
+		}
+	}
+	return "other"
+}
+
+// methodLabel returns the method label of a request, "other" for a method the server doesn't know,
+// since a client may send any method
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// scrape returns the metrics served by routes
+func scrape(t *testing.T, routes http.Handler) string {
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, metricsContentType, rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestMetrics_Requests(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{
+		Email:   "user@example.com",
+		Syntax:  emailVerifier.Syntax{Valid: true},
+		Timings: emailVerifier.Timings{Syntax: time.Millisecond, MX: 20 * time.Millisecond, Total: 30 * time.Millisecond},
+	}, domainResult: &emailVerifier.DomainResult{Domain: "example.com", Valid: true}}
+	routes := newServer(v, config{metrics: true, apiKeys: []string{"key"}}).routes()
+
+	for _, path := range []string{"/v1/a@example.com/verification", "/v1/b@example.com/verification", "/v1/domain/example.com/verification", "/unknown"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-API-Key", "key")
+		routes.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/c@example.com/verification", nil))
+	routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/healthz", nil))
+
+	body := scrape(t, routes)
+	for _, line := range []string{
+		"# TYPE emailverifier_http_requests_total counter",
+		`emailverifier_http_requests_total{method="GET",route="/v1/:email/verification",status="200"} 2`,
+		`emailverifier_http_requests_total{method="GET",route="/v1/:email/verification",status="401"} 1`,
+		`emailverifier_http_requests_total{method="GET",route="/v1/domain/:domain/verification",status="200"} 1`,
+		`emailverifier_http_requests_total{method="GET",route="other",status="404"} 1`,
+		`emailverifier_http_requests_total{method="other",route="/healthz",status="405"} 1`,
+		"# TYPE emailverifier_http_request_duration_seconds histogram",
+		`emailverifier_http_request_duration_seconds_count{method="GET",route="/v1/:email/verification"} 3`,
+		`emailverifier_verification_stage_duration_seconds_bucket{stage="mx",le="0.01"} 0`,
+		`emailverifier_verification_stage_duration_seconds_bucket{stage="mx",le="0.025"} 2`,
+		`emailverifier_verification_stage_duration_seconds_bucket{stage="mx",le="+Inf"} 2`,
+		`emailverifier_verification_stage_duration_seconds_sum{stage="mx"} 0.04`,
+		`emailverifier_verification_stage_duration_seconds_count{stage="total"} 2`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	// the stages which didn't run aren't observed
+	assert.NotContains(t, body, `stage="catch_all"`)
+}
+
+func TestMetrics_Observer(t *testing.T) {
+	m := newMetrics()
+	m.ObserveVerification("example.com", "yes", time.Second)
+	m.ObserveVerification("example.org", "yes", time.Second)
+	m.ObserveVerification("example.net", emailVerifier.OutcomeError, time.Second)
+	m.ObserveSMTPDial("mx.example.com", nil, 10*time.Millisecond)
+	m.ObserveSMTPDial("mx.example.com", errors.New("dial tcp 192.0.2.1:25: i/o timeout"), time.Second)
+	m.ObserveSMTPDial("mx.example.com", errors.New("dial tcp 192.0.2.1:25: connect: connection refused"), time.Millisecond)
+	m.ObserveCacheHit("mx", true)
+	m.ObserveCacheHit("mx", false)
+
+	var b strings.Builder
+	assert.NoError(t, m.writeTo(&b))
+	for _, line := range []string{
+		`emailverifier_verifications_total{outcome="error"} 1`,
+		`emailverifier_verifications_total{outcome="yes"} 2`,
+		`emailverifier_smtp_dials_total{result="ok"} 1`,
+		`emailverifier_smtp_dials_total{result="refused"} 1`,
+		`emailverifier_smtp_dials_total{result="timeout"} 1`,
+		`emailverifier_smtp_dial_duration_seconds_count 3`,
+		`emailverifier_cache_lookups_total{cache="mx",result="hit"} 1`,
+		`emailverifier_cache_lookups_total{cache="mx",result="miss"} 1`,
+	} {
+		assert.Contains(t, b.String(), line+"\n")
+	}
+	// the domains are no labels
+	assert.NotContains(t, b.String(), "example.com")
+}
+
+func TestMetrics_Stats(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{
+		Email: "user@example.com",
+		SMTP:  &emailVerifier.SMTP{HostUnreachableReason: emailVerifier.UnreachableConnectTimeout},
+	}, stats: emailVerifier.Stats{
+		Caches: map[string]emailVerifier.CacheStats{
+			emailVerifier.CacheKindMX:     {Entries: 3},
+			emailVerifier.CacheKindResult: {Entries: -1},
+		},
+		Lists: map[string]emailVerifier.ListStats{emailVerifier.ListFree: {Entries: 42}},
+		SMTP:  emailVerifier.SMTPStats{ConnectionsOpened: 5, ConnectionsReused: 2},
+	}}
+	routes := newServer(v, config{metrics: true}).routes()
+	routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/user@example.com/verification", nil))
+
+	body := scrape(t, routes)
+	for _, line := range []string{
+		`emailverifier_smtp_unreachable_total{reason="connect_timeout"} 1`,
+		"# TYPE emailverifier_smtp_connections_total counter",
+		`emailverifier_smtp_connections_total{result="failed"} 0`,
+		`emailverifier_smtp_connections_total{result="opened"} 5`,
+		`emailverifier_smtp_connections_total{result="reused"} 2`,
+		"# TYPE emailverifier_cache_entries gauge",
+		`emailverifier_cache_entries{cache="mx"} 3`,
+		"# TYPE emailverifier_list_entries gauge",
+		`emailverifier_list_entries{list="free"} 42`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	// a cache which doesn't count its values has no series
+	assert.NotContains(t, body, `emailverifier_cache_entries{cache="result"}`)
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	routes := newServer(&stubVerifier{}, config{}).routes()
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotEqual(t, http.StatusOK, rec.Code)
+
+	// the metrics on their own listener aren't served by the routes
+	routes = newServer(&stubVerifier{}, config{metrics: true, metricsAddr: ":9100"}).routes()
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMatchRoute(t *testing.T) {
+	patterns := []string{"/v1/domain/:domain/verification", "/v1/verification", "/v1/:email/verification"}
+	cases := map[string]string{
+		"/v1/domain/example.com/verification": "/v1/domain/:domain/verification",
+		"/v1/verification":                    "/v1/verification",
+		"/v1/user@example.com/verification":   "/v1/:email/verification",
+		"/v1//verification":                   "other",
+		"/v1/user@example.com":                "other",
+	}
+	for path, route := range cases {
+		assert.Equal(t, route, matchRoute(patterns, path), path)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\nd`, escapeLabel("a\\b\"c\nd"))
+}
+
+func TestMetrics_Stream(t *testing.T) {
+	v := &gatedVerifier{gate: make(chan struct{})}
+	close(v.gate)
+	ts := httptest.NewServer(newServer(v, config{metrics: true}).routes())
+	defer ts.Close()
+
+	// the streaming verifications read the body while writing through the recorder
+	resp, err := http.Post(ts.URL+"/v1/verifications/stream", "text/plain", strings.NewReader("a@example.com\nb@example.com\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(body), "\n"))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// What identifies a client of the rate limit, see config.rateBy
+const (
+	rateByIP  = "ip"  // the IP of the client, shared by the keys used from it
+	rateByKey = "key" // the API key of the client, shared by the IPs using it
+)
+
+// parseRate parses a rate like "10/s", "600/m" or "1000/h" into requests per second
+func parseRate(s string) (float64, error) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return 0, fmt.Errorf("invalid rate %q, expected requests/unit like 10/s", s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid rate %q, expected requests/unit like 10/s", s)
+	}
+	switch s[i+1:] {
+	case "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	default:
+		return 0, fmt.Errorf("invalid rate unit %q of %q, expected s, m or h", s[i+1:], s)
+	}
+}
+
+// bucket is the token bucket of a client
+type bucket struct {
+	tokens float64   // tokens at last
+	last   time.Time // time of the last update of tokens
+}
+
+// rateLimiter limits the requests of each client with a token bucket
+// refilled by rate tokens per second up to burst tokens
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64 // capacity of a bucket
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter returns a rate limiter of rate requests per second with bursts of burst requests
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// refillDuration returns the time to refill an empty bucket, after which an idle bucket is full
+// and equivalent to a new one
+func (l *rateLimiter) refillDuration() time.Duration {
+	return time.Duration(l.burst / l.rate * float64(time.Second))
+}
+
+// allow takes a token of the bucket of client, it returns false and the time until a token is available
+// when the bucket is empty
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep evicts the buckets idle for long enough to be full, at most once per refill duration,
+// so the buckets of past clients don't accumulate
+func (l *rateLimiter) sweep(now time.Time) {
+	idle := l.refillDuration()
+	if now.Sub(l.lastSweep) < idle {
+		return
+	}
+	for client, b := range l.buckets {
+		if now.Sub(b.last) >= idle {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// limit returns a handler calling next for the requests allowed by the bucket of the client
+// returned by clientID, other requests are answered with 429 and a Retry-After header
+func (l *rateLimiter) limit(clientID func(r *http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := l.allow(clientID(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, codeRateLimited, "rate limit exceeded", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP of the client of r, without trusting headers set by the client
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
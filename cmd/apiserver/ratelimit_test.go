@@ -0,0 +1,146 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+func TestParseRate(t *testing.T) {
+	cases := map[string]float64{"10/s": 10, "0.5/s": 0.5, "600/m": 10, "3600/h": 1}
+	for s, rate := range cases {
+		got, err := parseRate(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, rate, got, s)
+	}
+
+	for _, s := range []string{"", "10", "10/d", "0/s", "-1/s", "x/s"} {
+		_, err := parseRate(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// fakeClock is a clock advanced by the tests
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestRateLimiter_Allow(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := newRateLimiter(2, 3)
+	l.now = clock.now
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.allow("a")
+		assert.True(t, ok, i)
+	}
+	ok, retryAfter := l.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// the buckets of clients are independent
+	ok, _ = l.allow("b")
+	assert.True(t, ok)
+
+	clock.t = clock.t.Add(500 * time.Millisecond)
+	ok, _ = l.allow("a")
+	assert.True(t, ok)
+	ok, _ = l.allow("a")
+	assert.False(t, ok)
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := newRateLimiter(1, 2)
+	l.now = clock.now
+
+	for _, client := range []string{"a", "b", "c"} {
+		l.allow(client)
+	}
+	assert.Len(t, l.buckets, 3)
+
+	clock.t = clock.t.Add(time.Second)
+	l.allow("a")
+	assert.Len(t, l.buckets, 3)
+
+	// b and c are full again, a was used a second ago
+	clock.t = clock.t.Add(time.Second)
+	l.allow("d")
+	assert.Len(t, l.buckets, 2)
+	assert.Contains(t, l.buckets, "a")
+	assert.Contains(t, l.buckets, "d")
+}
+
+func TestRateLimit_Routes(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	s := newServer(v, config{rateLimit: 1, rateBurst: 2})
+	s.limiter.now = clock.now
+	routes := s.routes()
+
+	do := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, do("/v1/user@example.com/verification", "192.0.2.1:1234").Code, i)
+	}
+	rec := do("/v1/user@example.com/verification", "192.0.2.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, codeRateLimited, decodeError(t, rec.Body.String()).Error.Code)
+
+	// other clients and the health check aren't limited
+	assert.Equal(t, http.StatusOK, do("/v1/user@example.com/verification", "192.0.2.2:1234").Code)
+	assert.Equal(t, http.StatusOK, do("/health", "192.0.2.1:1234").Code)
+
+	clock.t = clock.t.Add(time.Second)
+	assert.Equal(t, http.StatusOK, do("/v1/user@example.com/verification", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("/v1/user@example.com/verification", "192.0.2.1:1234").Code)
+}
+
+func TestRateLimit_PerAPIKey(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	routes := newServer(v, config{apiKeys: []string{"key-1", "key-2"}, rateLimit: 1, rateBurst: 1}).routes()
+
+	do := func(key string) int {
+		req := httptest.NewRequest("GET", "/v1/user@example.com/verification", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do("key-1"))
+	assert.Equal(t, http.StatusTooManyRequests, do("key-1"))
+	assert.Equal(t, http.StatusOK, do("key-2"))
+	// an invalid key is rejected before consuming a token
+	assert.Equal(t, http.StatusUnauthorized, do("key-3"))
+}
+
+func TestRateLimit_ByIP(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com", Syntax: emailVerifier.Syntax{Valid: true}}}
+	routes := newServer(v, config{apiKeys: []string{"key-1", "key-2"}, rateLimit: 1, rateBurst: 1, rateBy: rateByIP}).routes()
+
+	do := func(key, remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/v1/user@example.com/verification", nil)
+		req.Header.Set("X-API-Key", key)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// the keys used from an IP share its limit
+	assert.Equal(t, http.StatusOK, do("key-1", "192.0.2.1:1234"))
+	assert.Equal(t, http.StatusTooManyRequests, do("key-2", "192.0.2.1:1234"))
+	assert.Equal(t, http.StatusOK, do("key-1", "192.0.2.2:1234"))
+}
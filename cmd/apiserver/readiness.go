@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"h12.io/socks"
+)
+
+const (
+	defaultReadyDomain   = "gmail.com"
+	defaultReadyInterval = 30 * time.Second
+
+	// readyTimeout bounds each check of the readiness
+	readyTimeout = 10 * time.Second
+)
+
+// check is the outcome of a readiness check
+type check struct {
+	Name      string    `json:"name"`
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// readiness periodically checks that the server can verify emails: the MX records of a domain
+// are resolved and, when the smtp check is enabled, a connection to port 25 of its first mail server
+// is opened, directly or through the proxy, and closed right away without any SMTP command
+type readiness struct {
+	mu     sync.RWMutex
+	checks []check // outcomes of the last checks, none before the first run
+
+	domain    string        // domain whose MX records are resolved
+	interval  time.Duration // interval of the checks
+	smtpCheck bool          // whether the connection to a mail server is checked
+	lookupMX  func(ctx context.Context, domain string) ([]*net.MX, error)
+	dial      func(ctx context.Context, addr string) (net.Conn, error)
+	now       func() time.Time
+}
+
+// newReadiness returns the readiness of a server configured by c
+func newReadiness(c config) *readiness {
+	r := &readiness{
+		domain:    c.readyDomain,
+		interval:  c.readyInterval,
+		smtpCheck: c.smtpCheck,
+		lookupMX:  net.DefaultResolver.LookupMX,
+		dial:      newDialer(c.proxy),
+		now:       time.Now,
+	}
+	if r.domain == "" {
+		r.domain = defaultReadyDomain
+	}
+	if r.interval <= 0 {
+		r.interval = defaultReadyInterval
+	}
+	return r
+}
+
+// newDialer returns a dialer of TCP connections through the SOCKS proxy URI, directly if empty
+func newDialer(proxy string) func(ctx context.Context, addr string) (net.Conn, error) {
+	if proxy == "" {
+		var d net.Dialer
+		return func(ctx context.Context, addr string) (net.Conn, error) {
+			return d.DialContext(ctx, "tcp", addr)
+		}
+	}
+
+	dial := socks.Dial(proxy)
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		type dialed struct {
+			conn net.Conn
+			err  error
+		}
+		ch := make(chan dialed, 1)
+		go func() {
+			conn, err := dial("tcp", addr)
+			ch <- dialed{conn, err}
+		}()
+		select {
+		case d := <-ch:
+			return d.conn, d.err
+		case <-ctx.Done():
+			// the connection dialed after the timeout is closed
+			go func() {
+				if d := <-ch; d.conn != nil {
+					d.conn.Close()
+				}
+			}()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// run checks the readiness every interval until ctx is done
+func (r *readiness) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check runs the checks of the readiness and records their outcomes
+func (r *readiness) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	mxs, err := r.lookupMX(ctx, r.domain)
+	if err == nil && len(mxs) == 0 {
+		err = fmt.Errorf("no MX records of %s", r.domain)
+	}
+	checks := []check{r.newCheck("dns", err)}
+
+	if r.smtpCheck {
+		if err == nil {
+			err = r.dialSMTP(ctx, mxs[0].Host)
+		} else {
+			err = errors.New("skipped, dns check failed")
+		}
+		checks = append(checks, r.newCheck("smtp", err))
+	}
+
+	r.mu.Lock()
+	r.checks = checks
+	r.mu.Unlock()
+}
+
+// dialSMTP opens and closes a connection to port 25 of host
+func (r *readiness) dialSMTP(ctx context.Context, host string) error {
+	conn, err := r.dial(ctx, net.JoinHostPort(strings.TrimSuffix(host, "."), "25"))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// newCheck returns the outcome of the check name which failed with err, if not nil
+func (r *readiness) newCheck(name string, err error) check {
+	c := check{Name: name, OK: err == nil, CheckedAt: r.now()}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	return c
+}
+
+// status returns the outcomes of the last checks and, when the server isn't ready, the reason:
+// no checks yet, a failed check or checks older than three intervals
+func (r *readiness) status() ([]check, string) {
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+
+	if len(checks) == 0 {
+		return checks, "not checked yet"
+	}
+	for _, c := range checks {
+		if !c.OK {
+			return checks, fmt.Sprintf("%s check failed: %s", c.Name, c.Error)
+		}
+		if age := r.now().Sub(c.CheckedAt); age > 3*r.interval {
+			return checks, fmt.Sprintf("%s check is stale, last run %s ago", c.Name, age.Round(time.Second))
+		}
+	}
+	return checks, ""
+}
+
+// readinessResponse is the response of the readiness endpoint
+type readinessResponse struct {
+	Status string  `json:"status"` // "ready" or "not_ready"
+	Reason string  `json:"reason,omitempty"`
+	Checks []check `json:"checks"`
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubReadiness returns the readiness of a server configured by c, resolving and dialing with the stubs
+func stubReadiness(c config, mxErr, dialErr error) (*readiness, *fakeClock, *[]string) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	var dialed []string
+	r := newReadiness(c)
+	r.now = clock.now
+	r.lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		if mxErr != nil {
+			return nil, mxErr
+		}
+		return []*net.MX{{Host: "mx." + domain + ".", Pref: 10}}, nil
+	}
+	r.dial = func(ctx context.Context, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+	return r, clock, &dialed
+}
+
+func TestReadiness(t *testing.T) {
+	r, clock, dialed := stubReadiness(config{smtpCheck: true, readyDomain: "example.com", readyInterval: time.Minute}, nil, nil)
+
+	_, reason := r.status()
+	assert.Equal(t, "not checked yet", reason)
+
+	r.check(context.Background())
+	checks, reason := r.status()
+	assert.Empty(t, reason)
+	assert.Len(t, checks, 2)
+	assert.Equal(t, []string{"mx.example.com:25"}, *dialed)
+
+	clock.t = clock.t.Add(4 * time.Minute)
+	_, reason = r.status()
+	assert.Contains(t, reason, "stale")
+}
+
+func TestReadiness_Failed(t *testing.T) {
+	r, _, dialed := stubReadiness(config{smtpCheck: true}, errors.New("lookup gmail.com: no such host"), nil)
+	r.check(context.Background())
+	checks, reason := r.status()
+	assert.Equal(t, "dns check failed: lookup gmail.com: no such host", reason)
+	assert.False(t, checks[1].OK)
+	assert.Empty(t, *dialed)
+
+	r, _, _ = stubReadiness(config{smtpCheck: true}, nil, errors.New("connection refused"))
+	r.check(context.Background())
+	_, reason = r.status()
+	assert.Equal(t, "smtp check failed: connection refused", reason)
+
+	// the mail server isn't reached without the smtp check
+	r, _, dialed = stubReadiness(config{}, nil, errors.New("connection refused"))
+	r.check(context.Background())
+	checks, reason = r.status()
+	assert.Empty(t, reason)
+	assert.Len(t, checks, 1)
+	assert.Empty(t, *dialed)
+}
+
+func TestGetReadiness(t *testing.T) {
+	s := newServer(&stubVerifier{}, config{apiKeys: []string{"key"}})
+	r, _, _ := stubReadiness(config{smtpCheck: true}, nil, errors.New("connection refused"))
+	s.readiness = r
+	routes := s.routes()
+
+	do := func(path string) (int, readinessResponse) {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		var resp readinessResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	code, resp := do("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", resp.Status)
+	assert.Equal(t, "not checked yet", resp.Reason)
+
+	r.check(context.Background())
+	code, resp = do("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "smtp check failed: connection refused", resp.Reason)
+
+	r.dial = func(ctx context.Context, addr string) (net.Conn, error) {
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+	r.check(context.Background())
+	code, resp = do("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", resp.Status)
+
+	// the liveness doesn't depend on the readiness checks
+	code, resp = do("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+}
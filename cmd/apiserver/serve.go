@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Exit codes of the apiserver
+const (
+	exitOK              = 0 // the server shut down cleanly
+	exitServeError      = 1 // the listener failed
+	exitInvalidConfig   = 2
+	exitShutdownTimeout = 3 // requests were still in flight after the grace period
+)
+
+// readHeaderTimeout bounds the time to read the headers of a request
+const readHeaderTimeout = 10 * time.Second
+
+// errShutdownTimeout is returned by serve when requests are still in flight after the grace period
+var errShutdownTimeout = errors.New("shutdown grace period exceeded")
+
+// newHTTPServer returns the HTTP server of handler configured by c, serving HTTPS with tlsConfig if not nil
+func newHTTPServer(handler http.Handler, c config, tlsConfig *tls.Config) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      c.writeTimeout,
+		TLSConfig:         tlsConfig,
+	}
+}
+
+// unixPrefix is the prefix of a listen address of a Unix socket, like "unix:/run/verifier.sock"
+const unixPrefix = "unix:"
+
+// listen listens on the TCP address addr, or the path of a Unix socket after the "unix:" prefix.
+// A stale socket file left by a server which didn't shut down cleanly is removed first.
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return net.Listen("tcp", addr)
+	}
+
+	path := strings.TrimPrefix(addr, unixPrefix)
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("listen unix %s: socket in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// tlsConfig returns the TLS config of the certificate and key files of c, nil if none
+func (c config) tlsConfig() (*tls.Config, error) {
+	if c.tlsCert == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS certificate %s and key %s: %w", c.tlsCert, c.tlsKey, err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// serve serves the connections of ln with srv until ctx is done, then shuts srv down:
+// the listener is closed and the requests in flight are given the grace period to complete.
+// The requests still in flight after it have their context canceled and their connection closed.
+// It returns nil after a clean shutdown, errShutdownTimeout after the grace period,
+// and the error of the listener when it fails before ctx is done.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, grace time.Duration) error {
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv.BaseContext = func(net.Listener) context.Context { return requestCtx }
+
+	served := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			served <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		served <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		cancelRequests()
+		_ = srv.Close()
+		return errShutdownTimeout
+	}
+	return nil
+}
+
+// signalContext returns a context done on the first SIGINT or SIGTERM,
+// a second one terminates the process right away
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		cancel()
+	}()
+	return ctx
+}
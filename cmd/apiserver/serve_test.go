@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// startServer serves the routes of a server verifying with v until the returned cancel is called,
+// the error of serve is sent on the returned channel
+func startServer(t *testing.T, v verifier, grace time.Duration) (string, context.CancelFunc, <-chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	c := config{writeTimeout: defaultWriteTimeout}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, newHTTPServer(newServer(v, c).routes(), c, nil), ln, grace)
+	}()
+	return "http://" + ln.Addr().String(), cancel, served
+}
+
+func TestServe_GracefulShutdown(t *testing.T) {
+	v := &gatedVerifier{gate: make(chan struct{})}
+	url, shutdown, served := startServer(t, v, 5*time.Second)
+
+	type response struct {
+		resp *http.Response
+		err  error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Get(url + "/v1/user@example.com/verification")
+		responses <- response{resp, err}
+	}()
+
+	// the shutdown starts while the verification is in flight
+	time.Sleep(100 * time.Millisecond)
+	shutdown()
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned before the request completed: %v", err)
+	default:
+	}
+
+	// new connections are refused
+	_, err := http.Get(url + "/healthz")
+	assert.Error(t, err)
+
+	v.gate <- struct{}{}
+	r := <-responses
+	if assert.NoError(t, r.err) {
+		defer r.resp.Body.Close()
+		assert.Equal(t, http.StatusOK, r.resp.StatusCode)
+		var ret emailVerifier.Result
+		assert.NoError(t, json.NewDecoder(r.resp.Body).Decode(&ret))
+		assert.Equal(t, "user@example.com", ret.Email)
+	}
+	assert.NoError(t, <-served)
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	v := &gatedVerifier{gate: make(chan struct{})}
+	defer close(v.gate)
+	url, shutdown, served := startServer(t, v, 100*time.Millisecond)
+
+	failed := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url + "/v1/user@example.com/verification")
+		if err == nil {
+			resp.Body.Close()
+		}
+		failed <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	shutdown()
+	assert.Equal(t, errShutdownTimeout, <-served)
+	// the connection of the request in flight is closed
+	assert.Error(t, <-failed)
+}
+
+func TestServe_ListenerError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	ln.Close()
+
+	err = serve(context.Background(), newHTTPServer(http.NotFoundHandler(), config{}, nil), ln, time.Second)
+	assert.Error(t, err)
+	assert.NotEqual(t, errShutdownTimeout, err)
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	path := filepath.Join(tempDir(t), "verifier.sock")
+
+	// a stale socket is replaced, a socket in use isn't
+	stale, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := listen(unixPrefix + path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = listen(unixPrefix + path)
+	assert.Error(t, err)
+
+	ctx, shutdown := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, newHTTPServer(newServer(&stubVerifier{}, config{}).routes(), config{}, nil), ln, time.Second)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://verifier/healthz")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	shutdown()
+	assert.NoError(t, <-served)
+}
+
+// writeCertificate writes a self-signed certificate of 127.0.0.1 and its key to dir
+func writeCertificate(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile, cert
+}
+
+func TestServe_TLS(t *testing.T) {
+	certFile, keyFile, cert := writeCertificate(t, tempDir(t))
+	c := config{tlsCert: certFile, tlsKey: keyFile}
+	tlsConfig, err := c.tlsConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ln, err := listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	ctx, shutdown := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, newHTTPServer(newServer(&stubVerifier{}, c).routes(), c, tlsConfig), ln, time.Second)
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get("https://" + ln.Addr().String() + "/healthz")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	shutdown()
+	assert.NoError(t, <-served)
+}
+
+func TestConfig_TLSConfigInvalid(t *testing.T) {
+	tlsConfig, err := config{}.tlsConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	_, err = config{tlsCert: "/nonexistent/cert.pem", tlsKey: "/nonexistent/key.pem"}.tlsConfig()
+	assert.EqualError(t, err, "load TLS certificate /nonexistent/cert.pem and key /nonexistent/key.pem: open /nonexistent/cert.pem: no such file or directory")
+}
@@ -0,0 +1,632 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// verifier is the part of *emailVerifier.Verifier used by the server
+type verifier interface {
+	Verify(email string) (*emailVerifier.Result, error)
+	VerifyDomain(domain string) (*emailVerifier.DomainResult, error)
+	VerifyBatch(emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult
+	IsCatchAll(ctx context.Context, domain string) (bool, error)
+	Stats() emailVerifier.Stats
+}
+
+// contextVerifier is a verifier whose verifications stop when their context is done,
+// with the partial result computed until then
+type contextVerifier interface {
+	VerifyWithContext(ctx context.Context, email string) (*emailVerifier.Result, error)
+}
+
+// batchContextVerifier is a verifier whose batch verifications stop when their context is done
+type batchContextVerifier interface {
+	VerifyBatchWithContext(ctx context.Context, emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult
+}
+
+// server serves the verification API with a verifier shared by all requests
+type server struct {
+	verifier       verifier
+	dryRunVerifier verifier      // verifier of the dry-run verifications, whose smtp check only plans its commands
+	maxBatch       int           // maximum number of emails of a bulk verification
+	batchTimeout   time.Duration // timeout of a bulk verification request
+	requestTimeout time.Duration // timeout of the verification of an email, none if zero
+	apiKeys        []string      // keys required by the /v1 routes, none if empty
+	limiter        *rateLimiter  // rate limiter of the /v1 routes, nil if unlimited
+	rateBy         string        // what identifies a client of the rate limiter, see config.rateBy
+	cache          *resultCache  // cache of the email verifications, nil if disabled
+	readiness      *readiness    // outcomes of the readiness checks
+	async          *asyncJobs    // asynchronous verifications
+	corsOrigins    []string      // origins allowed to call the API from a browser
+
+	metrics         *metrics // metrics of the requests and verifications, nil if disabled
+	metricsOnRoutes bool     // whether the metrics are served by the routes rather than their own listener
+}
+
+// newServer returns a server verifying with v, configured by c
+func newServer(v verifier, c config) *server {
+	s := &server{
+		verifier:       v,
+		maxBatch:       c.maxBatch,
+		batchTimeout:   c.batchTimeout,
+		requestTimeout: c.requestTimeout,
+		apiKeys:        c.apiKeys,
+		readiness:      newReadiness(c),
+		corsOrigins:    c.corsOrigins,
+	}
+	s.async = newAsyncJobs(c, func(ctx context.Context, email string) verification {
+		return s.verify(ctx, email, false)
+	})
+	if c.metrics {
+		s.metrics = newMetrics()
+		s.metrics.observeStats(v.Stats)
+		s.metricsOnRoutes = c.metricsAddr == ""
+	}
+	if s.maxBatch <= 0 {
+		s.maxBatch = defaultMaxBatch
+	}
+	if s.batchTimeout <= 0 {
+		s.batchTimeout = defaultBatchTimeout
+	}
+	if c.rateLimit > 0 {
+		s.limiter = newRateLimiter(c.rateLimit, c.rateBurst)
+		s.rateBy = c.rateBy
+	}
+	if c.cacheTTL > 0 {
+		s.cache = newResultCache(c.cacheTTL, c.cacheTransientTTL, c.cacheSize)
+	}
+	return s
+}
+
+// routes returns the handler of the API routes
+func (s *server) routes() http.Handler {
+	// the patterns of the routes, the route label of the metrics of a request
+	var patterns []string
+	handle := func(router *httprouter.Router, method, path string, handle httprouter.Handle) {
+		patterns = append(patterns, path)
+		router.Handle(method, path, handle)
+	}
+
+	router := newRouter()
+
+	// httprouter doesn't allow the static "domain" segment next to the ":email" parameter in one router
+	domainRouter := newRouter()
+	handle(domainRouter, "GET", "/v1/domain/:domain/verification", s.GetDomainVerification)
+	handle(domainRouter, "GET", "/v1/domain/:domain/catch-all", s.GetDomainCatchAll)
+
+	// the email is passed in the query or body, since proxies and routers normalize some characters of paths
+	verificationRouter := newRouter()
+	handle(verificationRouter, "GET", "/v1/verification", s.GetVerification)
+	handle(verificationRouter, "POST", "/v1/verification", s.PostVerification)
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/domain/", domainRouter)
+	mux.Handle("/v1/verification", verificationRouter)
+
+	// a bulk verification which exceeds the timeout is answered with 503, it has no partial response
+	bulkRouter := newRouter()
+	handle(bulkRouter, "POST", "/v1/verifications", s.PostVerifications)
+	mux.Handle("/v1/verifications", http.TimeoutHandler(bulkRouter, s.batchTimeout, timeoutBody))
+
+	// a streaming verification has no timeout, it ends when the body ends or the client disconnects
+	streamRouter := newRouter()
+	handle(streamRouter, "POST", "/v1/verifications/stream", s.PostVerificationsStream)
+	mux.Handle("/v1/verifications/stream", streamRouter)
+
+	// an asynchronous verification is answered right away, its result is posted to a callback or polled
+	asyncRouter := newRouter()
+	handle(asyncRouter, "POST", "/v1/verifications/async", s.PostAsyncVerification)
+	handle(asyncRouter, "GET", "/v1/jobs/:id", s.GetJob)
+	mux.Handle("/v1/verifications/async", asyncRouter)
+	mux.Handle("/v1/jobs/", asyncRouter)
+
+	statsRouter := newRouter()
+	handle(statsRouter, "GET", "/v1/stats", s.GetStats)
+	mux.Handle("/v1/stats", statsRouter)
+
+	// the pattern with a parameter is registered last, so the static patterns are matched first
+	handle(router, "GET", "/v1/:email/verification", s.GetEmailVerification)
+	mux.Handle("/", router)
+
+	// the rate limit applies to authenticated requests, so an invalid key doesn't consume the limit of a client
+	var handler http.Handler = mux
+	if s.limiter != nil {
+		handler = s.limiter.limit(s.clientID, handler)
+	}
+
+	// the health checks are answered without an api key, for load balancers and orchestrators
+	healthRouter := newRouter()
+	handle(healthRouter, "GET", "/health", s.GetHealth)
+	handle(healthRouter, "GET", "/healthz", s.GetHealth)
+	handle(healthRouter, "GET", "/readyz", s.GetReadiness)
+	root := http.NewServeMux()
+	root.Handle("/health", healthRouter)
+	root.Handle("/healthz", healthRouter)
+	root.Handle("/readyz", healthRouter)
+	root.Handle("/", requireAPIKey(s.apiKeys, handler))
+	handler = root
+
+	// the metrics are scraped without an api key, unless they are served on their own listener
+	if s.metrics != nil {
+		if s.metricsOnRoutes {
+			patterns = append(patterns, "/metrics")
+			root.Handle("/metrics", s.metrics)
+		}
+		handler = s.metrics.instrument(patterns, root)
+	}
+
+	// the preflight requests of browsers have no api key, they are answered first
+	return cors(s.corsOrigins, handler)
+}
+
+// clientID returns the id of the client of r for rate limiting: by default its API key when keys are required,
+// its IP otherwise, since a client could pick a new unchecked key for every request
+func (s *server) clientID(r *http.Request) string {
+	if s.rateBy == rateByKey || (s.rateBy == "" && len(s.apiKeys) > 0) {
+		return "key:" + requestAPIKey(r)
+	}
+	return "ip:" + clientIP(r)
+}
+
+// GetHealth answers 200 as long as the process is up
+func (s *server) GetHealth(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// GetReadiness answers 200 when the last readiness checks passed recently, 503 with the reason otherwise.
+// It only reads the outcomes of the checks, which run in the background.
+func (s *server) GetReadiness(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	checks, reason := s.readiness.status()
+	if checks == nil {
+		checks = []check{}
+	}
+	if reason != "" {
+		writeJSON(w, http.StatusServiceUnavailable, readinessResponse{Status: "not_ready", Reason: reason, Checks: checks})
+		return
+	}
+	writeJSON(w, http.StatusOK, readinessResponse{Status: "ready", Checks: checks})
+}
+
+func (s *server) GetEmailVerification(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	s.writeEmailVerification(w, r, ps.ByName("email"))
+}
+
+// GetVerification verifies the email of the query parameter "email"
+func (s *server) GetVerification(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	email, err := queryEmail(r.URL.RawQuery)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error(), nil)
+		return
+	}
+	s.writeEmailVerification(w, r, email)
+}
+
+// verificationRequest is the body of a POST verification request
+type verificationRequest struct {
+	Email string `json:"email"`
+}
+
+// PostVerification verifies the email of the JSON body {"email": "..."}
+func (s *server) PostVerification(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req verificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid request body", nil)
+		return
+	}
+	if req.Email == "" {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "missing email", nil)
+		return
+	}
+	s.writeEmailVerification(w, r, req.Email)
+}
+
+// bulkVerification is the result of an email of a bulk verification,
+// each email independently has either a result or an error
+type bulkVerification struct {
+	Email  string                `json:"email"`
+	Result *emailVerifier.Result `json:"result,omitempty"`
+	Error  *apiError             `json:"error,omitempty"`
+	*CacheStatus
+}
+
+// newBulkVerification returns the bulk verification of email with the verification v
+func newBulkVerification(email string, v verification) bulkVerification {
+	if v.err != nil {
+		_, apiErr := newVerificationError(v.err)
+		return bulkVerification{Email: email, Error: &apiErr, CacheStatus: v.cache}
+	}
+	return bulkVerification{Email: email, Result: v.result, CacheStatus: v.cache}
+}
+
+// verification is the result and error of the verification of an email
+type verification struct {
+	result *emailVerifier.Result
+	err    error
+	cache  *CacheStatus // cache status of a cached verification, nil otherwise
+}
+
+// cached returns the cached verification of email, unless the cache is disabled or refresh is set
+func (s *server) cached(email string, refresh bool) (verification, bool) {
+	if s.cache == nil || refresh {
+		return verification{}, false
+	}
+	entry, ok := s.cache.get(email)
+	if s.metrics != nil {
+		s.metrics.ObserveCacheHit("api", ok)
+	}
+	if !ok {
+		return verification{}, false
+	}
+	return verification{result: entry.resultFor(email), err: entry.err, cache: s.cache.status(entry)}, true
+}
+
+// store caches the verification of email when the cache is enabled, and records the timings of its stages
+func (s *server) store(email string, ret *emailVerifier.Result, err error) {
+	if s.cache != nil {
+		s.cache.add(email, ret, err)
+	}
+	if s.metrics != nil && ret != nil {
+		s.metrics.observeResult(ret)
+	}
+}
+
+// verify verifies email until ctx is done, or returns its cached verification unless refresh is set.
+// A verification interrupted by ctx isn't cached.
+func (s *server) verify(ctx context.Context, email string, refresh bool) verification {
+	if v, ok := s.cached(email, refresh); ok {
+		return v
+	}
+	ret, err := s.verifyContext(ctx, s.verifier, email)
+	if ctx.Err() != nil && err != nil {
+		return verification{result: ret, err: ctx.Err()}
+	}
+	s.store(email, ret, err)
+	return verification{result: ret, err: err}
+}
+
+// verifyContext verifies email with v until ctx is done. A verifier which doesn't observe the context keeps verifying
+// in the background until its own timeouts, the partial result is then only the syntax of the address.
+func (s *server) verifyContext(ctx context.Context, v verifier, email string) (*emailVerifier.Result, error) {
+	if v, ok := v.(contextVerifier); ok {
+		return v.VerifyWithContext(ctx, email)
+	}
+
+	type verified struct {
+		ret *emailVerifier.Result
+		err error
+	}
+	ch := make(chan verified, 1)
+	go func() {
+		ret, err := v.Verify(email)
+		ch <- verified{ret, err}
+	}()
+	select {
+	case v := <-ch:
+		return v.ret, v.err
+	case <-ctx.Done():
+		syntax, _ := emailVerifier.ParseAddress(email)
+		ret := emailVerifier.NewResult(email)
+		ret.Syntax = *syntax
+		return ret, ctx.Err()
+	}
+}
+
+// refreshParam returns the "refresh" query parameter of r, which bypasses the cache when true
+func refreshParam(r *http.Request) (bool, error) {
+	return boolParam(r, "refresh")
+}
+
+// boolParam returns the boolean query parameter name of r, false if it's missing
+func boolParam(r *http.Request, name string) (bool, error) {
+	param := r.URL.Query().Get(name)
+	if param == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s parameter", name)
+	}
+	return value, nil
+}
+
+// verifyDryRun verifies email with the dry-run verifier until ctx is done, bypassing the cache
+func (s *server) verifyDryRun(ctx context.Context, email string) verification {
+	ret, err := s.verifyContext(ctx, s.dryRunVerifier, email)
+	if ctx.Err() != nil && err != nil {
+		return verification{result: ret, err: ctx.Err()}
+	}
+	return verification{result: ret, err: err}
+}
+
+// PostVerifications verifies the emails of the JSON array body and responds with
+// their results in the same order. More than maxBatch emails are rejected with 413.
+func (s *server) PostVerifications(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	refresh, err := refreshParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error(), nil)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.maxBatch)*maxEmailBytes)
+	var emails []string
+	if err := json.NewDecoder(r.Body).Decode(&emails); err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			writeError(w, http.StatusRequestEntityTooLarge, codeTooManyEmails, "too many emails", nil)
+			return
+		}
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid request body", nil)
+		return
+	}
+	if len(emails) > s.maxBatch {
+		message := fmt.Sprintf("too many emails, at most %d are allowed", s.maxBatch)
+		writeError(w, http.StatusRequestEntityTooLarge, codeTooManyEmails, message, nil)
+		return
+	}
+
+	// only the emails missing from the cache are verified, at the index of their result
+	verifications := make([]bulkVerification, len(emails))
+	var missing []string
+	var missingIndexes []int
+	for i, email := range emails {
+		if v, ok := s.cached(email, refresh); ok {
+			verifications[i] = newBulkVerification(email, v)
+			continue
+		}
+		missing = append(missing, email)
+		missingIndexes = append(missingIndexes, i)
+	}
+	if len(missing) > 0 {
+		// the verifications stop once the batch timeout answers the request or the client disconnects
+		ctx, cancel := context.WithTimeout(r.Context(), s.batchTimeout)
+		defer cancel()
+		results := s.verifyBatch(ctx, missing, emailVerifier.BatchOptions{GroupByDomain: true})
+		for i, ret := range results {
+			// an interrupted verification isn't cached
+			if ctx.Err() == nil || ret.Err == nil {
+				s.store(ret.Email, ret.Result, ret.Err)
+			}
+			verifications[missingIndexes[i]] = newBulkVerification(ret.Email, verification{result: ret.Result, err: ret.Err})
+		}
+	}
+	writeJSON(w, http.StatusOK, verifications)
+}
+
+// verifyBatch verifies emails with the verifier until ctx is done. A verifier which doesn't observe the context
+// keeps verifying until its own timeouts.
+func (s *server) verifyBatch(ctx context.Context, emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult {
+	if v, ok := s.verifier.(batchContextVerifier); ok {
+		return v.VerifyBatchWithContext(ctx, emails, opts)
+	}
+	return s.verifier.VerifyBatch(emails, opts)
+}
+
+// PostVerificationsStream verifies the newline-delimited emails of the body and writes an NDJSON line per email
+// as soon as its verification completes, so the lines are in the order of completion. A slow client slows down
+// the reading of the body, and a client disconnect stops the verifications which haven't started yet.
+func (s *server) PostVerificationsStream(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	refresh, err := refreshParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	// a full duplex request context isn't canceled on disconnect until the body or the response fails
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+
+	// an HTTP/1 server discards the unread body once the response starts, unless full duplex is enabled (Go 1.21+)
+	if d, ok := w.(interface{ EnableFullDuplex() error }); ok {
+		_ = d.EnableFullDuplex()
+	}
+	// the write timeout of the server doesn't apply to a stream, which ends with its body (Go 1.20+)
+	if d, ok := w.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		_ = d.SetWriteDeadline(time.Time{})
+	}
+
+	// the headers are sent right away, so the client doesn't wait for the first verification
+	w.WriteHeader(http.StatusOK)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	emails := make(chan string)
+	go func() {
+		defer close(emails)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			email := strings.TrimSpace(scanner.Text())
+			if email == "" {
+				continue
+			}
+			select {
+			case emails <- email:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if scanner.Err() != nil {
+			cancel()
+		}
+	}()
+
+	verifications := make(chan bulkVerification)
+	var wg sync.WaitGroup
+	for i := 0; i < streamConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for email := range emails {
+				if ctx.Err() != nil {
+					continue
+				}
+				v := s.verify(ctx, email, refresh)
+				select {
+				case verifications <- newBulkVerification(email, v):
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(verifications)
+	}()
+
+	encoder := json.NewEncoder(w)
+	for verification := range verifications {
+		if err := encoder.Encode(verification); err != nil {
+			cancel()
+			continue
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// queryEmail returns the "email" parameter of the raw query. Unlike url.ParseQuery it keeps a "+" as is,
+// so the raw and the URL-encoded form of an address like "a+b@example.com" are the same
+func queryEmail(rawQuery string) (string, error) {
+	for _, param := range strings.Split(rawQuery, "&") {
+		if !strings.HasPrefix(param, "email=") {
+			continue
+		}
+		email, err := url.PathUnescape(strings.TrimPrefix(param, "email="))
+		if err != nil {
+			return "", errors.New("invalid email parameter")
+		}
+		if email != "" {
+			return email, nil
+		}
+	}
+	return "", errors.New("missing email")
+}
+
+// verificationResponse is the response of the verification of an email
+type verificationResponse struct {
+	*emailVerifier.Result
+	*CacheStatus
+}
+
+// writeEmailVerification writes the verification result of email, the request r may bypass the cache.
+// The verification stops when the client disconnects, or after the request timeout which is answered with 504.
+func (s *server) writeEmailVerification(w http.ResponseWriter, r *http.Request, email string) {
+	refresh, err := refreshParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error(), nil)
+		return
+	}
+	dryRun, err := boolParam(r, "dry_run")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error(), nil)
+		return
+	}
+	if dryRun && s.dryRunVerifier == nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "dry runs are unsupported", nil)
+		return
+	}
+
+	ctx := r.Context()
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+	var v verification
+	if dryRun {
+		v = s.verifyDryRun(ctx, email)
+	} else {
+		v = s.verify(ctx, email, refresh)
+	}
+	if errors.Is(v.err, context.Canceled) {
+		// the client is gone, there is no one to answer
+		return
+	}
+	if v.cache != nil {
+		w.Header().Set("Age", strconv.FormatInt(v.cache.Age, 10))
+	}
+	if v.err != nil {
+		writeVerificationError(w, v.err, v.result, v.cache)
+		return
+	}
+	if !v.result.Syntax.Valid {
+		writeError(w, http.StatusBadRequest, codeInvalidSyntax, "email address syntax is invalid", v.result)
+		return
+	}
+	writeJSON(w, http.StatusOK, verificationResponse{Result: v.result, CacheStatus: v.cache})
+}
+
+func (s *server) GetDomainVerification(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ret, err := s.verifier.VerifyDomain(ps.ByName("domain"))
+	if err != nil {
+		writeVerificationError(w, err, ret, nil)
+		return
+	}
+	if !ret.Valid {
+		writeError(w, http.StatusBadRequest, codeInvalidSyntax, "domain syntax is invalid", ret)
+		return
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
+
+// catchAllResponse is the response of the catch-all check of a domain
+type catchAllResponse struct {
+	Domain                string `json:"domain"`
+	CatchAll              *bool  `json:"catch_all"`                         // null if the check was skipped or failed
+	SkipReason            string `json:"skip_reason,omitempty"`             // why the check was skipped, if it was
+	HostUnreachableReason string `json:"host_unreachable_reason,omitempty"` // why the mail servers couldn't be reached
+}
+
+// GetStats answers the counters of the verifier and the sizes of its cache and domain lists
+func (s *server) GetStats(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeJSON(w, http.StatusOK, s.verifier.Stats())
+}
+
+// GetDomainCatchAll checks whether the mail servers of a domain accept any address, without verifying an address.
+// A domain of the allowlist or the blocklist isn't checked, which is answered with its skip reason.
+func (s *server) GetDomainCatchAll(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := r.Context()
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+
+	ret := catchAllResponse{Domain: ps.ByName("domain")}
+	catchAll, err := s.verifier.IsCatchAll(ctx, ret.Domain)
+	if err == nil {
+		ret.CatchAll = &catchAll
+		writeJSON(w, http.StatusOK, ret)
+		return
+	}
+
+	var catchAllErr *emailVerifier.CatchAllError
+	if errors.As(err, &catchAllErr) {
+		ret.SkipReason = catchAllErr.SkipReason
+		ret.HostUnreachableReason = catchAllErr.HostUnreachableReason
+	}
+	switch {
+	case ret.SkipReason != "":
+		writeJSON(w, http.StatusOK, ret)
+	case errors.Is(ctx.Err(), context.Canceled):
+		// the client is gone, there is no one to answer
+	case ctx.Err() != nil:
+		writeVerificationError(w, ctx.Err(), ret, nil)
+	default:
+		writeVerificationError(w, err, ret, nil)
+	}
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// stubVerifier answers with its results and errors and records the verified input
+type stubVerifier struct {
+	result       *emailVerifier.Result
+	domainResult *emailVerifier.DomainResult
+	catchAll     bool
+	stats        emailVerifier.Stats
+	err          error
+	verified     []string
+}
+
+func (s *stubVerifier) Verify(email string) (*emailVerifier.Result, error) {
+	s.verified = append(s.verified, email)
+	return s.result, s.err
+}
+
+func (s *stubVerifier) VerifyBatch(emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult {
+	s.verified = append(s.verified, emails...)
+	results := make([]emailVerifier.BatchResult, len(emails))
+	for i, email := range emails {
+		results[i] = emailVerifier.BatchResult{Email: email, Result: &emailVerifier.Result{Email: email, Syntax: emailVerifier.Syntax{Valid: true}}}
+		if strings.HasSuffix(email, "@error.test") {
+			results[i] = emailVerifier.BatchResult{Email: email, Err: s.err}
+		}
+	}
+	return results
+}
+
+func (s *stubVerifier) VerifyDomain(domain string) (*emailVerifier.DomainResult, error) {
+	s.verified = append(s.verified, domain)
+	return s.domainResult, s.err
+}
+
+func (s *stubVerifier) IsCatchAll(ctx context.Context, domain string) (bool, error) {
+	s.verified = append(s.verified, domain)
+	return s.catchAll, s.err
+}
+
+func (s *stubVerifier) Stats() emailVerifier.Stats {
+	return s.stats
+}
+
+// get requests path from the routes of a server verifying with v
+func get(t *testing.T, v verifier, path string) (int, string) {
+	rec := httptest.NewRecorder()
+	newServer(v, config{}).routes().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	return rec.Code, string(body)
+}
+
+// decodeError decodes the error response body
+func decodeError(t *testing.T, body string) errorResponse {
+	var resp errorResponse
+	assert.NoError(t, json.Unmarshal([]byte(body), &resp))
+	return resp
+}
+
+func TestGetEmailVerification(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{
+		Email:     "user@example.com",
+		Reachable: "yes",
+		Syntax:    emailVerifier.Syntax{Valid: true},
+	}}
+
+	code, body := get(t, v, "/v1/user@example.com/verification")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"email":"user@example.com"`)
+	assert.Contains(t, body, `"reachable":"yes"`)
+	assert.Equal(t, []string{"user@example.com"}, v.verified)
+}
+
+func TestGetEmailVerification_SMTPSoftFail(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{DropOn: "MAIL"}, "example.com")
+	defer srv.Close()
+	v, err := emailVerifier.NewVerifierWithOptions(
+		emailVerifier.WithSMTPCheck(),
+		emailVerifier.WithSMTPSoftFail(),
+		emailVerifier.WithDialer(srv.Dial),
+		emailVerifier.WithResolver(srv.Resolver()),
+	)
+	assert.NoError(t, err)
+
+	code, body := get(t, v, "/v1/user@example.com/verification")
+	assert.Equal(t, http.StatusOK, code)
+	var ret emailVerifier.Result
+	assert.NoError(t, json.Unmarshal([]byte(body), &ret))
+	assert.True(t, ret.HasMxRecords)
+	assert.Equal(t, "unknown", ret.Reachable)
+	if assert.NotNil(t, ret.SMTP) {
+		assert.NotNil(t, ret.SMTP.Error)
+	}
+}
+
+func TestGetEmailVerification_DryRun(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	v := &stubVerifier{}
+	s := newServer(v, config{cacheTTL: time.Hour})
+	var err error
+	s.dryRunVerifier, err = config{smtpCheck: true}.newVerifier(
+		emailVerifier.WithSMTPDryRun(),
+		emailVerifier.WithDialer(srv.Dial),
+		emailVerifier.WithResolver(srv.Resolver()),
+	)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest("GET", "/v1/user@example.com/verification?dry_run=true", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var ret emailVerifier.Result
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ret))
+	assert.Equal(t, "unknown", ret.Reachable)
+	if assert.NotNil(t, ret.SMTP) && assert.NotNil(t, ret.SMTP.Plan) {
+		assert.Equal(t, []string{"mx.example.com.:25"}, ret.SMTP.Plan.Hosts)
+		assert.Contains(t, ret.SMTP.Plan.DeliverableCommands, "RCPT TO:<user@example.com>")
+	}
+	assert.Equal(t, 0, srv.Connections())
+	assert.Empty(t, v.verified)
+
+	rec = httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest("GET", "/v1/user@example.com/verification?dry_run=maybe", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid dry_run parameter", decodeError(t, rec.Body.String()).Error.Message)
+}
+
+func TestGetEmailVerification_InvalidSyntax(t *testing.T) {
+	v := &stubVerifier{result: &emailVerifier.Result{Email: "invalid"}}
+
+	code, body := get(t, v, "/v1/invalid/verification")
+	assert.Equal(t, http.StatusBadRequest, code)
+	resp := decodeError(t, body)
+	assert.Equal(t, codeInvalidSyntax, resp.Error.Code)
+	assert.Equal(t, "invalid", resp.Result.(map[string]interface{})["email"])
+}
+
+func TestGetEmailVerification_Error(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+		kind string
+	}{
+		{emailVerifier.ParseSMTPError(errors.New("i/o timeout")), http.StatusGatewayTimeout, codeUpstreamTimeout},
+		{emailVerifier.ParseSMTPError(errors.New("lookup example.com: no such host")), http.StatusBadGateway, codeUpstreamError},
+		{errors.New("unexpected"), http.StatusInternalServerError, codeInternal},
+	}
+	for _, c := range cases {
+		v := &stubVerifier{result: &emailVerifier.Result{Email: "user@example.com"}, err: c.err}
+
+		code, body := get(t, v, "/v1/user@example.com/verification")
+		assert.Equal(t, c.code, code, c.err.Error())
+		resp := decodeError(t, body)
+		assert.Equal(t, c.kind, resp.Error.Code)
+		assert.Equal(t, c.err.Error(), resp.Error.Message)
+		assert.Equal(t, "user@example.com", resp.Result.(map[string]interface{})["email"])
+	}
+}
+
+func TestGetDomainVerification_InvalidSyntax(t *testing.T) {
+	v := &stubVerifier{domainResult: &emailVerifier.DomainResult{Domain: "invalid"}}
+
+	code, body := get(t, v, "/v1/domain/invalid/verification")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, codeInvalidSyntax, decodeError(t, body).Error.Code)
+}
+
+func TestUnknownRoute(t *testing.T) {
+	code, body := get(t, &stubVerifier{}, "/v2/unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, codeNotFound, decodeError(t, body).Error.Code)
+}
+
+func TestGetDomainVerification(t *testing.T) {
+	v := &stubVerifier{domainResult: &emailVerifier.DomainResult{Domain: "example.com", Valid: true}}
+
+	code, body := get(t, v, "/v1/domain/example.com/verification")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"domain":"example.com"`)
+	assert.Equal(t, []string{"example.com"}, v.verified)
+}
+
+func TestGetDomainCatchAll(t *testing.T) {
+	v := &stubVerifier{catchAll: true}
+
+	code, body := get(t, v, "/v1/domain/example.com/catch-all")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"domain":"example.com","catch_all":true}`, body)
+	assert.Equal(t, []string{"example.com"}, v.verified)
+}
+
+func TestGetDomainCatchAll_Skipped(t *testing.T) {
+	v := &stubVerifier{err: &emailVerifier.CatchAllError{Domain: "example.com", SkipReason: emailVerifier.SkipReasonBlocklisted}}
+
+	code, body := get(t, v, "/v1/domain/example.com/catch-all")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"domain":"example.com","catch_all":null,"skip_reason":"domain_blocklisted"}`, body)
+}
+
+func TestGetDomainCatchAll_Error(t *testing.T) {
+	v := &stubVerifier{err: &emailVerifier.CatchAllError{
+		Domain:                "example.com",
+		HostUnreachableReason: emailVerifier.UnreachableConnectTimeout,
+		Err:                   &emailVerifier.LookupError{Message: emailVerifier.ErrTimeout, Details: "i/o timeout"},
+	}}
+
+	code, body := get(t, v, "/v1/domain/example.com/catch-all")
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	resp := decodeError(t, body)
+	assert.Equal(t, codeUpstreamTimeout, resp.Error.Code)
+	assert.Equal(t, map[string]interface{}{
+		"domain":                  "example.com",
+		"catch_all":               nil,
+		"host_unreachable_reason": emailVerifier.UnreachableConnectTimeout,
+	}, resp.Result)
+}
+
+func TestGetStats(t *testing.T) {
+	v := &stubVerifier{stats: emailVerifier.Stats{
+		Caches:       map[string]emailVerifier.CacheStats{emailVerifier.CacheKindMX: {Entries: 3, Hits: 3, Misses: 1, HitRatio: 0.75}},
+		CacheEntries: 3,
+		Lists:        map[string]emailVerifier.ListStats{emailVerifier.ListRole: {Entries: 10}},
+		SMTP:         emailVerifier.SMTPStats{ConnectionsOpened: 2, ConnectionsReused: 5, ConnectionsFailed: 1},
+	}}
+
+	code, body := get(t, v, "/v1/stats")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{
+		"caches":{"mx":{"entries":3,"hits":3,"misses":1,"hit_ratio":0.75}},
+		"cache_entries":3,
+		"cache_evictions":0,
+		"lists":{"role":{"entries":10,"last_update":"0001-01-01T00:00:00Z"}},
+		"smtp":{"connections_opened":2,"connections_reused":5,"connections_failed":1}
+	}`, body)
+}
+
+func TestVerification_QueryAndBody(t *testing.T) {
+	const email = "a/b+c@example.com"
+	requests := []*http.Request{
+		httptest.NewRequest("GET", "/v1/verification?email=a%2Fb%2Bc%40example.com", nil),
+		httptest.NewRequest("GET", "/v1/verification?email=a/b+c@example.com", nil),
+		httptest.NewRequest("GET", "/v1/verification?other=1&email=a%2Fb+c@example.com", nil),
+		httptest.NewRequest("POST", "/v1/verification", strings.NewReader(`{"email":"a/b+c@example.com"}`)),
+	}
+
+	var bodies []string
+	for _, req := range requests {
+		v := &stubVerifier{result: &emailVerifier.Result{Email: email, Syntax: emailVerifier.Syntax{Valid: true}}}
+		rec := httptest.NewRecorder()
+		newServer(v, config{}).routes().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, req.URL.String())
+		assert.Equal(t, []string{email}, v.verified, req.URL.String())
+		bodies = append(bodies, rec.Body.String())
+	}
+	for _, body := range bodies[1:] {
+		assert.Equal(t, bodies[0], body)
+	}
+}
+
+func TestVerification_BadRequest(t *testing.T) {
+	requests := []*http.Request{
+		httptest.NewRequest("GET", "/v1/verification", nil),
+		httptest.NewRequest("GET", "/v1/verification?email=%zz", nil),
+		httptest.NewRequest("POST", "/v1/verification", strings.NewReader(`{"email":`)),
+		httptest.NewRequest("POST", "/v1/verification", strings.NewReader(`{}`)),
+	}
+	for _, req := range requests {
+		v := &stubVerifier{}
+		rec := httptest.NewRecorder()
+		newServer(v, config{}).routes().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, req.URL.String())
+		assert.Empty(t, v.verified)
+	}
+}
+
+// post posts body to path of a server verifying with v configured by c
+func post(t *testing.T, v verifier, c config, path, body string) (int, string) {
+	rec := httptest.NewRecorder()
+	newServer(v, c).routes().ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+	return rec.Code, rec.Body.String()
+}
+
+func TestPostVerifications(t *testing.T) {
+	v := &stubVerifier{err: errors.New("lookup failed")}
+
+	code, body := post(t, v, config{}, "/v1/verifications", `["a@example.com","b@error.test"]`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"a@example.com", "b@error.test"}, v.verified)
+
+	var verifications []bulkVerification
+	assert.NoError(t, json.Unmarshal([]byte(body), &verifications))
+	assert.Len(t, verifications, 2)
+	assert.Equal(t, "a@example.com", verifications[0].Email)
+	assert.NotNil(t, verifications[0].Result)
+	assert.Empty(t, verifications[0].Error)
+	assert.Equal(t, "b@error.test", verifications[1].Email)
+	assert.Nil(t, verifications[1].Result)
+	assert.Equal(t, &apiError{Code: codeInternal, Message: "lookup failed"}, verifications[1].Error)
+}
+
+func TestPostVerifications_TooLarge(t *testing.T) {
+	v := &stubVerifier{}
+
+	code, _ := post(t, v, config{maxBatch: 2}, "/v1/verifications", `["a@example.com","b@example.com","c@example.com"]`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	code, _ = post(t, v, config{maxBatch: 1}, "/v1/verifications", `["`+strings.Repeat("a", 2*maxEmailBytes)+`@example.com"]`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	assert.Empty(t, v.verified)
+}
+
+func TestPostVerifications_BadRequest(t *testing.T) {
+	v := &stubVerifier{}
+
+	code, _ := post(t, v, config{}, "/v1/verifications", `{"email":"a@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Empty(t, v.verified)
+}
+
+// slowVerifier blocks VerifyBatch until release is closed
+type slowVerifier struct {
+	stubVerifier
+	release chan struct{}
+}
+
+func (s *slowVerifier) VerifyBatch(emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult {
+	<-s.release
+	return nil
+}
+
+func TestPostVerifications_Timeout(t *testing.T) {
+	v := &slowVerifier{release: make(chan struct{})}
+	defer close(v.release)
+
+	code, body := post(t, v, config{batchTimeout: 10 * time.Millisecond}, "/v1/verifications", `["a@example.com"]`)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, codeTimeout, decodeError(t, body).Error.Code)
+}
+
+func TestPostVerifications_TimeoutStopsVerifications(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	stopped := make(chan struct{})
+	var once sync.Once
+	v, err := emailVerifier.NewVerifierWithOptions(
+		emailVerifier.WithSMTPCheck(),
+		emailVerifier.WithResolver(srv.Resolver()),
+		emailVerifier.WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			once.Do(func() { close(stopped) })
+			return nil, ctx.Err()
+		}),
+	)
+	assert.NoError(t, err)
+
+	code, body := post(t, v, config{batchTimeout: 50 * time.Millisecond}, "/v1/verifications", `["a@example.com","b@example.com"]`)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, codeTimeout, decodeError(t, body).Error.Code)
+
+	// the timeout interrupts the pending SMTP checks rather than leaving them running in the background
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the SMTP dial outlived the batch timeout")
+	}
+}
+
+// gatedVerifier verifies an email once a value is sent on its gate
+type gatedVerifier struct {
+	stubVerifier
+	gate chan struct{}
+}
+
+func (g *gatedVerifier) Verify(email string) (*emailVerifier.Result, error) {
+	<-g.gate
+	return &emailVerifier.Result{Email: email, Syntax: emailVerifier.Syntax{Valid: true}}, nil
+}
+
+func TestPostVerificationsStream(t *testing.T) {
+	v := &gatedVerifier{gate: make(chan struct{})}
+	ts := httptest.NewServer(newServer(v, config{}).routes())
+	defer ts.Close()
+
+	body, input := io.Pipe()
+	go func() {
+		for i := 0; i < 100; i++ {
+			_, _ = fmt.Fprintf(input, "user%d@example.com\n", i)
+		}
+		_ = input.Close()
+	}()
+
+	resp, err := http.Post(ts.URL+"/v1/verifications/stream", "text/plain", body)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	// each line is delivered as soon as its verification completes, before the next one is released
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(resp.Body)
+	for i := 0; i < 100; i++ {
+		v.gate <- struct{}{}
+		if !assert.True(t, scanner.Scan()) {
+			return
+		}
+		var verification bulkVerification
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &verification))
+		assert.NotNil(t, verification.Result)
+		seen[verification.Email] = true
+	}
+	assert.False(t, scanner.Scan())
+	assert.Len(t, seen, 100)
+}
+
+func TestPostVerificationsStream_ClientDisconnect(t *testing.T) {
+	v := &gatedVerifier{gate: make(chan struct{})}
+	ts := httptest.NewServer(newServer(v, config{}).routes())
+	defer ts.Close()
+
+	body, input := io.Pipe()
+	go func() {
+		for i := 0; ; i++ {
+			if _, err := fmt.Fprintf(input, "user%d@example.com\n", i); err != nil {
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequest("POST", ts.URL+"/v1/verifications/stream", body)
+	assert.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	assert.NoError(t, err)
+
+	v.gate <- struct{}{}
+	scanner := bufio.NewScanner(resp.Body)
+	assert.True(t, scanner.Scan())
+	cancel()
+	_ = resp.Body.Close()
+	_ = input.CloseWithError(errors.New("client gone"))
+
+	// the verifications which were waiting end, and the ones of the emails read before the disconnect was noticed,
+	// then no new one starts although the input never ended
+	started := 0
+	for {
+		select {
+		case v.gate <- struct{}{}:
+			started++
+			continue
+		case <-time.After(200 * time.Millisecond):
+		}
+		break
+	}
+	assert.True(t, started <= 2*streamConcurrency, "%d verifications after the disconnect", started)
+}
+
+func TestGetVerification_RequestTimeout(t *testing.T) {
+	v := &gatedVerifier{gate: make(chan struct{})}
+	defer close(v.gate)
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	routes := newServer(v, config{requestTimeout: 50 * time.Millisecond}).routes()
+	routes.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/verification?email=user@example.com", nil))
+	assert.True(t, time.Since(start) < time.Second, "answered after %s", time.Since(start))
+
+	// the partial result has the syntax of the address
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	resp := decodeError(t, rec.Body.String())
+	assert.Equal(t, codeRequestTimeout, resp.Error.Code)
+	result := resp.Result.(map[string]interface{})
+	assert.Equal(t, "user@example.com", result["email"])
+	assert.Equal(t, true, result["syntax"].(map[string]interface{})["valid"])
+}
+
+// stallingVerifier stalls a verification after its MX lookup until its context is done
+type stallingVerifier struct {
+	stubVerifier
+}
+
+func (s *stallingVerifier) VerifyWithContext(ctx context.Context, email string) (*emailVerifier.Result, error) {
+	<-ctx.Done()
+	return &emailVerifier.Result{Email: email, Syntax: emailVerifier.Syntax{Valid: true}, HasMxRecords: true}, ctx.Err()
+}
+
+func TestGetVerification_RequestTimeoutPartialResult(t *testing.T) {
+	v := &stallingVerifier{}
+	s := newServer(v, config{requestTimeout: 50 * time.Millisecond, cacheTTL: time.Hour, cacheSize: 10})
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest("GET", "/v1/verification?email=user@example.com", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	resp := decodeError(t, rec.Body.String())
+	assert.Equal(t, codeRequestTimeout, resp.Error.Code)
+	assert.Equal(t, true, resp.Result.(map[string]interface{})["has_mx_records"])
+
+	// an interrupted verification isn't cached
+	_, ok := s.cache.get("user@example.com")
+	assert.False(t, ok)
+}
+
+func TestGetVerification_ClientCancel(t *testing.T) {
+	v := &stallingVerifier{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v1/verification?email=user@example.com", nil).WithContext(ctx)
+	newServer(v, config{}).routes().ServeHTTP(rec, req)
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/cmd/grpcserver/verifierpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newResult returns the message of the result ret, nil if ret is nil
+func newResult(ret *emailVerifier.Result) *verifierpb.Result {
+	if ret == nil {
+		return nil
+	}
+	return &verifierpb.Result{
+		Email:            ret.Email,
+		CanonicalEmail:   ret.CanonicalEmail,
+		Name:             ret.Name,
+		Reachable:        ret.Reachable,
+		Syntax:           newSyntax(ret.Syntax),
+		Smtp:             newSMTP(ret.SMTP),
+		Gravatar:         newGravatar(ret.Gravatar),
+		Avatar:           newAvatar(ret.Avatar),
+		Suggestion:       ret.Suggestion,
+		Disposable:       ret.Disposable,
+		DisposableReason: ret.DisposableReason,
+		RoleAccount:      ret.RoleAccount,
+		Free:             ret.Free,
+		HasMxRecords:     ret.HasMxRecords,
+		Skipped:          ret.Skipped,
+		SkipReason:       ret.SkipReason,
+		Timings:          newTimings(ret.Timings),
+		SmtpChecked:      ret.SMTPChecked,
+		GravatarChecked:  ret.GravatarChecked,
+		DomainAuth:       newDomainAuth(ret.DomainAuth),
+		TimedOut:         ret.TimedOut,
+		TimedOutStages:   ret.TimedOutStages,
+		Quality:          ret.Quality,
+		Score:            int32(ret.Score),
+	}
+}
+
+func newSyntax(syntax emailVerifier.Syntax) *verifierpb.Syntax {
+	return &verifierpb.Syntax{
+		Username:      syntax.Username,
+		Domain:        syntax.Domain,
+		DomainAscii:   syntax.DomainASCII,
+		DomainUnicode: syntax.DomainUnicode,
+		Valid:         syntax.Valid,
+		HasSubAddress: syntax.HasSubAddress,
+		Tag:           syntax.Tag,
+		Reasons:       syntax.Reasons,
+	}
+}
+
+func newSMTP(smtp *emailVerifier.SMTP) *verifierpb.SMTP {
+	if smtp == nil {
+		return nil
+	}
+	return &verifierpb.SMTP{
+		HostExists:            smtp.HostExists,
+		FullInbox:             smtp.FullInbox,
+		CatchAll:              smtp.CatchAll,
+		Deliverable:           smtp.Deliverable,
+		Disabled:              smtp.Disabled,
+		Smtputf8Unsupported:   smtp.SMTPUTF8Unsupported,
+		ImplicitTls:           smtp.ImplicitTLS,
+		Blocked:               smtp.Blocked,
+		RejectReason:          smtp.RejectReason,
+		Error:                 newSMTPError(smtp.Error),
+		Relay:                 smtp.Relay,
+		MxOverride:            smtp.MXOverride,
+		HostUnreachableReason: smtp.HostUnreachableReason,
+		ConnectionRetries:     int32(smtp.ConnectionRetries),
+		ProbeReliability:      smtp.ProbeReliability,
+		Plan:                  newSMTPPlan(smtp.Plan),
+		FailedStage:           smtp.FailedStage,
+		ReplyCode:             int32(smtp.ReplyCode),
+		Starttls:              smtp.STARTTLS,
+		StarttlsError:         smtp.STARTTLSError,
+	}
+}
+
+func newGravatar(gravatar *emailVerifier.Gravatar) *verifierpb.Gravatar {
+	if gravatar == nil {
+		return nil
+	}
+	return &verifierpb.Gravatar{
+		HasGravatar: gravatar.HasGravatar,
+		GravatarUrl: gravatar.GravatarUrl,
+		Hash:        gravatar.Hash,
+		AvatarUrl:   gravatar.AvatarUrl,
+	}
+}
+
+func newAvatar(avatar *emailVerifier.Avatar) *verifierpb.Avatar {
+	if avatar == nil {
+		return nil
+	}
+	return &verifierpb.Avatar{
+		Provider:  avatar.Provider,
+		HasAvatar: avatar.HasAvatar,
+		Hash:      avatar.Hash,
+		Url:       avatar.Url,
+	}
+}
+
+func newTimings(timings emailVerifier.Timings) *verifierpb.Timings {
+	return &verifierpb.Timings{
+		Syntax:      durationpb.New(timings.Syntax),
+		Mx:          durationpb.New(timings.MX),
+		CatchAll:    durationpb.New(timings.CatchAll),
+		Deliverable: durationpb.New(timings.Deliverable),
+		Total:       durationpb.New(timings.Total),
+	}
+}
+
+// newDomainResult returns the message of the domain result ret, nil if ret is nil
+func newDomainResult(ret *emailVerifier.DomainResult) *verifierpb.DomainResult {
+	if ret == nil {
+		return nil
+	}
+	return &verifierpb.DomainResult{
+		Domain:           ret.Domain,
+		DomainAscii:      ret.DomainASCII,
+		DomainUnicode:    ret.DomainUnicode,
+		Valid:            ret.Valid,
+		Reasons:          ret.Reasons,
+		Resolves:         ret.Resolves,
+		HasMxRecords:     ret.HasMxRecords,
+		NullMx:           ret.NullMX,
+		Smtp:             newSMTP(ret.SMTP),
+		Suggestion:       ret.Suggestion,
+		Disposable:       ret.Disposable,
+		DisposableReason: ret.DisposableReason,
+		Free:             ret.Free,
+		Skipped:          ret.Skipped,
+		SkipReason:       ret.SkipReason,
+		DomainAuth:       newDomainAuth(ret.DomainAuth),
+	}
+}
+
+func newDomainAuth(auth *emailVerifier.DomainAuth) *verifierpb.DomainAuth {
+	if auth == nil {
+		return nil
+	}
+	ret := &verifierpb.DomainAuth{}
+	if auth.MTASTS != nil {
+		ret.MtaSts = &verifierpb.MTASTS{
+			Published:     auth.MTASTS.Published,
+			Id:            auth.MTASTS.ID,
+			Mode:          auth.MTASTS.Mode,
+			Mx:            auth.MTASTS.MX,
+			MaxAge:        int32(auth.MTASTS.MaxAge),
+			Misconfigured: auth.MTASTS.Misconfigured,
+			Error:         auth.MTASTS.Error,
+		}
+	}
+	if auth.SPF != nil {
+		ret.Spf = &verifierpb.SPF{
+			Published:     auth.SPF.Published,
+			Record:        auth.SPF.Record,
+			Policy:        auth.SPF.Policy,
+			Redirect:      auth.SPF.Redirect,
+			Misconfigured: auth.SPF.Misconfigured,
+			Error:         auth.SPF.Error,
+		}
+	}
+	if auth.DKIM != nil {
+		ret.Dkim = &verifierpb.DKIM{
+			Published: auth.DKIM.Published,
+			Selectors: auth.DKIM.Selectors,
+		}
+	}
+	if auth.DMARC != nil {
+		ret.Dmarc = &verifierpb.DMARC{
+			Published:       auth.DMARC.Published,
+			Record:          auth.DMARC.Record,
+			Policy:          auth.DMARC.Policy,
+			SubdomainPolicy: auth.DMARC.SubdomainPolicy,
+			Percent:         int32(auth.DMARC.Percent),
+			Rua:             auth.DMARC.RUA,
+			Misconfigured:   auth.DMARC.Misconfigured,
+			Error:           auth.DMARC.Error,
+		}
+	}
+	return ret
+}
+
+func newSMTPPlan(plan *emailVerifier.SMTPPlan) *verifierpb.SMTPPlan {
+	if plan == nil {
+		return nil
+	}
+	return &verifierpb.SMTPPlan{
+		Hosts:               plan.Hosts,
+		CatchAllAddress:     plan.CatchAllAddress,
+		CatchAllCommands:    plan.CatchAllCommands,
+		DeliverableCommands: plan.DeliverableCommands,
+	}
+}
+
+func newSMTPError(err *emailVerifier.LookupError) *verifierpb.SMTPError {
+	if err == nil {
+		return nil
+	}
+	return &verifierpb.SMTPError{Message: err.Message, Details: err.Details}
+}
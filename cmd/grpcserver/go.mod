@@ -0,0 +1,26 @@
+module github.com/vikt0r0/email-verifier/cmd/grpcserver
+
+go 1.25.0
+
+require (
+	github.com/stretchr/testify v1.7.1
+	github.com/vikt0r0/email-verifier v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260414002931-afd174a4e478
+	google.golang.org/grpc v1.82.1
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/hbollon/go-edlib v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.53.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+	golang.org/x/sys v0.43.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+	h12.io/socks v1.0.3 // indirect
+)
+
+// the server is built with the verifier of this repository
+replace github.com/vikt0r0/email-verifier => ../..
@@ -0,0 +1,224 @@
+// Command grpcserver serves the verifier over gRPC, see proto/emailverifier/v1/verifier.proto.
+// It's a module of its own, so the library doesn't depend on gRPC.
+package main
+
+//go:generate protoc -I proto --go_out=. --go_opt=module=github.com/vikt0r0/email-verifier/cmd/grpcserver --go-grpc_out=. --go-grpc_opt=module=github.com/vikt0r0/email-verifier/cmd/grpcserver emailverifier/v1/verifier.proto
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/cmd/grpcserver/verifierpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Exit codes of the grpcserver, the same as the apiserver's
+const (
+	exitOK              = 0 // the server shut down cleanly
+	exitServeError      = 1 // the listener failed
+	exitInvalidConfig   = 2
+	exitShutdownTimeout = 3 // calls were still in flight after the grace period
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// config is the configuration of the grpcserver, set by flags which default to environment variables.
+// The verifier is configured by the same variables as the apiserver's.
+type config struct {
+	listenAddr string        // address the server listens on
+	smtpCheck  bool          // whether the smtp check is enabled
+	softFail   bool          // whether an error of the smtp check is answered in the result instead of failing
+	domainAuth bool          // whether the domain auth check is enabled
+	proxy      string        // SOCKS5 proxy URI of the smtp check, none if empty
+	dns        string        // comma separated nameservers of the DNS lookups, the system ones if empty
+	helloName  string        // name of the `EHLO:` SMTP command, the verifier default if empty
+	fromEmail  string        // email of the `MAIL FROM:` SMTP command, the verifier default if empty
+	timeout    time.Duration // timeout of connecting to a mail server, the verifier default if zero
+
+	shutdownTimeout time.Duration // grace period of the calls in flight at shutdown
+}
+
+// parseConfig parses the config from the command line arguments args,
+// the flags default to the environment variables looked up by getenv
+func parseConfig(args []string, getenv func(string) string) (config, error) {
+	var c config
+	smtpCheck := true
+	if s := getenv("VERIFIER_SMTP_CHECK"); s != "" {
+		var err error
+		if smtpCheck, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_SMTP_CHECK %q", s)
+		}
+	}
+	softFail := true
+	if s := getenv("VERIFIER_SMTP_SOFT_FAIL"); s != "" {
+		var err error
+		if softFail, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_SMTP_SOFT_FAIL %q", s)
+		}
+	}
+	var domainAuth bool
+	if s := getenv("VERIFIER_DOMAIN_AUTH_CHECK"); s != "" {
+		var err error
+		if domainAuth, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_DOMAIN_AUTH_CHECK %q", s)
+		}
+	}
+	var timeout time.Duration
+	if s := getenv("VERIFIER_TIMEOUT"); s != "" {
+		var err error
+		if timeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_TIMEOUT %q", s)
+		}
+	}
+	shutdownTimeout := defaultShutdownTimeout
+	if s := getenv("VERIFIER_SHUTDOWN_TIMEOUT"); s != "" {
+		var err error
+		if shutdownTimeout, err = time.ParseDuration(s); err != nil {
+			return c, fmt.Errorf("invalid VERIFIER_SHUTDOWN_TIMEOUT %q", s)
+		}
+	}
+	listenAddr := getenv("VERIFIER_GRPC_LISTEN_ADDR")
+	if listenAddr == "" {
+		listenAddr = ":9090"
+	}
+
+	fs := flag.NewFlagSet("grpcserver", flag.ContinueOnError)
+	fs.StringVar(&c.listenAddr, "listen", listenAddr, "listen address (VERIFIER_GRPC_LISTEN_ADDR)")
+	fs.BoolVar(&c.smtpCheck, "smtp-check", smtpCheck, "enable the smtp check (VERIFIER_SMTP_CHECK)")
+	fs.BoolVar(&c.softFail, "smtp-soft-fail", softFail, "answer an error of the smtp check in the result (VERIFIER_SMTP_SOFT_FAIL)")
+	fs.BoolVar(&c.domainAuth, "domain-auth-check", domainAuth, "enable the MTA-STS check of the domains (VERIFIER_DOMAIN_AUTH_CHECK)")
+	fs.StringVar(&c.proxy, "proxy", getenv("VERIFIER_PROXY"), "comma separated SOCKS5 or HTTP proxy URIs of the smtp check, used in turn (VERIFIER_PROXY)")
+	fs.StringVar(&c.dns, "dns", getenv("VERIFIER_DNS"), "comma separated nameservers of the DNS lookups, e.g. 8.8.8.8:53, the system ones if empty (VERIFIER_DNS)")
+	fs.StringVar(&c.helloName, "hello-name", getenv("VERIFIER_HELLO_NAME"), "name of the EHLO command (VERIFIER_HELLO_NAME)")
+	fs.StringVar(&c.fromEmail, "from-email", getenv("VERIFIER_FROM_EMAIL"), "email of the MAIL FROM command (VERIFIER_FROM_EMAIL)")
+	fs.DurationVar(&c.timeout, "timeout", timeout, "timeout of connecting to a mail server (VERIFIER_TIMEOUT)")
+	fs.DurationVar(&c.shutdownTimeout, "shutdown-timeout", shutdownTimeout, "grace period of the calls in flight at shutdown (VERIFIER_SHUTDOWN_TIMEOUT)")
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+	if c.shutdownTimeout < 0 {
+		return c, fmt.Errorf("invalid shutdown timeout %s", c.shutdownTimeout)
+	}
+	return c, nil
+}
+
+// newVerifier creates the verifier configured by c
+func (c config) newVerifier() (*emailVerifier.Verifier, error) {
+	var opts []emailVerifier.Option
+	if c.smtpCheck {
+		opts = append(opts, emailVerifier.WithSMTPCheck())
+	}
+	if c.softFail {
+		opts = append(opts, emailVerifier.WithSMTPSoftFail())
+	}
+	if c.domainAuth {
+		opts = append(opts, emailVerifier.WithDomainAuthCheck())
+	}
+	if c.proxy != "" {
+		opts = append(opts, emailVerifier.WithProxies(strings.Split(c.proxy, ",")...))
+	}
+	if c.dns != "" {
+		opts = append(opts, emailVerifier.WithNameservers(strings.Split(c.dns, ",")...))
+	}
+	if c.helloName != "" {
+		opts = append(opts, emailVerifier.WithHelloName(c.helloName))
+	}
+	if c.fromEmail != "" {
+		opts = append(opts, emailVerifier.WithFromEmail(c.fromEmail))
+	}
+	if c.timeout != 0 {
+		opts = append(opts, emailVerifier.WithTimeout(c.timeout))
+	}
+	return emailVerifier.NewVerifierWithOptions(opts...)
+}
+
+// newGRPCServer returns the gRPC server of the Verifier service verifying with v, and of the health service
+func newGRPCServer(v verifier) *grpc.Server {
+	srv := grpc.NewServer()
+	verifierpb.RegisterVerifierServer(srv, &server{verifier: v})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
+
+// serve serves the connections of ln with srv until ctx is done, then stops srv gracefully:
+// the listener is closed and the calls in flight are given the grace period to complete.
+// It returns nil after a clean shutdown, errShutdownTimeout after the grace period,
+// and the error of the listener when it fails before ctx is done.
+func serve(ctx context.Context, srv *grpc.Server, ln net.Listener, grace time.Duration) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(grace):
+		srv.Stop()
+		return errShutdownTimeout
+	}
+}
+
+// errShutdownTimeout is returned by serve when calls are still in flight after the grace period
+var errShutdownTimeout = errors.New("shutdown grace period exceeded")
+
+func main() {
+	c, err := parseConfig(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	}
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		os.Exit(exitInvalidConfig)
+	}
+	verifier, err := c.newVerifier()
+	if err != nil {
+		log.Printf("invalid configuration: %v", err)
+		os.Exit(exitInvalidConfig)
+	}
+	ln, err := net.Listen("tcp", c.listenAddr)
+	if err != nil {
+		log.Printf("listen: %v", err)
+		os.Exit(exitServeError)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("listening on %s", ln.Addr())
+	switch err := serve(ctx, newGRPCServer(verifier), ln, c.shutdownTimeout); {
+	case err == nil:
+		log.Print("shut down")
+		os.Exit(exitOK)
+	case errors.Is(err, errShutdownTimeout):
+		log.Printf("shut down: %v", err)
+		os.Exit(exitShutdownTimeout)
+	default:
+		log.Printf("serve: %v", err)
+		os.Exit(exitServeError)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// env returns a getenv func of the variables vars
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := parseConfig(nil, env(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, config{listenAddr: ":9090", smtpCheck: true, softFail: true, shutdownTimeout: defaultShutdownTimeout}, c)
+
+	c, err = parseConfig([]string{"-listen", ":9091"}, env(map[string]string{
+		"VERIFIER_GRPC_LISTEN_ADDR":  ":8081",
+		"VERIFIER_SMTP_CHECK":        "false",
+		"VERIFIER_SMTP_SOFT_FAIL":    "false",
+		"VERIFIER_DOMAIN_AUTH_CHECK": "true",
+		"VERIFIER_PROXY":             "socks5://127.0.0.1:1080",
+		"VERIFIER_DNS":               "8.8.8.8:53",
+		"VERIFIER_TIMEOUT":           "10s",
+		"VERIFIER_SHUTDOWN_TIMEOUT":  "5s",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, config{
+		listenAddr:      ":9091",
+		domainAuth:      true,
+		proxy:           "socks5://127.0.0.1:1080",
+		dns:             "8.8.8.8:53",
+		timeout:         10 * time.Second,
+		shutdownTimeout: 5 * time.Second,
+	}, c)
+	_, err = c.newVerifier()
+	assert.NoError(t, err)
+
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_SMTP_SOFT_FAIL": "maybe"}))
+	assert.Error(t, err)
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_DOMAIN_AUTH_CHECK": "maybe"}))
+	assert.Error(t, err)
+	_, err = parseConfig(nil, env(map[string]string{"VERIFIER_TIMEOUT": "soon"}))
+	assert.Error(t, err)
+	_, err = parseConfig([]string{"-shutdown-timeout", "-1s"}, env(nil))
+	assert.Error(t, err)
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	v := &stubVerifier{gate: make(chan struct{})}
+	defer close(v.gate)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	client := dialAddr(t, ln.Addr().String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, newGRPCServer(v), ln, 50*time.Millisecond) }()
+
+	// a call stalled in a verification outlives the grace period
+	go func() { _, _ = client.VerifyEmail(context.Background(), verifyRequest("user@example.com")) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	assert.Equal(t, errShutdownTimeout, <-served)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/cmd/grpcserver/verifierpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// batchConcurrency is the number of emails of a batch verified concurrently
+const batchConcurrency = 10
+
+// verifier is the part of *emailVerifier.Verifier used by the server
+type verifier interface {
+	Verify(email string) (*emailVerifier.Result, error)
+	VerifyDomain(domain string) (*emailVerifier.DomainResult, error)
+}
+
+// contextVerifier is a verifier whose verifications stop when their context is done,
+// with the partial result computed until then
+type contextVerifier interface {
+	VerifyWithContext(ctx context.Context, email string) (*emailVerifier.Result, error)
+}
+
+// domainContextVerifier is a verifier whose domain verifications stop when their context is done,
+// with the partial result computed until then
+type domainContextVerifier interface {
+	VerifyDomainWithContext(ctx context.Context, domain string) (*emailVerifier.DomainResult, error)
+}
+
+// server serves the Verifier service with a verifier shared by all calls
+type server struct {
+	verifierpb.UnimplementedVerifierServer
+	verifier verifier
+}
+
+// VerifyEmail verifies the email of the request until the deadline of ctx
+func (s *server) VerifyEmail(ctx context.Context, req *verifierpb.VerifyEmailRequest) (*verifierpb.VerifyEmailResponse, error) {
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing email")
+	}
+	ret, err := s.verify(ctx, req.GetEmail())
+	if st := resultStatus(ret, err); st != nil {
+		return nil, st.Err()
+	}
+	return &verifierpb.VerifyEmailResponse{Result: newResult(ret)}, nil
+}
+
+// VerifyDomain verifies the domain of the request until the deadline of ctx
+func (s *server) VerifyDomain(ctx context.Context, req *verifierpb.VerifyDomainRequest) (*verifierpb.VerifyDomainResponse, error) {
+	if req.GetDomain() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing domain")
+	}
+
+	ret, err := s.verifyDomain(ctx, req.GetDomain())
+	if err != nil && ret == nil {
+		return nil, status.New(verificationCode(err), err.Error()).Err()
+	}
+	if err != nil {
+		return nil, withDetails(status.New(verificationCode(err), err.Error()), newDomainResult(ret)).Err()
+	}
+	if !ret.Valid {
+		return nil, withDetails(status.New(codes.InvalidArgument, "domain syntax is invalid"), newDomainResult(ret)).Err()
+	}
+	return &verifierpb.VerifyDomainResponse{Result: newDomainResult(ret)}, nil
+}
+
+// VerifyBatch verifies the emails received on stream, batchConcurrency at a time, and sends the response
+// of each as soon as its verification completes. A client disconnect stops the verifications which haven't
+// started yet.
+func (s *server) VerifyBatch(stream verifierpb.Verifier_VerifyBatchServer) error {
+	ctx := stream.Context()
+
+	emails := make(chan string)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(emails)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					recvErr <- err
+				}
+				return
+			}
+			select {
+			case emails <- req.GetEmail():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	responses := make(chan *verifierpb.VerifyBatchResponse)
+	var wg sync.WaitGroup
+	for i := 0; i < batchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for email := range emails {
+				if ctx.Err() != nil {
+					continue
+				}
+				resp := s.verifyBatchEmail(ctx, email)
+				select {
+				case responses <- resp:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(responses)
+	}()
+
+	for resp := range responses {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	select {
+	case err := <-recvErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// verifyBatchEmail returns the response of email of a batch
+func (s *server) verifyBatchEmail(ctx context.Context, email string) *verifierpb.VerifyBatchResponse {
+	ret, err := s.verify(ctx, email)
+	if st := resultStatus(ret, err); st != nil {
+		return &verifierpb.VerifyBatchResponse{Email: email, Error: st.Proto()}
+	}
+	return &verifierpb.VerifyBatchResponse{Email: email, Result: newResult(ret)}
+}
+
+// verify verifies email until ctx is done. A verifier which doesn't observe the context keeps verifying
+// in the background until its own timeouts, the partial result is then only the syntax of the address.
+func (s *server) verify(ctx context.Context, email string) (*emailVerifier.Result, error) {
+	if v, ok := s.verifier.(contextVerifier); ok {
+		return v.VerifyWithContext(ctx, email)
+	}
+
+	var ret *emailVerifier.Result
+	var err error
+	if ctxErr := await(ctx, func() { ret, err = s.verifier.Verify(email) }); ctxErr != nil {
+		syntax, _ := emailVerifier.ParseAddress(email)
+		partial := emailVerifier.NewResult(email)
+		partial.Syntax = *syntax
+		return partial, ctxErr
+	}
+	return ret, err
+}
+
+// verifyDomain verifies domain until ctx is done. A verifier which doesn't observe the context keeps verifying
+// in the background until its own timeouts, there is then no partial result.
+func (s *server) verifyDomain(ctx context.Context, domain string) (*emailVerifier.DomainResult, error) {
+	if v, ok := s.verifier.(domainContextVerifier); ok {
+		return v.VerifyDomainWithContext(ctx, domain)
+	}
+
+	var ret *emailVerifier.DomainResult
+	var err error
+	if ctxErr := await(ctx, func() { ret, err = s.verifier.VerifyDomain(domain) }); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return ret, err
+}
+
+// await runs f until it returns or ctx is done, and returns the error of ctx in the latter case.
+// f keeps running in the background after ctx is done, its outcome must then be ignored.
+func await(ctx context.Context, f func()) error {
+	done := make(chan struct{})
+	go func() {
+		f()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resultStatus returns the status of a failed verification with its partial result ret in the details,
+// nil if the verification succeeded
+func resultStatus(ret *emailVerifier.Result, err error) *status.Status {
+	switch {
+	case err != nil:
+		return withDetails(status.New(verificationCode(err), err.Error()), newResult(ret))
+	case !ret.Syntax.Valid:
+		return withDetails(status.New(codes.InvalidArgument, "email address syntax is invalid"), newResult(ret))
+	default:
+		return nil
+	}
+}
+
+// verificationCode returns the status code of an error returned by a verification:
+// DeadlineExceeded for the deadline of the call or a mail server timeout, Unavailable for other SMTP
+// and DNS failures and Internal for anything else
+func verificationCode(err error) codes.Code {
+	var lookupErr *emailVerifier.LookupError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.As(err, &lookupErr) && lookupErr.Message == emailVerifier.ErrTimeout:
+		return codes.DeadlineExceeded
+	case errors.As(err, &lookupErr):
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// withDetails returns st with the partial result in its details, st itself if there is none
+func withDetails(st *status.Status, partial proto.Message) *status.Status {
+	if !partial.ProtoReflect().IsValid() {
+		return st
+	}
+	if withPartial, err := st.WithDetails(protoadapt.MessageV1Of(partial)); err == nil {
+		return withPartial
+	}
+	return st
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/cmd/grpcserver/verifierpb"
+	"github.com/vikt0r0/email-verifier/smtptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// stubVerifier answers with its results and errors, and waits for its gate when set.
+// Without a result, an email with an @ is valid and reachable.
+type stubVerifier struct {
+	result       *emailVerifier.Result
+	domainResult *emailVerifier.DomainResult
+	err          error
+	gate         chan struct{}
+}
+
+func (s *stubVerifier) Verify(email string) (*emailVerifier.Result, error) {
+	if s.gate != nil {
+		<-s.gate
+	}
+	if s.result == nil {
+		syntax := emailVerifier.Syntax{Valid: strings.Contains(email, "@")}
+		return &emailVerifier.Result{Email: email, Reachable: "yes", Syntax: syntax}, s.err
+	}
+	return s.result, s.err
+}
+
+func (s *stubVerifier) VerifyDomain(domain string) (*emailVerifier.DomainResult, error) {
+	return s.domainResult, s.err
+}
+
+// dial returns a client of a server verifying with v, stopped when the test ends
+func dial(t *testing.T, v verifier) verifierpb.VerifierClient {
+	ln := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(v)
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return verifierpb.NewVerifierClient(conn)
+}
+
+// dialAddr returns a client of the server listening on the TCP address addr
+func dialAddr(t *testing.T, addr string) verifierpb.VerifierClient {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return verifierpb.NewVerifierClient(conn)
+}
+
+func verifyRequest(email string) *verifierpb.VerifyEmailRequest {
+	return &verifierpb.VerifyEmailRequest{Email: email}
+}
+
+// partialResult returns the partial result in the details of the status of err
+func partialResult(t *testing.T, err error) *verifierpb.Result {
+	for _, detail := range status.Convert(err).Details() {
+		if ret, ok := detail.(*verifierpb.Result); ok {
+			return ret
+		}
+	}
+	t.Fatalf("no partial result in %v", err)
+	return nil
+}
+
+func TestVerifyEmail(t *testing.T) {
+	client := dial(t, &stubVerifier{result: &emailVerifier.Result{
+		Email:     "user@example.com",
+		Reachable: "yes",
+		Syntax:    emailVerifier.Syntax{Username: "user", Domain: "example.com", Valid: true},
+		SMTP:      &emailVerifier.SMTP{HostExists: true, Deliverable: true},
+		Timings:   emailVerifier.Timings{Total: 2 * time.Second},
+	}})
+
+	resp, err := client.VerifyEmail(context.Background(), verifyRequest("user@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "yes", resp.GetResult().GetReachable())
+	assert.Equal(t, "example.com", resp.GetResult().GetSyntax().GetDomain())
+	assert.True(t, resp.GetResult().GetSmtp().GetDeliverable())
+	assert.Nil(t, resp.GetResult().GetGravatar())
+	assert.Equal(t, 2*time.Second, resp.GetResult().GetTimings().GetTotal().AsDuration())
+}
+
+func TestVerifyEmail_Errors(t *testing.T) {
+	tests := []struct {
+		verifier *stubVerifier
+		email    string
+		code     codes.Code
+	}{
+		{&stubVerifier{}, "", codes.InvalidArgument},
+		{&stubVerifier{result: &emailVerifier.Result{Email: "invalid"}}, "invalid", codes.InvalidArgument},
+		{&stubVerifier{err: &emailVerifier.LookupError{Message: emailVerifier.ErrTimeout}}, "user@example.com", codes.DeadlineExceeded},
+		{&stubVerifier{err: &emailVerifier.LookupError{Message: emailVerifier.ErrNoSuchHost}}, "user@example.com", codes.Unavailable},
+		{&stubVerifier{err: errors.New("unexpected")}, "user@example.com", codes.Internal},
+	}
+	for _, test := range tests {
+		_, err := dial(t, test.verifier).VerifyEmail(context.Background(), verifyRequest(test.email))
+		assert.Equal(t, test.code, status.Code(err), test.email)
+	}
+
+	// the status of an invalid address has its partial result
+	_, err := dial(t, tests[1].verifier).VerifyEmail(context.Background(), verifyRequest("invalid"))
+	assert.Equal(t, "invalid", partialResult(t, err).GetEmail())
+}
+
+func TestVerifyEmail_Deadline(t *testing.T) {
+	v := &stubVerifier{gate: make(chan struct{})}
+	defer close(v.gate)
+	client := dial(t, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.VerifyEmail(ctx, verifyRequest("user@example.com"))
+	assert.True(t, time.Since(start) < time.Second, "answered after %s", time.Since(start))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestVerifyDomain(t *testing.T) {
+	client := dial(t, &stubVerifier{domainResult: &emailVerifier.DomainResult{Domain: "example.com", Valid: true, HasMxRecords: true}})
+	resp, err := client.VerifyDomain(context.Background(), &verifierpb.VerifyDomainRequest{Domain: "example.com"})
+	assert.NoError(t, err)
+	assert.True(t, resp.GetResult().GetHasMxRecords())
+
+	client = dial(t, &stubVerifier{domainResult: &emailVerifier.DomainResult{Domain: "invalid"}})
+	_, err = client.VerifyDomain(context.Background(), &verifierpb.VerifyDomainRequest{Domain: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Len(t, status.Convert(err).Details(), 1)
+}
+
+func TestVerifyDomain_Deadline(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	stopped := make(chan struct{})
+	var once sync.Once
+	v, err := emailVerifier.NewVerifierWithOptions(
+		emailVerifier.WithSMTPCheck(),
+		emailVerifier.WithResolver(srv.Resolver()),
+		emailVerifier.WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			once.Do(func() { close(stopped) })
+			return nil, ctx.Err()
+		}),
+	)
+	assert.NoError(t, err)
+	client := dial(t, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.VerifyDomain(ctx, &verifierpb.VerifyDomainRequest{Domain: "example.com"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// the deadline of the call reaches the SMTP checks, which don't outlive it
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the SMTP dial outlived the deadline of the call")
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	client := dial(t, &stubVerifier{})
+	stream, err := client.VerifyBatch(context.Background())
+	assert.NoError(t, err)
+
+	go func() {
+		for i := 0; i < 50; i++ {
+			_ = stream.Send(&verifierpb.VerifyBatchRequest{Email: fmt.Sprintf("user%d@example.com", i)})
+		}
+		_ = stream.Send(&verifierpb.VerifyBatchRequest{Email: "invalid"})
+		_ = stream.CloseSend()
+	}()
+
+	seen := make(map[string]bool)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		seen[resp.GetEmail()] = true
+		if resp.GetEmail() == "invalid" {
+			assert.Equal(t, int32(codes.InvalidArgument), resp.GetError().GetCode())
+			continue
+		}
+		assert.Equal(t, "yes", resp.GetResult().GetReachable())
+	}
+	assert.Len(t, seen, 51)
+}
@@ -0,0 +1,1992 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: emailverifier/v1/verifier.proto
+
+package verifierpb
+
+import (
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type VerifyEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyEmailRequest) Reset() {
+	*x = VerifyEmailRequest{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailRequest) ProtoMessage() {}
+
+func (x *VerifyEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEmailRequest.ProtoReflect.Descriptor instead.
+func (*VerifyEmailRequest) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *VerifyEmailRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type VerifyEmailResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *Result                `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyEmailResponse) Reset() {
+	*x = VerifyEmailResponse{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyEmailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailResponse) ProtoMessage() {}
+
+func (x *VerifyEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEmailResponse.ProtoReflect.Descriptor instead.
+func (*VerifyEmailResponse) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VerifyEmailResponse) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type VerifyDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyDomainRequest) Reset() {
+	*x = VerifyDomainRequest{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyDomainRequest) ProtoMessage() {}
+
+func (x *VerifyDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyDomainRequest.ProtoReflect.Descriptor instead.
+func (*VerifyDomainRequest) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *VerifyDomainRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type VerifyDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *DomainResult          `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyDomainResponse) Reset() {
+	*x = VerifyDomainResponse{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyDomainResponse) ProtoMessage() {}
+
+func (x *VerifyDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyDomainResponse.ProtoReflect.Descriptor instead.
+func (*VerifyDomainResponse) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VerifyDomainResponse) GetResult() *DomainResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type VerifyBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyBatchRequest) Reset() {
+	*x = VerifyBatchRequest{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyBatchRequest) ProtoMessage() {}
+
+func (x *VerifyBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyBatchRequest.ProtoReflect.Descriptor instead.
+func (*VerifyBatchRequest) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyBatchRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type VerifyBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Result        *Result                `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Error         *status.Status         `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyBatchResponse) Reset() {
+	*x = VerifyBatchResponse{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyBatchResponse) ProtoMessage() {}
+
+func (x *VerifyBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyBatchResponse.ProtoReflect.Descriptor instead.
+func (*VerifyBatchResponse) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VerifyBatchResponse) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *VerifyBatchResponse) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *VerifyBatchResponse) GetError() *status.Status {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type Result struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Email            string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	CanonicalEmail   string                 `protobuf:"bytes,2,opt,name=canonical_email,json=canonicalEmail,proto3" json:"canonical_email,omitempty"`
+	Name             string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Reachable        string                 `protobuf:"bytes,4,opt,name=reachable,proto3" json:"reachable,omitempty"`
+	Syntax           *Syntax                `protobuf:"bytes,5,opt,name=syntax,proto3" json:"syntax,omitempty"`
+	Smtp             *SMTP                  `protobuf:"bytes,6,opt,name=smtp,proto3" json:"smtp,omitempty"`
+	Gravatar         *Gravatar              `protobuf:"bytes,7,opt,name=gravatar,proto3" json:"gravatar,omitempty"`
+	Avatar           *Avatar                `protobuf:"bytes,8,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	Suggestion       string                 `protobuf:"bytes,9,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	Disposable       bool                   `protobuf:"varint,10,opt,name=disposable,proto3" json:"disposable,omitempty"`
+	DisposableReason string                 `protobuf:"bytes,11,opt,name=disposable_reason,json=disposableReason,proto3" json:"disposable_reason,omitempty"`
+	RoleAccount      bool                   `protobuf:"varint,12,opt,name=role_account,json=roleAccount,proto3" json:"role_account,omitempty"`
+	Free             bool                   `protobuf:"varint,13,opt,name=free,proto3" json:"free,omitempty"`
+	HasMxRecords     bool                   `protobuf:"varint,14,opt,name=has_mx_records,json=hasMxRecords,proto3" json:"has_mx_records,omitempty"`
+	Skipped          bool                   `protobuf:"varint,15,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	SkipReason       string                 `protobuf:"bytes,16,opt,name=skip_reason,json=skipReason,proto3" json:"skip_reason,omitempty"`
+	Timings          *Timings               `protobuf:"bytes,17,opt,name=timings,proto3" json:"timings,omitempty"`
+	SmtpChecked      bool                   `protobuf:"varint,18,opt,name=smtp_checked,json=smtpChecked,proto3" json:"smtp_checked,omitempty"`
+	GravatarChecked  bool                   `protobuf:"varint,19,opt,name=gravatar_checked,json=gravatarChecked,proto3" json:"gravatar_checked,omitempty"`
+	DomainAuth       *DomainAuth            `protobuf:"bytes,20,opt,name=domain_auth,json=domainAuth,proto3" json:"domain_auth,omitempty"`
+	TimedOut         bool                   `protobuf:"varint,21,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	TimedOutStages   []string               `protobuf:"bytes,22,rep,name=timed_out_stages,json=timedOutStages,proto3" json:"timed_out_stages,omitempty"`
+	Quality          string                 `protobuf:"bytes,23,opt,name=quality,proto3" json:"quality,omitempty"`
+	Score            int32                  `protobuf:"varint,24,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Result) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Result) GetCanonicalEmail() string {
+	if x != nil {
+		return x.CanonicalEmail
+	}
+	return ""
+}
+
+func (x *Result) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Result) GetReachable() string {
+	if x != nil {
+		return x.Reachable
+	}
+	return ""
+}
+
+func (x *Result) GetSyntax() *Syntax {
+	if x != nil {
+		return x.Syntax
+	}
+	return nil
+}
+
+func (x *Result) GetSmtp() *SMTP {
+	if x != nil {
+		return x.Smtp
+	}
+	return nil
+}
+
+func (x *Result) GetGravatar() *Gravatar {
+	if x != nil {
+		return x.Gravatar
+	}
+	return nil
+}
+
+func (x *Result) GetAvatar() *Avatar {
+	if x != nil {
+		return x.Avatar
+	}
+	return nil
+}
+
+func (x *Result) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *Result) GetDisposable() bool {
+	if x != nil {
+		return x.Disposable
+	}
+	return false
+}
+
+func (x *Result) GetDisposableReason() string {
+	if x != nil {
+		return x.DisposableReason
+	}
+	return ""
+}
+
+func (x *Result) GetRoleAccount() bool {
+	if x != nil {
+		return x.RoleAccount
+	}
+	return false
+}
+
+func (x *Result) GetFree() bool {
+	if x != nil {
+		return x.Free
+	}
+	return false
+}
+
+func (x *Result) GetHasMxRecords() bool {
+	if x != nil {
+		return x.HasMxRecords
+	}
+	return false
+}
+
+func (x *Result) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+func (x *Result) GetSkipReason() string {
+	if x != nil {
+		return x.SkipReason
+	}
+	return ""
+}
+
+func (x *Result) GetTimings() *Timings {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+func (x *Result) GetSmtpChecked() bool {
+	if x != nil {
+		return x.SmtpChecked
+	}
+	return false
+}
+
+func (x *Result) GetGravatarChecked() bool {
+	if x != nil {
+		return x.GravatarChecked
+	}
+	return false
+}
+
+func (x *Result) GetDomainAuth() *DomainAuth {
+	if x != nil {
+		return x.DomainAuth
+	}
+	return nil
+}
+
+func (x *Result) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+func (x *Result) GetTimedOutStages() []string {
+	if x != nil {
+		return x.TimedOutStages
+	}
+	return nil
+}
+
+func (x *Result) GetQuality() string {
+	if x != nil {
+		return x.Quality
+	}
+	return ""
+}
+
+func (x *Result) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Syntax struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	DomainAscii   string                 `protobuf:"bytes,3,opt,name=domain_ascii,json=domainAscii,proto3" json:"domain_ascii,omitempty"`
+	DomainUnicode string                 `protobuf:"bytes,4,opt,name=domain_unicode,json=domainUnicode,proto3" json:"domain_unicode,omitempty"`
+	Valid         bool                   `protobuf:"varint,5,opt,name=valid,proto3" json:"valid,omitempty"`
+	HasSubAddress bool                   `protobuf:"varint,6,opt,name=has_sub_address,json=hasSubAddress,proto3" json:"has_sub_address,omitempty"`
+	Tag           string                 `protobuf:"bytes,7,opt,name=tag,proto3" json:"tag,omitempty"`
+	Reasons       []string               `protobuf:"bytes,8,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Syntax) Reset() {
+	*x = Syntax{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Syntax) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Syntax) ProtoMessage() {}
+
+func (x *Syntax) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Syntax.ProtoReflect.Descriptor instead.
+func (*Syntax) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Syntax) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Syntax) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Syntax) GetDomainAscii() string {
+	if x != nil {
+		return x.DomainAscii
+	}
+	return ""
+}
+
+func (x *Syntax) GetDomainUnicode() string {
+	if x != nil {
+		return x.DomainUnicode
+	}
+	return ""
+}
+
+func (x *Syntax) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Syntax) GetHasSubAddress() bool {
+	if x != nil {
+		return x.HasSubAddress
+	}
+	return false
+}
+
+func (x *Syntax) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *Syntax) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+type SMTP struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	HostExists            bool                   `protobuf:"varint,1,opt,name=host_exists,json=hostExists,proto3" json:"host_exists,omitempty"`
+	FullInbox             bool                   `protobuf:"varint,2,opt,name=full_inbox,json=fullInbox,proto3" json:"full_inbox,omitempty"`
+	CatchAll              bool                   `protobuf:"varint,3,opt,name=catch_all,json=catchAll,proto3" json:"catch_all,omitempty"`
+	Deliverable           bool                   `protobuf:"varint,4,opt,name=deliverable,proto3" json:"deliverable,omitempty"`
+	Disabled              bool                   `protobuf:"varint,5,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	Smtputf8Unsupported   bool                   `protobuf:"varint,6,opt,name=smtputf8_unsupported,json=smtputf8Unsupported,proto3" json:"smtputf8_unsupported,omitempty"`
+	ImplicitTls           bool                   `protobuf:"varint,7,opt,name=implicit_tls,json=implicitTls,proto3" json:"implicit_tls,omitempty"`
+	Blocked               bool                   `protobuf:"varint,8,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	RejectReason          string                 `protobuf:"bytes,9,opt,name=reject_reason,json=rejectReason,proto3" json:"reject_reason,omitempty"`
+	Error                 *SMTPError             `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	Relay                 string                 `protobuf:"bytes,11,opt,name=relay,proto3" json:"relay,omitempty"`
+	MxOverride            bool                   `protobuf:"varint,12,opt,name=mx_override,json=mxOverride,proto3" json:"mx_override,omitempty"`
+	HostUnreachableReason string                 `protobuf:"bytes,13,opt,name=host_unreachable_reason,json=hostUnreachableReason,proto3" json:"host_unreachable_reason,omitempty"`
+	ConnectionRetries     int32                  `protobuf:"varint,14,opt,name=connection_retries,json=connectionRetries,proto3" json:"connection_retries,omitempty"`
+	ProbeReliability      string                 `protobuf:"bytes,15,opt,name=probe_reliability,json=probeReliability,proto3" json:"probe_reliability,omitempty"`
+	Plan                  *SMTPPlan              `protobuf:"bytes,16,opt,name=plan,proto3" json:"plan,omitempty"`
+	FailedStage           string                 `protobuf:"bytes,17,opt,name=failed_stage,json=failedStage,proto3" json:"failed_stage,omitempty"`
+	ReplyCode             int32                  `protobuf:"varint,18,opt,name=reply_code,json=replyCode,proto3" json:"reply_code,omitempty"`
+	Starttls              bool                   `protobuf:"varint,19,opt,name=starttls,proto3" json:"starttls,omitempty"`
+	StarttlsError         string                 `protobuf:"bytes,20,opt,name=starttls_error,json=starttlsError,proto3" json:"starttls_error,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SMTP) Reset() {
+	*x = SMTP{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SMTP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SMTP) ProtoMessage() {}
+
+func (x *SMTP) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SMTP.ProtoReflect.Descriptor instead.
+func (*SMTP) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SMTP) GetHostExists() bool {
+	if x != nil {
+		return x.HostExists
+	}
+	return false
+}
+
+func (x *SMTP) GetFullInbox() bool {
+	if x != nil {
+		return x.FullInbox
+	}
+	return false
+}
+
+func (x *SMTP) GetCatchAll() bool {
+	if x != nil {
+		return x.CatchAll
+	}
+	return false
+}
+
+func (x *SMTP) GetDeliverable() bool {
+	if x != nil {
+		return x.Deliverable
+	}
+	return false
+}
+
+func (x *SMTP) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *SMTP) GetSmtputf8Unsupported() bool {
+	if x != nil {
+		return x.Smtputf8Unsupported
+	}
+	return false
+}
+
+func (x *SMTP) GetImplicitTls() bool {
+	if x != nil {
+		return x.ImplicitTls
+	}
+	return false
+}
+
+func (x *SMTP) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+func (x *SMTP) GetRejectReason() string {
+	if x != nil {
+		return x.RejectReason
+	}
+	return ""
+}
+
+func (x *SMTP) GetError() *SMTPError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *SMTP) GetRelay() string {
+	if x != nil {
+		return x.Relay
+	}
+	return ""
+}
+
+func (x *SMTP) GetMxOverride() bool {
+	if x != nil {
+		return x.MxOverride
+	}
+	return false
+}
+
+func (x *SMTP) GetHostUnreachableReason() string {
+	if x != nil {
+		return x.HostUnreachableReason
+	}
+	return ""
+}
+
+func (x *SMTP) GetConnectionRetries() int32 {
+	if x != nil {
+		return x.ConnectionRetries
+	}
+	return 0
+}
+
+func (x *SMTP) GetProbeReliability() string {
+	if x != nil {
+		return x.ProbeReliability
+	}
+	return ""
+}
+
+func (x *SMTP) GetPlan() *SMTPPlan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *SMTP) GetFailedStage() string {
+	if x != nil {
+		return x.FailedStage
+	}
+	return ""
+}
+
+func (x *SMTP) GetReplyCode() int32 {
+	if x != nil {
+		return x.ReplyCode
+	}
+	return 0
+}
+
+func (x *SMTP) GetStarttls() bool {
+	if x != nil {
+		return x.Starttls
+	}
+	return false
+}
+
+func (x *SMTP) GetStarttlsError() string {
+	if x != nil {
+		return x.StarttlsError
+	}
+	return ""
+}
+
+type SMTPError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Details       string                 `protobuf:"bytes,2,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SMTPError) Reset() {
+	*x = SMTPError{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SMTPError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SMTPError) ProtoMessage() {}
+
+func (x *SMTPError) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SMTPError.ProtoReflect.Descriptor instead.
+func (*SMTPError) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SMTPError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SMTPError) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+type SMTPPlan struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Hosts               []string               `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	CatchAllAddress     string                 `protobuf:"bytes,2,opt,name=catch_all_address,json=catchAllAddress,proto3" json:"catch_all_address,omitempty"`
+	CatchAllCommands    []string               `protobuf:"bytes,3,rep,name=catch_all_commands,json=catchAllCommands,proto3" json:"catch_all_commands,omitempty"`
+	DeliverableCommands []string               `protobuf:"bytes,4,rep,name=deliverable_commands,json=deliverableCommands,proto3" json:"deliverable_commands,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SMTPPlan) Reset() {
+	*x = SMTPPlan{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SMTPPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SMTPPlan) ProtoMessage() {}
+
+func (x *SMTPPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SMTPPlan.ProtoReflect.Descriptor instead.
+func (*SMTPPlan) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SMTPPlan) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *SMTPPlan) GetCatchAllAddress() string {
+	if x != nil {
+		return x.CatchAllAddress
+	}
+	return ""
+}
+
+func (x *SMTPPlan) GetCatchAllCommands() []string {
+	if x != nil {
+		return x.CatchAllCommands
+	}
+	return nil
+}
+
+func (x *SMTPPlan) GetDeliverableCommands() []string {
+	if x != nil {
+		return x.DeliverableCommands
+	}
+	return nil
+}
+
+type DomainAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MtaSts        *MTASTS                `protobuf:"bytes,1,opt,name=mta_sts,json=mtaSts,proto3" json:"mta_sts,omitempty"`
+	Spf           *SPF                   `protobuf:"bytes,2,opt,name=spf,proto3" json:"spf,omitempty"`
+	Dkim          *DKIM                  `protobuf:"bytes,3,opt,name=dkim,proto3" json:"dkim,omitempty"`
+	Dmarc         *DMARC                 `protobuf:"bytes,4,opt,name=dmarc,proto3" json:"dmarc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DomainAuth) Reset() {
+	*x = DomainAuth{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DomainAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DomainAuth) ProtoMessage() {}
+
+func (x *DomainAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DomainAuth.ProtoReflect.Descriptor instead.
+func (*DomainAuth) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DomainAuth) GetMtaSts() *MTASTS {
+	if x != nil {
+		return x.MtaSts
+	}
+	return nil
+}
+
+func (x *DomainAuth) GetSpf() *SPF {
+	if x != nil {
+		return x.Spf
+	}
+	return nil
+}
+
+func (x *DomainAuth) GetDkim() *DKIM {
+	if x != nil {
+		return x.Dkim
+	}
+	return nil
+}
+
+func (x *DomainAuth) GetDmarc() *DMARC {
+	if x != nil {
+		return x.Dmarc
+	}
+	return nil
+}
+
+type MTASTS struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Published     bool                   `protobuf:"varint,1,opt,name=published,proto3" json:"published,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Mode          string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	Mx            []string               `protobuf:"bytes,4,rep,name=mx,proto3" json:"mx,omitempty"`
+	MaxAge        int32                  `protobuf:"varint,5,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`
+	Misconfigured bool                   `protobuf:"varint,6,opt,name=misconfigured,proto3" json:"misconfigured,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MTASTS) Reset() {
+	*x = MTASTS{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MTASTS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MTASTS) ProtoMessage() {}
+
+func (x *MTASTS) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MTASTS.ProtoReflect.Descriptor instead.
+func (*MTASTS) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MTASTS) GetPublished() bool {
+	if x != nil {
+		return x.Published
+	}
+	return false
+}
+
+func (x *MTASTS) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MTASTS) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *MTASTS) GetMx() []string {
+	if x != nil {
+		return x.Mx
+	}
+	return nil
+}
+
+func (x *MTASTS) GetMaxAge() int32 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+func (x *MTASTS) GetMisconfigured() bool {
+	if x != nil {
+		return x.Misconfigured
+	}
+	return false
+}
+
+func (x *MTASTS) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SPF struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Published     bool                   `protobuf:"varint,1,opt,name=published,proto3" json:"published,omitempty"`
+	Record        string                 `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	Policy        string                 `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	Redirect      string                 `protobuf:"bytes,4,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	Misconfigured bool                   `protobuf:"varint,5,opt,name=misconfigured,proto3" json:"misconfigured,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SPF) Reset() {
+	*x = SPF{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SPF) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SPF) ProtoMessage() {}
+
+func (x *SPF) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SPF.ProtoReflect.Descriptor instead.
+func (*SPF) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SPF) GetPublished() bool {
+	if x != nil {
+		return x.Published
+	}
+	return false
+}
+
+func (x *SPF) GetRecord() string {
+	if x != nil {
+		return x.Record
+	}
+	return ""
+}
+
+func (x *SPF) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *SPF) GetRedirect() string {
+	if x != nil {
+		return x.Redirect
+	}
+	return ""
+}
+
+func (x *SPF) GetMisconfigured() bool {
+	if x != nil {
+		return x.Misconfigured
+	}
+	return false
+}
+
+func (x *SPF) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DKIM struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Published     bool                   `protobuf:"varint,1,opt,name=published,proto3" json:"published,omitempty"`
+	Selectors     []string               `protobuf:"bytes,2,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DKIM) Reset() {
+	*x = DKIM{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DKIM) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DKIM) ProtoMessage() {}
+
+func (x *DKIM) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DKIM.ProtoReflect.Descriptor instead.
+func (*DKIM) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DKIM) GetPublished() bool {
+	if x != nil {
+		return x.Published
+	}
+	return false
+}
+
+func (x *DKIM) GetSelectors() []string {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+type DMARC struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Published       bool                   `protobuf:"varint,1,opt,name=published,proto3" json:"published,omitempty"`
+	Record          string                 `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	Policy          string                 `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	SubdomainPolicy string                 `protobuf:"bytes,4,opt,name=subdomain_policy,json=subdomainPolicy,proto3" json:"subdomain_policy,omitempty"`
+	Percent         int32                  `protobuf:"varint,5,opt,name=percent,proto3" json:"percent,omitempty"`
+	Rua             []string               `protobuf:"bytes,6,rep,name=rua,proto3" json:"rua,omitempty"`
+	Misconfigured   bool                   `protobuf:"varint,7,opt,name=misconfigured,proto3" json:"misconfigured,omitempty"`
+	Error           string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DMARC) Reset() {
+	*x = DMARC{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DMARC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DMARC) ProtoMessage() {}
+
+func (x *DMARC) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DMARC.ProtoReflect.Descriptor instead.
+func (*DMARC) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DMARC) GetPublished() bool {
+	if x != nil {
+		return x.Published
+	}
+	return false
+}
+
+func (x *DMARC) GetRecord() string {
+	if x != nil {
+		return x.Record
+	}
+	return ""
+}
+
+func (x *DMARC) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *DMARC) GetSubdomainPolicy() string {
+	if x != nil {
+		return x.SubdomainPolicy
+	}
+	return ""
+}
+
+func (x *DMARC) GetPercent() int32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *DMARC) GetRua() []string {
+	if x != nil {
+		return x.Rua
+	}
+	return nil
+}
+
+func (x *DMARC) GetMisconfigured() bool {
+	if x != nil {
+		return x.Misconfigured
+	}
+	return false
+}
+
+func (x *DMARC) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Gravatar struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HasGravatar   bool                   `protobuf:"varint,1,opt,name=has_gravatar,json=hasGravatar,proto3" json:"has_gravatar,omitempty"`
+	GravatarUrl   string                 `protobuf:"bytes,2,opt,name=gravatar_url,json=gravatarUrl,proto3" json:"gravatar_url,omitempty"`
+	Hash          string                 `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,4,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Gravatar) Reset() {
+	*x = Gravatar{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Gravatar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Gravatar) ProtoMessage() {}
+
+func (x *Gravatar) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Gravatar.ProtoReflect.Descriptor instead.
+func (*Gravatar) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Gravatar) GetHasGravatar() bool {
+	if x != nil {
+		return x.HasGravatar
+	}
+	return false
+}
+
+func (x *Gravatar) GetGravatarUrl() string {
+	if x != nil {
+		return x.GravatarUrl
+	}
+	return ""
+}
+
+func (x *Gravatar) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Gravatar) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+type Avatar struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	HasAvatar     bool                   `protobuf:"varint,2,opt,name=has_avatar,json=hasAvatar,proto3" json:"has_avatar,omitempty"`
+	Hash          string                 `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Avatar) Reset() {
+	*x = Avatar{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Avatar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Avatar) ProtoMessage() {}
+
+func (x *Avatar) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Avatar.ProtoReflect.Descriptor instead.
+func (*Avatar) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Avatar) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Avatar) GetHasAvatar() bool {
+	if x != nil {
+		return x.HasAvatar
+	}
+	return false
+}
+
+func (x *Avatar) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Avatar) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type Timings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Syntax        *durationpb.Duration   `protobuf:"bytes,1,opt,name=syntax,proto3" json:"syntax,omitempty"`
+	Mx            *durationpb.Duration   `protobuf:"bytes,2,opt,name=mx,proto3" json:"mx,omitempty"`
+	CatchAll      *durationpb.Duration   `protobuf:"bytes,3,opt,name=catch_all,json=catchAll,proto3" json:"catch_all,omitempty"`
+	Deliverable   *durationpb.Duration   `protobuf:"bytes,4,opt,name=deliverable,proto3" json:"deliverable,omitempty"`
+	Total         *durationpb.Duration   `protobuf:"bytes,5,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Timings) Reset() {
+	*x = Timings{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Timings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timings) ProtoMessage() {}
+
+func (x *Timings) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timings.ProtoReflect.Descriptor instead.
+func (*Timings) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Timings) GetSyntax() *durationpb.Duration {
+	if x != nil {
+		return x.Syntax
+	}
+	return nil
+}
+
+func (x *Timings) GetMx() *durationpb.Duration {
+	if x != nil {
+		return x.Mx
+	}
+	return nil
+}
+
+func (x *Timings) GetCatchAll() *durationpb.Duration {
+	if x != nil {
+		return x.CatchAll
+	}
+	return nil
+}
+
+func (x *Timings) GetDeliverable() *durationpb.Duration {
+	if x != nil {
+		return x.Deliverable
+	}
+	return nil
+}
+
+func (x *Timings) GetTotal() *durationpb.Duration {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+type DomainResult struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Domain           string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	DomainAscii      string                 `protobuf:"bytes,2,opt,name=domain_ascii,json=domainAscii,proto3" json:"domain_ascii,omitempty"`
+	DomainUnicode    string                 `protobuf:"bytes,3,opt,name=domain_unicode,json=domainUnicode,proto3" json:"domain_unicode,omitempty"`
+	Valid            bool                   `protobuf:"varint,4,opt,name=valid,proto3" json:"valid,omitempty"`
+	Reasons          []string               `protobuf:"bytes,5,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	Resolves         bool                   `protobuf:"varint,6,opt,name=resolves,proto3" json:"resolves,omitempty"`
+	HasMxRecords     bool                   `protobuf:"varint,7,opt,name=has_mx_records,json=hasMxRecords,proto3" json:"has_mx_records,omitempty"`
+	NullMx           bool                   `protobuf:"varint,8,opt,name=null_mx,json=nullMx,proto3" json:"null_mx,omitempty"`
+	Smtp             *SMTP                  `protobuf:"bytes,9,opt,name=smtp,proto3" json:"smtp,omitempty"`
+	Suggestion       string                 `protobuf:"bytes,10,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	Disposable       bool                   `protobuf:"varint,11,opt,name=disposable,proto3" json:"disposable,omitempty"`
+	DisposableReason string                 `protobuf:"bytes,12,opt,name=disposable_reason,json=disposableReason,proto3" json:"disposable_reason,omitempty"`
+	Free             bool                   `protobuf:"varint,13,opt,name=free,proto3" json:"free,omitempty"`
+	Skipped          bool                   `protobuf:"varint,14,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	SkipReason       string                 `protobuf:"bytes,15,opt,name=skip_reason,json=skipReason,proto3" json:"skip_reason,omitempty"`
+	DomainAuth       *DomainAuth            `protobuf:"bytes,16,opt,name=domain_auth,json=domainAuth,proto3" json:"domain_auth,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DomainResult) Reset() {
+	*x = DomainResult{}
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DomainResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DomainResult) ProtoMessage() {}
+
+func (x *DomainResult) ProtoReflect() protoreflect.Message {
+	mi := &file_emailverifier_v1_verifier_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DomainResult.ProtoReflect.Descriptor instead.
+func (*DomainResult) Descriptor() ([]byte, []int) {
+	return file_emailverifier_v1_verifier_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DomainResult) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *DomainResult) GetDomainAscii() string {
+	if x != nil {
+		return x.DomainAscii
+	}
+	return ""
+}
+
+func (x *DomainResult) GetDomainUnicode() string {
+	if x != nil {
+		return x.DomainUnicode
+	}
+	return ""
+}
+
+func (x *DomainResult) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *DomainResult) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *DomainResult) GetResolves() bool {
+	if x != nil {
+		return x.Resolves
+	}
+	return false
+}
+
+func (x *DomainResult) GetHasMxRecords() bool {
+	if x != nil {
+		return x.HasMxRecords
+	}
+	return false
+}
+
+func (x *DomainResult) GetNullMx() bool {
+	if x != nil {
+		return x.NullMx
+	}
+	return false
+}
+
+func (x *DomainResult) GetSmtp() *SMTP {
+	if x != nil {
+		return x.Smtp
+	}
+	return nil
+}
+
+func (x *DomainResult) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *DomainResult) GetDisposable() bool {
+	if x != nil {
+		return x.Disposable
+	}
+	return false
+}
+
+func (x *DomainResult) GetDisposableReason() string {
+	if x != nil {
+		return x.DisposableReason
+	}
+	return ""
+}
+
+func (x *DomainResult) GetFree() bool {
+	if x != nil {
+		return x.Free
+	}
+	return false
+}
+
+func (x *DomainResult) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+func (x *DomainResult) GetSkipReason() string {
+	if x != nil {
+		return x.SkipReason
+	}
+	return ""
+}
+
+func (x *DomainResult) GetDomainAuth() *DomainAuth {
+	if x != nil {
+		return x.DomainAuth
+	}
+	return nil
+}
+
+var File_emailverifier_v1_verifier_proto protoreflect.FileDescriptor
+
+const file_emailverifier_v1_verifier_proto_rawDesc = "" +
+	"\n" +
+	"\x1femailverifier/v1/verifier.proto\x12\x10emailverifier.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x17google/rpc/status.proto\"*\n" +
+	"\x12VerifyEmailRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"G\n" +
+	"\x13VerifyEmailResponse\x120\n" +
+	"\x06result\x18\x01 \x01(\v2\x18.emailverifier.v1.ResultR\x06result\"-\n" +
+	"\x13VerifyDomainRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\"N\n" +
+	"\x14VerifyDomainResponse\x126\n" +
+	"\x06result\x18\x01 \x01(\v2\x1e.emailverifier.v1.DomainResultR\x06result\"*\n" +
+	"\x12VerifyBatchRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"\x87\x01\n" +
+	"\x13VerifyBatchResponse\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x120\n" +
+	"\x06result\x18\x02 \x01(\v2\x18.emailverifier.v1.ResultR\x06result\x12(\n" +
+	"\x05error\x18\x03 \x01(\v2\x12.google.rpc.StatusR\x05error\"\xff\x06\n" +
+	"\x06Result\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12'\n" +
+	"\x0fcanonical_email\x18\x02 \x01(\tR\x0ecanonicalEmail\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
+	"\treachable\x18\x04 \x01(\tR\treachable\x120\n" +
+	"\x06syntax\x18\x05 \x01(\v2\x18.emailverifier.v1.SyntaxR\x06syntax\x12*\n" +
+	"\x04smtp\x18\x06 \x01(\v2\x16.emailverifier.v1.SMTPR\x04smtp\x126\n" +
+	"\bgravatar\x18\a \x01(\v2\x1a.emailverifier.v1.GravatarR\bgravatar\x120\n" +
+	"\x06avatar\x18\b \x01(\v2\x18.emailverifier.v1.AvatarR\x06avatar\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\t \x01(\tR\n" +
+	"suggestion\x12\x1e\n" +
+	"\n" +
+	"disposable\x18\n" +
+	" \x01(\bR\n" +
+	"disposable\x12+\n" +
+	"\x11disposable_reason\x18\v \x01(\tR\x10disposableReason\x12!\n" +
+	"\frole_account\x18\f \x01(\bR\vroleAccount\x12\x12\n" +
+	"\x04free\x18\r \x01(\bR\x04free\x12$\n" +
+	"\x0ehas_mx_records\x18\x0e \x01(\bR\fhasMxRecords\x12\x18\n" +
+	"\askipped\x18\x0f \x01(\bR\askipped\x12\x1f\n" +
+	"\vskip_reason\x18\x10 \x01(\tR\n" +
+	"skipReason\x123\n" +
+	"\atimings\x18\x11 \x01(\v2\x19.emailverifier.v1.TimingsR\atimings\x12!\n" +
+	"\fsmtp_checked\x18\x12 \x01(\bR\vsmtpChecked\x12)\n" +
+	"\x10gravatar_checked\x18\x13 \x01(\bR\x0fgravatarChecked\x12=\n" +
+	"\vdomain_auth\x18\x14 \x01(\v2\x1c.emailverifier.v1.DomainAuthR\n" +
+	"domainAuth\x12\x1b\n" +
+	"\ttimed_out\x18\x15 \x01(\bR\btimedOut\x12(\n" +
+	"\x10timed_out_stages\x18\x16 \x03(\tR\x0etimedOutStages\x12\x18\n" +
+	"\aquality\x18\x17 \x01(\tR\aquality\x12\x14\n" +
+	"\x05score\x18\x18 \x01(\x05R\x05score\"\xf0\x01\n" +
+	"\x06Syntax\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12!\n" +
+	"\fdomain_ascii\x18\x03 \x01(\tR\vdomainAscii\x12%\n" +
+	"\x0edomain_unicode\x18\x04 \x01(\tR\rdomainUnicode\x12\x14\n" +
+	"\x05valid\x18\x05 \x01(\bR\x05valid\x12&\n" +
+	"\x0fhas_sub_address\x18\x06 \x01(\bR\rhasSubAddress\x12\x10\n" +
+	"\x03tag\x18\a \x01(\tR\x03tag\x12\x18\n" +
+	"\areasons\x18\b \x03(\tR\areasons\"\xe9\x05\n" +
+	"\x04SMTP\x12\x1f\n" +
+	"\vhost_exists\x18\x01 \x01(\bR\n" +
+	"hostExists\x12\x1d\n" +
+	"\n" +
+	"full_inbox\x18\x02 \x01(\bR\tfullInbox\x12\x1b\n" +
+	"\tcatch_all\x18\x03 \x01(\bR\bcatchAll\x12 \n" +
+	"\vdeliverable\x18\x04 \x01(\bR\vdeliverable\x12\x1a\n" +
+	"\bdisabled\x18\x05 \x01(\bR\bdisabled\x121\n" +
+	"\x14smtputf8_unsupported\x18\x06 \x01(\bR\x13smtputf8Unsupported\x12!\n" +
+	"\fimplicit_tls\x18\a \x01(\bR\vimplicitTls\x12\x18\n" +
+	"\ablocked\x18\b \x01(\bR\ablocked\x12#\n" +
+	"\rreject_reason\x18\t \x01(\tR\frejectReason\x121\n" +
+	"\x05error\x18\n" +
+	" \x01(\v2\x1b.emailverifier.v1.SMTPErrorR\x05error\x12\x14\n" +
+	"\x05relay\x18\v \x01(\tR\x05relay\x12\x1f\n" +
+	"\vmx_override\x18\f \x01(\bR\n" +
+	"mxOverride\x126\n" +
+	"\x17host_unreachable_reason\x18\r \x01(\tR\x15hostUnreachableReason\x12-\n" +
+	"\x12connection_retries\x18\x0e \x01(\x05R\x11connectionRetries\x12+\n" +
+	"\x11probe_reliability\x18\x0f \x01(\tR\x10probeReliability\x12.\n" +
+	"\x04plan\x18\x10 \x01(\v2\x1a.emailverifier.v1.SMTPPlanR\x04plan\x12!\n" +
+	"\ffailed_stage\x18\x11 \x01(\tR\vfailedStage\x12\x1d\n" +
+	"\n" +
+	"reply_code\x18\x12 \x01(\x05R\treplyCode\x12\x1a\n" +
+	"\bstarttls\x18\x13 \x01(\bR\bstarttls\x12%\n" +
+	"\x0estarttls_error\x18\x14 \x01(\tR\rstarttlsError\"?\n" +
+	"\tSMTPError\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x18\n" +
+	"\adetails\x18\x02 \x01(\tR\adetails\"\xad\x01\n" +
+	"\bSMTPPlan\x12\x14\n" +
+	"\x05hosts\x18\x01 \x03(\tR\x05hosts\x12*\n" +
+	"\x11catch_all_address\x18\x02 \x01(\tR\x0fcatchAllAddress\x12,\n" +
+	"\x12catch_all_commands\x18\x03 \x03(\tR\x10catchAllCommands\x121\n" +
+	"\x14deliverable_commands\x18\x04 \x03(\tR\x13deliverableCommands\"\xc3\x01\n" +
+	"\n" +
+	"DomainAuth\x121\n" +
+	"\amta_sts\x18\x01 \x01(\v2\x18.emailverifier.v1.MTASTSR\x06mtaSts\x12'\n" +
+	"\x03spf\x18\x02 \x01(\v2\x15.emailverifier.v1.SPFR\x03spf\x12*\n" +
+	"\x04dkim\x18\x03 \x01(\v2\x16.emailverifier.v1.DKIMR\x04dkim\x12-\n" +
+	"\x05dmarc\x18\x04 \x01(\v2\x17.emailverifier.v1.DMARCR\x05dmarc\"\xaf\x01\n" +
+	"\x06MTASTS\x12\x1c\n" +
+	"\tpublished\x18\x01 \x01(\bR\tpublished\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12\x0e\n" +
+	"\x02mx\x18\x04 \x03(\tR\x02mx\x12\x17\n" +
+	"\amax_age\x18\x05 \x01(\x05R\x06maxAge\x12$\n" +
+	"\rmisconfigured\x18\x06 \x01(\bR\rmisconfigured\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xab\x01\n" +
+	"\x03SPF\x12\x1c\n" +
+	"\tpublished\x18\x01 \x01(\bR\tpublished\x12\x16\n" +
+	"\x06record\x18\x02 \x01(\tR\x06record\x12\x16\n" +
+	"\x06policy\x18\x03 \x01(\tR\x06policy\x12\x1a\n" +
+	"\bredirect\x18\x04 \x01(\tR\bredirect\x12$\n" +
+	"\rmisconfigured\x18\x05 \x01(\bR\rmisconfigured\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"B\n" +
+	"\x04DKIM\x12\x1c\n" +
+	"\tpublished\x18\x01 \x01(\bR\tpublished\x12\x1c\n" +
+	"\tselectors\x18\x02 \x03(\tR\tselectors\"\xe8\x01\n" +
+	"\x05DMARC\x12\x1c\n" +
+	"\tpublished\x18\x01 \x01(\bR\tpublished\x12\x16\n" +
+	"\x06record\x18\x02 \x01(\tR\x06record\x12\x16\n" +
+	"\x06policy\x18\x03 \x01(\tR\x06policy\x12)\n" +
+	"\x10subdomain_policy\x18\x04 \x01(\tR\x0fsubdomainPolicy\x12\x18\n" +
+	"\apercent\x18\x05 \x01(\x05R\apercent\x12\x10\n" +
+	"\x03rua\x18\x06 \x03(\tR\x03rua\x12$\n" +
+	"\rmisconfigured\x18\a \x01(\bR\rmisconfigured\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\"\x83\x01\n" +
+	"\bGravatar\x12!\n" +
+	"\fhas_gravatar\x18\x01 \x01(\bR\vhasGravatar\x12!\n" +
+	"\fgravatar_url\x18\x02 \x01(\tR\vgravatarUrl\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x04 \x01(\tR\tavatarUrl\"i\n" +
+	"\x06Avatar\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1d\n" +
+	"\n" +
+	"has_avatar\x18\x02 \x01(\bR\thasAvatar\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\"\x8d\x02\n" +
+	"\aTimings\x121\n" +
+	"\x06syntax\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06syntax\x12)\n" +
+	"\x02mx\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x02mx\x126\n" +
+	"\tcatch_all\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bcatchAll\x12;\n" +
+	"\vdeliverable\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\vdeliverable\x12/\n" +
+	"\x05total\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x05total\"\xa2\x04\n" +
+	"\fDomainResult\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12!\n" +
+	"\fdomain_ascii\x18\x02 \x01(\tR\vdomainAscii\x12%\n" +
+	"\x0edomain_unicode\x18\x03 \x01(\tR\rdomainUnicode\x12\x14\n" +
+	"\x05valid\x18\x04 \x01(\bR\x05valid\x12\x18\n" +
+	"\areasons\x18\x05 \x03(\tR\areasons\x12\x1a\n" +
+	"\bresolves\x18\x06 \x01(\bR\bresolves\x12$\n" +
+	"\x0ehas_mx_records\x18\a \x01(\bR\fhasMxRecords\x12\x17\n" +
+	"\anull_mx\x18\b \x01(\bR\x06nullMx\x12*\n" +
+	"\x04smtp\x18\t \x01(\v2\x16.emailverifier.v1.SMTPR\x04smtp\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\n" +
+	" \x01(\tR\n" +
+	"suggestion\x12\x1e\n" +
+	"\n" +
+	"disposable\x18\v \x01(\bR\n" +
+	"disposable\x12+\n" +
+	"\x11disposable_reason\x18\f \x01(\tR\x10disposableReason\x12\x12\n" +
+	"\x04free\x18\r \x01(\bR\x04free\x12\x18\n" +
+	"\askipped\x18\x0e \x01(\bR\askipped\x12\x1f\n" +
+	"\vskip_reason\x18\x0f \x01(\tR\n" +
+	"skipReason\x12=\n" +
+	"\vdomain_auth\x18\x10 \x01(\v2\x1c.emailverifier.v1.DomainAuthR\n" +
+	"domainAuth2\xa5\x02\n" +
+	"\bVerifier\x12Z\n" +
+	"\vVerifyEmail\x12$.emailverifier.v1.VerifyEmailRequest\x1a%.emailverifier.v1.VerifyEmailResponse\x12]\n" +
+	"\fVerifyDomain\x12%.emailverifier.v1.VerifyDomainRequest\x1a&.emailverifier.v1.VerifyDomainResponse\x12^\n" +
+	"\vVerifyBatch\x12$.emailverifier.v1.VerifyBatchRequest\x1a%.emailverifier.v1.VerifyBatchResponse(\x010\x01B=Z;github.com/vikt0r0/email-verifier/cmd/grpcserver/verifierpbb\x06proto3"
+
+var (
+	file_emailverifier_v1_verifier_proto_rawDescOnce sync.Once
+	file_emailverifier_v1_verifier_proto_rawDescData []byte
+)
+
+func file_emailverifier_v1_verifier_proto_rawDescGZIP() []byte {
+	file_emailverifier_v1_verifier_proto_rawDescOnce.Do(func() {
+		file_emailverifier_v1_verifier_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_emailverifier_v1_verifier_proto_rawDesc), len(file_emailverifier_v1_verifier_proto_rawDesc)))
+	})
+	return file_emailverifier_v1_verifier_proto_rawDescData
+}
+
+var file_emailverifier_v1_verifier_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_emailverifier_v1_verifier_proto_goTypes = []any{
+	(*VerifyEmailRequest)(nil),   // 0: emailverifier.v1.VerifyEmailRequest
+	(*VerifyEmailResponse)(nil),  // 1: emailverifier.v1.VerifyEmailResponse
+	(*VerifyDomainRequest)(nil),  // 2: emailverifier.v1.VerifyDomainRequest
+	(*VerifyDomainResponse)(nil), // 3: emailverifier.v1.VerifyDomainResponse
+	(*VerifyBatchRequest)(nil),   // 4: emailverifier.v1.VerifyBatchRequest
+	(*VerifyBatchResponse)(nil),  // 5: emailverifier.v1.VerifyBatchResponse
+	(*Result)(nil),               // 6: emailverifier.v1.Result
+	(*Syntax)(nil),               // 7: emailverifier.v1.Syntax
+	(*SMTP)(nil),                 // 8: emailverifier.v1.SMTP
+	(*SMTPError)(nil),            // 9: emailverifier.v1.SMTPError
+	(*SMTPPlan)(nil),             // 10: emailverifier.v1.SMTPPlan
+	(*DomainAuth)(nil),           // 11: emailverifier.v1.DomainAuth
+	(*MTASTS)(nil),               // 12: emailverifier.v1.MTASTS
+	(*SPF)(nil),                  // 13: emailverifier.v1.SPF
+	(*DKIM)(nil),                 // 14: emailverifier.v1.DKIM
+	(*DMARC)(nil),                // 15: emailverifier.v1.DMARC
+	(*Gravatar)(nil),             // 16: emailverifier.v1.Gravatar
+	(*Avatar)(nil),               // 17: emailverifier.v1.Avatar
+	(*Timings)(nil),              // 18: emailverifier.v1.Timings
+	(*DomainResult)(nil),         // 19: emailverifier.v1.DomainResult
+	(*status.Status)(nil),        // 20: google.rpc.Status
+	(*durationpb.Duration)(nil),  // 21: google.protobuf.Duration
+}
+var file_emailverifier_v1_verifier_proto_depIdxs = []int32{
+	6,  // 0: emailverifier.v1.VerifyEmailResponse.result:type_name -> emailverifier.v1.Result
+	19, // 1: emailverifier.v1.VerifyDomainResponse.result:type_name -> emailverifier.v1.DomainResult
+	6,  // 2: emailverifier.v1.VerifyBatchResponse.result:type_name -> emailverifier.v1.Result
+	20, // 3: emailverifier.v1.VerifyBatchResponse.error:type_name -> google.rpc.Status
+	7,  // 4: emailverifier.v1.Result.syntax:type_name -> emailverifier.v1.Syntax
+	8,  // 5: emailverifier.v1.Result.smtp:type_name -> emailverifier.v1.SMTP
+	16, // 6: emailverifier.v1.Result.gravatar:type_name -> emailverifier.v1.Gravatar
+	17, // 7: emailverifier.v1.Result.avatar:type_name -> emailverifier.v1.Avatar
+	18, // 8: emailverifier.v1.Result.timings:type_name -> emailverifier.v1.Timings
+	11, // 9: emailverifier.v1.Result.domain_auth:type_name -> emailverifier.v1.DomainAuth
+	9,  // 10: emailverifier.v1.SMTP.error:type_name -> emailverifier.v1.SMTPError
+	10, // 11: emailverifier.v1.SMTP.plan:type_name -> emailverifier.v1.SMTPPlan
+	12, // 12: emailverifier.v1.DomainAuth.mta_sts:type_name -> emailverifier.v1.MTASTS
+	13, // 13: emailverifier.v1.DomainAuth.spf:type_name -> emailverifier.v1.SPF
+	14, // 14: emailverifier.v1.DomainAuth.dkim:type_name -> emailverifier.v1.DKIM
+	15, // 15: emailverifier.v1.DomainAuth.dmarc:type_name -> emailverifier.v1.DMARC
+	21, // 16: emailverifier.v1.Timings.syntax:type_name -> google.protobuf.Duration
+	21, // 17: emailverifier.v1.Timings.mx:type_name -> google.protobuf.Duration
+	21, // 18: emailverifier.v1.Timings.catch_all:type_name -> google.protobuf.Duration
+	21, // 19: emailverifier.v1.Timings.deliverable:type_name -> google.protobuf.Duration
+	21, // 20: emailverifier.v1.Timings.total:type_name -> google.protobuf.Duration
+	8,  // 21: emailverifier.v1.DomainResult.smtp:type_name -> emailverifier.v1.SMTP
+	11, // 22: emailverifier.v1.DomainResult.domain_auth:type_name -> emailverifier.v1.DomainAuth
+	0,  // 23: emailverifier.v1.Verifier.VerifyEmail:input_type -> emailverifier.v1.VerifyEmailRequest
+	2,  // 24: emailverifier.v1.Verifier.VerifyDomain:input_type -> emailverifier.v1.VerifyDomainRequest
+	4,  // 25: emailverifier.v1.Verifier.VerifyBatch:input_type -> emailverifier.v1.VerifyBatchRequest
+	1,  // 26: emailverifier.v1.Verifier.VerifyEmail:output_type -> emailverifier.v1.VerifyEmailResponse
+	3,  // 27: emailverifier.v1.Verifier.VerifyDomain:output_type -> emailverifier.v1.VerifyDomainResponse
+	5,  // 28: emailverifier.v1.Verifier.VerifyBatch:output_type -> emailverifier.v1.VerifyBatchResponse
+	26, // [26:29] is the sub-list for method output_type
+	23, // [23:26] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_emailverifier_v1_verifier_proto_init() }
+func file_emailverifier_v1_verifier_proto_init() {
+	if File_emailverifier_v1_verifier_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_emailverifier_v1_verifier_proto_rawDesc), len(file_emailverifier_v1_verifier_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_emailverifier_v1_verifier_proto_goTypes,
+		DependencyIndexes: file_emailverifier_v1_verifier_proto_depIdxs,
+		MessageInfos:      file_emailverifier_v1_verifier_proto_msgTypes,
+	}.Build()
+	File_emailverifier_v1_verifier_proto = out.File
+	file_emailverifier_v1_verifier_proto_goTypes = nil
+	file_emailverifier_v1_verifier_proto_depIdxs = nil
+}
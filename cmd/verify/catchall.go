@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// catchAllLine is the result of the catch-all check of a domain
+type catchAllLine struct {
+	Domain     string `json:"domain"`
+	CatchAll   *bool  `json:"catch_all"`             // null if the check was skipped or failed
+	SkipReason string `json:"skip_reason,omitempty"` // why the check was skipped, if it was
+	Error      string `json:"error,omitempty"`       // error of a check which failed
+}
+
+// runCatchAll checks whether the domains are catch-all with v, c.concurrency at a time,
+// writes their results to stdout and returns the exit code
+func runCatchAll(c config, v verifier, domains []string, stdout, stderr io.Writer) int {
+	lines := make([]catchAllLine, len(domains))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				lines[i] = checkCatchAll(v, domains[i])
+			}
+		}()
+	}
+	for i := range domains {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if err := writeCatchAll(stdout, c.format, lines); err != nil {
+		fmt.Fprintf(stderr, "write results: %v\n", err)
+		return exitFailed
+	}
+	failed := 0
+	for _, line := range lines {
+		if line.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d of %d catch-all checks failed\n", failed, len(lines))
+		return exitFailed
+	}
+	return exitOK
+}
+
+// checkCatchAll checks whether domain is catch-all with v
+func checkCatchAll(v verifier, domain string) catchAllLine {
+	line := catchAllLine{Domain: domain}
+	catchAll, err := v.IsCatchAll(context.Background(), domain)
+	var catchAllErr *emailVerifier.CatchAllError
+	switch {
+	case err == nil:
+		line.CatchAll = &catchAll
+	case errors.As(err, &catchAllErr) && catchAllErr.SkipReason != "":
+		line.SkipReason = catchAllErr.SkipReason
+	default:
+		line.Error = err.Error()
+	}
+	return line
+}
+
+// writeCatchAll writes the results of the catch-all checks to w in the format
+func writeCatchAll(w io.Writer, format string, lines []catchAllLine) error {
+	if format == formatCSV {
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"domain", "catch_all", "skip_reason", "error"}); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			catchAll := ""
+			if line.CatchAll != nil {
+				catchAll = strconv.FormatBool(*line.CatchAll)
+			}
+			if err := writer.Write([]string{line.Domain, catchAll, line.SkipReason, line.Error}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_CatchAll(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	c := config{format: formatJSONLines, concurrency: 2, progress: true, catchAll: true}
+
+	code := run(c, &stubVerifier{}, strings.NewReader("catchall.example.com\nexample.com\nblocked.test\n"), &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `{"domain":"catchall.example.com","catch_all":true}
+{"domain":"example.com","catch_all":false}
+{"domain":"blocked.test","catch_all":null,"skip_reason":"domain_blocklisted"}
+`, stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_CatchAllFailed(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	c := config{format: formatCSV, concurrency: 1, catchAll: true}
+
+	code := run(c, &stubVerifier{}, strings.NewReader("catchall.example.com\nfailed.test\n"), &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, "domain,catch_all,skip_reason,error\ncatchall.example.com,true,,\nfailed.test,,,timeout\n", stdout.String())
+	assert.Equal(t, "1 of 2 catch-all checks failed\n", stderr.String())
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// readLines returns the non-empty lines of r, trimmed of spaces
+func readLines(r io.Reader) ([]string, error) {
+	var emails []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if email := strings.TrimSpace(scanner.Text()); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails, scanner.Err()
+}
+
+// readCSV returns the non-empty values of the column of the CSV r, whose first record is a header.
+// The column is the name of a header, case insensitive, or its 1-based index.
+func readCSV(r io.Reader, column string) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index, err := columnIndex(header, column)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []string
+	for n := 2; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return emails, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if index >= len(record) {
+			return nil, fmt.Errorf("record %d has no column %s", n, column)
+		}
+		if email := strings.TrimSpace(record[index]); email != "" {
+			emails = append(emails, email)
+		}
+	}
+}
+
+// columnIndex returns the 0-based index of the column, a name of header or a 1-based index
+func columnIndex(header []string, column string) (int, error) {
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(column); err == nil && i >= 1 && i <= len(header) {
+		return i - 1, nil
+	}
+	return 0, fmt.Errorf("no column %s in the CSV header", column)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLines(t *testing.T) {
+	emails, err := readLines(strings.NewReader("a@example.com\n\n  b@example.com \r\nc@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, emails)
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "name,Email\nAlice,a@example.com\nBob,\nCarol, c@example.com\n"
+
+	emails, err := readCSV(strings.NewReader(input), "email")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com", "c@example.com"}, emails)
+
+	emails, err = readCSV(strings.NewReader(input), "2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com", "c@example.com"}, emails)
+
+	emails, err = readCSV(strings.NewReader(""), "email")
+	assert.NoError(t, err)
+	assert.Empty(t, emails)
+}
+
+func TestReadCSV_Invalid(t *testing.T) {
+	_, err := readCSV(strings.NewReader("name,email\n"), "address")
+	assert.EqualError(t, err, "no column address in the CSV header")
+
+	_, err = readCSV(strings.NewReader("name,email\n"), "3")
+	assert.Error(t, err)
+
+	_, err = readCSV(strings.NewReader("name,email\nAlice\n"), "email")
+	assert.EqualError(t, err, "record 2 has no column email")
+
+	_, err = readCSV(strings.NewReader("name,email\n\"Alice,a@example.com\n"), "email")
+	assert.Error(t, err)
+}
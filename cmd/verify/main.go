@@ -0,0 +1,310 @@
+// Command verify verifies a list of email addresses without the API server,
+// read from stdin with an address per line or from a column of a CSV file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// Exit codes of verify
+const (
+	exitOK      = 0 // every address was verified, whatever its result
+	exitFailed  = 1 // the verification of an address failed, e.g. the mail server timed out
+	exitInvalid = 2 // the flags or the input are invalid
+)
+
+// progressInterval is the interval of the progress reports
+const progressInterval = time.Second
+
+// config is the configuration of verify, set by flags
+type config struct {
+	csvPath     string // CSV file of the addresses, "-" for stdin, lines of stdin if empty
+	column      string // column of the addresses in the CSV file, a header name or a 1-based index
+	format      string // format of the results, jsonl or csv
+	concurrency int    // number of domains verified concurrently
+	smtpCheck   bool   // whether the smtp check is enabled
+	proxy       string // SOCKS5 proxy URI of the smtp check, none if empty
+	dns         string // comma separated nameservers of the DNS lookups, the system ones if empty
+	timeout     time.Duration
+	progress    bool   // whether the progress is reported to stderr
+	catchAll    bool   // whether the input are domains whose catch-all check is reported instead of addresses
+	statePath   string // file recording the results as they complete, whose addresses are skipped on restart, none if empty
+	stats       bool   // whether the stats of the verifier are reported to stderr at the end of the run
+}
+
+// parseConfig parses the config from the command line arguments args, writing the usage to output
+func parseConfig(args []string, output io.Writer) (config, error) {
+	var c config
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&c.csvPath, "csv", "", "CSV file of the addresses, - for stdin, an address per line of stdin if empty")
+	fs.StringVar(&c.column, "column", "email", "column of the addresses in the CSV file, a header name or a 1-based index")
+	fs.StringVar(&c.format, "format", formatJSONLines, "format of the results: jsonl or csv")
+	fs.IntVar(&c.concurrency, "concurrency", 10, "number of domains verified concurrently")
+	fs.BoolVar(&c.smtpCheck, "smtp", true, "enable the smtp check")
+	fs.StringVar(&c.proxy, "proxy", "", "comma separated SOCKS5 or HTTP proxy URIs of the smtp check, used in turn")
+	fs.StringVar(&c.dns, "dns", "", "comma separated nameservers of the DNS lookups, e.g. 8.8.8.8:53, the system ones if empty")
+	fs.DurationVar(&c.timeout, "timeout", 0, "timeout of connecting to a mail server, the verifier default if zero")
+	fs.BoolVar(&c.progress, "progress", true, "report the progress to stderr")
+	fs.BoolVar(&c.catchAll, "catch-all", false, "report whether the domains of the input are catch-all instead of verifying addresses")
+	fs.StringVar(&c.statePath, "state-file", "", "file recording the results as they complete, to resume an interrupted run without verifying its addresses again")
+	fs.BoolVar(&c.stats, "stats", false, "report the connections and the cache and list statistics of the verifier to stderr at the end of the run")
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+	if fs.NArg() > 0 {
+		return c, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if c.format != formatJSONLines && c.format != formatCSV {
+		return c, fmt.Errorf("invalid format %q", c.format)
+	}
+	if c.concurrency <= 0 {
+		return c, fmt.Errorf("invalid concurrency %d", c.concurrency)
+	}
+	if c.catchAll && c.statePath != "" {
+		return c, errors.New("a state file is unsupported with -catch-all")
+	}
+	return c, nil
+}
+
+// newVerifier creates the verifier configured by c
+func (c config) newVerifier() (*emailVerifier.Verifier, error) {
+	var opts []emailVerifier.Option
+	if c.smtpCheck {
+		opts = append(opts, emailVerifier.WithSMTPCheck())
+	}
+	if c.proxy != "" {
+		opts = append(opts, emailVerifier.WithProxies(strings.Split(c.proxy, ",")...))
+	}
+	if c.dns != "" {
+		opts = append(opts, emailVerifier.WithNameservers(strings.Split(c.dns, ",")...))
+	}
+	if c.timeout != 0 {
+		opts = append(opts, emailVerifier.WithTimeout(c.timeout))
+	}
+	return emailVerifier.NewVerifierWithOptions(opts...)
+}
+
+// verifier is the part of *emailVerifier.Verifier used by verify
+type verifier interface {
+	VerifyBatch(emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult
+	SetObserver(o emailVerifier.Observer) *emailVerifier.Verifier
+	IsCatchAll(ctx context.Context, domain string) (bool, error)
+	Stats() emailVerifier.Stats
+}
+
+// readEmails reads the addresses, or the domains of the catch-all checks, from the CSV file of c, or the lines of stdin
+func (c config) readEmails(stdin io.Reader) ([]string, error) {
+	switch c.csvPath {
+	case "":
+		return readLines(stdin)
+	case "-":
+		return readCSV(stdin, c.column)
+	}
+	f, err := os.Open(c.csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCSV(f, c.column)
+}
+
+// run verifies the addresses read from stdin or the CSV file of c with v, writes their results to stdout
+// and the progress to stderr, and returns the exit code
+func run(c config, v verifier, stdin io.Reader, stdout, stderr io.Writer) int {
+	emails, err := c.readEmails(stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "read addresses: %v\n", err)
+		return exitInvalid
+	}
+	if c.stats {
+		defer func() { writeStats(stderr, v.Stats()) }()
+	}
+	if c.catchAll {
+		return runCatchAll(c, v, emails, stdout, stderr)
+	}
+
+	opts := emailVerifier.BatchOptions{Concurrency: c.concurrency, GroupByDomain: true}
+	var resumed map[string]emailVerifier.BatchResult
+	if c.statePath != "" {
+		var state *stateFile
+		state, resumed, err = openState(c.statePath, stderr)
+		if err != nil {
+			fmt.Fprintf(stderr, "open state file: %v\n", err)
+			return exitFailed
+		}
+		defer func() {
+			if err := state.close(); err != nil {
+				fmt.Fprintf(stderr, "write state file: %v\n", err)
+			}
+		}()
+		opts.OnResult = func(i int, result emailVerifier.BatchResult) {
+			state.write(result)
+		}
+	}
+	var pending []string
+	for _, email := range emails {
+		if _, ok := resumed[email]; !ok {
+			pending = append(pending, email)
+		}
+	}
+	if n := len(emails) - len(pending); n > 0 {
+		fmt.Fprintf(stderr, "resumed %d of %d addresses from the state file\n", n, len(emails))
+	}
+
+	var p *progress
+	if c.progress {
+		p = newProgress(len(pending), stderr)
+		v.SetObserver(p)
+		defer v.SetObserver(nil)
+		p.start()
+	}
+	verified := v.VerifyBatch(pending, opts)
+	if p != nil {
+		p.stop()
+	}
+
+	// the resumed results are merged with the verified ones in the order of the input
+	results := make([]emailVerifier.BatchResult, len(emails))
+	for i, email := range emails {
+		if result, ok := resumed[email]; ok {
+			results[i] = result
+		} else {
+			results[i], verified = verified[0], verified[1:]
+		}
+	}
+
+	if err := writeResults(stdout, c.format, results); err != nil {
+		fmt.Fprintf(stderr, "write results: %v\n", err)
+		return exitFailed
+	}
+	failed := 0
+	for _, ret := range results {
+		if ret.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d of %d verifications failed\n", failed, len(results))
+		return exitFailed
+	}
+	return exitOK
+}
+
+// progress reports the number of verified addresses periodically, it observes the verifications of the verifier
+type progress struct {
+	verified int64 // accessed atomically
+	total    int
+	w        io.Writer
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newProgress(total int, w io.Writer) *progress {
+	return &progress{total: total, w: w, done: make(chan struct{})}
+}
+
+func (p *progress) ObserveVerification(domain string, outcome string, d time.Duration) {
+	atomic.AddInt64(&p.verified, 1)
+}
+
+func (p *progress) ObserveSMTPDial(host string, err error, d time.Duration) {}
+
+func (p *progress) ObserveCacheHit(kind string, hit bool) {}
+
+// start reports the progress every progressInterval until stop
+func (p *progress) start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.report()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop stops the periodic reports and reports the final progress
+func (p *progress) stop() {
+	close(p.done)
+	p.wg.Wait()
+	p.report()
+}
+
+func (p *progress) report() {
+	fmt.Fprintf(p.w, "verified %d/%d\n", atomic.LoadInt64(&p.verified), p.total)
+}
+
+// writeStats writes the stats of a verifier to w, a line per counter group
+func writeStats(w io.Writer, stats emailVerifier.Stats) {
+	fmt.Fprintf(w, "stats: smtp connections: %d opened, %d reused, %d failed\n",
+		stats.SMTP.ConnectionsOpened, stats.SMTP.ConnectionsReused, stats.SMTP.ConnectionsFailed)
+	kinds := make([]string, 0, len(stats.Caches))
+	for kind := range stats.Caches {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		cache := stats.Caches[kind]
+		fmt.Fprintf(w, "stats: %s cache: %s entries, %d hits, %d misses, %.1f%% hit ratio\n",
+			kind, countText(cache.Entries), cache.Hits, cache.Misses, cache.HitRatio*100)
+	}
+	fmt.Fprintf(w, "stats: cache: %s entries, %d evictions\n", countText(stats.CacheEntries), stats.CacheEvictions)
+	names := make([]string, 0, len(stats.Lists))
+	for name := range stats.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		list := stats.Lists[name]
+		updated := "built-in"
+		if !list.LastUpdate.IsZero() {
+			updated = "updated " + list.LastUpdate.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "stats: %s list: %d entries, %s\n", name, list.Entries, updated)
+	}
+}
+
+// countText formats a count of the stats, which is -1 if it's unknown
+func countText(n int) string {
+	if n < 0 {
+		return "unknown"
+	}
+	return strconv.Itoa(n)
+}
+
+func main() {
+	c, err := parseConfig(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %v\n", err)
+		os.Exit(exitInvalid)
+	}
+	v, err := c.newVerifier()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %v\n", err)
+		os.Exit(exitInvalid)
+	}
+	os.Exit(run(c, v, os.Stdin, os.Stdout, os.Stderr))
+}
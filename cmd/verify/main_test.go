@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// tempDir returns a temporary directory removed at the end of the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "verify")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// stubVerifier verifies every address as reachable, except the ones of the domain failed.test which fail
+type stubVerifier struct {
+	observer emailVerifier.Observer
+	opts     emailVerifier.BatchOptions
+	verified []string // addresses of the batches
+	stats    emailVerifier.Stats
+}
+
+func (s *stubVerifier) VerifyBatch(emails []string, opts emailVerifier.BatchOptions) []emailVerifier.BatchResult {
+	s.opts = opts
+	s.verified = append(s.verified, emails...)
+	results := make([]emailVerifier.BatchResult, len(emails))
+	for i, email := range emails {
+		results[i] = emailVerifier.BatchResult{Email: email, Result: &emailVerifier.Result{Email: email, Reachable: "yes"}}
+		if strings.HasSuffix(email, "@failed.test") {
+			results[i] = emailVerifier.BatchResult{Email: email, Err: errors.New("timeout")}
+		}
+		if s.observer != nil {
+			s.observer.ObserveVerification("example.com", "yes", time.Millisecond)
+		}
+		if opts.OnResult != nil {
+			opts.OnResult(i, results[i])
+		}
+	}
+	return results
+}
+
+// IsCatchAll reports the domains starting with "catchall." as catch-all, blocklists blocked.test
+// and fails for failed.test
+func (s *stubVerifier) IsCatchAll(ctx context.Context, domain string) (bool, error) {
+	switch domain {
+	case "blocked.test":
+		return false, &emailVerifier.CatchAllError{Domain: domain, SkipReason: emailVerifier.SkipReasonBlocklisted}
+	case "failed.test":
+		return false, errors.New("timeout")
+	}
+	return strings.HasPrefix(domain, "catchall."), nil
+}
+
+func (s *stubVerifier) Stats() emailVerifier.Stats {
+	return s.stats
+}
+
+func (s *stubVerifier) SetObserver(o emailVerifier.Observer) *emailVerifier.Verifier {
+	s.observer = o
+	return nil
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := parseConfig(nil, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, config{column: "email", format: formatJSONLines, concurrency: 10, smtpCheck: true, progress: true}, c)
+	_, err = c.newVerifier()
+	assert.NoError(t, err)
+
+	c, err = parseConfig([]string{"-csv", "list.csv", "-column", "2", "-format", "csv", "-concurrency", "5", "-smtp=false", "-timeout", "5s", "-catch-all", "-stats"}, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, config{csvPath: "list.csv", column: "2", format: formatCSV, concurrency: 5, timeout: 5 * time.Second, progress: true, catchAll: true, stats: true}, c)
+
+	for _, args := range [][]string{{"-format", "xml"}, {"-concurrency", "0"}, {"list.txt"}, {"-catch-all", "-state-file", "state.jsonl"}} {
+		_, err = parseConfig(args, ioutil.Discard)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestRun(t *testing.T) {
+	v := &stubVerifier{}
+	var stdout, stderr bytes.Buffer
+	c := config{format: formatJSONLines, concurrency: 3, progress: true}
+
+	code := run(c, v, strings.NewReader("a@example.com\nb@example.com\n"), &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout.String(), "\n"))
+	assert.Equal(t, "verified 2/2\n", stderr.String())
+	assert.Equal(t, emailVerifier.BatchOptions{Concurrency: 3, GroupByDomain: true}, v.opts)
+	assert.Nil(t, v.observer)
+}
+
+func TestRun_Failed(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	c := config{format: formatCSV, concurrency: 1}
+
+	code := run(c, &stubVerifier{}, strings.NewReader("a@example.com\nb@failed.test\n"), &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stdout.String(), "b@failed.test,")
+	assert.True(t, strings.HasSuffix(stdout.String(), ",timeout\n"))
+	assert.Equal(t, "1 of 2 verifications failed\n", stderr.String())
+}
+
+func TestRun_Stats(t *testing.T) {
+	v := &stubVerifier{stats: emailVerifier.Stats{
+		Caches:       map[string]emailVerifier.CacheStats{emailVerifier.CacheKindMX: {Entries: -1, Hits: 3, Misses: 1, HitRatio: 0.75}},
+		CacheEntries: -1,
+		Lists: map[string]emailVerifier.ListStats{
+			emailVerifier.ListRole: {Entries: 10},
+			emailVerifier.ListFree: {Entries: 20, LastUpdate: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		},
+		SMTP: emailVerifier.SMTPStats{ConnectionsOpened: 2, ConnectionsReused: 5, ConnectionsFailed: 1},
+	}}
+	var stdout, stderr bytes.Buffer
+	c := config{format: formatJSONLines, concurrency: 1, stats: true}
+
+	code := run(c, v, strings.NewReader("a@example.com\n"), &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "stats: smtp connections: 2 opened, 5 reused, 1 failed\n"+
+		"stats: mx cache: unknown entries, 3 hits, 1 misses, 75.0% hit ratio\n"+
+		"stats: cache: unknown entries, 0 evictions\n"+
+		"stats: free list: 20 entries, updated 2026-10-01T12:00:00Z\n"+
+		"stats: role list: 10 entries, built-in\n", stderr.String())
+}
+
+func TestRun_CSVFile(t *testing.T) {
+	path := filepath.Join(tempDir(t), "list.csv")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("name,email\nAlice,a@example.com\n"), 0600))
+	var stdout, stderr bytes.Buffer
+
+	code := run(config{csvPath: path, column: "email", format: formatJSONLines, concurrency: 1}, &stubVerifier{}, nil, &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), `"email":"a@example.com"`)
+
+	code = run(config{csvPath: path, column: "address", format: formatJSONLines, concurrency: 1}, &stubVerifier{}, nil, &stdout, &stderr)
+	assert.Equal(t, exitInvalid, code)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// Formats of the results
+const (
+	formatJSONLines = "jsonl"
+	formatCSV       = "csv"
+)
+
+// jsonLine is the JSON line of the result of an email
+type jsonLine struct {
+	Email  string                `json:"email"`
+	Result *emailVerifier.Result `json:"result,omitempty"`
+	Error  string                `json:"error,omitempty"` // error of a verification which failed
+}
+
+// writeResults writes the results to w in the format
+func writeResults(w io.Writer, format string, results []emailVerifier.BatchResult) error {
+	if format == formatCSV {
+		return writeCSV(w, results)
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, ret := range results {
+		line := jsonLine{Email: ret.Email, Result: ret.Result}
+		if ret.Err != nil {
+			line.Error = ret.Err.Error()
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes the results to w as CSV with a header, the columns of a flattened result and the error
+func writeCSV(w io.Writer, results []emailVerifier.BatchResult) error {
+	writer := emailVerifier.NewCSVWriter(w, "error")
+	for _, ret := range results {
+		r, errMessage := ret.Result, ""
+		if r == nil {
+			r = emailVerifier.NewResult(ret.Email)
+		}
+		if ret.Err != nil {
+			errMessage = ret.Err.Error()
+		}
+		if err := writer.Write(r, errMessage); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// testResults are results of a valid, an invalid and a failed verification
+var testResults = []emailVerifier.BatchResult{
+	{Email: "user@example.com", Result: &emailVerifier.Result{
+		Email:        "user@example.com",
+		Reachable:    "yes",
+		Syntax:       emailVerifier.Syntax{Valid: true},
+		HasMxRecords: true,
+		Free:         true,
+		SMTP:         &emailVerifier.SMTP{HostExists: true, Deliverable: true},
+	}},
+	{Email: "invalid", Result: &emailVerifier.Result{Email: "invalid", Reachable: "unknown"}},
+	{Email: "user@timeout.test", Err: errors.New("timeout")},
+}
+
+func TestWriteResults_JSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeResults(&buf, formatJSONLines, testResults))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 3)
+	assert.Contains(t, string(lines[0]), `"reachable":"yes"`)
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(lines[0], &line))
+	assert.NotContains(t, line, "error")
+	assert.Equal(t, `{"email":"user@timeout.test","error":"timeout"}`, string(lines[2]))
+}
+
+func TestWriteResults_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeResults(&buf, formatCSV, testResults))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	header := records[0]
+	column := func(record []string, name string) string {
+		for i, column := range header {
+			if column == name {
+				return record[i]
+			}
+		}
+		t.Fatalf("no column %s", name)
+		return ""
+	}
+
+	assert.Equal(t, "user@example.com", column(records[1], "email"))
+	assert.Equal(t, "true", column(records[1], "smtp.deliverable"))
+	assert.Equal(t, "yes", column(records[1], "reachable"))
+	assert.Equal(t, "false", column(records[2], "syntax.valid"))
+	assert.Equal(t, "", column(records[2], "smtp.deliverable"))
+	assert.Equal(t, "user@timeout.test", column(records[3], "email"))
+	assert.Equal(t, "timeout", column(records[3], "error"))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+// stateFile records the results of verify as JSON lines as they complete, so an interrupted run resumes
+// without verifying the addresses again
+type stateFile struct {
+	f   *os.File
+	err error // first failure to write a result
+}
+
+// openState opens the state file at path, created if it doesn't exist, and returns it with the successful results
+// it records by address. The failed verifications are verified again, and the corrupted entries are skipped
+// with a warning written to stderr.
+func openState(path string, stderr io.Writer) (*stateFile, map[string]emailVerifier.BatchResult, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	results := make(map[string]emailVerifier.BatchResult)
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry jsonLine
+		if err := json.Unmarshal(line, &entry); err != nil || entry.Email == "" || (entry.Result == nil && entry.Error == "") {
+			fmt.Fprintf(stderr, "state file line %d: skipped corrupted entry\n", n+1)
+			continue
+		}
+		if entry.Error == "" {
+			results[entry.Email] = emailVerifier.BatchResult{Email: entry.Email, Result: entry.Result}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the last entry of an interrupted write isn't terminated
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := f.Write([]byte("\n")); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+	return &stateFile{f: f}, results, nil
+}
+
+// write appends result to the state file, the first failure is kept in s.err
+func (s *stateFile) write(result emailVerifier.BatchResult) {
+	if s.err != nil {
+		return
+	}
+	entry := jsonLine{Email: result.Email, Result: result.Result}
+	if result.Err != nil {
+		entry.Error = result.Err.Error()
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		// an entry is written at once, so an interrupted run leaves at most the last one corrupted
+		_, err = s.f.Write(append(line, '\n'))
+	}
+	s.err = err
+}
+
+// close closes the state file and returns the first failure to write it
+func (s *stateFile) close() error {
+	if err := s.f.Close(); s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	emailVerifier "github.com/vikt0r0/email-verifier"
+)
+
+func TestRun_StateFile(t *testing.T) {
+	path := filepath.Join(tempDir(t), "state.jsonl")
+	c := config{format: formatJSONLines, concurrency: 1, statePath: path}
+	var stdout, stderr bytes.Buffer
+
+	code := run(c, &stubVerifier{}, strings.NewReader("a@example.com\nb@failed.test\n"), &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	state, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, stdout.String(), string(state))
+
+	// The successful results are resumed and merged in the order of the input, the failed ones are verified again
+	v := &stubVerifier{}
+	var resumed bytes.Buffer
+	stderr.Reset()
+	code = run(c, v, strings.NewReader("c@example.com\na@example.com\nb@failed.test\n"), &resumed, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, []string{"c@example.com", "b@failed.test"}, v.verified)
+	lines := strings.Split(stdout.String(), "\n")
+	assert.True(t, strings.HasPrefix(resumed.String(), `{"email":"c@example.com","result":`), resumed.String())
+	assert.True(t, strings.HasSuffix(resumed.String(), lines[0]+"\n"+lines[1]+"\n"), resumed.String())
+	assert.Equal(t, "resumed 1 of 3 addresses from the state file\n1 of 3 verifications failed\n", stderr.String())
+}
+
+func TestOpenState_Corrupted(t *testing.T) {
+	path := filepath.Join(tempDir(t), "state.jsonl")
+	content := `{"email":"a@example.com","result":{"email":"a@example.com","reachable":"yes"}}
+not json
+{"email":"b@example.com"}
+
+{"email":"c@example.com","error":"timeout"}
+{"email":"d@example.com","res`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	var stderr bytes.Buffer
+
+	state, results, err := openState(path, &stderr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com"}, resultEmails(results))
+	assert.Equal(t, "yes", results["a@example.com"].Result.Reachable)
+	assert.Equal(t, "state file line 2: skipped corrupted entry\n"+
+		"state file line 3: skipped corrupted entry\n"+
+		"state file line 6: skipped corrupted entry\n", stderr.String())
+
+	// The interrupted entry is terminated before the next one
+	state.write(testResults[0])
+	assert.NoError(t, state.close())
+	stderr.Reset()
+	_, results, err = openState(path, &stderr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com", "user@example.com"}, resultEmails(results))
+	assert.Equal(t, testResults[0].Result.SMTP, results["user@example.com"].Result.SMTP)
+	assert.Equal(t, 3, strings.Count(stderr.String(), "\n"))
+}
+
+func TestOpenState_Invalid(t *testing.T) {
+	_, _, err := openState(tempDir(t), ioutil.Discard)
+	assert.Error(t, err)
+}
+
+// resultEmails returns the sorted addresses of results
+func resultEmails(results map[string]emailVerifier.BatchResult) []string {
+	var emails []string
+	for email := range results {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	return emails
+}
@@ -7,8 +7,22 @@ const (
 	defaultFromEmail = "hello@maneo.dk"
 	defaultHelloName = "mail.maneo.dk"
 
-	smtpTimeout = 30 * time.Second
-	smtpPort    = ":25"
+	defaultSubAddressSeparator = "+"
+
+	smtpTimeout    = 30 * time.Second
+	smtpPort       = 25
+	tlsSMTPPort    = 465 // port of SMTP over implicit TLS
+	submissionPort = 587 // port of the message submission, see WithSMTPPortFallback
+
+	defaultConnectionRetries    = 1                      // reconnections to a mail server which drops the connection before its banner
+	defaultConnectionRetryDelay = 250 * time.Millisecond // base delay of a reconnection, which is jittered up to twice as long
+
+	proxyMaxFailures  = 3           // failures to reach a proxy in a row after which it's evicted from the pool
+	proxyEvictionTime = time.Minute // time a proxy is evicted for
+
+	mxHealthHalfLife    = 5 * time.Minute // time after which the recorded connections to an MX host count half as much
+	mxUnhealthyFailures = 2.5             // decayed failures from which an MX host failing more often than not is unhealthy, about 3 recent ones
+	mxLatencyWeight     = 0.3             // weight of a connection in the moving average of the latency of an MX host
 
 	reachableYes     = "yes"
 	reachableNo      = "no"
@@ -16,12 +30,40 @@ const (
 
 	alphanumeric = "abcdefghijklmnopqrstuvwxyz0123456789"
 
-	disposableDataURL = "https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.json"
+	disposableDataURL       = "https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.json"
+	disposableUpdateTimeout = 5 * time.Second
+
+	defaultDisposableUpdateInterval = 24 * time.Hour // interval of the auto update of the disposable domains
 
 	gravatarBaseUrl    = "https://www.gravatar.com/avatar/"
 	gravatarDefaultMd5 = "d5fe5cbcc31cff5f8ac010db72eb000c"
+	libravatarBaseUrl  = "https://seccdn.libravatar.org/avatar/"
+	avatarTimeout      = 10 * time.Second
+
+	mxCacheTTL              = time.Hour       // TTL of the cached MX records of a domain, at most, whose records may expire sooner
+	mxFallbackCacheTTL      = 5 * time.Minute // TTL of the cached MX records of a domain whose DNS TTL is unknown
+	defaultCatchAllCacheTTL = time.Hour       // TTL of the cached catch-all check of a domain
+	domainCacheSize         = 10000           // entries of the cache of the domains of a verifier without a cache
+
+	domainAuthTimeout = 5 * time.Second // timeout of the DNS lookups and the policy requests of the domain auth check
 
 	domainThreshold      float32 = 0.82
 	secondLevelThreshold float32 = 0.82
 	topLevelThreshold    float32 = 0.6
+
+	defaultSuggestionMaxDistance = 2
+
+	defaultBatchConcurrency   = 10
+	maxBatchDomainConnections = 2
+
+	defaultSMTPPoolIdleTimeout = 30 * time.Second // idle time after which a pooled connection is closed
+	defaultSMTPPoolMaxProbes   = 20               // RCPT commands sent over a pooled connection before it's closed
+	smtpPoolMaxIdle            = 4                // idle connections pooled per domain
+
+	apiVerifierTimeout       = 10 * time.Second
+	apiVerifierInterval      = time.Second
+	apiVerifierUserAgent     = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36"
+	yahooSignupURL           = "https://login.yahoo.com/account/create"
+	yahooValidateURL         = "https://login.yahoo.com/account/module/create?validateField=userId"
+	outlookCredentialTypeURL = "https://login.microsoftonline.com/common/GetCredentialType"
 )
@@ -0,0 +1,361 @@
+package emailverifier
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DisposableDomainSource opens a list of disposable domains, the list is either a JSON array of domains
+// or contains one domain per line where blank lines and lines starting with "#" are ignored.
+// An entry like "*.example.com" matches every subdomain of example.com.
+type DisposableDomainSource func(ctx context.Context) (io.ReadCloser, error)
+
+// Reasons explaining why a domain is considered disposable
+const (
+	DisposableReasonList        = "list"         // the domain is in the disposable domains list
+	DisposableReasonMXHeuristic = "mx_heuristic" // the domain's MX hosts belong to known disposable email infrastructure
+)
+
+// disposableMXSuffixes are host suffixes of MX servers run by disposable email providers,
+// which also receive the mail of many domains not yet in the disposable domains list
+var disposableMXSuffixes = map[string]bool{
+	"mailinator.com":    true,
+	"guerrillamail.com": true,
+	"yopmail.com":       true,
+	"trashmail.com":     true,
+	"dispostable.com":   true,
+	"mailnesia.com":     true,
+	"maildrop.cc":       true,
+	"harakirimail.com":  true,
+	"33mail.com":        true,
+	"mail.tm":           true,
+}
+
+// wildcardPrefix marks a domain list entry matching all subdomains of the domain following it
+const wildcardPrefix = "*."
+
+// domainSet is an immutable set of domains, it's replaced as a whole on every change
+type domainSet map[string]struct{}
+
+// DisposableUpdateStatus describes the updates of the disposable domains list
+type DisposableUpdateStatus struct {
+	LastAttempt time.Time `json:"last_attempt"` // when an update was last attempted
+	LastSuccess time.Time `json:"last_success"` // when the list was last updated successfully
+	Count       int       `json:"count"`        // number of entries in the current list
+	LastError   error     `json:"-"`            // error of the last attempt, nil if it succeeded
+}
+
+var (
+	disposableUpdateMu          sync.Mutex             // serializes the updates of the disposable domains from a source
+	disposableUpdateStatus      DisposableUpdateStatus // status of the updates, guarded by disposableUpdateMu
+	disposableMu                sync.Mutex             // serializes the writers of the disposable domains
+	disposableSyncDomains       atomic.Value           // current domainSet of disposable domains, read without locking
+	additionalDisposableDomains = domainSet{}          // additional disposable domains set via users of this library
+)
+
+// init loads disposable_domain meta data to disposableSyncDomains which are safe for concurrent use
+func init() {
+	domains := make(domainSet, len(disposableDomains))
+	for d := range disposableDomains {
+		domains[d] = struct{}{}
+	}
+	disposableSyncDomains.Store(domains)
+}
+
+// URLDisposableDomainSource returns a source fetching the disposable domains list from url
+func URLDisposableDomainSource(url string) DisposableDomainSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("get disposable domains from %s with status_code: %d", url, resp.StatusCode)
+		}
+		return resp.Body, nil
+	}
+}
+
+// FileDisposableDomainSource returns a source reading the disposable domains list from a local file
+func FileDisposableDomainSource(path string) DisposableDomainSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+}
+
+// SetDisposableDomainSource sets the source used by the auto update of the disposable domains,
+// defaults to the list maintained at https://github.com/disposable/disposable-email-domains
+func (v *Verifier) SetDisposableDomainSource(source DisposableDomainSource) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.disposableSource = source
+	return v
+}
+
+// EnableDisposableMXHeuristic enables detecting disposable domains which are not in the disposable domains list
+// by their MX hosts, which are compared against the hosts of known disposable email infrastructure.
+// The heuristic only applies to domains whose MX records were fetched.
+func (v *Verifier) EnableDisposableMXHeuristic() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.disposableMXHeuristicEnabled = true
+	return v
+}
+
+// DisableDisposableMXHeuristic disables detecting disposable domains by their MX hosts
+func (v *Verifier) DisableDisposableMXHeuristic() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.disposableMXHeuristicEnabled = false
+	return v
+}
+
+// AddDisposableMXSuffixes adds host suffixes of MX servers of disposable email infrastructure,
+// e.g. "mx.example-temp-mail.com" matches the MX hosts "mx.example-temp-mail.com" and "in1.mx.example-temp-mail.com"
+func (v *Verifier) AddDisposableMXSuffixes(suffixes []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// the map is replaced rather than modified, since snapshots share it
+	mxSuffixes := make(map[string]bool, len(v.disposableMXSuffixes)+len(suffixes))
+	for suffix := range v.disposableMXSuffixes {
+		mxSuffixes[suffix] = true
+	}
+	for _, suffix := range suffixes {
+		mxSuffixes[strings.Trim(strings.ToLower(suffix), ".")] = true
+	}
+	v.disposableMXSuffixes = mxSuffixes
+	return v
+}
+
+// isDisposableMX checks if any of records points to a host of disposable email infrastructure
+func (v *Verifier) isDisposableMX(records []*net.MX) bool {
+	for _, record := range records {
+		host := strings.TrimSuffix(strings.ToLower(record.Host), ".")
+		for suffix := host; suffix != ""; {
+			if disposableMXSuffixes[suffix] || v.disposableMXSuffixes[suffix] {
+				return true
+			}
+			i := strings.IndexByte(suffix, '.')
+			if i < 0 {
+				break
+			}
+			suffix = suffix[i+1:]
+		}
+	}
+	return false
+}
+
+// LoadDisposableDomains replaces the disposable domains with the list read from r,
+// domains added by AddDisposableDomains are kept.
+// If r can't be parsed the current domains are left untouched and the error is returned.
+func (v *Verifier) LoadDisposableDomains(r io.Reader) error {
+	return loadDisposableDomains(r, true)
+}
+
+// MergeDisposableDomains adds the list of disposable domains read from r to the current ones.
+// If r can't be parsed the current domains are left untouched and the error is returned.
+func (v *Verifier) MergeDisposableDomains(r io.Reader) error {
+	return loadDisposableDomains(r, false)
+}
+
+// OnDisposableUpdate sets a callback invoked after each update of the disposable domains started by v,
+// either by the auto update or UpdateDisposableDomainsNow, with the number of entries in the list
+// and the error of the update
+func (v *Verifier) OnDisposableUpdate(callback func(count int, err error)) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.onDisposableUpdate = callback
+	return v
+}
+
+// UpdateDisposableDomainsNow updates the disposable domains from the source set by SetDisposableDomainSource
+// right away. If the update fails the current list is kept and the error is returned.
+func (v *Verifier) UpdateDisposableDomainsNow(ctx context.Context) error {
+	v = v.snapshot()
+	source := v.disposableSource
+	if source == nil {
+		source = URLDisposableDomainSource(disposableDataURL)
+	}
+
+	disposableUpdateMu.Lock()
+	err := updateDisposableDomainsFromSource(ctx, source)
+	now := time.Now()
+	disposableUpdateStatus.LastAttempt = now
+	disposableUpdateStatus.LastError = err
+	if err == nil {
+		disposableUpdateStatus.LastSuccess = now
+	}
+	count := len(currentDisposableDomains())
+	disposableUpdateMu.Unlock()
+
+	if v.onDisposableUpdate != nil {
+		v.onDisposableUpdate(count, err)
+	}
+	return err
+}
+
+// DisposableUpdateStatus returns the status of the updates of the disposable domains list
+func (v *Verifier) DisposableUpdateStatus() DisposableUpdateStatus {
+	disposableUpdateMu.Lock()
+	status := disposableUpdateStatus
+	disposableUpdateMu.Unlock()
+
+	status.Count = len(currentDisposableDomains())
+	return status
+}
+
+// updateDisposableDomainsFromSource replaces the disposable domains with the list opened by source
+func updateDisposableDomainsFromSource(ctx context.Context, source DisposableDomainSource) error {
+	rc, err := source(ctx)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return loadDisposableDomains(rc, true)
+}
+
+// loadDisposableDomains parses the list of disposable domains read from r and stores it,
+// an empty list leaves the current domains untouched
+func loadDisposableDomains(r io.Reader, replace bool) error {
+	domains, err := parseDomainList(r)
+	if err != nil {
+		return err
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+
+	storeDisposableDomains(domains, replace)
+	return nil
+}
+
+// storeDisposableDomains atomically replaces the current disposable domains with a new set,
+// which consists of domains and, unless replace, the current domains
+func storeDisposableDomains(domains []string, replace bool) {
+	disposableMu.Lock()
+	defer disposableMu.Unlock()
+
+	current := currentDisposableDomains()
+	next := make(domainSet, len(domains)+len(additionalDisposableDomains))
+	if !replace {
+		for d := range current {
+			next[d] = struct{}{}
+		}
+	}
+	for _, d := range domains {
+		next[d] = struct{}{}
+	}
+	for d := range additionalDisposableDomains {
+		next[d] = struct{}{}
+	}
+
+	disposableSyncDomains.Store(next)
+	disposableListUpdate.touch()
+}
+
+// addDisposableDomains adds domains which are kept across updates of the disposable domains
+func addDisposableDomains(domains []string) {
+	disposableMu.Lock()
+	for _, d := range domains {
+		additionalDisposableDomains[d] = struct{}{}
+	}
+	disposableMu.Unlock()
+
+	storeDisposableDomains(domains, false)
+}
+
+// matchesWildcard checks if s has a wildcard entry "*.parent" for any of the parent domains of domain
+func (s domainSet) matchesWildcard(domain string) bool {
+	for parent := domain; ; {
+		i := strings.IndexByte(parent, '.')
+		if i < 0 {
+			return false
+		}
+		parent = parent[i+1:]
+		if _, found := s[wildcardPrefix+parent]; found {
+			return true
+		}
+	}
+}
+
+// currentDisposableDomains returns the current set of disposable domains
+func currentDisposableDomains() domainSet {
+	return disposableSyncDomains.Load().(domainSet)
+}
+
+// isDisposableDomain checks if domain is in the current set of disposable domains.
+// domain matches an entry equal to domain, or a wildcard entry "*.parent" for any of its parent domains.
+func isDisposableDomain(domain string) bool {
+	domains := currentDisposableDomains()
+	if _, found := domains[domain]; found {
+		return true
+	}
+	return domains.matchesWildcard(domain)
+}
+
+// parseDomainList parses a JSON array of domains or a list of one domain per line,
+// where blank lines and lines starting with "#" are ignored
+func parseDomainList(r io.Reader) ([]string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return nil, nil
+	}
+
+	var domains []string
+	if content[0] == '[' {
+		if err = json.Unmarshal(content, &domains); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			domains = append(domains, line)
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, d := range domains {
+		name := strings.TrimPrefix(d, wildcardPrefix)
+		if !strings.Contains(name, ".") || strings.IndexFunc(name, func(r rune) bool { return !isDomainChar(r) }) >= 0 {
+			return nil, fmt.Errorf("invalid domain %q in domain list at entry %d", d, i+1)
+		}
+		domains[i] = strings.ToLower(d)
+	}
+	return domains, nil
+}
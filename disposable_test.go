@@ -0,0 +1,345 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tempDir returns a temporary directory removed at the end of the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "emailverifier")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// restoreDisposableDomains restores the disposable domains current at the time of calling
+func restoreDisposableDomains(t *testing.T) {
+	current := currentDisposableDomains()
+	t.Cleanup(func() { disposableSyncDomains.Store(current) })
+}
+
+func TestParseDomainList_Lines(t *testing.T) {
+	domains, err := parseDomainList(strings.NewReader("# comment\n\nA.example.com\n  b.example.org  \n#c.example.net\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.example.com", "b.example.org"}, domains)
+}
+
+func TestParseDomainList_JSON(t *testing.T) {
+	domains, err := parseDomainList(strings.NewReader(`["a.example.com", "B.example.org"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.example.com", "b.example.org"}, domains)
+}
+
+func TestParseDomainList_Invalid(t *testing.T) {
+	_, err := parseDomainList(strings.NewReader("a.example.com\nnot a domain\n"))
+	assert.Error(t, err)
+
+	_, err = parseDomainList(strings.NewReader(`["a.example.com",`))
+	assert.Error(t, err)
+}
+
+func TestLoadDisposableDomains_Replace(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	err := verifier.LoadDisposableDomains(strings.NewReader("load-a-example.com\nload-b-example.com\n"))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsDisposable("load-a-example.com"))
+	assert.True(t, verifier.IsDisposable("load-b-example.com"))
+	assert.False(t, verifier.IsDisposable("0009827.com"))
+}
+
+func TestLoadDisposableDomains_KeepsAdditionalDomains(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	verifier.AddDisposableDomains([]string{"additional-example.com"})
+	err := verifier.LoadDisposableDomains(strings.NewReader("load-a-example.com"))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsDisposable("additional-example.com"))
+}
+
+func TestMergeDisposableDomains(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	err := verifier.MergeDisposableDomains(strings.NewReader("merge-example.com"))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsDisposable("merge-example.com"))
+	assert.True(t, verifier.IsDisposable("0009827.com"))
+}
+
+func TestLoadDisposableDomains_ParseErrorKeepsPreviousList(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	err := verifier.LoadDisposableDomains(strings.NewReader("valid-example.com\ninvalid domain\n"))
+	assert.Error(t, err)
+	assert.True(t, verifier.IsDisposable("0009827.com"))
+	assert.False(t, verifier.IsDisposable("valid-example.com"))
+}
+
+func TestLoadDisposableDomains_EmptyKeepsPreviousList(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	err := verifier.LoadDisposableDomains(strings.NewReader("# nothing here\n"))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsDisposable("0009827.com"))
+}
+
+func TestLoadDisposableDomains_Concurrent(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, verifier.LoadDisposableDomains(strings.NewReader("0009827.com\nconcurrent-example.com")))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.True(t, verifier.IsDisposable("0009827.com"))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUpdateDisposableDomainsFromSource_File(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	path := filepath.Join(tempDir(t), "domains.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("file-example.com\n"), 0644))
+
+	err := updateDisposableDomainsFromSource(context.Background(), FileDisposableDomainSource(path))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsDisposable("file-example.com"))
+}
+
+func TestUpdateDisposableDomainsFromSource_SourceError(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	source := func(ctx context.Context) (io.ReadCloser, error) {
+		return nil, errors.New("source unavailable")
+	}
+	err := updateDisposableDomainsFromSource(context.Background(), source)
+	assert.EqualError(t, err, "source unavailable")
+	assert.True(t, verifier.IsDisposable("0009827.com"))
+
+	err = updateDisposableDomainsFromSource(context.Background(), FileDisposableDomainSource(filepath.Join(tempDir(t), "missing")))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSetDisposableDomainSource(t *testing.T) {
+	source := FileDisposableDomainSource("domains.txt")
+	v := NewVerifier().SetDisposableDomainSource(source)
+	assert.NotNil(t, v.disposableSource)
+}
+
+func TestIsDisposableDomain_Subdomains(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	err := verifier.LoadDisposableDomains(strings.NewReader("mailinator-test.com\n*.wild-test.net\nhost.exact-test.org\nthrowaway-test.co.uk\n"))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		domain   string
+		expected bool
+	}{
+		{domain: "mailinator-test.com", expected: true},
+		{domain: "abc.mailinator-test.com", expected: true},
+		{domain: "a.b.c.mailinator-test.com", expected: true},
+		{domain: "ABC.Mailinator-Test.com", expected: true},
+		{domain: "wild-test.net", expected: false},
+		{domain: "xyz.wild-test.net", expected: true},
+		{domain: "x.y.z.wild-test.net", expected: true},
+		{domain: "host.exact-test.org", expected: true},
+		{domain: "exact-test.org", expected: false},
+		{domain: "other.exact-test.org", expected: false},
+		{domain: "sub.host.exact-test.org", expected: false},
+		{domain: "throwaway-test.co.uk", expected: true},
+		{domain: "a.b.throwaway-test.co.uk", expected: true},
+		{domain: "co.uk", expected: false},
+		{domain: "other.co.uk", expected: false},
+		{domain: "mailinator-test.com.example.org", expected: false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, verifier.IsDisposable(c.domain), c.domain)
+	}
+}
+
+func TestIsDisposableDomain_PublicSuffixEntry(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	// a public suffix in the list must not flag every registrable domain below it
+	err := verifier.LoadDisposableDomains(strings.NewReader("co.uk\n"))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsDisposable("co.uk"))
+	assert.False(t, verifier.IsDisposable("example.co.uk"))
+	assert.False(t, verifier.IsDisposable("mail.example.co.uk"))
+}
+
+func TestParseDomainList_Wildcard(t *testing.T) {
+	domains, err := parseDomainList(strings.NewReader("*.Example.com\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*.example.com"}, domains)
+
+	_, err = parseDomainList(strings.NewReader("*.com.*\n"))
+	assert.Error(t, err)
+}
+
+func TestIsDisposableMX(t *testing.T) {
+	v := NewVerifier().AddDisposableMXSuffixes([]string{"MX.Temp-Inbox.test."})
+
+	cases := []struct {
+		hosts    []string
+		expected bool
+	}{
+		{hosts: []string{"mail.mailinator.com."}, expected: true},
+		{hosts: []string{"mailinator.com"}, expected: true},
+		{hosts: []string{"alt1.aspmx.l.google.com.", "mail.mailinator.com."}, expected: true},
+		{hosts: []string{"mx.temp-inbox.test."}, expected: true},
+		{hosts: []string{"in1.mx.temp-inbox.test."}, expected: true},
+		{hosts: []string{"temp-inbox.test."}, expected: false},
+		{hosts: []string{"notmailinator.com."}, expected: false},
+		{hosts: []string{"mailinator.com.example.org."}, expected: false},
+		{hosts: []string{"aspmx.l.google.com."}, expected: false},
+		{hosts: nil, expected: false},
+	}
+	for _, c := range cases {
+		var records []*net.MX
+		for _, host := range c.hosts {
+			records = append(records, &net.MX{Host: host, Pref: 10})
+		}
+		assert.Equal(t, c.expected, v.isDisposableMX(records), strings.Join(c.hosts, ","))
+	}
+
+	assert.False(t, NewVerifier().isDisposableMX([]*net.MX{{Host: "mx.temp-inbox.test."}}))
+}
+
+func TestDisposableMXHeuristicToggle(t *testing.T) {
+	v := NewVerifier().EnableDisposableMXHeuristic()
+	assert.True(t, v.disposableMXHeuristicEnabled)
+	v.DisableDisposableMXHeuristic()
+	assert.False(t, v.disposableMXHeuristicEnabled)
+}
+
+func TestUpdateDisposableDomainsNow(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	path := filepath.Join(tempDir(t), "domains.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("now-a-example.com\nnow-b-example.com\n"), 0644))
+
+	var (
+		calls int
+		count int
+		err   error
+	)
+	v := NewVerifier().
+		SetDisposableDomainSource(FileDisposableDomainSource(path)).
+		OnDisposableUpdate(func(c int, e error) {
+			calls++
+			count, err = c, e
+		})
+
+	before := time.Now()
+	assert.NoError(t, v.UpdateDisposableDomainsNow(context.Background()))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, len(currentDisposableDomains()), count)
+	assert.NoError(t, err)
+	assert.True(t, v.IsDisposable("now-a-example.com"))
+
+	status := v.DisposableUpdateStatus()
+	assert.Equal(t, count, status.Count)
+	assert.NoError(t, status.LastError)
+	assert.False(t, status.LastSuccess.Before(before))
+	assert.Equal(t, status.LastSuccess, status.LastAttempt)
+}
+
+func TestUpdateDisposableDomainsNow_Failed(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	var (
+		count int
+		err   error
+	)
+	v := NewVerifier().
+		SetDisposableDomainSource(func(ctx context.Context) (io.ReadCloser, error) {
+			return nil, errors.New("source unavailable")
+		}).
+		OnDisposableUpdate(func(c int, e error) {
+			count, err = c, e
+		})
+
+	previous := len(currentDisposableDomains())
+	assert.EqualError(t, v.UpdateDisposableDomainsNow(context.Background()), "source unavailable")
+	assert.EqualError(t, err, "source unavailable")
+	assert.Equal(t, previous, count)
+	assert.True(t, v.IsDisposable("0009827.com"))
+
+	status := v.DisposableUpdateStatus()
+	assert.EqualError(t, status.LastError, "source unavailable")
+	assert.Equal(t, previous, status.Count)
+	assert.True(t, status.LastAttempt.After(status.LastSuccess))
+}
+
+func TestUpdateDisposableDomainsNow_Concurrent(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	v := NewVerifier().SetDisposableDomainSource(func(ctx context.Context) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("0009827.com\nconcurrent-example.com\n")), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, v.UpdateDisposableDomainsNow(context.Background()))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.True(t, v.IsDisposable("0009827.com"))
+		}()
+		go func() {
+			defer wg.Done()
+			v.DisposableUpdateStatus()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, len(currentDisposableDomains()), v.DisposableUpdateStatus().Count)
+	assert.True(t, v.IsDisposable("concurrent-example.com"))
+}
+
+func TestEnableAutoUpdateDisposableEvery(t *testing.T) {
+	restoreDisposableDomains(t)
+
+	var updates int32
+	v := NewVerifier().
+		SetDisposableDomainSource(func(ctx context.Context) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader("0009827.com\nscheduled-example.com\n")), nil
+		}).
+		OnDisposableUpdate(func(c int, e error) {
+			atomic.AddInt32(&updates, 1)
+		})
+
+	v.EnableAutoUpdateDisposableEvery(10 * time.Millisecond)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&updates) >= 2 }, time.Second, time.Millisecond)
+	assert.True(t, v.IsDisposable("scheduled-example.com"))
+
+	v.DisableAutoUpdateDisposable()
+	stopped := atomic.LoadInt32(&updates)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&updates))
+}
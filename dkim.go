@@ -0,0 +1,112 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// defaultDKIMSelectors are the DKIM selectors of the common mail providers and sending services
+var defaultDKIMSelectors = []string{
+	"default", "dkim", "mail", "google", "selector1", "selector2", "k1", "k2", "k3",
+	"s1", "s2", "fm1", "fm2", "fm3", "mandrill", "mxvault", "protonmail", "zoho", "smtp",
+}
+
+// DKIM is detail about the DKIM keys a domain publishes (RFC 6376).
+// Selectors can't be listed, so only the probed ones are known: a domain without any of them may still sign its mail.
+type DKIM struct {
+	Published bool     `json:"published"` // whether any of the probed selectors has a key record
+	Selectors []string `json:"selectors"` // probed selectors which have a key record, in the order they were probed
+}
+
+// SetDKIMSelectors sets the DKIM selectors probed by the domain auth check and CheckDKIM, like "google" or "selector1".
+// No selectors restore the default ones of the common mail providers.
+func (v *Verifier) SetDKIMSelectors(selectors ...string) *Verifier {
+	return v.apply(WithDKIMSelectors(selectors...))
+}
+
+// CheckDKIM returns which of the DKIM selectors, see SetDKIMSelectors, have a key record "{selector}._domainkey.{domain}".
+// A failed DNS lookup yields an error.
+func (v *Verifier) CheckDKIM(domain string) (*DKIM, error) {
+	v = v.snapshot()
+	domain = domainToASCII(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	ctx, cancel := context.WithTimeout(v.context(), domainAuthTimeout)
+	defer cancel()
+
+	return v.checkDKIM(ctx, domain)
+}
+
+// checkDKIM looks up the key records of the DKIM selectors of domain concurrently
+func (v *Verifier) checkDKIM(ctx context.Context, domain string) (*DKIM, error) {
+	selectors := v.dkimSelectors
+	if len(selectors) == 0 {
+		selectors = defaultDKIMSelectors
+	}
+
+	found := make([]bool, len(selectors))
+	errs := make([]error, len(selectors))
+	var wg sync.WaitGroup
+	for i, selector := range selectors {
+		wg.Add(1)
+		go func(i int, selector string) {
+			defer wg.Done()
+			found[i], errs[i] = v.lookupDKIMKey(ctx, selector+"._domainkey."+domain)
+		}(i, selector)
+	}
+	wg.Wait()
+
+	ret := &DKIM{}
+	for i, selector := range selectors {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if found[i] {
+			ret.Published = true
+			ret.Selectors = append(ret.Selectors, selector)
+		}
+	}
+	return ret, nil
+}
+
+// lookupDKIMKey returns whether name has a DKIM key record
+func (v *Verifier) lookupDKIMKey(ctx context.Context, name string) (bool, error) {
+	records, err := v.lookupResolver().LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, ParseSMTPError(err)
+	}
+	for _, record := range records {
+		if isDKIMKeyRecord(record) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isDKIMKeyRecord returns whether the TXT record is a DKIM key record, like "v=DKIM1; k=rsa; p=MIGfMA0...",
+// whose version is optional but whose "p" tag is required (RFC 6376 section 3.6.1)
+func isDKIMKeyRecord(record string) bool {
+	for _, tag := range strings.Split(record, ";") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "p=") || tag == "v=DKIM1" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDKIMSelector returns an error if selector isn't a sequence of DNS labels
+func validateDKIMSelector(selector string) error {
+	for _, label := range strings.Split(selector, ".") {
+		if label == "" || len(label) > 63 || strings.Trim(label, alphanumeric+"-_") != "" {
+			return fmt.Errorf("invalid DKIM selector %q", selector)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDKIM(t *testing.T) {
+	v := newTXTTestVerifier(t, map[string][]string{
+		"google._domainkey.example.com":    {"v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"},
+		"selector1._domainkey.example.com": {"k=rsa; p="},
+		"mail._domainkey.example.com":      {"v=spf1 -all"},
+	})
+
+	dkim, err := v.CheckDKIM("Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &DKIM{Published: true, Selectors: []string{"google", "selector1"}}, dkim)
+
+	// Only the configured selectors are probed
+	dkim, err = v.SetDKIMSelectors("Mail", "s1", "selector1").CheckDKIM("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &DKIM{Published: true, Selectors: []string{"selector1"}}, dkim)
+
+	dkim, err = v.SetDKIMSelectors("s1").CheckDKIM("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &DKIM{}, dkim)
+
+	// No selectors restore the default ones
+	dkim, err = v.SetDKIMSelectors().CheckDKIM("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"google", "selector1"}, dkim.Selectors)
+}
+
+func TestCheckDKIM_IDN(t *testing.T) {
+	v := newTXTTestVerifier(t, map[string][]string{"google._domainkey.xn--mnchen-3ya.de": {"v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"}})
+
+	dkim, err := v.CheckDKIM("München.de")
+	assert.NoError(t, err)
+	assert.Equal(t, &DKIM{Published: true, Selectors: []string{"google"}}, dkim)
+}
+
+func TestIsDKIMKeyRecord(t *testing.T) {
+	assert.True(t, isDKIMKeyRecord("v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"))
+	assert.True(t, isDKIMKeyRecord("p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"))
+	assert.True(t, isDKIMKeyRecord("v=DKIM1; p="))
+	assert.False(t, isDKIMKeyRecord("v=spf1 -all"))
+	assert.False(t, isDKIMKeyRecord(""))
+}
+
+func TestValidateDKIMSelector(t *testing.T) {
+	assert.NoError(t, validateDKIMSelector("google"))
+	assert.NoError(t, validateDKIMSelector("s1024-2013.example"))
+	assert.NoError(t, validateDKIMSelector("my_selector"))
+	assert.Error(t, validateDKIMSelector(""))
+	assert.Error(t, validateDKIMSelector("a..b"))
+	assert.Error(t, validateDKIMSelector("bad selector"))
+}
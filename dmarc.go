@@ -0,0 +1,134 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Policies of a DMARC record for the mail which fails its authentication, see DMARC.Policy
+const (
+	DMARCPolicyNone       = "none"       // the mail is delivered, the domain only monitors the failures
+	DMARCPolicyQuarantine = "quarantine" // the mail is treated as suspicious, e.g. delivered to the spam folder
+	DMARCPolicyReject     = "reject"     // the mail is rejected
+)
+
+// DMARC is detail about the DMARC record of a domain (RFC 7489).
+// A domain without a "_dmarc" TXT record doesn't publish a policy, and a domain with several of them
+// or whose record is invalid is misconfigured.
+type DMARC struct {
+	Published       bool     `json:"published"`        // whether the domain publishes a DMARC TXT record
+	Record          string   `json:"record"`           // DMARC record, like "v=DMARC1; p=reject; rua=mailto:dmarc@example.com"
+	Policy          string   `json:"policy"`           // policy of the domain, see the DMARCPolicy constants, empty without a valid record
+	SubdomainPolicy string   `json:"subdomain_policy"` // policy of the subdomains, the policy of the domain unless the record sets another
+	Percent         int      `json:"percent"`          // percentage of the failing mail the policy applies to, 100 unless the record sets another
+	RUA             []string `json:"rua"`              // URIs the aggregate reports are sent to, like "mailto:dmarc@example.com"
+	Misconfigured   bool     `json:"misconfigured"`    // whether the domain publishes several DMARC records or an invalid one
+	Error           string   `json:"error"`            // why the record is misconfigured
+}
+
+// CheckDMARC returns the DMARC policy of domain from its "_dmarc" TXT record.
+// A failed DNS lookup yields an error, while a domain without a record has an unpublished DMARC.
+func (v *Verifier) CheckDMARC(domain string) (*DMARC, error) {
+	v = v.snapshot()
+	domain = domainToASCII(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	ctx, cancel := context.WithTimeout(v.context(), domainAuthTimeout)
+	defer cancel()
+
+	return v.checkDMARC(ctx, domain)
+}
+
+// checkDMARC looks up the DMARC TXT record of domain and parses its policy
+func (v *Verifier) checkDMARC(ctx context.Context, domain string) (*DMARC, error) {
+	records, err := v.lookupResolver().LookupTXT(ctx, "_dmarc."+domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return &DMARC{}, nil
+		}
+		return nil, ParseSMTPError(err)
+	}
+
+	// Only the records of the version DMARC1 count, and several of them mean no policy (RFC 7489 section 6.6.3)
+	var dmarc []string
+	for _, record := range records {
+		if isDMARCRecord(record) {
+			dmarc = append(dmarc, record)
+		}
+	}
+	if len(dmarc) == 0 {
+		return &DMARC{}, nil
+	}
+
+	ret := &DMARC{Published: true, Record: dmarc[0]}
+	if len(dmarc) > 1 {
+		ret.Misconfigured = true
+		ret.Error = "several DMARC TXT records"
+		return ret, nil
+	}
+	if err := parseDMARCRecord(dmarc[0], ret); err != nil {
+		ret.Policy, ret.SubdomainPolicy, ret.Percent, ret.RUA = "", "", 0, nil
+		ret.Misconfigured = true
+		ret.Error = err.Error()
+	}
+	return ret, nil
+}
+
+// isDMARCRecord returns whether the TXT record is a DMARC record, whose first tag is "v=DMARC1"
+func isDMARCRecord(record string) bool {
+	version := strings.TrimSpace(strings.Split(record, ";")[0])
+	return strings.ReplaceAll(version, " ", "") == "v=DMARC1"
+}
+
+// parseDMARCRecord parses the tags of the DMARC record, like "v=DMARC1; p=reject; pct=50", into ret
+func parseDMARCRecord(record string, ret *DMARC) error {
+	percent := "100"
+	for _, tag := range strings.Split(record, ";")[1:] {
+		index := strings.IndexByte(tag, '=')
+		if index < 0 {
+			continue
+		}
+		key, value := strings.ToLower(strings.TrimSpace(tag[:index])), strings.TrimSpace(tag[index+1:])
+		switch key {
+		case "p":
+			ret.Policy = strings.ToLower(value)
+		case "sp":
+			ret.SubdomainPolicy = strings.ToLower(value)
+		case "pct":
+			percent = value
+		case "rua":
+			for _, uri := range strings.Split(value, ",") {
+				if uri = strings.TrimSpace(uri); uri != "" {
+					ret.RUA = append(ret.RUA, uri)
+				}
+			}
+		}
+	}
+
+	if !isDMARCPolicy(ret.Policy) {
+		return fmt.Errorf("invalid policy %q", ret.Policy)
+	}
+	if ret.SubdomainPolicy == "" {
+		ret.SubdomainPolicy = ret.Policy
+	} else if !isDMARCPolicy(ret.SubdomainPolicy) {
+		return fmt.Errorf("invalid subdomain policy %q", ret.SubdomainPolicy)
+	}
+	pct, err := strconv.Atoi(percent)
+	if err != nil || pct < 0 || pct > 100 {
+		return fmt.Errorf("invalid percentage %q", percent)
+	}
+	ret.Percent = pct
+	return nil
+}
+
+// isDMARCPolicy returns whether policy is one of the DMARCPolicy constants
+func isDMARCPolicy(policy string) bool {
+	switch policy {
+	case DMARCPolicyNone, DMARCPolicyQuarantine, DMARCPolicyReject:
+		return true
+	}
+	return false
+}
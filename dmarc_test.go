@@ -0,0 +1,92 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDMARC(t *testing.T) {
+	cases := []struct {
+		name     string
+		txt      []string
+		expected *DMARC
+	}{
+		{"not published", nil, &DMARC{}},
+		{"other records", []string{"v=spf1 -all", "v=DMARC2; p=reject"}, &DMARC{}},
+		{
+			name: "reject",
+			txt:  []string{"v=DMARC1; p=Reject; sp=none; pct=50; rua=mailto:dmarc@example.com, mailto:reports@example.net!10m"},
+			expected: &DMARC{
+				Published:       true,
+				Record:          "v=DMARC1; p=Reject; sp=none; pct=50; rua=mailto:dmarc@example.com, mailto:reports@example.net!10m",
+				Policy:          DMARCPolicyReject,
+				SubdomainPolicy: DMARCPolicyNone,
+				Percent:         50,
+				RUA:             []string{"mailto:dmarc@example.com", "mailto:reports@example.net!10m"},
+			},
+		},
+		{
+			name:     "defaults",
+			txt:      []string{"v=DMARC1;p=quarantine"},
+			expected: &DMARC{Published: true, Record: "v=DMARC1;p=quarantine", Policy: DMARCPolicyQuarantine, SubdomainPolicy: DMARCPolicyQuarantine, Percent: 100},
+		},
+		{
+			name:     "several records",
+			txt:      []string{"v=DMARC1; p=none", "v=DMARC1; p=reject"},
+			expected: &DMARC{Published: true, Record: "v=DMARC1; p=none", Misconfigured: true, Error: "several DMARC TXT records"},
+		},
+		{
+			name:     "invalid policy",
+			txt:      []string{"v=DMARC1; rua=mailto:dmarc@example.com"},
+			expected: &DMARC{Published: true, Record: "v=DMARC1; rua=mailto:dmarc@example.com", Misconfigured: true, Error: `invalid policy ""`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := newTXTTestVerifier(t, map[string][]string{"_dmarc.example.com": c.txt})
+
+			dmarc, err := v.CheckDMARC("Example.com")
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, dmarc)
+		})
+	}
+}
+
+func TestCheckDMARC_IDN(t *testing.T) {
+	v := newTXTTestVerifier(t, map[string][]string{"_dmarc.xn--mnchen-3ya.de": {"v=DMARC1; p=reject"}})
+
+	dmarc, err := v.CheckDMARC("München.de")
+	assert.NoError(t, err)
+	assert.Equal(t, &DMARC{Published: true, Record: "v=DMARC1; p=reject", Policy: DMARCPolicyReject, SubdomainPolicy: DMARCPolicyReject, Percent: 100}, dmarc)
+}
+
+func TestParseDMARCRecord(t *testing.T) {
+	cases := []struct {
+		record string
+		err    string
+	}{
+		{"v=DMARC1; p=none", ""},
+		{"v=DMARC1; p=reject; sp=quarantine; pct=0", ""},
+		{"v=DMARC1; p=block", `invalid policy "block"`},
+		{"v=DMARC1; p=none; sp=all", `invalid subdomain policy "all"`},
+		{"v=DMARC1; p=none; pct=101", `invalid percentage "101"`},
+		{"v=DMARC1; p=none; pct=half", `invalid percentage "half"`},
+	}
+	for _, c := range cases {
+		err := parseDMARCRecord(c.record, &DMARC{})
+		if c.err == "" {
+			assert.NoError(t, err, c.record)
+		} else {
+			assert.EqualError(t, err, c.err, c.record)
+		}
+	}
+}
+
+func TestIsDMARCRecord(t *testing.T) {
+	assert.True(t, isDMARCRecord("v=DMARC1; p=none"))
+	assert.True(t, isDMARCRecord("v = DMARC1 ;p=none"))
+	assert.False(t, isDMARCRecord("p=none; v=DMARC1"))
+	assert.False(t, isDMARCRecord("v=DMARC10; p=none"))
+	assert.False(t, isDMARCRecord(""))
+}
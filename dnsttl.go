@@ -0,0 +1,141 @@
+package emailverifier
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ttlRecorder records the lowest TTL of the MX and CNAME answers a resolver receives,
+// since net.Resolver doesn't return the TTLs of the records
+type ttlRecorder struct {
+	mu    sync.Mutex
+	ttl   uint32
+	found bool
+}
+
+// record reads the TTLs of the answers of the DNS response msg, a failed or unparsable response has none
+func (r *ttlRecorder) record(msg []byte) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil || !h.Response || h.RCode != dnsmessage.RCodeSuccess {
+		return
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return
+	}
+	for {
+		answer, err := p.AnswerHeader()
+		if err != nil {
+			return
+		}
+		if answer.Type == dnsmessage.TypeMX || answer.Type == dnsmessage.TypeCNAME {
+			r.mu.Lock()
+			if !r.found || answer.TTL < r.ttl {
+				r.ttl, r.found = answer.TTL, true
+			}
+			r.mu.Unlock()
+		}
+		if err := p.SkipAnswer(); err != nil {
+			return
+		}
+	}
+}
+
+// duration returns the lowest recorded TTL, and false if no answer was recorded
+func (r *ttlRecorder) duration() (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Duration(r.ttl) * time.Second, r.found
+}
+
+// cacheTTL returns how long the MX records whose answers r recorded are cached: their lowest TTL, at most mxCacheTTL,
+// or mxFallbackCacheTTL when r recorded none, e.g. with the resolver of the system, so records which change
+// aren't cached for an hour
+func (r *ttlRecorder) cacheTTL() time.Duration {
+	ttl, ok := r.duration()
+	switch {
+	case !ok:
+		return mxFallbackCacheTTL
+	case ttl > mxCacheTTL:
+		return mxCacheTTL
+	}
+	return ttl
+}
+
+// resolver returns a resolver dialing the DNS servers like base, whose responses are recorded by r.
+// Only the Go resolver dials, so base is returned as is unless it prefers the Go resolver, rather than
+// switching it from the resolver of the system, and r records nothing then.
+func (r *ttlRecorder) resolver(base *net.Resolver) *net.Resolver {
+	if !base.PreferGo {
+		return base
+	}
+	dial := base.Dial
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	return &net.Resolver{
+		PreferGo:     true,
+		StrictErrors: base.StrictErrors,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, err := dial(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			// the resolver frames the messages of a net.PacketConn as datagrams, and those of other connections
+			// like DNS over TCP
+			if pc, ok := conn.(net.PacketConn); ok {
+				return &ttlPacketConn{Conn: conn, pc: pc, r: r}, nil
+			}
+			return &ttlConn{Conn: conn, r: r}, nil
+		},
+	}
+}
+
+// ttlConn is a stream connection to a DNS server, whose responses prefixed by their length are recorded
+type ttlConn struct {
+	net.Conn
+	r   *ttlRecorder
+	buf []byte // bytes read of the pending responses
+}
+
+func (c *ttlConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.buf = append(c.buf, b[:n]...)
+	for len(c.buf) >= 2 {
+		length := int(c.buf[0])<<8 | int(c.buf[1])
+		if len(c.buf) < 2+length {
+			break
+		}
+		c.r.record(c.buf[2 : 2+length])
+		c.buf = c.buf[2+length:]
+	}
+	return n, err
+}
+
+// ttlPacketConn is a datagram connection to a DNS server, whose responses are recorded
+type ttlPacketConn struct {
+	net.Conn
+	pc net.PacketConn
+	r  *ttlRecorder
+}
+
+func (c *ttlPacketConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.r.record(b[:n])
+	return n, err
+}
+
+func (c *ttlPacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.pc.ReadFrom(b)
+	c.r.record(b[:n])
+	return n, addr, err
+}
+
+func (c *ttlPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	return c.pc.WriteTo(b, addr)
+}
@@ -0,0 +1,73 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsResponse returns a response of rcode to an MX query of example.com, answered by a CNAME record
+// of cnameTTL and an MX record of mxTTL
+func dnsResponse(t *testing.T, rcode dnsmessage.RCode, cnameTTL, mxTTL uint32) []byte {
+	name := dnsmessage.MustNewName("example.com.")
+	target := dnsmessage.MustNewName("mail.example.net.")
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, RCode: rcode})
+	assert.NoError(t, b.StartQuestions())
+	assert.NoError(t, b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeMX, Class: dnsmessage.ClassINET}))
+	assert.NoError(t, b.StartAnswers())
+	assert.NoError(t, b.CNAMEResource(dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: cnameTTL},
+		dnsmessage.CNAMEResource{CNAME: target}))
+	assert.NoError(t, b.MXResource(dnsmessage.ResourceHeader{Name: target, Class: dnsmessage.ClassINET, TTL: mxTTL},
+		dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mx.example.net.")}))
+	msg, err := b.Finish()
+	assert.NoError(t, err)
+	return msg
+}
+
+func TestTTLRecorder(t *testing.T) {
+	var r ttlRecorder
+	_, ok := r.duration()
+	assert.False(t, ok)
+
+	// the failed and unparsable responses have no TTL
+	r.record(dnsResponse(t, dnsmessage.RCodeNameError, 10, 10))
+	r.record([]byte("not a response"))
+	_, ok = r.duration()
+	assert.False(t, ok)
+
+	r.record(dnsResponse(t, dnsmessage.RCodeSuccess, 300, 30))
+	ttl, ok := r.duration()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, ttl)
+
+	// the lowest TTL of the responses and of the CNAME records is kept
+	r.record(dnsResponse(t, dnsmessage.RCodeSuccess, 20, 3600))
+	ttl, _ = r.duration()
+	assert.Equal(t, 20*time.Second, ttl)
+}
+
+func TestTTLRecorder_CacheTTL(t *testing.T) {
+	var r ttlRecorder
+	assert.Equal(t, mxFallbackCacheTTL, r.cacheTTL())
+
+	r.record(dnsResponse(t, dnsmessage.RCodeSuccess, 7200, 7200))
+	assert.Equal(t, mxCacheTTL, r.cacheTTL())
+	r.record(dnsResponse(t, dnsmessage.RCodeSuccess, 30, 30))
+	assert.Equal(t, 30*time.Second, r.cacheTTL())
+}
+
+func TestTTLRecorder_SystemResolver(t *testing.T) {
+	var r ttlRecorder
+	// the resolver of the system is kept, whose TTLs can't be read, so its records are cached for the fallback TTL
+	assert.Equal(t, net.DefaultResolver, r.resolver(net.DefaultResolver))
+	assert.Equal(t, mxFallbackCacheTTL, r.cacheTTL())
+
+	base := &net.Resolver{PreferGo: true, StrictErrors: true}
+	resolver := r.resolver(base)
+	assert.False(t, resolver == base)
+	assert.True(t, resolver.PreferGo)
+	assert.True(t, resolver.StrictErrors)
+}
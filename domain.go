@@ -0,0 +1,131 @@
+package emailverifier
+
+import "context"
+
+// domainProbeUsername is the local part used to check the syntax of a domain
+const domainProbeUsername = "postmaster"
+
+// DomainResult is the result of a domain verification
+type DomainResult struct {
+	Domain           string      `json:"domain"`            // passed domain
+	DomainASCII      string      `json:"domain_ascii"`      // ASCII (punycode) form of the domain
+	DomainUnicode    string      `json:"domain_unicode"`    // Unicode form of the domain
+	Valid            bool        `json:"valid"`             // whether the domain syntax is valid
+	Reasons          []string    `json:"reasons,omitempty"` // why the domain syntax is invalid, see the Reason constants
+	Resolves         bool        `json:"resolves"`          // whether the domain has MX, A or AAAA records
+	HasMxRecords     bool        `json:"has_mx_records"`    // whether or not MX-Records for the domain
+	NullMX           bool        `json:"null_mx"`           // whether the domain publishes a null MX record, i.e. accepts no email (RFC 7505)
+	SMTP             *SMTP       `json:"smtp"`              // details about the catch-all check of the mail server
+	DomainAuth       *DomainAuth `json:"domain_auth"`       // authentication policies of the domain, null if the domain auth check didn't run
+	Suggestion       string      `json:"suggestion"`        // domain suggestion when domain is misspelled
+	Disposable       bool        `json:"disposable"`        // is this a domain of a DEA (disposable email address) provider
+	DisposableReason string      `json:"disposable_reason"` // why the domain is considered disposable, see the DisposableReason constants
+	Free             bool        `json:"free"`              // is domain a free email domain
+	Skipped          bool        `json:"skipped"`           // whether the checks requiring network access were skipped
+	SkipReason       string      `json:"skip_reason"`       // why the checks were skipped, see the SkipReason constants
+}
+
+// VerifyDomain performs every check of Verify which doesn't need a local part on domain,
+// i.e. syntax, misc, mx and the smtp catch-all checks
+func (v *Verifier) VerifyDomain(domain string) (*DomainResult, error) {
+	return v.VerifyDomainWithContext(context.Background(), domain)
+}
+
+// VerifyDomainWithContext performs the checks of VerifyDomain until ctx is done, which interrupts the pending
+// DNS lookups, connections and SMTP commands. A verification ctx interrupted returns the partial result
+// and the error of ctx.
+func (v *Verifier) VerifyDomainWithContext(ctx context.Context, domain string) (*DomainResult, error) {
+	v = v.snapshot()
+	if ctx.Done() != nil {
+		v = v.withContext(ctx)
+	}
+	ret, err := v.verifyDomain(domain)
+	if err != nil && contextExpired(ctx) {
+		err = contextError(ctx)
+	}
+	return ret, err
+}
+
+// verifyDomain performs the checks of VerifyDomain
+func (v *Verifier) verifyDomain(domain string) (*DomainResult, error) {
+	ret := DomainResult{
+		Domain: domain,
+	}
+
+	syntax := v.ParseAddress(domainProbeUsername + "@" + domain)
+	ret.Valid = syntax.Valid
+	ret.Reasons = syntax.Reasons
+	if !syntax.Valid {
+		ret.Suggestion = v.suggestDomain(domain)
+		return &ret, nil
+	}
+	ret.DomainASCII = syntax.DomainASCII
+	ret.DomainUnicode = syntax.DomainUnicode
+
+	ret.Free = v.IsFreeDomain(syntax.Domain)
+
+	// The allowlist and blocklist are checked before any network access
+	if _, ok := v.allowlisted(syntax.DomainASCII); ok {
+		ret.Skipped = true
+		ret.SkipReason = SkipReasonAllowlisted
+		return &ret, nil
+	}
+	if v.blocklisted(syntax.DomainASCII) {
+		ret.Skipped = true
+		ret.SkipReason = SkipReasonBlocklisted
+		return &ret, nil
+	}
+
+	ret.Disposable = v.IsDisposable(syntax.Domain)
+	if ret.Disposable {
+		ret.DisposableReason = DisposableReasonList
+		return &ret, nil
+	}
+
+	// A domain without MX records is no lookup error here, mail is then delivered to its A or AAAA records
+	mx, err := v.CheckMX(syntax.DomainASCII)
+	if err != nil && !isNoSuchHost(err) {
+		return &ret, err
+	}
+	if mx != nil {
+		ret.HasMxRecords = mx.HasMXRecord
+		ret.NullMX = isNullMX(mx.Records)
+	}
+
+	if ret.HasMxRecords {
+		ret.Resolves = true
+	} else {
+		hosts, _ := v.lookupResolver().LookupHost(v.context(), syntax.DomainASCII)
+		ret.Resolves = len(hosts) > 0
+		ret.Suggestion = v.suggestDomain(syntax.Domain)
+	}
+
+	if v.disposableMXHeuristicEnabled && mx != nil && v.isDisposableMX(mx.Records) {
+		ret.Disposable = true
+		ret.DisposableReason = DisposableReasonMXHeuristic
+		return &ret, nil
+	}
+
+	if v.domainAuthCheckEnabled && ret.Resolves {
+		if ret.DomainAuth, err = v.checkDomainAuth(syntax.DomainASCII); err != nil {
+			return &ret, err
+		}
+	}
+
+	if v.smtpCheckEnabled && ret.HasMxRecords && !ret.NullMX {
+		// The catch-all check of the domain is shared with Verify while it's cached
+		var smtp SMTP
+		if err := v.catchAll(syntax.DomainASCII, &smtp); err != nil {
+			return &ret, err
+		}
+		ret.SMTP = &smtp
+	}
+
+	return &ret, nil
+}
+
+// isNoSuchHost checks if err is a lookup error of a domain which doesn't exist or has no records
+func isNoSuchHost(err error) bool {
+	e, ok := err.(*LookupError)
+	return ok && e.Message == ErrNoSuchHost
+}
@@ -0,0 +1,106 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestVerifyDomain_InvalidSyntax(t *testing.T) {
+	ret, err := NewVerifier().VerifyDomain("exa mple.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.Valid)
+	assert.Equal(t, "exa mple.com", ret.Domain)
+	assert.NotEmpty(t, ret.Reasons)
+	assert.False(t, ret.Resolves)
+}
+
+func TestVerifyDomain_InvalidSyntaxSuggestion(t *testing.T) {
+	ret, err := NewVerifier().EnableDomainSuggest().VerifyDomain("gmailcom")
+	assert.NoError(t, err)
+	assert.False(t, ret.Valid)
+	assert.Equal(t, "gmail.com", ret.Suggestion)
+}
+
+func TestVerifyDomain_Disposable(t *testing.T) {
+	ret, err := NewVerifier().EnableSMTPCheck().VerifyDomain("zzjbfwqi.shop")
+	assert.NoError(t, err)
+	assert.Equal(t, &DomainResult{
+		Domain:           "zzjbfwqi.shop",
+		DomainASCII:      "zzjbfwqi.shop",
+		DomainUnicode:    "zzjbfwqi.shop",
+		Valid:            true,
+		Disposable:       true,
+		DisposableReason: DisposableReasonList,
+	}, ret)
+}
+
+func TestVerifyDomain_Allowlisted(t *testing.T) {
+	v := NewVerifier().SetDomainAllowlist([]string{"*.trusted.test"}, reachableYes)
+	ret, err := v.VerifyDomain("Mail.Trusted.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.Valid)
+	assert.True(t, ret.Skipped)
+	assert.Equal(t, SkipReasonAllowlisted, ret.SkipReason)
+	assert.Equal(t, "mail.trusted.test", ret.DomainASCII)
+}
+
+func TestVerifyDomain_Blocklisted(t *testing.T) {
+	v := NewVerifier().SetDomainBlocklist([]string{"yahoo.com"})
+	ret, err := v.VerifyDomain("yahoo.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.Free)
+	assert.True(t, ret.Skipped)
+	assert.Equal(t, SkipReasonBlocklisted, ret.SkipReason)
+	assert.Nil(t, ret.SMTP)
+}
+
+func TestVerifyDomain_IDN(t *testing.T) {
+	v := NewVerifier().SetDomainBlocklist([]string{"münchen.de"})
+	ret, err := v.VerifyDomain("münchen.de")
+	assert.NoError(t, err)
+	assert.Equal(t, "xn--mnchen-3ya.de", ret.DomainASCII)
+	assert.Equal(t, "münchen.de", ret.DomainUnicode)
+	assert.True(t, ret.Skipped)
+}
+
+func TestVerifyDomain_SharesCatchAllCache(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "250 2.1.5 OK"}, []string{"example.com"})
+
+	ret, err := v.VerifyDomain("example.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.CatchAll)
+	assert.Equal(t, 1, srv.Connections())
+
+	// The cached catch-all check serves the later verifications of the domain
+	ret, err = v.VerifyDomain("example.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.CatchAll)
+	result, err := v.Verify("username@example.com")
+	assert.NoError(t, err)
+	assert.True(t, result.SMTP.CatchAll)
+	assert.Equal(t, 1, srv.Connections())
+}
+
+func TestVerifyDomainWithContext_Canceled(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{BannerDelay: 10 * time.Second}, []string{"example.com"})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	ret, err := v.VerifyDomainWithContext(ctx, "example.com")
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, ret.HasMxRecords)
+}
+
+func TestIsNoSuchHost(t *testing.T) {
+	assert.True(t, isNoSuchHost(newLookupError(ErrNoSuchHost, "lookup example.invalid: no such host")))
+	assert.False(t, isNoSuchHost(newLookupError(ErrTimeout, "i/o timeout")))
+	assert.False(t, isNoSuchHost(errors.New(ErrNoSuchHost)))
+}
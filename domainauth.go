@@ -0,0 +1,232 @@
+package emailverifier
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Modes of an MTA-STS policy, see MTASTS.Mode
+const (
+	MTASTSModeEnforce = "enforce" // sending servers refuse to deliver to an MX host which fails the policy
+	MTASTSModeTesting = "testing" // sending servers report the failures of the policy but deliver anyway
+	MTASTSModeNone    = "none"    // the domain withdraws its policy
+)
+
+// maxMTASTSPolicySize is the size limit of an MTA-STS policy (RFC 8461 section 3.2)
+const maxMTASTSPolicySize = 64 * 1024
+
+// DomainAuth is detail about the policies a domain publishes to authenticate its mail servers
+type DomainAuth struct {
+	MTASTS *MTASTS `json:"mta_sts"` // MTA-STS policy of the domain
+	SPF    *SPF    `json:"spf"`     // SPF record of the domain
+	DKIM   *DKIM   `json:"dkim"`    // DKIM selectors of the domain
+	DMARC  *DMARC  `json:"dmarc"`   // DMARC policy of the domain
+}
+
+// MTASTS is detail about the MTA-STS policy of a domain (RFC 8461).
+// A domain without a "_mta-sts" TXT record doesn't publish a policy, and a domain whose record
+// is published but whose policy can't be fetched or is invalid is misconfigured.
+type MTASTS struct {
+	Published     bool     `json:"published"`     // whether the domain publishes an MTA-STS TXT record
+	ID            string   `json:"id"`            // id of the policy in the TXT record
+	Mode          string   `json:"mode"`          // mode of the policy, see the MTASTSMode constants, empty without a valid policy
+	MX            []string `json:"mx"`            // MX host patterns of the policy, like "*.example.com"
+	MaxAge        int      `json:"max_age"`       // lifetime of the policy in seconds
+	Misconfigured bool     `json:"misconfigured"` // whether the record is published but the policy can't be fetched or is invalid
+	Error         string   `json:"error"`         // why the policy is misconfigured
+}
+
+// EnableDomainAuthCheck enables the domain auth check, which reports the MTA-STS policy, the SPF record, the DKIM selectors and the DMARC policy of the domain
+// of the verified addresses and domains, we don't check it by default
+func (v *Verifier) EnableDomainAuthCheck() *Verifier {
+	return v.apply(WithDomainAuthCheck())
+}
+
+// DisableDomainAuthCheck disables the domain auth check
+func (v *Verifier) DisableDomainAuthCheck() *Verifier {
+	return v.apply(WithoutDomainAuthCheck())
+}
+
+// SetDomainAuthHTTPClient sets the HTTP client fetching the MTA-STS policies of the domain auth check.
+// Redirects are never followed, as RFC 8461 requires. A nil client restores http.DefaultClient
+// with a timeout of 5 seconds.
+func (v *Verifier) SetDomainAuthHTTPClient(client *http.Client) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.domainAuthClient = client
+	return v
+}
+
+// CheckDomainAuth returns the authentication policies of domain, i.e. its MTA-STS policy, its SPF record, its DKIM selectors and its DMARC policy.
+// A failed DNS lookup yields an error, while a policy which can't be fetched is reported as misconfigured.
+func (v *Verifier) CheckDomainAuth(domain string) (*DomainAuth, error) {
+	v = v.snapshot()
+	return v.checkDomainAuth(domain)
+}
+
+// checkDomainAuth performs CheckDomainAuth
+func (v *Verifier) checkDomainAuth(domain string) (*DomainAuth, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	ctx, cancel := context.WithTimeout(v.context(), domainAuthTimeout)
+	defer cancel()
+
+	mtaSTS, err := v.checkMTASTS(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	spf, err := v.checkSPF(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	dkim, err := v.checkDKIM(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	dmarc, err := v.checkDMARC(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainAuth{MTASTS: mtaSTS, SPF: spf, DKIM: dkim, DMARC: dmarc}, nil
+}
+
+// checkMTASTS looks up the MTA-STS TXT record of domain and fetches the policy it announces
+func (v *Verifier) checkMTASTS(ctx context.Context, domain string) (*MTASTS, error) {
+	records, err := v.lookupResolver().LookupTXT(ctx, "_mta-sts."+domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return &MTASTS{}, nil
+		}
+		return nil, ParseSMTPError(err)
+	}
+
+	// Only the records of the version STSv1 count, and several of them mean no policy (RFC 8461 section 3.1)
+	var ids []string
+	for _, record := range records {
+		if id, ok := parseMTASTSRecord(record); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return &MTASTS{}, nil
+	}
+
+	ret := &MTASTS{Published: true, ID: ids[0]}
+	if len(ids) > 1 {
+		ret.Misconfigured = true
+		ret.Error = "several MTA-STS TXT records"
+		return ret, nil
+	}
+	if err := fetchMTASTSPolicy(ctx, v.domainAuthHTTPClient(), domain, ret); err != nil {
+		// A policy the verify timeout interrupted isn't known to be misconfigured
+		if v.expired() {
+			return nil, err
+		}
+		ret.Mode, ret.MX, ret.MaxAge = "", nil, 0
+		ret.Misconfigured = true
+		ret.Error = err.Error()
+	}
+	return ret, nil
+}
+
+// domainAuthHTTPClient returns the client fetching the MTA-STS policies, which doesn't follow redirects
+func (v *Verifier) domainAuthHTTPClient() *http.Client {
+	client := http.Client{Timeout: domainAuthTimeout}
+	if v.domainAuthClient != nil {
+		client = *v.domainAuthClient
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &client
+}
+
+// parseMTASTSRecord returns the id of the MTA-STS TXT record, like "v=STSv1; id=20160831085700Z;",
+// ok is false if record isn't of the version STSv1 or has no valid id
+func parseMTASTSRecord(record string) (id string, ok bool) {
+	fields := strings.Split(record, ";")
+	if strings.TrimSpace(fields[0]) != "v=STSv1" {
+		return "", false
+	}
+	for _, field := range fields[1:] {
+		field = strings.TrimSpace(field)
+		if strings.HasPrefix(field, "id=") {
+			id = strings.TrimPrefix(field, "id=")
+		}
+	}
+	if id == "" || len(id) > 32 || strings.Trim(id, alphanumeric+"ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", false
+	}
+	return id, true
+}
+
+// fetchMTASTSPolicy fetches the MTA-STS policy of domain with client and records it in ret
+func fetchMTASTSPolicy(ctx context.Context, client *http.Client, domain string, ret *MTASTS) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://mta-sts."+domain+"/.well-known/mta-sts.txt", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the policy: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch the policy: unexpected status %s", resp.Status)
+	}
+	return parseMTASTSPolicy(io.LimitReader(resp.Body, maxMTASTSPolicySize), ret)
+}
+
+// parseMTASTSPolicy parses the policy body, lines of "key: value" (RFC 8461 section 3.2), into ret
+func parseMTASTSPolicy(body io.Reader, ret *MTASTS) error {
+	var version, maxAge string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		index := strings.IndexByte(line, ':')
+		if index < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:])
+		switch key {
+		case "version":
+			version = value
+		case "mode":
+			ret.Mode = value
+		case "mx":
+			ret.MX = append(ret.MX, strings.ToLower(value))
+		case "max_age":
+			maxAge = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the policy: %v", err)
+	}
+
+	if version != "STSv1" {
+		return fmt.Errorf("invalid policy version %q", version)
+	}
+	switch ret.Mode {
+	case MTASTSModeEnforce, MTASTSModeTesting, MTASTSModeNone:
+	default:
+		return fmt.Errorf("invalid policy mode %q", ret.Mode)
+	}
+	age, err := strconv.Atoi(maxAge)
+	if err != nil || age < 0 || age > 31557600 {
+		return fmt.Errorf("invalid policy max_age %q", maxAge)
+	}
+	ret.MaxAge = age
+	if len(ret.MX) == 0 && ret.Mode != MTASTSModeNone {
+		return errors.New("policy without mx")
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package emailverifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+const testMTASTSPolicy = "version: STSv1\r\nmode: enforce\r\nmx: mx.example.com\r\nmx: *.Example.net\r\nmax_age: 86400\r\n"
+
+// newMTASTSTestVerifier returns a verifier resolving the domains with the TXT records txt,
+// whose MTA-STS policies are served by handler
+func newMTASTSTestVerifier(t *testing.T, txt map[string][]string, handler http.HandlerFunc) *Verifier {
+	srv := smtptest.NewServer(smtptest.Behavior{TXT: txt}, "example.com")
+	t.Cleanup(srv.Close)
+	policy := httptest.NewTLSServer(handler)
+	t.Cleanup(policy.Close)
+
+	// The test certificate is valid for example.com, every policy host is dialed at the test server
+	transport := policy.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.ServerName = "example.com"
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, policy.Listener.Addr().String())
+	}
+
+	v, err := NewVerifierWithOptions(WithResolver(srv.Resolver()), WithDomainAuthCheck())
+	assert.NoError(t, err)
+	return v.SetDomainAuthHTTPClient(&http.Client{Transport: transport})
+}
+
+func TestCheckDomainAuth_Enforce(t *testing.T) {
+	var host, path string
+	v := newMTASTSTestVerifier(t, map[string][]string{
+		"_mta-sts.example.com": {"v=spf1 -all", "v=STSv1; id=20160831085700Z;"},
+		"example.com":          {"v=STSv1; id=1", "v=spf1 mx ~all"},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		host, path = r.Host, r.URL.Path
+		fmt.Fprint(w, testMTASTSPolicy)
+	})
+
+	auth, err := v.CheckDomainAuth("Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &DomainAuth{MTASTS: &MTASTS{
+		Published: true,
+		ID:        "20160831085700Z",
+		Mode:      MTASTSModeEnforce,
+		MX:        []string{"mx.example.com", "*.example.net"},
+		MaxAge:    86400,
+	}, SPF: &SPF{Published: true, Record: "v=spf1 mx ~all", Policy: SPFPolicySoftFail}, DKIM: &DKIM{}, DMARC: &DMARC{}}, auth)
+	assert.Equal(t, "mta-sts.example.com", host)
+	assert.Equal(t, "/.well-known/mta-sts.txt", path)
+}
+
+func TestCheckDomainAuth_NotPublished(t *testing.T) {
+	v := newMTASTSTestVerifier(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the policy of a domain without the TXT record is fetched")
+	})
+
+	auth, err := v.CheckDomainAuth("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &DomainAuth{MTASTS: &MTASTS{}, SPF: &SPF{}, DKIM: &DKIM{}, DMARC: &DMARC{}}, auth)
+}
+
+func TestCheckDomainAuth_Misconfigured(t *testing.T) {
+	cases := []struct {
+		name    string
+		txt     []string
+		handler http.HandlerFunc
+		err     string
+	}{
+		{
+			name:    "not found",
+			txt:     []string{"v=STSv1; id=1"},
+			handler: http.NotFound,
+			err:     "unexpected status 404",
+		},
+		{
+			name: "redirect",
+			txt:  []string{"v=STSv1; id=1"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "https://example.com/policy", http.StatusFound)
+			},
+			err: "unexpected status 302",
+		},
+		{
+			name: "invalid mode",
+			txt:  []string{"v=STSv1; id=1"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, strings.Replace(testMTASTSPolicy, "enforce", "strict", 1))
+			},
+			err: `invalid policy mode "strict"`,
+		},
+		{
+			name:    "several records",
+			txt:     []string{"v=STSv1; id=1", "v=STSv1; id=2"},
+			handler: http.NotFound,
+			err:     "several MTA-STS TXT records",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := newMTASTSTestVerifier(t, map[string][]string{"_mta-sts.example.com": c.txt}, c.handler)
+
+			auth, err := v.CheckDomainAuth("example.com")
+			assert.NoError(t, err)
+			if assert.NotNil(t, auth) && assert.NotNil(t, auth.MTASTS) {
+				assert.True(t, auth.MTASTS.Published)
+				assert.True(t, auth.MTASTS.Misconfigured)
+				assert.Empty(t, auth.MTASTS.Mode)
+				assert.Contains(t, auth.MTASTS.Error, c.err)
+			}
+		})
+	}
+}
+
+func TestParseMTASTSRecord(t *testing.T) {
+	cases := []struct {
+		record string
+		id     string
+		ok     bool
+	}{
+		{"v=STSv1; id=20160831085700Z;", "20160831085700Z", true},
+		{"v=STSv1;id=abc", "abc", true},
+		{"v=STSv1; id=abc; ext=1", "abc", true},
+		{"v=STSv1;", "", false},
+		{"v=STSv1; id=a-b", "", false},
+		{"v=STSv2; id=abc", "", false},
+		{"v=spf1 -all", "", false},
+	}
+	for _, c := range cases {
+		id, ok := parseMTASTSRecord(c.record)
+		assert.Equal(t, c.id, id, c.record)
+		assert.Equal(t, c.ok, ok, c.record)
+	}
+}
+
+func TestParseMTASTSPolicy(t *testing.T) {
+	var ret MTASTS
+	assert.NoError(t, parseMTASTSPolicy(strings.NewReader("version: STSv1\nmode: none\nmax_age: 0\n"), &ret))
+	assert.Equal(t, MTASTSModeNone, ret.Mode)
+
+	for _, policy := range []string{
+		"mode: enforce\nmx: mx.example.com\nmax_age: 86400\n",
+		"version: STSv1\nmode: enforce\nmx: mx.example.com\n",
+		"version: STSv1\nmode: enforce\nmx: mx.example.com\nmax_age: 99999999\n",
+		"version: STSv1\nmode: testing\nmax_age: 86400\n",
+	} {
+		var ret MTASTS
+		assert.Error(t, parseMTASTSPolicy(strings.NewReader(policy), &ret), policy)
+	}
+}
+
+func TestVerify_DomainAuth(t *testing.T) {
+	v := newMTASTSTestVerifier(t, map[string][]string{
+		"_mta-sts.example.com": {"v=STSv1; id=1"},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testMTASTSPolicy)
+	})
+
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ret.DomainAuth) && assert.NotNil(t, ret.DomainAuth.MTASTS) {
+		assert.Equal(t, MTASTSModeEnforce, ret.DomainAuth.MTASTS.Mode)
+	}
+
+	domain, err := v.VerifyDomain("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, ret.DomainAuth, domain.DomainAuth)
+
+	results := v.VerifyBatch([]string{"a@example.com", "b@example.com"}, BatchOptions{GroupByDomain: true})
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+		assert.Equal(t, ret.DomainAuth, result.Result.DomainAuth)
+	}
+
+	v.DisableDomainAuthCheck()
+	ret, err = v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, ret.DomainAuth)
+}
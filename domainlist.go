@@ -0,0 +1,83 @@
+package emailverifier
+
+import (
+	"strings"
+)
+
+// Reasons explaining why the checks requiring network access were skipped
+const (
+	SkipReasonAllowlisted = "domain_allowlisted" // the domain is in the allowlist of the verifier
+	SkipReasonBlocklisted = "domain_blocklisted" // the domain is in the blocklist of the verifier
+)
+
+// domainAllowlist is an immutable allowlist of domains
+type domainAllowlist struct {
+	domains   domainSet // allowlisted domains and wildcard entries
+	reachable string    // reachability reported for the addresses of allowlisted domains
+}
+
+// SetDomainAllowlist replaces the domains which are trusted without any check requiring network access,
+// Verify reports their addresses with the given reachable value ("yes", "no" or "unknown").
+// An entry like "*.example.com" matches every subdomain of example.com.
+// The allowlist can be replaced while Verify is running.
+func (v *Verifier) SetDomainAllowlist(domains []string, reachable string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.domainAllowlist = &domainAllowlist{
+		domains:   newDomainSet(domains),
+		reachable: reachable,
+	}
+	return v
+}
+
+// SetDomainBlocklist replaces the domains which must never be probed,
+// Verify skips any check requiring network access for their addresses and marks the result as skipped.
+// An entry like "*.example.com" matches every subdomain of example.com.
+// The blocklist can be replaced while Verify is running.
+func (v *Verifier) SetDomainBlocklist(domains []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.domainBlocklist = newDomainSet(domains)
+	return v
+}
+
+// allowlisted checks if domain is allowlisted and returns the reachability configured for it
+func (v *Verifier) allowlisted(domain string) (string, bool) {
+	if v.domainAllowlist == nil || !v.domainAllowlist.domains.matches(domain) {
+		return "", false
+	}
+	return v.domainAllowlist.reachable, true
+}
+
+// blocklisted checks if domain is blocklisted
+func (v *Verifier) blocklisted(domain string) bool {
+	return v.domainBlocklist.matches(domain)
+}
+
+// newDomainSet returns a domainSet of the lowercase ASCII form of domains
+func newDomainSet(domains []string) domainSet {
+	set := make(domainSet, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if strings.HasPrefix(d, wildcardPrefix) {
+			set[wildcardPrefix+domainToASCII(strings.TrimPrefix(d, wildcardPrefix))] = struct{}{}
+			continue
+		}
+		set[domainToASCII(d)] = struct{}{}
+	}
+	return set
+}
+
+// matches checks if the lowercase ASCII form of domain is in s, either exactly or by a wildcard entry
+func (s domainSet) matches(domain string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	domain = domainToASCII(strings.ToLower(domain))
+	if _, found := s[domain]; found {
+		return true
+	}
+	return s.matchesWildcard(domain)
+}
@@ -0,0 +1,102 @@
+package emailverifier
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainSetMatches(t *testing.T) {
+	set := newDomainSet([]string{"Example.com", "*.partner.test", "münchen.de"})
+
+	cases := map[string]bool{
+		"example.com":           true,
+		"EXAMPLE.COM":           true,
+		"mail.example.com":      false,
+		"partner.test":          false,
+		"mx.partner.test":       true,
+		"a.b.partner.test":      true,
+		"münchen.de":            true,
+		"xn--mnchen-3ya.de":     true,
+		"notpartner.test":       false,
+		"example.com.other.org": false,
+	}
+	for domain, expected := range cases {
+		assert.Equal(t, expected, set.matches(domain), domain)
+	}
+
+	assert.False(t, domainSet(nil).matches("example.com"))
+}
+
+func TestVerify_DomainAllowlist(t *testing.T) {
+	v := NewVerifier().
+		EnableSMTPCheck().
+		AddDisposableDomains([]string{"trusted-disposable.test"}).
+		SetDomainAllowlist([]string{"trusted.test", "*.trusted-disposable.test", "trusted-disposable.test"}, reachableYes)
+
+	ret, err := v.Verify("user@trusted.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableYes, ret.Reachable)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true}, ret.SMTP)
+	assert.False(t, ret.SMTPChecked)
+	assert.True(t, ret.Skipped)
+	assert.Equal(t, SkipReasonAllowlisted, ret.SkipReason)
+	assert.False(t, ret.HasMxRecords)
+
+	ret, err = v.Verify("user@mx.trusted-disposable.test")
+	assert.NoError(t, err)
+	assert.False(t, ret.Disposable)
+	assert.Equal(t, SkipReasonAllowlisted, ret.SkipReason)
+}
+
+func TestVerify_DomainAllowlistReachableUnknown(t *testing.T) {
+	v := NewVerifier().SetDomainAllowlist([]string{"trusted.test"}, reachableUnknown)
+
+	ret, err := v.Verify("user@trusted.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.False(t, ret.SMTP.Deliverable)
+}
+
+func TestVerify_DomainBlocklist(t *testing.T) {
+	v := NewVerifier().EnableSMTPCheck().SetDomainBlocklist([]string{"*.complained.test"})
+
+	ret, err := v.Verify("Sales@mail.complained.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Nil(t, ret.SMTP)
+	assert.True(t, ret.Skipped)
+	assert.Equal(t, SkipReasonBlocklisted, ret.SkipReason)
+	assert.True(t, ret.RoleAccount)
+}
+
+func TestVerify_DomainBlocklistInvalidSyntax(t *testing.T) {
+	v := NewVerifier().SetDomainBlocklist([]string{"complained.test"})
+
+	ret, err := v.Verify("not an address@complained.test")
+	assert.NoError(t, err)
+	assert.False(t, ret.Syntax.Valid)
+	assert.False(t, ret.Skipped)
+}
+
+func TestSetDomainBlocklist_HotSwap(t *testing.T) {
+	v := NewVerifier().SetDomainBlocklist([]string{"a.test"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			v.SetDomainBlocklist([]string{"a.test", "b.test"})
+		}()
+		go func() {
+			defer wg.Done()
+			ret, err := v.Verify("user@a.test")
+			assert.NoError(t, err)
+			assert.True(t, ret.Skipped)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, v.blocklisted("b.test"))
+}
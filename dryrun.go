@@ -0,0 +1,103 @@
+package emailverifier
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// SMTPPlan is the smtp check the dry-run mode reports instead of performing it, see EnableSMTPDryRun
+type SMTPPlan struct {
+	Hosts               []string `json:"hosts"`                // servers the check would dial as host:port, the MX hosts all at once
+	CatchAllAddress     string   `json:"catch_all_address"`    // random address of the catch-all check
+	CatchAllCommands    []string `json:"catch_all_commands"`   // commands of the connection of the catch-all check
+	DeliverableCommands []string `json:"deliverable_commands"` // commands of the connection of the deliverability check, which is skipped for a catch-all domain, empty without a username
+}
+
+// EnableSMTPDryRun makes the smtp check resolve the servers of the domain and report the commands it would send
+// in SMTP.Plan, without connecting to any server. The reachability of the address is "unknown" then.
+// The providers with an API verifier are planned like any other domain, and the dry-run verifications aren't cached.
+func (v *Verifier) EnableSMTPDryRun() *Verifier {
+	return v.apply(WithSMTPDryRun())
+}
+
+// DisableSMTPDryRun makes the smtp check connect to the servers again, which is the default
+func (v *Verifier) DisableSMTPDryRun() *Verifier {
+	return v.apply(WithoutSMTPDryRun())
+}
+
+// planSMTP returns the smtp check of the address of username at domain with the plan of its connections,
+// or the failure to resolve the servers of domain
+func (v *Verifier) planSMTP(domain, username string) (*SMTP, error) {
+	domain = domainToASCII(domain)
+	ret := &SMTP{ProbeReliability: v.probeReliability(domain)}
+	hosts, err := v.smtpHosts(domain)
+	if err != nil {
+		ret.HostUnreachableReason = hostUnreachableReason(err)
+		return ret, ParseSMTPError(err)
+	}
+
+	ret.ImplicitTLS = v.implicitTLSConfig() != nil
+	ret.MXOverride = v.smtpOverride(domain) != nil
+	if relay := v.relayFor(domain); relay != nil {
+		ret.ImplicitTLS = relay.implicitTLS()
+		ret.STARTTLS = relay.useTLS && !ret.ImplicitTLS
+		ret.Relay = relay.host
+	}
+
+	plan := &SMTPPlan{Hosts: hosts, CatchAllAddress: GenerateRandomEmail(domain)}
+	plan.CatchAllCommands = v.planCommands(domain, plan.CatchAllAddress)
+	if username != "" {
+		plan.DeliverableCommands = v.planCommands(domain, fmt.Sprintf("%s@%s", quoteLocalPart(username), domain))
+	}
+	ret.Plan = plan
+	v.debug(domain, "smtp check planned", "hosts", hosts, "rcpt", plan.CatchAllAddress)
+	return ret, nil
+}
+
+// smtpHosts returns the servers the smtp check of domain dials as host:port: the smtp overrides, the relay
+// or the MX hosts, the failure to look up the MX hosts is returned as an unreachableError
+func (v *Verifier) smtpHosts(domain string) ([]string, error) {
+	if endpoints := v.smtpOverride(domain); endpoints != nil {
+		hosts := make([]string, len(endpoints))
+		for i, endpoint := range endpoints {
+			port := endpoint.port
+			if port == 0 {
+				port = v.port()
+			}
+			hosts[i] = net.JoinHostPort(endpoint.host, strconv.Itoa(port))
+		}
+		return hosts, nil
+	}
+	if relay := v.relayFor(domain); relay != nil {
+		return []string{net.JoinHostPort(relay.host, strconv.Itoa(relay.port))}, nil
+	}
+
+	mxHosts, err := v.mxHosts(domain)
+	if err != nil {
+		return nil, err
+	}
+	mxHosts = v.capMXHosts([][]string{mxHosts})[0]
+	// each MX host is dialed on the ports in order
+	hosts := make([]string, 0, len(mxHosts)*len(v.ports()))
+	for _, host := range mxHosts {
+		for _, port := range v.ports() {
+			hosts = append(hosts, net.JoinHostPort(host, strconv.Itoa(port)))
+		}
+	}
+	return hosts, nil
+}
+
+// planCommands returns the commands the connection of the smtp check of domain sends to check rcpt
+func (v *Verifier) planCommands(domain, rcpt string) []string {
+	commands := []string{"EHLO " + v.helloName}
+	if relay := v.relayFor(domain); relay != nil {
+		if relay.useTLS && !relay.implicitTLS() {
+			commands = append(commands, "STARTTLS", "EHLO "+v.helloName)
+		}
+		if relay.auth != nil {
+			commands = append(commands, "AUTH")
+		}
+	}
+	return append(commands, "MAIL FROM:<"+v.fromEmail+">", "RCPT TO:<"+rcpt+">")
+}
@@ -0,0 +1,96 @@
+package emailverifier
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestCheckSMTP_DryRun(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithSMTPDryRun(), WithFromEmail("verify@example.org"), WithHelloName("mail.example.org"))
+
+	ret, err := v.CheckSMTP("example.com", "user")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ret.Plan) {
+		random := ret.Plan.CatchAllAddress
+		assert.True(t, strings.HasSuffix(random, "@example.com"), random)
+		assert.Equal(t, &SMTP{
+			ProbeReliability: ProbeReliabilityUnknown,
+			Plan: &SMTPPlan{
+				Hosts:               []string{"mx.example.com.:25"},
+				CatchAllAddress:     random,
+				CatchAllCommands:    []string{"EHLO mail.example.org", "MAIL FROM:<verify@example.org>", "RCPT TO:<" + random + ">"},
+				DeliverableCommands: []string{"EHLO mail.example.org", "MAIL FROM:<verify@example.org>", "RCPT TO:<user@example.com>"},
+			},
+		}, ret)
+	}
+	assert.Equal(t, 0, srv.Connections())
+
+	ret, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Empty(t, ret.Plan.DeliverableCommands)
+}
+
+func TestCheckSMTP_DryRunServers(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithSMTPDryRun(),
+		WithDomainSMTPOverride("example.net", "mail.internal", 0), WithDomainSMTPOverride("example.net", "backup.internal", 2525),
+		WithSMTPRelay("relay.example.org", 587, smtp.PlainAuth("", "user", "password", "relay.example.org"), true))
+
+	// The relay is secured by STARTTLS and authenticated
+	ret, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "relay.example.org", ret.Relay)
+	assert.True(t, ret.STARTTLS)
+	assert.Equal(t, []string{"relay.example.org:587"}, ret.Plan.Hosts)
+	assert.Equal(t, []string{"EHLO " + defaultHelloName, "STARTTLS", "EHLO " + defaultHelloName, "AUTH"}, ret.Plan.CatchAllCommands[:4])
+
+	ret, err = v.CheckSMTP("example.net", "")
+	assert.NoError(t, err)
+	assert.True(t, ret.MXOverride)
+	assert.Equal(t, []string{"mail.internal:25", "backup.internal:2525"}, ret.Plan.Hosts)
+	assert.Equal(t, 0, srv.Connections())
+}
+
+func TestCheckSMTP_DryRunUnresolved(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithSMTPDryRun())
+
+	ret, err := v.CheckSMTP("example.org", "user")
+	assert.Error(t, err)
+	assert.Nil(t, ret.Plan)
+	assert.Equal(t, UnreachableNXDomain, ret.HostUnreachableReason)
+}
+
+func TestVerify_DryRun(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithSMTPDryRun(), WithCache(cache), WithResultCacheTTL(time.Hour))
+
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.True(t, ret.SMTPChecked)
+	assert.NotNil(t, ret.SMTP.Plan)
+
+	results := v.VerifyBatch([]string{"user@example.com", "other@example.com"}, BatchOptions{GroupByDomain: true})
+	for _, result := range results {
+		if assert.NoError(t, result.Err) {
+			assert.Equal(t, reachableUnknown, result.Result.Reachable)
+			assert.NotNil(t, result.Result.SMTP.Plan)
+		}
+	}
+	assert.Equal(t, 0, srv.Connections())
+
+	// The dry runs aren't cached, so the real check runs afterwards
+	v.DisableSMTPDryRun()
+	ret, err = v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, ret.SMTP.Plan)
+	assert.Equal(t, 1, srv.Connections())
+}
@@ -1,6 +1,8 @@
 package emailverifier
 
 import (
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,6 +14,7 @@ const (
 	ErrNoSuchHost        = "Mail server does not exist"
 	ErrServerUnavailable = "Mail server is unavailable"
 	ErrBlocked           = "Blocked by mail server"
+	ErrTLSCertificate    = "TLS certificate verification failed"
 
 	// RCPT Errors
 	ErrTryAgainLater           = "Try again later"
@@ -40,9 +43,24 @@ func (e *LookupError) Error() string {
 	return fmt.Sprintf("%s : %s", e.Message, e.Details)
 }
 
+// SyntaxError is an email address syntax error
+type SyntaxError struct {
+	Email   string   `json:"email" xml:"email"`
+	Reasons []string `json:"reasons" xml:"reasons"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid email address syntax %q : %s", e.Email, strings.Join(e.Reasons, ", "))
+}
+
 // ParseSMTPError receives an MX Servers response message
 // and generates the corresponding MX error
 func ParseSMTPError(err error) *LookupError {
+	// An error which was parsed already keeps its message
+	var lookupErr *LookupError
+	if errors.As(err, &lookupErr) {
+		return lookupErr
+	}
 	errStr := err.Error()
 
 	// Verify the length of the error before reading nil indexes
@@ -128,6 +146,8 @@ func parseBasicErr(err error) *LookupError {
 
 	// Return a more understandable error
 	switch {
+	case isCertificateError(err):
+		return newLookupError(ErrTLSCertificate, errStr)
 	case insContains(errStr,
 		"spamhaus",
 		"proofpoint",
@@ -147,6 +167,14 @@ func parseBasicErr(err error) *LookupError {
 	}
 }
 
+// isCertificateError checks if err is the failure to verify the certificate of a TLS server
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
+
 // insContains returns true if any of the substrings
 // are found in the passed string. This method of checking
 // contains is case insensitive
@@ -1,7 +1,9 @@
 package emailverifier
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -174,3 +176,15 @@ func TestParseError_basicErr_blocked(t *testing.T) {
 	assert.Equal(t, ErrBlocked, le.Message)
 	assert.Equal(t, err.Error(), le.Details)
 }
+
+func TestParseError_basicErr_certificate(t *testing.T) {
+	err := fmt.Errorf("tls: failed to verify certificate: %w", x509.UnknownAuthorityError{})
+	le := ParseSMTPError(err)
+	assert.Equal(t, ErrTLSCertificate, le.Message)
+	assert.Equal(t, err.Error(), le.Details)
+}
+
+func TestParseError_LookupError(t *testing.T) {
+	err := newLookupError(ErrTLSCertificate, "x509: certificate signed by unknown authority")
+	assert.Equal(t, err, ParseSMTPError(err))
+}
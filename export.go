@@ -0,0 +1,229 @@
+package emailverifier
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResultColumns are the names of the columns of a flattened Result, in the order of the CSVWriter columns.
+// Nested fields are prefixed by their section, like "smtp.deliverable". The names are stable,
+// new columns are only appended.
+var ResultColumns = []string{
+	"email",
+	"canonical_email",
+	"name",
+	"reachable",
+	"syntax.valid",
+	"syntax.username",
+	"syntax.domain",
+	"syntax.domain_ascii",
+	"syntax.domain_unicode",
+	"syntax.has_sub_address",
+	"syntax.tag",
+	"syntax.reasons",
+	"has_mx_records",
+	"smtp.host_exists",
+	"smtp.full_inbox",
+	"smtp.catch_all",
+	"smtp.deliverable",
+	"smtp.disabled",
+	"smtp.smtputf8_unsupported",
+	"gravatar.has_gravatar",
+	"gravatar.hash",
+	"gravatar.avatar_url",
+	"avatar.provider",
+	"avatar.has_avatar",
+	"avatar.url",
+	"suggestion",
+	"disposable",
+	"disposable_reason",
+	"role_account",
+	"free",
+	"skipped",
+	"skip_reason",
+	"timings.total_ms",
+	"smtp.implicit_tls",
+	"smtp.blocked",
+	"smtp.reject_reason",
+	"smtp.error",
+	"smtp_checked",
+	"gravatar_checked",
+	"domain_auth.mta_sts.published",
+	"domain_auth.mta_sts.mode",
+	"domain_auth.mta_sts.misconfigured",
+	"smtp.relay",
+	"smtp.mx_override",
+	"smtp.host_unreachable_reason",
+	"timed_out",
+	"timed_out_stages",
+	"smtp.connection_retries",
+	"smtp.probe_reliability",
+	"smtp.plan.hosts",
+	"smtp.plan.catch_all_address",
+	"smtp.failed_stage",
+	"smtp.reply_code",
+	"smtp.starttls",
+	"quality",
+	"score",
+	"domain_auth.spf.published",
+	"domain_auth.spf.policy",
+	"domain_auth.spf.misconfigured",
+	"domain_auth.dkim.selectors",
+	"domain_auth.dmarc.published",
+	"domain_auth.dmarc.policy",
+	"domain_auth.dmarc.misconfigured",
+}
+
+// Flatten returns the fields of the result by the names of ResultColumns, for spreadsheets and BI tools.
+// Booleans are "true" or "false", lists are joined by ";" and durations are whole milliseconds.
+// The cells of a section which is nil, like smtp without the smtp check, are empty,
+// and every cell of a nil result is empty.
+func (r *Result) Flatten() map[string]string {
+	flat := make(map[string]string, len(ResultColumns))
+	for _, column := range ResultColumns {
+		flat[column] = ""
+	}
+	if r == nil {
+		return flat
+	}
+
+	flat["email"] = r.Email
+	flat["canonical_email"] = r.CanonicalEmail
+	flat["name"] = r.Name
+	flat["reachable"] = r.Reachable
+	flat["quality"] = r.Quality
+	flat["score"] = strconv.Itoa(r.Score)
+	flat["syntax.valid"] = strconv.FormatBool(r.Syntax.Valid)
+	flat["syntax.username"] = r.Syntax.Username
+	flat["syntax.domain"] = r.Syntax.Domain
+	flat["syntax.domain_ascii"] = r.Syntax.DomainASCII
+	flat["syntax.domain_unicode"] = r.Syntax.DomainUnicode
+	flat["syntax.has_sub_address"] = strconv.FormatBool(r.Syntax.HasSubAddress)
+	flat["syntax.tag"] = r.Syntax.Tag
+	flat["syntax.reasons"] = strings.Join(r.Syntax.Reasons, ";")
+	flat["has_mx_records"] = strconv.FormatBool(r.HasMxRecords)
+	flat["timed_out"] = strconv.FormatBool(r.TimedOut)
+	flat["timed_out_stages"] = strings.Join(r.TimedOutStages, ";")
+	if r.SMTP != nil {
+		flat["smtp.host_exists"] = strconv.FormatBool(r.SMTP.HostExists)
+		flat["smtp.full_inbox"] = strconv.FormatBool(r.SMTP.FullInbox)
+		flat["smtp.catch_all"] = strconv.FormatBool(r.SMTP.CatchAll)
+		flat["smtp.deliverable"] = strconv.FormatBool(r.SMTP.Deliverable)
+		flat["smtp.disabled"] = strconv.FormatBool(r.SMTP.Disabled)
+		flat["smtp.smtputf8_unsupported"] = strconv.FormatBool(r.SMTP.SMTPUTF8Unsupported)
+		flat["smtp.implicit_tls"] = strconv.FormatBool(r.SMTP.ImplicitTLS)
+		flat["smtp.blocked"] = strconv.FormatBool(r.SMTP.Blocked)
+		flat["smtp.reject_reason"] = r.SMTP.RejectReason
+		flat["smtp.relay"] = r.SMTP.Relay
+		flat["smtp.mx_override"] = strconv.FormatBool(r.SMTP.MXOverride)
+		flat["smtp.host_unreachable_reason"] = r.SMTP.HostUnreachableReason
+		flat["smtp.connection_retries"] = strconv.Itoa(r.SMTP.ConnectionRetries)
+		flat["smtp.probe_reliability"] = r.SMTP.ProbeReliability
+		flat["smtp.failed_stage"] = r.SMTP.FailedStage
+		flat["smtp.reply_code"] = strconv.Itoa(r.SMTP.ReplyCode)
+		flat["smtp.starttls"] = strconv.FormatBool(r.SMTP.STARTTLS)
+		if r.SMTP.Plan != nil {
+			flat["smtp.plan.hosts"] = strings.Join(r.SMTP.Plan.Hosts, ";")
+			flat["smtp.plan.catch_all_address"] = r.SMTP.Plan.CatchAllAddress
+		}
+		if r.SMTP.Error != nil {
+			flat["smtp.error"] = r.SMTP.Error.Message
+		}
+	}
+	if r.Gravatar != nil {
+		flat["gravatar.has_gravatar"] = strconv.FormatBool(r.Gravatar.HasGravatar)
+		flat["gravatar.hash"] = r.Gravatar.Hash
+		flat["gravatar.avatar_url"] = r.Gravatar.AvatarUrl
+	}
+	if r.Avatar != nil {
+		flat["avatar.provider"] = r.Avatar.Provider
+		flat["avatar.has_avatar"] = strconv.FormatBool(r.Avatar.HasAvatar)
+		flat["avatar.url"] = r.Avatar.Url
+	}
+	if r.DomainAuth != nil && r.DomainAuth.MTASTS != nil {
+		flat["domain_auth.mta_sts.published"] = strconv.FormatBool(r.DomainAuth.MTASTS.Published)
+		flat["domain_auth.mta_sts.mode"] = r.DomainAuth.MTASTS.Mode
+		flat["domain_auth.mta_sts.misconfigured"] = strconv.FormatBool(r.DomainAuth.MTASTS.Misconfigured)
+	}
+	if r.DomainAuth != nil && r.DomainAuth.SPF != nil {
+		flat["domain_auth.spf.published"] = strconv.FormatBool(r.DomainAuth.SPF.Published)
+		flat["domain_auth.spf.policy"] = r.DomainAuth.SPF.Policy
+		flat["domain_auth.spf.misconfigured"] = strconv.FormatBool(r.DomainAuth.SPF.Misconfigured)
+	}
+	if r.DomainAuth != nil && r.DomainAuth.DKIM != nil {
+		flat["domain_auth.dkim.selectors"] = strings.Join(r.DomainAuth.DKIM.Selectors, ";")
+	}
+	if r.DomainAuth != nil && r.DomainAuth.DMARC != nil {
+		flat["domain_auth.dmarc.published"] = strconv.FormatBool(r.DomainAuth.DMARC.Published)
+		flat["domain_auth.dmarc.policy"] = r.DomainAuth.DMARC.Policy
+		flat["domain_auth.dmarc.misconfigured"] = strconv.FormatBool(r.DomainAuth.DMARC.Misconfigured)
+	}
+	flat["suggestion"] = r.Suggestion
+	flat["disposable"] = strconv.FormatBool(r.Disposable)
+	flat["disposable_reason"] = r.DisposableReason
+	flat["role_account"] = strconv.FormatBool(r.RoleAccount)
+	flat["free"] = strconv.FormatBool(r.Free)
+	flat["skipped"] = strconv.FormatBool(r.Skipped)
+	flat["skip_reason"] = r.SkipReason
+	flat["smtp_checked"] = strconv.FormatBool(r.SMTPChecked)
+	flat["gravatar_checked"] = strconv.FormatBool(r.GravatarChecked)
+	flat["timings.total_ms"] = strconv.FormatInt(int64(r.Timings.Total/time.Millisecond), 10)
+	return flat
+}
+
+// CSVWriter writes results as CSV: a header row of ResultColumns and the extra columns,
+// followed by a row per result
+type CSVWriter struct {
+	w             *csv.Writer
+	extraColumns  []string
+	headerWritten bool
+}
+
+// NewCSVWriter returns a writer of results to w, whose rows end with the extra columns,
+// e.g. the error of a verification which failed
+func NewCSVWriter(w io.Writer, extraColumns ...string) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), extraColumns: extraColumns}
+}
+
+// Write writes the row of the result r, nil for a row of empty cells, with the values of the extra columns.
+// The header is written before the first row.
+func (w *CSVWriter) Write(r *Result, extra ...string) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	flat := r.Flatten()
+	row := make([]string, 0, len(ResultColumns)+len(w.extraColumns))
+	for _, column := range ResultColumns {
+		row = append(row, flat[column])
+	}
+	for i := range w.extraColumns {
+		value := ""
+		if i < len(extra) {
+			value = extra[i]
+		}
+		row = append(row, value)
+	}
+	return w.w.Write(row)
+}
+
+// Flush writes the buffered rows to the underlying writer, and the header if no row was written
+func (w *CSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// writeHeader writes the header unless it was written already
+func (w *CSVWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return w.w.Write(append(append([]string(nil), ResultColumns...), w.extraColumns...))
+}
@@ -0,0 +1,86 @@
+package emailverifier
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult_Flatten(t *testing.T) {
+	r := &Result{
+		Email:     "User+tag@Example.com",
+		Reachable: reachableYes,
+		Syntax: Syntax{
+			Username:      "User+tag",
+			Domain:        "example.com",
+			Valid:         true,
+			HasSubAddress: true,
+			Tag:           "tag",
+		},
+		HasMxRecords: true,
+		SMTP:         &SMTP{HostExists: true, Deliverable: true, ImplicitTLS: true},
+		DomainAuth:   &DomainAuth{MTASTS: &MTASTS{Published: true, Mode: MTASTSModeTesting}, SPF: &SPF{Published: true, Policy: SPFPolicyFail}},
+		Free:         true,
+		Timings:      Timings{Total: 1500 * time.Millisecond},
+	}
+
+	flat := r.Flatten()
+	assert.Len(t, flat, len(ResultColumns))
+	assert.Equal(t, "User+tag@Example.com", flat["email"])
+	assert.Equal(t, "yes", flat["reachable"])
+	assert.Equal(t, "true", flat["syntax.valid"])
+	assert.Equal(t, "tag", flat["syntax.tag"])
+	assert.Equal(t, "true", flat["smtp.deliverable"])
+	assert.Equal(t, "false", flat["smtp.catch_all"])
+	assert.Equal(t, "true", flat["smtp.implicit_tls"])
+	assert.Equal(t, "testing", flat["domain_auth.mta_sts.mode"])
+	assert.Equal(t, "fail", flat["domain_auth.spf.policy"])
+	assert.Equal(t, "false", flat["disposable"])
+	assert.Equal(t, "1500", flat["timings.total_ms"])
+
+	// the sections which are nil have empty cells
+	assert.Equal(t, "", flat["gravatar.has_gravatar"])
+	assert.Equal(t, "", flat["avatar.provider"])
+}
+
+func TestResult_Flatten_Nil(t *testing.T) {
+	var r *Result
+	flat := r.Flatten()
+	assert.Len(t, flat, len(ResultColumns))
+	for column, value := range flat {
+		assert.Empty(t, value, column)
+	}
+
+	flat = (&Result{Email: "invalid", Syntax: Syntax{Reasons: []string{"missing_at", "too_short"}}}).Flatten()
+	assert.Equal(t, "false", flat["syntax.valid"])
+	assert.Equal(t, "missing_at;too_short", flat["syntax.reasons"])
+	assert.Equal(t, "", flat["smtp.host_exists"])
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, "error")
+	assert.NoError(t, w.Write(&Result{Email: "a@example.com", Reachable: reachableYes, Syntax: Syntax{Valid: true}}))
+	assert.NoError(t, w.Write(nil, "timeout"))
+	assert.NoError(t, w.Flush())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, append(append([]string(nil), ResultColumns...), "error"), records[0])
+	assert.Equal(t, "a@example.com", records[1][0])
+	assert.Equal(t, "yes", records[1][3])
+	assert.Equal(t, "", records[1][len(ResultColumns)])
+	assert.Equal(t, "", records[2][0])
+	assert.Equal(t, "timeout", records[2][len(ResultColumns)])
+}
+
+func TestCSVWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewCSVWriter(&buf).Flush())
+	assert.Equal(t, "email,canonical_email,", buf.String()[:len("email,canonical_email,")])
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,105 @@
+package emailverifier
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	freeMu          sync.Mutex   // serializes the writers of the free domains
+	freeSyncDomains atomic.Value // current free domains as map[string]bool, never modified once stored
+)
+
+// init loads free_domain meta data to freeSyncDomains which are safe for concurrent use
+func init() {
+	domains := make(map[string]bool, len(freeDomains))
+	for d := range freeDomains {
+		domains[strings.ToLower(d)] = true
+	}
+	freeSyncDomains.Store(domains)
+}
+
+// IsFreeDomain checks if domain is a free email provider domain,
+// using the free domains shared by all verifiers
+func IsFreeDomain(domain string) bool {
+	return defaultVerifier.IsFreeDomain(domain)
+}
+
+// AddFreeDomains adds domains as free email provider domains
+func (v *Verifier) AddFreeDomains(domains []string) *Verifier {
+	updateFreeDomains(func(current map[string]bool) {
+		for _, d := range domains {
+			current[strings.ToLower(d)] = true
+		}
+	}, false)
+	return v
+}
+
+// RemoveFreeDomains removes domains from the free email provider domains
+func (v *Verifier) RemoveFreeDomains(domains []string) *Verifier {
+	updateFreeDomains(func(current map[string]bool) {
+		for _, d := range domains {
+			for _, variant := range domainVariants(strings.ToLower(d)) {
+				delete(current, variant)
+			}
+		}
+	}, false)
+	return v
+}
+
+// LoadFreeDomains replaces the free email provider domains with the list read from r,
+// the list has the same format as a DisposableDomainSource.
+// If r can't be parsed the current domains are left untouched and the error is returned.
+func (v *Verifier) LoadFreeDomains(r io.Reader) error {
+	return loadFreeDomains(r, true)
+}
+
+// MergeFreeDomains adds the list of free email provider domains read from r to the current ones.
+// If r can't be parsed the current domains are left untouched and the error is returned.
+func (v *Verifier) MergeFreeDomains(r io.Reader) error {
+	return loadFreeDomains(r, false)
+}
+
+// loadFreeDomains parses the list of free domains read from r and stores it,
+// an empty list leaves the current domains untouched
+func loadFreeDomains(r io.Reader, replace bool) error {
+	domains, err := parseDomainList(r)
+	if err != nil {
+		return err
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+
+	updateFreeDomains(func(current map[string]bool) {
+		for _, d := range domains {
+			current[d] = true
+		}
+	}, replace)
+	return nil
+}
+
+// updateFreeDomains atomically replaces the current free domains with a copy modified by update,
+// the copy starts empty if replace
+func updateFreeDomains(update func(map[string]bool), replace bool) {
+	freeMu.Lock()
+	defer freeMu.Unlock()
+
+	next := make(map[string]bool)
+	if !replace {
+		for d := range currentFreeDomains() {
+			next[d] = true
+		}
+	}
+	update(next)
+
+	freeSyncDomains.Store(next)
+	freeListUpdate.touch()
+}
+
+// currentFreeDomains returns the current free domains
+func currentFreeDomains() map[string]bool {
+	return freeSyncDomains.Load().(map[string]bool)
+}
@@ -0,0 +1,109 @@
+package emailverifier
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreFreeDomains restores the free domains current at the time of calling
+func restoreFreeDomains(t *testing.T) {
+	current := currentFreeDomains()
+	t.Cleanup(func() { freeSyncDomains.Store(current) })
+}
+
+func TestIsFreeDomain_Package(t *testing.T) {
+	assert.True(t, IsFreeDomain("yahoo.com"))
+	assert.True(t, IsFreeDomain("YAHOO.COM"))
+	assert.False(t, IsFreeDomain("github.com"))
+}
+
+func TestIsFreeDomain_ConsumerProviders(t *testing.T) {
+	v := NewVerifier()
+	for _, domain := range []string{"gmail.com", "googlemail.com", "yahoo.com", "outlook.com", "hotmail.com", "icloud.com",
+		"aol.com", "protonmail.com", "gmx.de", "yandex.ru", "mail.ru"} {
+		assert.True(t, v.IsFreeDomain(domain), domain)
+		assert.True(t, v.IsFreeDomain("someone@"+domain), domain)
+	}
+	assert.False(t, v.IsFreeDomain("someone@github.com"))
+}
+
+func TestAddFreeDomains(t *testing.T) {
+	restoreFreeDomains(t)
+
+	v := NewVerifier().AddFreeDomains([]string{"Regional-Free.test", "bücher-mail.test"})
+	assert.True(t, v.IsFreeDomain("regional-free.test"))
+	assert.True(t, v.IsFreeDomain("REGIONAL-FREE.TEST"))
+	assert.True(t, IsFreeDomain("regional-free.test"))
+	assert.True(t, v.IsFreeDomain("bücher-mail.test"))
+	assert.True(t, v.IsFreeDomain(domainToASCII("bücher-mail.test")))
+}
+
+func TestRemoveFreeDomains(t *testing.T) {
+	restoreFreeDomains(t)
+
+	v := NewVerifier().RemoveFreeDomains([]string{"Yahoo.com"})
+	assert.False(t, v.IsFreeDomain("yahoo.com"))
+	assert.True(t, v.IsFreeDomain("gmail.com"))
+}
+
+func TestLoadFreeDomains(t *testing.T) {
+	restoreFreeDomains(t)
+
+	err := verifier.LoadFreeDomains(strings.NewReader("# regional providers\nweb-de.test\nmail-ru.test\n"))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsFreeDomain("web-de.test"))
+	assert.True(t, verifier.IsFreeDomain("mail-ru.test"))
+	assert.False(t, verifier.IsFreeDomain("yahoo.com"))
+}
+
+func TestMergeFreeDomains(t *testing.T) {
+	restoreFreeDomains(t)
+
+	err := verifier.MergeFreeDomains(strings.NewReader(`["naver-com.test", "qq-com.test"]`))
+	assert.NoError(t, err)
+	assert.True(t, verifier.IsFreeDomain("naver-com.test"))
+	assert.True(t, verifier.IsFreeDomain("yahoo.com"))
+}
+
+func TestLoadFreeDomains_ParseErrorKeepsPreviousList(t *testing.T) {
+	restoreFreeDomains(t)
+
+	err := verifier.LoadFreeDomains(strings.NewReader("valid-free.test\nnot a domain\n"))
+	assert.Error(t, err)
+	assert.True(t, verifier.IsFreeDomain("yahoo.com"))
+	assert.False(t, verifier.IsFreeDomain("valid-free.test"))
+}
+
+func TestFreeDomains_SuggestionCandidates(t *testing.T) {
+	restoreFreeDomains(t)
+
+	v := NewVerifier().AddFreeDomains([]string{"regionalmail.test"})
+	assert.Equal(t, "regionalmail.test", v.SuggestDomain("regionalmial.test"))
+}
+
+func TestFreeDomains_Concurrent(t *testing.T) {
+	restoreFreeDomains(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			verifier.AddFreeDomains([]string{"concurrent-free.test"})
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, verifier.MergeFreeDomains(strings.NewReader("merged-free.test")))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.True(t, verifier.IsFreeDomain("gmail.com"))
+		}()
+	}
+	wg.Wait()
+	assert.True(t, verifier.IsFreeDomain("concurrent-free.test"))
+	assert.True(t, verifier.IsFreeDomain("merged-free.test"))
+}
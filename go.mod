@@ -9,6 +9,7 @@ require (
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/stretchr/testify v1.7.1
 	golang.org/x/net v0.0.0-20201207224615-747e23833adb
+	golang.org/x/sync v0.2.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/h2non/gock.v1 v1.1.2
 	h12.io/socks v1.0.3
@@ -2,57 +2,78 @@ package emailverifier
 
 import (
 	"context"
-	"io/ioutil"
 	"net/http"
 	"strings"
-	"time"
 )
 
 // Gravatar is detail about the Gravatar
 type Gravatar struct {
 	HasGravatar bool   // whether has gravatar
 	GravatarUrl string // gravatar url
+	Hash        string // md5 hash of the normalized email, which identifies the avatar
+	AvatarUrl   string // canonical avatar url, empty if no gravatar
+}
+
+// SetGravatarHTTPClient sets the HTTP client of the gravatar check, so its request honors custom transports,
+// proxies and timeouts. A nil client restores http.DefaultClient with a timeout of 10 seconds.
+func (v *Verifier) SetGravatarHTTPClient(client *http.Client) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.gravatarClient = client
+	return v
 }
 
 // CheckGravatar will return the Gravatar records for the given email.
+// An email without avatar yields a Gravatar with HasGravatar false, while
+// a failed request or an unexpected response status yields an error.
 func (v *Verifier) CheckGravatar(email string) (*Gravatar, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	return v.CheckGravatarWithContext(context.Background(), email)
+}
+
+// CheckGravatarWithContext performs CheckGravatar until ctx is done, which interrupts the pending request
+func (v *Verifier) CheckGravatarWithContext(ctx context.Context, email string) (*Gravatar, error) {
+	v = v.snapshot()
+	return checkGravatar(ctx, v.gravatarClient, email)
+}
+
+// checkGravatar returns the Gravatar records of email requested with client
+func checkGravatar(ctx context.Context, client *http.Client, email string) (*Gravatar, error) {
 	err, emailMd5 := getMD5Hash(strings.ToLower(strings.TrimSpace(email)))
 	if err != nil {
 		return nil, err
 	}
 	gravatarUrl := gravatarBaseUrl + emailMd5 + "?d=404"
-	req, err := http.NewRequest("GET", gravatarUrl, nil)
+	body, found, err := getAvatar(ctx, client, gravatarUrl)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return nil, err
+	if !found {
+		return &Gravatar{Hash: emailMd5}, nil
 	}
 
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
 	// check body
 	err, md5Body := getMD5Hash(string(body))
 	if err != nil {
 		return nil, err
 	}
-	if md5Body == gravatarDefaultMd5 || resp.StatusCode != 200 {
-		return &Gravatar{
-			HasGravatar: false,
-			GravatarUrl: "",
-		}, nil
+	if md5Body == gravatarDefaultMd5 {
+		return &Gravatar{Hash: emailMd5}, nil
 	}
 	return &Gravatar{
 		HasGravatar: true,
 		GravatarUrl: gravatarUrl,
+		Hash:        emailMd5,
+		AvatarUrl:   gravatarBaseUrl + emailMd5,
 	}, nil
 }
+
+// avatar converts g to an Avatar
+func (g *Gravatar) avatar() *Avatar {
+	return &Avatar{
+		Provider:  AvatarProviderGravatar,
+		HasAvatar: g.HasGravatar,
+		Hash:      g.Hash,
+		Url:       g.AvatarUrl,
+	}
+}
@@ -1,9 +1,13 @@
 package emailverifier
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
 )
 
 func TestCheckGravatarOK(t *testing.T) {
@@ -23,3 +27,90 @@ func TestCheckGravatarFailed(t *testing.T) {
 	assert.False(t, gravatar.HasGravatar)
 	assert.Empty(t, gravatar.GravatarUrl)
 }
+
+func TestCheckGravatar_NotFound(t *testing.T) {
+	defer gock.Off()
+	_, hash := getMD5Hash("nobody@example.com")
+	gock.New("https://www.gravatar.com").
+		Get("/avatar/" + hash).
+		Reply(404)
+
+	gravatar, err := NewVerifier().CheckGravatar(" Nobody@Example.com ")
+	assert.NoError(t, err)
+	assert.False(t, gravatar.HasGravatar)
+	assert.Equal(t, hash, gravatar.Hash)
+	assert.Empty(t, gravatar.AvatarUrl)
+}
+
+func TestCheckGravatar_Found(t *testing.T) {
+	defer gock.Off()
+	_, hash := getMD5Hash("somebody@example.com")
+	gock.New("https://www.gravatar.com").
+		Get("/avatar/" + hash).
+		Reply(200).
+		BodyString("avatar")
+
+	gravatar, err := NewVerifier().CheckGravatar("somebody@example.com")
+	assert.NoError(t, err)
+	assert.True(t, gravatar.HasGravatar)
+	assert.Equal(t, hash, gravatar.Hash)
+	assert.Equal(t, "https://www.gravatar.com/avatar/"+hash, gravatar.AvatarUrl)
+}
+
+func TestCheckGravatarWithContext_Canceled(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://www.gravatar.com").
+		Reply(200).
+		BodyString("avatar")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	gravatar, err := NewVerifier().CheckGravatarWithContext(ctx, "somebody@example.com")
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Nil(t, gravatar)
+}
+
+func TestCheckGravatar_UnexpectedStatus(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://www.gravatar.com").
+		Reply(503)
+
+	gravatar, err := NewVerifier().CheckGravatar("somebody@example.com")
+	assert.Error(t, err)
+	assert.Nil(t, gravatar)
+}
+
+func TestCheckGravatar_HTTPClient(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://www.gravatar.com").
+		Reply(200).
+		BodyString("avatar")
+
+	client := &http.Client{Transport: &http.Transport{}}
+	gock.InterceptClient(client)
+	defer gock.RestoreClient(client)
+	v := NewVerifier().SetGravatarHTTPClient(client)
+
+	gravatar, err := v.CheckGravatar("somebody@example.com")
+	assert.NoError(t, err)
+	assert.True(t, gravatar.HasGravatar)
+	assert.True(t, gock.IsDone())
+}
+
+func TestCheckGravatar_NetworkError(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	v := NewVerifier().SetGravatarHTTPClient(client)
+
+	gravatar, err := v.CheckGravatar("somebody@example.com")
+	assert.Error(t, err)
+	assert.Nil(t, gravatar)
+}
+
+// roundTripFunc is an http.RoundTripper calling itself
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,114 @@
+package emailverifier
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lenientGreeting configures the tolerant reading of the banners of the mail servers, see EnableLenientGreeting
+type lenientGreeting struct {
+	timeout time.Duration // time the banner may take once connected, the connect timeout if zero
+}
+
+// bannerTimeout returns the time the banner may take once connected to a mail server, with the connect timeout
+func (g *lenientGreeting) bannerTimeout(connectTimeout time.Duration) time.Duration {
+	if g.timeout > 0 {
+		return g.timeout
+	}
+	return connectTimeout
+}
+
+// EnableLenientGreeting tolerates the banners of the mail servers which net/smtp rejects: the lines which aren't
+// replies are discarded until the final 220 line, the 220- continuation lines may mix with other text, and a server
+// which sends 220- lines without a final one is accepted once bannerTimeout elapses. A 4xx banner is a temporary
+// failure, reported with the "temp_fail" host unreachable reason. bannerTimeout is the time the banner may take
+// once connected, on top of the connect timeout, which it defaults to if zero.
+// The connections over implicit TLS read the banner strictly.
+func (v *Verifier) EnableLenientGreeting(bannerTimeout time.Duration) *Verifier {
+	return v.apply(WithLenientGreeting(bannerTimeout))
+}
+
+// DisableLenientGreeting reads the banners strictly like net/smtp, which is the default
+func (v *Verifier) DisableLenientGreeting() *Verifier {
+	return v.apply(WithoutLenientGreeting())
+}
+
+// greetedConn is a connection whose banner was read by readLenientBanner,
+// its reads start with the final line of the banner
+type greetedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *greetedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// greet reads the banner of the server of conn leniently until bannerDeadline, and returns the connection
+// to create the client with, whose reads start with the final line of the banner and time out at commandDeadline,
+// none if zero
+func greet(conn net.Conn, bannerDeadline, commandDeadline time.Time) (net.Conn, error) {
+	_ = conn.SetReadDeadline(bannerDeadline)
+	r := bufio.NewReader(conn)
+	line, err := readLenientBanner(r)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetReadDeadline(commandDeadline)
+	return &greetedConn{Conn: conn, r: io.MultiReader(strings.NewReader(line+"\r\n"), r)}, nil
+}
+
+// readLenientBanner reads the banner of a server from r up to its final 220 line, which it returns, discarding
+// the lines which aren't replies and the continuation lines. A server sending 220- lines without a final one
+// is greeted by the last of them once the reads time out. A 4xx reply is an unreachableError of UnreachableTempFail.
+func readLenientBanner(r *bufio.Reader) (string, error) {
+	var greeting string // last 220- continuation line, made final
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			var netErr net.Error
+			if greeting != "" && errors.As(err, &netErr) && netErr.Timeout() {
+				return greeting, nil
+			}
+			return "", err
+		}
+
+		code, continued, text := parseReplyLine(strings.TrimRight(line, "\r\n"))
+		switch {
+		case code == 0:
+			// not a reply, e.g. a notice printed before the banner
+		case continued:
+			if code == 220 {
+				greeting = "220 " + text
+			}
+		case code == 220:
+			return "220 " + text, nil
+		case code >= 400 && code < 500:
+			return "", unreachable(UnreachableTempFail, &textproto.Error{Code: code, Msg: text})
+		default:
+			return "", &textproto.Error{Code: code, Msg: text}
+		}
+	}
+}
+
+// parseReplyLine parses a line of an SMTP reply into its code, whether it's continued on the next line and its text.
+// The code of a line which isn't a reply is zero.
+func parseReplyLine(line string) (int, bool, string) {
+	if len(line) < 3 || len(line) > 3 && line[3] != ' ' && line[3] != '-' {
+		return 0, false, ""
+	}
+	code, err := strconv.Atoi(line[:3])
+	if err != nil || code < 100 || code > 599 {
+		return 0, false, ""
+	}
+	if len(line) == 3 {
+		return code, false, ""
+	}
+	return code, line[3] == '-', line[4:]
+}
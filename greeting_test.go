@@ -0,0 +1,140 @@
+package emailverifier
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestReadLenientBanner(t *testing.T) {
+	cases := []struct {
+		name       string
+		transcript string
+		greeting   string
+	}{
+		{"standard", "220 mx.example.com ESMTP\r\n", "220 mx.example.com ESMTP"},
+		{"multi-line", "220-mx.example.com ESMTP\r\n220-No UCE\r\n220 Ready\r\n", "220 Ready"},
+		{"notice before the banner", "Welcome to the mail gateway\r\n\r\n220 gateway.example.com ESMTP\r\n", "220 gateway.example.com ESMTP"},
+		{"text between continuations", "220-gateway.example.com\r\n  Authorized use only\r\n220 gateway.example.com ready\r\n", "220 gateway.example.com ready"},
+		{"masked banner", "220 ****************************************\r\n", "220 ****************************************"},
+		{"bare line feeds", "220-mx.example.com\n220 ESMTP\n", "220 ESMTP"},
+		{"bare code", "220\r\n", "220 "},
+		{"other continuation", "250-unexpected\r\n220 mx.example.com\r\n", "220 mx.example.com"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			greeting, err := readLenientBanner(bufio.NewReader(strings.NewReader(c.transcript)))
+			assert.NoError(t, err)
+			assert.Equal(t, c.greeting, greeting)
+		})
+	}
+}
+
+func TestReadLenientBanner_Rejected(t *testing.T) {
+	_, err := readLenientBanner(bufio.NewReader(strings.NewReader("Please wait\r\n421-Too many connections\r\n421 Try again later\r\n")))
+	assert.Equal(t, UnreachableTempFail, hostUnreachableReason(err))
+	var reply *textproto.Error
+	if assert.True(t, errors.As(err, &reply)) {
+		assert.Equal(t, 421, reply.Code)
+		assert.Equal(t, "Try again later", reply.Msg)
+	}
+
+	_, err = readLenientBanner(bufio.NewReader(strings.NewReader("554 5.7.1 No SMTP service here\r\n")))
+	assert.Empty(t, hostUnreachableReason(err))
+	assert.Equal(t, &textproto.Error{Code: 554, Msg: "5.7.1 No SMTP service here"}, err)
+
+	_, err = readLenientBanner(bufio.NewReader(strings.NewReader("Welcome\r\n")))
+	assert.Error(t, err)
+}
+
+func TestParseReplyLine(t *testing.T) {
+	cases := []struct {
+		line      string
+		code      int
+		continued bool
+		text      string
+	}{
+		{"220 ready", 220, false, "ready"},
+		{"220-ready", 220, true, "ready"},
+		{"220", 220, false, ""},
+		{"220ready", 0, false, ""},
+		{"abc ready", 0, false, ""},
+		{"999 ready", 0, false, ""},
+		{"", 0, false, ""},
+	}
+	for _, c := range cases {
+		code, continued, text := parseReplyLine(c.line)
+		assert.Equal(t, c.code, code, c.line)
+		assert.Equal(t, c.continued, continued, c.line)
+		assert.Equal(t, c.text, text, c.line)
+	}
+}
+
+func TestCheckSMTP_LenientGreeting(t *testing.T) {
+	behavior := smtptest.Behavior{Banner: "Welcome to the mail gateway\r\n220 gateway.example.com ESMTP"}
+	// every check connects, instead of reusing the cached catch-all check
+	v, _ := newSMTPTestVerifier(t, behavior, []string{"example.com"}, WithCatchAllCacheTTL(0))
+
+	_, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+
+	v.EnableLenientGreeting(0)
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+
+	v.DisableLenientGreeting()
+	_, err = v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+}
+
+func TestCheckSMTP_LenientGreetingUnterminated(t *testing.T) {
+	// the server waits for the client after its continuation lines
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{Banner: "220-gateway.example.com ESMTP\r\n220-No UCE"}, []string{"example.com"},
+		WithLenientGreeting(50*time.Millisecond))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+}
+
+func TestCheckSMTP_LenientGreetingDelayed(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{BannerDelay: 100 * time.Millisecond}, []string{"example.com"},
+		WithTimeout(50*time.Millisecond), WithLenientGreeting(time.Second))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+}
+
+func TestCheckSMTP_LenientGreetingTempFail(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{Banner: "421 4.3.2 Service not available, try again later"}, []string{"example.com"},
+		WithLenientGreeting(0))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, ErrTryAgainLater, ParseSMTPError(err).Message)
+	assert.Equal(t, &SMTP{HostUnreachableReason: UnreachableTempFail, ProbeReliability: ProbeReliabilityUnknown, FailedStage: SMTPStageBanner, ReplyCode: 421}, smtp)
+}
+
+func TestGreet_Deadline(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	go func() {
+		_, _ = server.Write([]byte("notice\r\n220 ready\r\n"))
+	}()
+
+	conn, err := greet(client, time.Now().Add(time.Second), time.Time{})
+	assert.NoError(t, err)
+	line, err := textproto.NewReader(bufio.NewReader(conn)).ReadLine()
+	assert.NoError(t, err)
+	assert.Equal(t, "220 ready", line)
+}
@@ -0,0 +1,54 @@
+package emailverifier
+
+import (
+	"net/smtp"
+	"time"
+)
+
+// SetGreylistRetry sets how many times a RCPT greylisted by the mail server, with a 450 or 451 reply,
+// is sent again over the same connection, after delay doubled on each retry. Zero attempts disables
+// the retries (default), which reports the greylisted RCPT as the ambiguous result it is.
+// The delay should stay below the idle timeout of the mail servers, a few minutes at most.
+func (v *Verifier) SetGreylistRetry(attempts int, delay time.Duration) *Verifier {
+	return v.apply(WithGreylistRetry(attempts, delay))
+}
+
+// isGreylisted reports whether a RCPT failed with err because the mail server greylisted it,
+// asking to try again later
+func isGreylisted(err error) bool {
+	code, _ := replyCode(err)
+	if code != 450 && code != 451 {
+		return false
+	}
+	reason, _ := classifyRejection(err)
+	return reason != RejectMailboxFull
+}
+
+// rcpt sends the RCPT of email over client, a connection to a mail server of domain.
+// A greylisted RCPT is sent again in a new transaction up to the greylist retry attempts,
+// unless the server drops the connection or the verification times out.
+func (v *Verifier) rcpt(client *smtp.Client, domain, email string) error {
+	if v.smtpPool != nil {
+		v.smtpPool.probe(client)
+	}
+	err := client.Rcpt(email)
+	delay := v.greylistRetryDelay
+	for retry := 1; retry <= v.greylistRetries && isGreylisted(err); retry++ {
+		v.debug(domain, "rcpt greylisted, retrying", "rcpt", email, "retry", retry, "delay", delay, "reply", replyText(err))
+		select {
+		case <-time.After(delay):
+		case <-v.context().Done():
+			return err
+		}
+		if resetErr := v.resetClient(client); resetErr != nil {
+			v.debug(domain, "greylist retry failed", "error", resetErr)
+			return err
+		}
+		if v.smtpPool != nil {
+			v.smtpPool.probe(client)
+		}
+		err = client.Rcpt(email)
+		delay *= 2
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package emailverifier
+
+import (
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestCheckSMTP_GreylistRetry(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{
+		Greylist:    true,
+		Rcpt:        map[string]string{"username@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 User unknown",
+	}, []string{"example.com"}, WithGreylistRetry(1, time.Millisecond))
+
+	// Both the random and the checked recipients pass the greylisting on their retry
+	smtp, err := v.CheckSMTP("example.com", "username")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true, ProbeReliability: ProbeReliabilityUnknown}, smtp)
+}
+
+func TestCheckSMTP_GreylistRetryDisabled(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{
+		Greylist:    true,
+		Rcpt:        map[string]string{"username@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 User unknown",
+	}, []string{"example.com"}, WithGreylistRetry(1, time.Millisecond))
+	v.SetGreylistRetry(0, 0)
+
+	smtp, err := v.CheckSMTP("example.com", "username")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, CatchAll: true, ProbeReliability: ProbeReliabilityUnknown, FailedStage: SMTPStageRcpt, ReplyCode: 451}, smtp)
+}
+
+func TestCheckSMTP_GreylistRetryTimeout(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{Greylist: true}, []string{"example.com"},
+		WithGreylistRetry(1, time.Minute), WithVerifyTimeout(50*time.Millisecond))
+
+	// The verification times out while waiting for the retry of the catch-all check
+	start := time.Now()
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, ret.TimedOut)
+}
+
+func TestIsGreylisted(t *testing.T) {
+	tests := []struct {
+		code int
+		msg  string
+		want bool
+	}{
+		{451, "4.7.1 Greylisted, try again later", true},
+		{450, "4.2.0 Recipient address rejected: Greylisted", true},
+		{450, "4.2.2 Mailbox full", false},
+		{452, "4.5.3 Too many recipients", false},
+		{550, "5.1.1 User unknown", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.want, isGreylisted(&textproto.Error{Code: tt.code, Msg: tt.msg}))
+		})
+	}
+}
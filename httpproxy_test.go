@@ -0,0 +1,110 @@
+package emailverifier
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// connectProxy is an HTTP proxy connecting the CONNECT requests to srv, or answering them with status
+// unless it's zero. It records the requests.
+type connectProxy struct {
+	srv    *smtptest.Server
+	status int
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+// start starts p and returns its URI with the user info userinfo, none if empty
+func (p *connectProxy) start(t *testing.T, userinfo string) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go p.serve(conn)
+		}
+	}()
+	if userinfo != "" {
+		userinfo += "@"
+	}
+	return "http://" + userinfo + ln.Addr().String()
+}
+
+func (p *connectProxy) serve(conn net.Conn) {
+	defer conn.Close()
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return
+	}
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	if p.status != 0 {
+		fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\n\r\n", p.status, http.StatusText(p.status))
+		return
+	}
+	target, err := p.srv.Dial(context.Background(), "tcp", req.Host)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+	// the banner of the server may follow the reply in the same read of the client
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func (p *connectProxy) received() []*http.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*http.Request(nil), p.requests...)
+}
+
+func TestCheckSMTP_HTTPProxy(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	p := &connectProxy{srv: srv}
+	v.Proxy(p.start(t, "user:secret"))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+
+	requests := p.received()
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, http.MethodConnect, requests[0].Method)
+		assert.Equal(t, "mx.example.com.:25", requests[0].Host)
+		// user:secret
+		assert.Equal(t, "Basic dXNlcjpzZWNyZXQ=", requests[0].Header.Get("Proxy-Authorization"))
+	}
+}
+
+func TestCheckSMTP_HTTPProxyRefused(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	p := &connectProxy{srv: srv, status: http.StatusProxyAuthRequired}
+	v.Proxy(p.start(t, ""))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, UnreachableProxyError, smtp.HostUnreachableReason)
+	assert.Equal(t, 0, srv.Connections())
+}
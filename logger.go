@@ -0,0 +1,74 @@
+package emailverifier
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Logger receives the debug events of a Verifier, each as a message followed by alternating attribute keys and values,
+// e.g. a *slog.Logger
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// SetLogger sets the logger receiving the debug events of the verifier, like the MX records found,
+// the dial attempts and the SMTP replies. Nothing is logged without a logger.
+func (v *Verifier) SetLogger(l Logger) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.logger = l
+	return v
+}
+
+// debug passes an event about domain to the logger, the proxy credentials are redacted from error values
+func (v *Verifier) debug(domain, msg string, args ...interface{}) {
+	if v.logger == nil {
+		return
+	}
+
+	attrs := make([]interface{}, 0, len(args)+2)
+	attrs = append(attrs, "domain", domain)
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			arg = v.redactProxy(err.Error())
+		}
+		attrs = append(attrs, arg)
+	}
+	v.logger.Debug(msg, attrs...)
+}
+
+// redactProxy replaces the password of the proxy URI within s
+func (v *Verifier) redactProxy(s string) string {
+	if v.proxies == nil {
+		return s
+	}
+	for _, proxyURI := range v.proxies.uris() {
+		u, err := url.Parse(proxyURI)
+		if err != nil || u.User == nil {
+			continue
+		}
+		if password, ok := u.User.Password(); ok && password != "" {
+			s = strings.Replace(s, password, "xxxxx", -1)
+		}
+	}
+	return s
+}
+
+// replyText describes the reply of an SMTP command, err is the error the command returned
+func replyText(err error) string {
+	if err == nil {
+		return "250 OK"
+	}
+	return err.Error()
+}
+
+// mxHosts returns the hosts of records
+func mxHosts(records []*net.MX) []string {
+	hosts := make([]string, len(records))
+	for i, r := range records {
+		hosts[i] = r.Host
+	}
+	return hosts
+}
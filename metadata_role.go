@@ -82,6 +82,8 @@ var roleAccounts = map[string]bool {
 	"analysts": true,
 	"analytics": true,
 	"android": true,
+	"anfrage": true,
+	"anfragen": true,
 	"angels": true,
 	"animation": true,
 	"announce": true,
@@ -104,6 +106,7 @@ var roleAccounts = map[string]bool {
 	"associates": true,
 	"associates-all": true,
 	"ateam": true,
+	"atencion-al-cliente": true,
 	"atencionalcliente": true,
 	"atendimento": true,
 	"auctions": true,
@@ -116,6 +119,7 @@ var roleAccounts = map[string]bool {
 	"berlin": true,
 	"bestellung": true,
 	"beta": true,
+	"bewerbung": true,
 	"biblioteca": true,
 	"bibliotheque": true,
 	"billing": true,
@@ -126,10 +130,12 @@ var roleAccounts = map[string]bool {
 	"blog": true,
 	"board": true,
 	"bod": true,
+	"boekhouding": true,
 	"bookclub": true,
 	"booking": true,
 	"bookings": true,
 	"boston": true,
+	"bounce*": true,
 	"boxoffice": true,
 	"brand": true,
 	"branding": true,
@@ -191,6 +197,7 @@ var roleAccounts = map[string]bool {
 	"comercial": true,
 	"comercial1": true,
 	"comercial2": true,
+	"commandes": true,
 	"comments": true,
 	"commercial": true,
 	"commerciale": true,
@@ -225,10 +232,12 @@ var roleAccounts = map[string]bool {
 	"contabilita": true,
 	"contact": true,
 	"contactenos": true,
+	"contactez-nous": true,
 	"contacto": true,
 	"contactus": true,
 	"contador": true,
 	"contato": true,
+	"contatto": true,
 	"content": true,
 	"contractor": true,
 	"contractors": true,
@@ -265,6 +274,7 @@ var roleAccounts = map[string]bool {
 	"daemon": true,
 	"data": true,
 	"database": true,
+	"datenschutz": true,
 	"deals": true,
 	"dean": true,
 	"delivery": true,
@@ -298,12 +308,14 @@ var roleAccounts = map[string]bool {
 	"dispatch": true,
 	"diversity": true,
 	"dns": true,
+	"do-not-reply*": true,
 	"docs": true,
 	"domain": true,
 	"domainmanagement": true,
 	"domains": true,
 	"donations": true,
 	"donors": true,
+	"donotreply*": true,
 	"download": true,
 	"dreamteam": true,
 	"ecommerce": true,
@@ -314,6 +326,7 @@ var roleAccounts = map[string]bool {
 	"einkauf": true,
 	"email": true,
 	"emergency": true,
+	"empleo": true,
 	"employee": true,
 	"employees": true,
 	"employment": true,
@@ -351,9 +364,11 @@ var roleAccounts = map[string]bool {
 	"export": true,
 	"facilities": true,
 	"facturacion": true,
+	"facturation": true,
 	"faculty": true,
 	"family": true,
 	"farmacia": true,
+	"fatturazione": true,
 	"faturamento": true,
 	"fax": true,
 	"fbl": true,
@@ -418,6 +433,7 @@ var roleAccounts = map[string]bool {
 	"ideas": true,
 	"implementation": true,
 	"import": true,
+	"impressum": true,
 	"inbound": true,
 	"inbox": true,
 	"india": true,
@@ -470,7 +486,11 @@ var roleAccounts = map[string]bool {
 	"join": true,
 	"jornalismo": true,
 	"junk": true,
+	"karriere": true,
+	"klantenservice": true,
 	"kontakt": true,
+	"kundendienst": true,
+	"kundenservice": true,
 	"kundeservice": true,
 	"la": true,
 	"lab": true,
@@ -548,17 +568,20 @@ var roleAccounts = map[string]bool {
 	"newyork": true,
 	"nntp": true,
 	"no-reply": true,
+	"no-reply*": true,
 	"no.replay": true,
 	"no.reply": true,
 	"nobody": true,
 	"noc": true,
 	"none": true,
 	"noreply": true,
+	"noreply*": true,
 	"noresponse": true,
 	"northamerica": true,
 	"nospam": true,
 	"notes": true,
 	"notifications": true,
+	"notifications*": true,
 	"notify": true,
 	"nps": true,
 	"null": true,
@@ -575,6 +598,7 @@ var roleAccounts = map[string]bool {
 	"offtopic": true,
 	"oficina": true,
 	"onboarding": true,
+	"ondersteuning": true,
 	"online": true,
 	"onsite": true,
 	"ooo": true,
@@ -599,6 +623,7 @@ var roleAccounts = map[string]bool {
 	"paypal": true,
 	"payroll": true,
 	"pd": true,
+	"pedidos": true,
 	"people": true,
 	"peoplemanagers": true,
 	"peopleops": true,
@@ -618,6 +643,7 @@ var roleAccounts = map[string]bool {
 	"ppc": true,
 	"pr": true,
 	"prefeitura": true,
+	"prensa": true,
 	"presales": true,
 	"presidencia": true,
 	"president": true,
@@ -667,6 +693,8 @@ var roleAccounts = map[string]bool {
 	"recepcion": true,
 	"reception": true,
 	"receptionist": true,
+	"rechnung": true,
+	"rechnungen": true,
 	"recruit": true,
 	"recruiter": true,
 	"recruiters": true,
@@ -698,6 +726,7 @@ var roleAccounts = map[string]bool {
 	"reservas": true,
 	"reservation": true,
 	"reservations": true,
+	"reservierung": true,
 	"residents": true,
 	"response": true,
 	"restaurant": true,
@@ -741,6 +770,7 @@ var roleAccounts = map[string]bool {
 	"seo": true,
 	"server": true,
 	"service": true,
+	"service-client": true,
 	"serviceclient": true,
 	"servicedesk": true,
 	"services": true,
@@ -774,6 +804,7 @@ var roleAccounts = map[string]bool {
 	"sport": true,
 	"squad": true,
 	"staff": true,
+	"stampa": true,
 	"startups": true,
 	"stats": true,
 	"stockholm": true,
@@ -796,6 +827,7 @@ var roleAccounts = map[string]bool {
 	"supply": true,
 	"support": true,
 	"support-team": true,
+	"supporto": true,
 	"supportteam": true,
 	"suprimentos": true,
 	"sydney": true,
@@ -856,12 +888,16 @@ var roleAccounts = map[string]bool {
 	"vendas": true,
 	"vendas1": true,
 	"vendas2": true,
+	"vendite": true,
 	"vendor": true,
 	"vendors": true,
 	"ventas": true,
 	"ventas1": true,
 	"ventas2": true,
+	"ventes": true,
 	"verkauf": true,
+	"verkoop": true,
+	"vertrieb": true,
 	"verwaltung": true,
 	"video": true,
 	"vip": true,
@@ -2,27 +2,106 @@ package emailverifier
 
 import (
 	"strings"
-	"sync"
 )
 
-var (
-	disposableSyncDomains sync.Map // concurrent safe map to store disposable domains data
-)
-
-// IsRoleAccount checks if username is a role-based account
+// IsRoleAccount checks if username is a role-based account,
+// a sub-address tag is ignored so "sales+q3" is a role-based account like "sales".
+// An address like "info@example.com" is checked by its local part.
 func (v *Verifier) IsRoleAccount(username string) bool {
-	return roleAccounts[strings.ToLower(username)]
+	v = v.snapshot()
+	if index := strings.LastIndex(username, "@"); index >= 0 {
+		return v.isRoleAccount(username[:index], v.canonicalDomain(username[index+1:]))
+	}
+	return v.isRoleAccount(username, "")
 }
 
-// IsFreeDomain checks if domain is a free domain
+// IsFreeDomain checks if domain, its registrable domain, or the canonical domain it's an alias of, is a free domain.
+// An address is checked by its domain.
 func (v *Verifier) IsFreeDomain(domain string) bool {
-	return freeDomains[domain]
+	v = v.snapshot()
+	domain = v.canonicalDomain(addressDomain(domain))
+	domains := currentFreeDomains()
+	for _, d := range v.matchedDomains(domain) {
+		if domains[strings.ToLower(d)] {
+			return true
+		}
+	}
+	return false
 }
 
-// IsDisposable checks if domain is a disposable domain
+// IsDisposable checks if domain, its registrable domain, or the canonical domain it's an alias of, is a disposable domain.
+// An address is checked by its domain, so IsDisposable("user@mailinator.com") is true.
 func (v *Verifier) IsDisposable(domain string) bool {
-	domain = domainToASCII(domain)
-	d := parsedDomain(domain)
-	_, found := disposableSyncDomains.Load(d)
-	return found
+	v = v.snapshot()
+	domain = v.canonicalDomain(addressDomain(domain))
+	for _, d := range v.matchedDomains(domain) {
+		if isDisposableDomain(strings.ToLower(d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressDomain returns the domain of s if it's an address, s otherwise
+func addressDomain(s string) string {
+	if index := strings.LastIndex(s, "@"); index >= 0 {
+		return s[index+1:]
+	}
+	return s
+}
+
+// domainVariants returns the distinct forms of domain to match against domain lists:
+// as passed, its ASCII (punycode) and its Unicode form
+func domainVariants(domain string) []string {
+	variants := []string{domain}
+	asciiDomain, unicodeDomain, ok := idnForms(strings.ToLower(domain))
+	if !ok {
+		return append(variants, domainToASCII(domain))
+	}
+	for _, d := range []string{asciiDomain, unicodeDomain} {
+		if d != domain {
+			variants = append(variants, d)
+		}
+	}
+	return variants
+}
+
+// matchedDomains returns the forms of domain to match against the domain lists and providers: its variants,
+// followed by the registrable domains of its variants unless the exact domain matching is enabled
+func (v *Verifier) matchedDomains(domain string) []string {
+	variants := domainVariants(domain)
+	if v.exactDomainMatching {
+		return variants
+	}
+	domains := variants
+	for _, d := range variants {
+		registrable, err := RegistrableDomain(d)
+		if err != nil || containsDomain(domains, registrable) {
+			continue
+		}
+		domains = append(domains, registrable)
+	}
+	return domains
+}
+
+// containsDomain reports whether domains contains domain, case insensitive
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnableExactDomainMatching matches the free and disposable domain lists and the mailbox providers against
+// the domain of an address only. By default the registrable domain (eTLD+1) of the domain is matched too,
+// so mail.example.co.uk matches an entry for example.co.uk.
+func (v *Verifier) EnableExactDomainMatching() *Verifier {
+	return v.apply(WithExactDomainMatching())
+}
+
+// DisableExactDomainMatching matches the registrable domain of a domain too, which is the default
+func (v *Verifier) DisableExactDomainMatching() *Verifier {
+	return v.apply(WithoutExactDomainMatching())
 }
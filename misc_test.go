@@ -36,6 +36,12 @@ func TestIsDisposableDomain_False(t *testing.T) {
 	assert.False(t, isDisposable)
 }
 
+func TestIsDisposable_Address(t *testing.T) {
+	assert.True(t, verifier.IsDisposable("user@DBBD8.club"))
+	assert.False(t, verifier.IsDisposable("user@gmail.com"))
+	assert.True(t, verifier.IsFreeDomain("user@gmail.com"))
+}
+
 func TestIsRoleAccount_True(t *testing.T) {
 	username := "administrator"
 
@@ -49,3 +55,42 @@ func TestIsRoleAccount_False(t *testing.T) {
 	isRoleAccount := verifier.IsRoleAccount(username)
 	assert.False(t, isRoleAccount)
 }
+
+func TestDomainVariants(t *testing.T) {
+	assert.Equal(t, []string{"example.com"}, domainVariants("example.com"))
+	assert.Equal(t, []string{"münchen.de", "xn--mnchen-3ya.de"}, domainVariants("münchen.de"))
+	assert.Equal(t, []string{"xn--mnchen-3ya.de", "münchen.de"}, domainVariants("xn--mnchen-3ya.de"))
+}
+
+func TestIsDisposableDomain_UnicodeForm(t *testing.T) {
+	v := NewVerifier().AddDisposableDomains([]string{"wegwerf-münchen.de"})
+	assert.True(t, v.IsDisposable("wegwerf-münchen.de"))
+	assert.True(t, v.IsDisposable(domainToASCII("wegwerf-münchen.de")))
+}
+
+func TestExactDomainMatching(t *testing.T) {
+	restoreDisposableDomains(t)
+	v := NewVerifier().AddDisposableDomains([]string{"throwaway-test.co.uk", "wegwerf-münchen.de"})
+
+	assert.True(t, v.IsFreeDomain("mail.yahoo.com"))
+	assert.True(t, v.IsDisposable("mail.throwaway-test.co.uk"))
+	assert.True(t, v.IsDisposable("mail.wegwerf-münchen.de"))
+	assert.True(t, v.IsDisposable("mail."+domainToASCII("wegwerf-münchen.de")))
+	assert.False(t, v.IsFreeDomain("co.uk"))
+
+	v.EnableExactDomainMatching()
+	assert.True(t, v.IsFreeDomain("yahoo.com"))
+	assert.False(t, v.IsFreeDomain("mail.yahoo.com"))
+	assert.True(t, v.IsDisposable("throwaway-test.co.uk"))
+	assert.False(t, v.IsDisposable("mail.throwaway-test.co.uk"))
+
+	v.DisableExactDomainMatching()
+	assert.True(t, v.IsFreeDomain("mail.yahoo.com"))
+}
+
+func TestMatchedDomains(t *testing.T) {
+	assert.Equal(t, []string{"example.com"}, verifier.matchedDomains("example.com"))
+	assert.Equal(t, []string{"localhost"}, verifier.matchedDomains("localhost"))
+	assert.Equal(t, []string{"mail.münchen.de", "mail.xn--mnchen-3ya.de", "münchen.de", "xn--mnchen-3ya.de"},
+		verifier.matchedDomains("mail.münchen.de"))
+}
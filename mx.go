@@ -1,6 +1,10 @@
 package emailverifier
 
-import "net"
+import (
+	"context"
+	"net"
+	"strings"
+)
 
 // Mx is detail about the Mx host
 type Mx struct {
@@ -10,13 +14,49 @@ type Mx struct {
 
 // CheckMX will return the DNS MX records for the given domain name sorted by preference.
 func (v *Verifier) CheckMX(domain string) (*Mx, error) {
+	v = v.snapshot()
 	domain = domainToASCII(domain)
-	mx, err := net.LookupMX(domain)
+	mx, err := v.lookupMX(domain)
 	if err != nil {
+		v.debug(domain, "mx lookup failed", "error", err)
 		return nil, ParseSMTPError(err)
 	}
+	v.debug(domain, "mx records found", "hosts", mxHosts(mx))
 	return &Mx{
 		HasMXRecord: len(mx) > 0,
 		Records:     mx,
 	}, nil
 }
+
+// CheckMXWithContext performs CheckMX until ctx is done, which interrupts the pending DNS lookup
+// and returns the error of ctx
+func (v *Verifier) CheckMXWithContext(ctx context.Context, domain string) (*Mx, error) {
+	mx, err := v.withContext(ctx).CheckMX(domain)
+	if err != nil && contextExpired(ctx) {
+		return nil, contextError(ctx)
+	}
+	return mx, err
+}
+
+// lookupMX returns the MX records of the ASCII domain. They are cached until the lowest TTL of the records,
+// at most mxCacheTTL, or for mxFallbackCacheTTL when the TTLs can't be read, in the cache of the verifier
+// or its own without one.
+func (v *Verifier) lookupMX(domain string) ([]*net.MX, error) {
+	key := strings.ToLower(domain)
+	var mx []*net.MX
+	if v.cacheGet(CacheKindMX, key, &mx) {
+		return mx, nil
+	}
+	var ttls ttlRecorder
+	mx, err := ttls.resolver(v.lookupResolver()).LookupMX(v.context(), domain)
+	if err != nil {
+		return nil, err
+	}
+	v.cacheSet(CacheKindMX, key, mx, ttls.cacheTTL())
+	return mx, nil
+}
+
+// isNullMX checks if records is a null MX record, which states that the domain accepts no email (RFC 7505)
+func isNullMX(records []*net.MX) bool {
+	return len(records) == 1 && (records[0].Host == "." || records[0].Host == "")
+}
@@ -1,9 +1,15 @@
 package emailverifier
 
 import (
+	"context"
+	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
 )
 
 func TestCheckMxOK(t *testing.T) {
@@ -21,3 +27,54 @@ func TestCheckNoMxOK(t *testing.T) {
 	assert.Nil(t, mx)
 	assert.Error(t, err, ErrNoSuchHost)
 }
+
+func TestIsNullMX(t *testing.T) {
+	assert.True(t, isNullMX([]*net.MX{{Host: ".", Pref: 0}}))
+	assert.False(t, isNullMX([]*net.MX{{Host: "mx.example.com.", Pref: 10}}))
+	assert.False(t, isNullMX([]*net.MX{{Host: ".", Pref: 0}, {Host: "mx.example.com.", Pref: 10}}))
+	assert.False(t, isNullMX(nil))
+}
+
+func TestCheckMX_CacheHonorsTTL(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	var dials int32
+	resolver := srv.Resolver()
+	dial := resolver.Dial
+	resolver.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return dial(ctx, network, address)
+	}
+	v, err := NewVerifierWithOptions(WithResolver(resolver))
+	assert.NoError(t, err)
+	now := time.Now()
+	v.domainCache.now = func() time.Time { return now }
+
+	// the records are cached without a cache set
+	for i := 0; i < 2; i++ {
+		mx, err := v.CheckMX("example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, []*net.MX{{Host: "mx.example.com.", Pref: 10}}, mx.Records)
+	}
+	lookups := atomic.LoadInt32(&dials)
+	assert.True(t, lookups > 0)
+	assert.Equal(t, CacheStats{Entries: 1, Hits: 1, Misses: 1, HitRatio: 0.5}, v.Stats().Caches[CacheKindMX])
+
+	// the records of the test server expire after 60 seconds, sooner than mxCacheTTL
+	now = now.Add(59 * time.Second)
+	_, err = v.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, lookups, atomic.LoadInt32(&dials))
+	now = now.Add(2 * time.Second)
+	_, err = v.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, atomic.LoadInt32(&dials) > lookups)
+
+	// the failed lookups aren't cached
+	_, err = v.CheckMX("example.org")
+	assert.Error(t, err)
+	lookups = atomic.LoadInt32(&dials)
+	_, err = v.CheckMX("example.org")
+	assert.Error(t, err)
+	assert.True(t, atomic.LoadInt32(&dials) > lookups)
+}
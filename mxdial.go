@@ -0,0 +1,67 @@
+package emailverifier
+
+import (
+	"net/smtp"
+)
+
+// EnableSequentialMXDialing makes the smtp check dial the MX hosts of a domain one at a time in preference order,
+// moving on to the next host only once the previous one failed, instead of dialing them all at once.
+// It's disabled by default, see also SetMaxMXAttempts.
+func (v *Verifier) EnableSequentialMXDialing() *Verifier {
+	return v.apply(WithSequentialMXDialing())
+}
+
+// DisableSequentialMXDialing makes the smtp check dial the MX hosts of a domain at once, which is the default
+func (v *Verifier) DisableSequentialMXDialing() *Verifier {
+	return v.apply(WithoutSequentialMXDialing())
+}
+
+// SetMaxMXAttempts sets how many MX hosts of a domain, in preference order, the smtp check dials at most,
+// zero dials every host (default)
+func (v *Verifier) SetMaxMXAttempts(max int) *Verifier {
+	return v.apply(WithMaxMXAttempts(max))
+}
+
+// capMXHosts returns the groups of MX hosts, dialed in order, without the hosts beyond the max MX attempts
+func (v *Verifier) capMXHosts(groups [][]string) [][]string {
+	if v.maxMXAttempts <= 0 {
+		return groups
+	}
+	left := v.maxMXAttempts
+	capped := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		if left == 0 {
+			break
+		}
+		if len(group) > left {
+			group = group[:left]
+		}
+		capped = append(capped, group)
+		left -= len(group)
+	}
+	return capped
+}
+
+// dialMXGroup connects to one of the MX hosts of domain in group, dialed at once or, with the sequential MX dialing,
+// one at a time in order. It returns the client and its host with the reconnections to the hosts dialed until then,
+// or the errors of every host dialed.
+func (v *Verifier) dialMXGroup(domain string, group []string) (*smtp.Client, string, int, []error) {
+	if !v.sequentialMX {
+		return v.dialMXHosts(domain, group)
+	}
+
+	var errs []error
+	var retries int
+	for _, host := range group {
+		client, host, n, hostErrs := v.dialMXHosts(domain, []string{host})
+		retries += n
+		if client != nil {
+			return client, host, retries, nil
+		}
+		errs = append(errs, hostErrs...)
+		if v.context().Err() != nil {
+			break
+		}
+	}
+	return nil, "", retries, errs
+}
@@ -0,0 +1,158 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// refusingDialer dials srv except the refused hosts, whose connections are refused.
+// It records the dialed hosts.
+type refusingDialer struct {
+	srv     *smtptest.Server
+	refused []string
+
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (d *refusingDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, _ := net.SplitHostPort(addr)
+	d.mu.Lock()
+	d.hosts = append(d.hosts, host)
+	d.mu.Unlock()
+	for _, refused := range d.refused {
+		if host == refused {
+			return nil, errors.New("connection refused")
+		}
+	}
+	return d.srv.Dial(ctx, network, addr)
+}
+
+func (d *refusingDialer) dialed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.hosts...)
+}
+
+// newMXDialTestVerifier returns a verifier of example.com, whose MX hosts are mx.example.com and mx2.example.com,
+// dialing with d
+func newMXDialTestVerifier(t *testing.T, d *refusingDialer, opts ...Option) *Verifier {
+	d.srv = smtptest.NewServer(smtptest.Behavior{BackupMX: []string{"example.com"}}, "example.com")
+	t.Cleanup(d.srv.Close)
+
+	opts = append([]Option{WithSMTPCheck(), WithDialer(d.dial), WithResolver(d.srv.Resolver())}, opts...)
+	v, err := NewVerifierWithOptions(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestCheckSMTP_SequentialMXDialing(t *testing.T) {
+	d := &refusingDialer{}
+	v := newMXDialTestVerifier(t, d, WithSequentialMXDialing())
+
+	// The backup MX host isn't dialed while the primary one answers
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	assert.Equal(t, []string{"mx.example.com."}, d.dialed())
+}
+
+func TestCheckSMTP_SequentialMXDialingFailover(t *testing.T) {
+	d := &refusingDialer{refused: []string{"mx.example.com."}}
+	v := newMXDialTestVerifier(t, d, WithSequentialMXDialing())
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	assert.Equal(t, []string{"mx.example.com.", "mx2.example.com."}, d.dialed())
+}
+
+func TestCheckSMTP_MaxMXAttempts(t *testing.T) {
+	d := &refusingDialer{refused: []string{"mx.example.com."}}
+	v := newMXDialTestVerifier(t, d, WithMaxMXAttempts(1))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.False(t, smtp.HostExists)
+	assert.Equal(t, []string{"mx.example.com."}, d.dialed())
+
+	// Without the cap the backup MX host answers
+	v.SetMaxMXAttempts(0)
+	smtp, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+}
+
+func TestCapMXHosts(t *testing.T) {
+	groups := [][]string{{"a", "b"}, {"c", "d"}}
+	tests := []struct {
+		max  int
+		want [][]string
+	}{
+		{0, groups},
+		{1, [][]string{{"a"}}},
+		{2, [][]string{{"a", "b"}}},
+		{3, [][]string{{"a", "b"}, {"c"}}},
+		{5, groups},
+	}
+	for _, tt := range tests {
+		v := &Verifier{config: config{maxMXAttempts: tt.max}}
+		assert.Equal(t, tt.want, v.capMXHosts(groups), "max %d", tt.max)
+	}
+}
+
+func TestCheckSMTP_CancelsLosingDials(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{BackupMX: []string{"example.com"}}, "example.com")
+	defer srv.Close()
+	// the primary MX host never answers, until its dial is canceled
+	canceled := make(chan struct{})
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, _, _ := net.SplitHostPort(addr); host == "mx.example.com." {
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		}
+		return srv.Dial(ctx, network, addr)
+	}
+	v, err := NewVerifierWithOptions(WithSMTPCheck(), WithDialer(dial), WithResolver(srv.Resolver()),
+		WithSMTPDialTimeout(time.Minute))
+	assert.NoError(t, err)
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the dial of the primary MX host wasn't canceled")
+	}
+}
+
+func TestCloseOnDone(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := closeOnDone(ctx, client)
+	cancel()
+
+	// the pending read fails once ctx is done
+	_, err := client.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.True(t, interrupted())
+
+	client, server = net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	interrupted = closeOnDone(context.Background(), client)
+	assert.False(t, interrupted())
+}
@@ -0,0 +1,143 @@
+package emailverifier
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostStats is the health of an MX host recorded by the adaptive MX selection, see Verifier.HostStats.
+// The connections count less as they age, halving every 5 minutes, so a failing host recovers.
+type HostStats struct {
+	Host        string        // MX host, lowercase without the trailing dot
+	Successes   float64       // decayed count of the successful connections to the host
+	Failures    float64       // decayed count of the connections which timed out or were refused
+	Latency     time.Duration // moving average of the duration of the successful connections
+	LastFailure time.Time     // time of the last failed connection, zero if none
+	Unhealthy   bool          // whether the host is dialed only once the healthy hosts of a domain failed
+}
+
+// mxHealth records the health of the MX hosts dialed by the verifiers sharing it
+type mxHealth struct {
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+	seen  map[string]time.Time // time the stats of each host were last decayed
+	now   func() time.Time
+}
+
+func newMXHealth() *mxHealth {
+	return &mxHealth{hosts: make(map[string]*HostStats), seen: make(map[string]time.Time), now: time.Now}
+}
+
+// hostKey returns the form of an MX host the stats are keyed by
+func hostKey(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// decay returns the stats of host decayed until now, the caller holds h.mu
+func (h *mxHealth) decay(host string, now time.Time) *HostStats {
+	stats, ok := h.hosts[host]
+	if !ok {
+		stats = &HostStats{Host: host}
+		h.hosts[host] = stats
+	} else if elapsed := now.Sub(h.seen[host]); elapsed > 0 {
+		factor := math.Pow(0.5, float64(elapsed)/float64(mxHealthHalfLife))
+		stats.Successes *= factor
+		stats.Failures *= factor
+	}
+	h.seen[host] = now
+	stats.Unhealthy = isUnhealthy(stats)
+	return stats
+}
+
+// record records a connection to host which took latency and failed with err, unless it's nil
+func (h *mxHealth) record(host string, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	stats := h.decay(hostKey(host), now)
+	if err != nil {
+		stats.Failures++
+		stats.LastFailure = now
+	} else {
+		stats.Successes++
+		if stats.Latency == 0 {
+			stats.Latency = latency
+		} else {
+			stats.Latency += time.Duration(mxLatencyWeight * float64(latency-stats.Latency))
+		}
+	}
+	stats.Unhealthy = isUnhealthy(stats)
+}
+
+// isUnhealthy reports whether the host of stats fails often enough to be dialed last
+func isUnhealthy(stats *HostStats) bool {
+	return stats.Failures >= mxUnhealthyFailures && stats.Failures > stats.Successes
+}
+
+// partition splits hosts into the healthy and the unhealthy ones, in their order
+func (h *mxHealth) partition(hosts []string) (healthy, unhealthy []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	for _, host := range hosts {
+		key := hostKey(host)
+		if _, ok := h.hosts[key]; ok && h.decay(key, now).Unhealthy {
+			unhealthy = append(unhealthy, host)
+		} else {
+			healthy = append(healthy, host)
+		}
+	}
+	return healthy, unhealthy
+}
+
+// stats returns the stats of the hosts decayed until now, sorted by host
+func (h *mxHealth) stats() []HostStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	stats := make([]HostStats, 0, len(h.hosts))
+	for host := range h.hosts {
+		stats = append(stats, *h.decay(host, now))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// EnableAdaptiveMXSelection records the health of the MX hosts the smtp check connects to, and dials the hosts
+// which keep timing out or refusing connections only once the other MX hosts of their domain failed.
+// The recorded connections count less as they age, so a host recovers. It's disabled by default,
+// all the MX hosts of a domain are dialed at once.
+func (v *Verifier) EnableAdaptiveMXSelection() *Verifier {
+	return v.apply(WithAdaptiveMXSelection())
+}
+
+// DisableAdaptiveMXSelection disables the adaptive MX selection and forgets the health of the MX hosts
+func (v *Verifier) DisableAdaptiveMXSelection() *Verifier {
+	return v.apply(WithoutAdaptiveMXSelection())
+}
+
+// HostStats returns the health of the MX hosts recorded by the adaptive MX selection, sorted by host,
+// nil if it's disabled
+func (v *Verifier) HostStats() []HostStats {
+	v = v.snapshot()
+	if v.mxHealth == nil {
+		return nil
+	}
+	return v.mxHealth.stats()
+}
+
+// recordMXDial records a connection to the MX host which took latency and failed with err in the health of the hosts,
+// unless the adaptive MX selection is disabled or the connection was interrupted by ctx, the context of its dial
+func (v *Verifier) recordMXDial(ctx context.Context, host string, latency time.Duration, err error) {
+	if v.mxHealth == nil || ctx.Err() != nil {
+		return
+	}
+	v.mxHealth.record(host, latency, err)
+}
@@ -0,0 +1,127 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestMXHealth_Decay(t *testing.T) {
+	h := newMXHealth()
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	h.record("mx.example.com.", 100*time.Millisecond, nil)
+	h.record("MX.example.com", 200*time.Millisecond, nil)
+	for i := 0; i < 3; i++ {
+		h.record("mx.example.com.", 0, errors.New("timeout"))
+	}
+	assert.Equal(t, []HostStats{{
+		Host:        "mx.example.com",
+		Successes:   2,
+		Failures:    3,
+		Latency:     130 * time.Millisecond,
+		LastFailure: now,
+		Unhealthy:   true,
+	}}, h.stats())
+	healthy, unhealthy := h.partition([]string{"mx.example.com.", "mx2.example.com."})
+	assert.Equal(t, []string{"mx2.example.com."}, healthy)
+	assert.Equal(t, []string{"mx.example.com."}, unhealthy)
+
+	// The failures count half as much after the half-life, so the host recovers
+	now = now.Add(mxHealthHalfLife)
+	stats := h.stats()
+	if assert.Len(t, stats, 1) {
+		assert.InDelta(t, 1, stats[0].Successes, 1e-9)
+		assert.InDelta(t, 1.5, stats[0].Failures, 1e-9)
+		assert.False(t, stats[0].Unhealthy)
+	}
+	healthy, unhealthy = h.partition([]string{"mx.example.com."})
+	assert.Equal(t, []string{"mx.example.com."}, healthy)
+	assert.Empty(t, unhealthy)
+}
+
+func TestAdaptiveMXSelection(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{BackupMX: []string{"example.com"}}, "example.com")
+	defer srv.Close()
+	var mu sync.Mutex
+	dials := map[string]int{}
+	// the primary MX host refuses the connections
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, _ := net.SplitHostPort(addr)
+		mu.Lock()
+		dials[host]++
+		mu.Unlock()
+		if strings.HasPrefix(host, "mx.") {
+			return nil, errors.New("connection refused")
+		}
+		return srv.Dial(ctx, network, addr)
+	}
+	dialed := func(host string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return dials[host]
+	}
+	// every check connects, instead of reusing the cached catch-all check
+	v, err := NewVerifierWithOptions(WithSMTPCheck(), WithDialer(dial), WithResolver(srv.Resolver()), WithAdaptiveMXSelection(),
+		WithCatchAllCacheTTL(0))
+	assert.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		_, err := v.CheckSMTP("example.com", "")
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool { return dialed("mx.example.com.") == i }, time.Second, time.Millisecond)
+	}
+	var stats []HostStats
+	assert.Eventually(t, func() bool {
+		stats = v.HostStats()
+		return len(stats) == 2 && stats[0].Failures > 2
+	}, time.Second, time.Millisecond)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "mx.example.com", stats[0].Host)
+		assert.True(t, stats[0].Unhealthy)
+		assert.Equal(t, "mx2.example.com", stats[1].Host)
+		assert.False(t, stats[1].Unhealthy)
+		assert.True(t, stats[1].Latency > 0)
+	}
+
+	// The unhealthy host isn't dialed while the healthy one answers
+	_, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, dialed("mx.example.com."))
+
+	v.DisableAdaptiveMXSelection()
+	assert.Nil(t, v.HostStats())
+	_, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return dialed("mx.example.com.") == 4 }, time.Second, time.Millisecond)
+}
+
+func TestAdaptiveMXSelection_AllUnhealthy(t *testing.T) {
+	var mu sync.Mutex
+	dials := 0
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials++
+		return nil, errors.New("connection refused")
+	}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithDialer(dial), WithAdaptiveMXSelection())
+
+	// The only host of a domain is dialed even though it's unhealthy
+	for i := 0; i < 4; i++ {
+		_, err := v.CheckSMTP("example.com", "")
+		assert.Error(t, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 4, dials)
+}
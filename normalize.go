@@ -0,0 +1,168 @@
+package emailverifier
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// dotInsensitiveDomains are domains whose mailbox provider ignores dots in the local part
+var dotInsensitiveDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// domainAliases maps domains to the canonical domain of their mailbox provider,
+// which the free, disposable and role-based account checks are performed on
+var domainAliases = map[string]string{
+	"googlemail.com": "gmail.com",
+}
+
+// providerSubAddressSeparators are sub-address separators of providers which don't use the default one
+var providerSubAddressSeparators = map[string]string{
+	"fastmail.com": "-",
+	"fastmail.fm":  "-",
+}
+
+// SubAddressSeparator sets the character(s) separating the sub-address tag in the local part, defaults to "+".
+// Every character of separators is treated as a separator, e.g. "+-"
+func (v *Verifier) SubAddressSeparator(separators string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.subAddressSeparator = separators
+	return v
+}
+
+// DomainSubAddressSeparator sets the sub-address separator character(s) used by the mailbox provider of domain,
+// it takes precedence over both the built-in provider table and SubAddressSeparator
+func (v *Verifier) DomainSubAddressSeparator(domain, separators string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// the map is replaced rather than modified, since snapshots share it
+	domainSeparators := make(map[string]string, len(v.domainSubAddressSeparators)+1)
+	for d, s := range v.domainSubAddressSeparators {
+		domainSeparators[d] = s
+	}
+	domainSeparators[strings.ToLower(domain)] = separators
+	v.domainSubAddressSeparators = domainSeparators
+	return v
+}
+
+// subAddressSeparators returns the sub-address separator character(s) used by the provider of domain
+func (v *Verifier) subAddressSeparators(domain string) string {
+	if separators, ok := v.domainSubAddressSeparators[domain]; ok {
+		return separators
+	}
+	if separators, ok := providerSubAddressSeparators[domain]; ok {
+		return separators
+	}
+	return v.subAddressSeparator
+}
+
+// splitSubAddress splits username into the mailbox name and the sub-address tag,
+// reports whether username is sub-addressed at all
+func (v *Verifier) splitSubAddress(username, domain string) (string, string, bool) {
+	// a separator inside a quoted local part is a literal character
+	if strings.HasPrefix(username, `"`) {
+		return username, "", false
+	}
+
+	separators := v.subAddressSeparators(strings.ToLower(domain))
+	if separators == "" {
+		return username, "", false
+	}
+
+	// a leading separator is part of the mailbox name, not a tag
+	index := strings.IndexAny(username, separators)
+	if index <= 0 {
+		return username, "", false
+	}
+
+	_, size := utf8.DecodeRuneInString(username[index:])
+	return username[:index], username[index+size:], true
+}
+
+// AddDotInsensitiveDomain adds a domain whose mailbox provider ignores dots in the local part,
+// e.g. john.smith@gmail.com and johnsmith@gmail.com are the same inbox
+func (v *Verifier) AddDotInsensitiveDomain(domain string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// the map is replaced rather than modified, since snapshots share it
+	domains := make(map[string]bool, len(v.dotInsensitiveDomains)+1)
+	for d := range v.dotInsensitiveDomains {
+		domains[d] = true
+	}
+	domains[strings.ToLower(domain)] = true
+	v.dotInsensitiveDomains = domains
+	return v
+}
+
+// isDotInsensitive checks if the mailbox provider of domain ignores dots in the local part
+func (v *Verifier) isDotInsensitive(domain string) bool {
+	return dotInsensitiveDomains[domain] || v.dotInsensitiveDomains[domain]
+}
+
+// AddDomainAlias adds alias as another domain of the mailbox provider of canonical, e.g. protonmail.ch of proton.me.
+// The free, disposable and role-based account checks are performed on the canonical domain,
+// following chains of aliases, whereas results still report the domain of the address.
+func (v *Verifier) AddDomainAlias(alias, canonical string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// the map is replaced rather than modified, since snapshots share it
+	aliases := make(map[string]string, len(v.domainAliases)+1)
+	for a, c := range v.domainAliases {
+		aliases[a] = c
+	}
+	aliases[aliasKey(alias)] = aliasKey(canonical)
+	v.domainAliases = aliases
+	return v
+}
+
+// aliasKey returns the lowercase ASCII form of domain, which the domain aliases are keyed by
+func aliasKey(domain string) string {
+	return domainToASCII(strings.ToLower(strings.TrimSpace(domain)))
+}
+
+// canonicalDomain returns the canonical domain of the mailbox provider of domain, following chains of aliases,
+// or domain unchanged if it isn't an alias. A cycle of aliases ends on one of its domains.
+func (v *Verifier) canonicalDomain(domain string) string {
+	key := aliasKey(domain)
+	canonical := key
+	for i := 0; i <= len(v.domainAliases)+len(domainAliases); i++ {
+		next, ok := v.domainAliases[canonical]
+		if !ok {
+			next, ok = domainAliases[canonical]
+		}
+		if !ok {
+			break
+		}
+		canonical = next
+	}
+	if canonical == key {
+		return domain
+	}
+	return canonical
+}
+
+// canonicalEmail returns the normalized form of an address which identifies the underlying inbox:
+// the domain is lower-cased, the sub-address tag is stripped
+// and dots are removed from the local part for providers which ignore them.
+func (v *Verifier) canonicalEmail(syntax Syntax) string {
+	username := syntax.Username
+	domain := strings.ToLower(syntax.Domain)
+
+	if syntax.HasSubAddress {
+		mailbox := username[:len(username)-len(syntax.Tag)]
+		_, size := utf8.DecodeLastRuneInString(mailbox)
+		username = mailbox[:len(mailbox)-size]
+	}
+
+	if v.isDotInsensitive(domain) {
+		username = strings.Replace(username, ".", "", -1)
+	}
+
+	return quoteLocalPart(username) + "@" + domain
+}
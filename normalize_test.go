@@ -0,0 +1,145 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestCanonicalEmail_StripDotsAndTag(t *testing.T) {
+	ret := verifier.canonicalEmail(verifier.ParseAddress("john.smith+promo@GMail.com"))
+	assert.Equal(t, "johnsmith@gmail.com", ret)
+}
+
+func TestCanonicalEmail_GoogleMail(t *testing.T) {
+	ret := verifier.canonicalEmail(verifier.ParseAddress("j.o.h.n@googlemail.com"))
+	assert.Equal(t, "john@googlemail.com", ret)
+}
+
+func TestCanonicalEmail_DotsSignificant(t *testing.T) {
+	ret := verifier.canonicalEmail(verifier.ParseAddress("john.smith+promo@Example.COM"))
+	assert.Equal(t, "john.smith@example.com", ret)
+}
+
+func TestCanonicalEmail_LeadingSeparator(t *testing.T) {
+	ret := verifier.canonicalEmail(verifier.ParseAddress("+john@example.com"))
+	assert.Equal(t, "+john@example.com", ret)
+}
+
+func TestCanonicalEmail_AddDotInsensitiveDomain(t *testing.T) {
+	v := NewVerifier()
+	assert.Equal(t, "john.smith@example.org", v.canonicalEmail(v.ParseAddress("john.smith@example.org")))
+
+	v.AddDotInsensitiveDomain("Example.ORG")
+	assert.Equal(t, "johnsmith@example.org", v.canonicalEmail(v.ParseAddress("john.smith@example.org")))
+}
+
+func TestCanonicalEmail_Quoted(t *testing.T) {
+	ret := verifier.canonicalEmail(verifier.ParseAddress(`"john..doe+x"@gmail.com`))
+	assert.Equal(t, "johndoe+x@gmail.com", ret)
+}
+
+func TestCheckEmail_CanonicalEmail(t *testing.T) {
+	email := "John.Smith+promo@GMAIL.com"
+
+	ret, _ := NewVerifier().Verify(email)
+	assert.Equal(t, email, ret.Email)
+	assert.Equal(t, "JohnSmith@gmail.com", ret.CanonicalEmail)
+}
+
+func TestSplitSubAddress_Plus(t *testing.T) {
+	mailbox, tag, ok := verifier.splitSubAddress("user+spam", "domain.com")
+	assert.True(t, ok)
+	assert.Equal(t, "user", mailbox)
+	assert.Equal(t, "spam", tag)
+}
+
+func TestSplitSubAddress_NoTag(t *testing.T) {
+	mailbox, tag, ok := verifier.splitSubAddress("user", "domain.com")
+	assert.False(t, ok)
+	assert.Equal(t, "user", mailbox)
+	assert.Equal(t, "", tag)
+}
+
+func TestSplitSubAddress_Provider(t *testing.T) {
+	mailbox, tag, ok := verifier.splitSubAddress("user-spam", "FastMail.com")
+	assert.True(t, ok)
+	assert.Equal(t, "user", mailbox)
+	assert.Equal(t, "spam", tag)
+
+	_, _, ok = verifier.splitSubAddress("user+spam", "fastmail.com")
+	assert.False(t, ok)
+}
+
+func TestSplitSubAddress_Quoted(t *testing.T) {
+	mailbox, _, ok := verifier.splitSubAddress(`"user+spam"`, "domain.com")
+	assert.False(t, ok)
+	assert.Equal(t, `"user+spam"`, mailbox)
+}
+
+func TestSplitSubAddress_CustomSeparators(t *testing.T) {
+	v := NewVerifier().SubAddressSeparator("+=").DomainSubAddressSeparator("example.org", "_")
+
+	_, tag, ok := v.splitSubAddress("user=spam", "domain.com")
+	assert.True(t, ok)
+	assert.Equal(t, "spam", tag)
+
+	_, tag, ok = v.splitSubAddress("user_spam", "example.org")
+	assert.True(t, ok)
+	assert.Equal(t, "spam", tag)
+
+	_, _, ok = NewVerifier().SubAddressSeparator("").splitSubAddress("user+spam", "domain.com")
+	assert.False(t, ok)
+}
+
+func TestCanonicalDomain(t *testing.T) {
+	v := NewVerifier().AddDomainAlias("Mail.Example.ORG", "example.org")
+
+	assert.Equal(t, "gmail.com", v.canonicalDomain("googlemail.com"))
+	assert.Equal(t, "gmail.com", v.canonicalDomain("GoogleMail.COM"))
+	assert.Equal(t, "example.org", v.canonicalDomain("mail.example.org"))
+	assert.Equal(t, "example.org", v.canonicalDomain("MAIL.example.org"))
+	assert.Equal(t, "Example.com", v.canonicalDomain("Example.com"))
+}
+
+func TestCanonicalDomain_Chain(t *testing.T) {
+	v := NewVerifier().AddDomainAlias("a.example", "b.example").AddDomainAlias("B.example", "c.example")
+	assert.Equal(t, "c.example", v.canonicalDomain("a.example"))
+	assert.Equal(t, "c.example", v.canonicalDomain("b.example"))
+
+	// a cycle of aliases ends on one of its domains
+	v.AddDomainAlias("c.example", "a.example")
+	assert.Contains(t, []string{"a.example", "b.example", "c.example"}, v.canonicalDomain("a.example"))
+}
+
+func TestDomainAlias_Classification(t *testing.T) {
+	restoreDisposableDomains(t)
+	addDisposableDomains([]string{"disposable-example.com"})
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"team.example.com"})
+	v.AddDomainAlias("mail.example.org", "disposable-example.com").
+		AddDomainAlias("relay.example.net", "Mail.Example.org").
+		AddDomainAlias("free.example.com", "gmail.com").
+		AddDomainAlias("team.example.com", "fastmail.com")
+
+	assert.False(t, verifier.IsDisposable("relay.example.net"))
+	assert.True(t, v.IsDisposable("mail.example.org"))
+	assert.True(t, v.IsDisposable("RELAY.example.net"))
+	assert.True(t, v.IsFreeDomain("Free.Example.com"))
+	assert.True(t, v.IsFreeDomain("googlemail.com"))
+
+	// the original domain is reported, the classification uses the canonical one
+	ret, err := v.Verify("sales-q3@Team.Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "sales-q3@Team.Example.com", ret.Email)
+	assert.Equal(t, "sales-q3@team.example.com", ret.CanonicalEmail)
+	assert.True(t, ret.RoleAccount)
+	assert.True(t, ret.Free)
+
+	ret, err = v.Verify("user@relay.example.net")
+	assert.NoError(t, err)
+	assert.Equal(t, "relay.example.net", ret.Syntax.Domain)
+	assert.Nil(t, ret.SMTP)
+	assert.True(t, ret.Disposable)
+}
@@ -0,0 +1,55 @@
+package emailverifier
+
+import (
+	"time"
+)
+
+// Outcomes of a verification passed to Observer.ObserveVerification besides the reachable values "yes", "no" and "unknown"
+const (
+	OutcomeInvalid = "invalid" // the address syntax is invalid
+	OutcomeSkipped = "skipped" // the checks requiring network access were skipped
+	OutcomeError   = "error"   // the verification failed with an error
+)
+
+// Observer receives measurements of a Verifier, e.g. to export them as metrics
+// without this package depending on a metrics library.
+// Its methods are called concurrently and should return quickly.
+type Observer interface {
+	// ObserveVerification is called after each verification of an address
+	// with the domain of the address, the outcome of the verification and its duration
+	ObserveVerification(domain string, outcome string, d time.Duration)
+
+	// ObserveSMTPDial is called after each connection attempt to the SMTP server host,
+	// err is the error of the attempt, nil if it succeeded
+	ObserveSMTPDial(host string, err error, d time.Duration)
+
+	// ObserveCacheHit is called after each lookup in a cache of kind, hit reports whether the entry was found
+	ObserveCacheHit(kind string, hit bool)
+}
+
+// SetObserver sets the observer receiving the measurements of the verifier, nil disables it
+func (v *Verifier) SetObserver(o Observer) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.observer = o
+	return v
+}
+
+// observeVerification passes the verification result ret and its error err to the observer
+func (v *Verifier) observeVerification(ret *Result, err error) {
+	if v.observer == nil {
+		return
+	}
+
+	outcome := ret.Reachable
+	switch {
+	case err != nil:
+		outcome = OutcomeError
+	case !ret.Syntax.Valid:
+		outcome = OutcomeInvalid
+	case ret.Skipped:
+		outcome = OutcomeSkipped
+	}
+	v.observer.ObserveVerification(ret.Syntax.DomainASCII, outcome, ret.Timings.Total)
+}
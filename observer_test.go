@@ -0,0 +1,85 @@
+package emailverifier
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// verification is a call of Observer.ObserveVerification
+type verification struct {
+	domain  string
+	outcome string
+}
+
+// cacheLookup is a call of Observer.ObserveCacheHit
+type cacheLookup struct {
+	kind string
+	hit  bool
+}
+
+// recordingObserver records the calls of its methods
+type recordingObserver struct {
+	mu            sync.Mutex
+	verifications []verification
+	cacheLookups  []cacheLookup
+}
+
+func (o *recordingObserver) ObserveVerification(domain string, outcome string, d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.verifications = append(o.verifications, verification{domain: domain, outcome: outcome})
+}
+
+func (o *recordingObserver) ObserveSMTPDial(host string, err error, d time.Duration) {}
+
+func (o *recordingObserver) ObserveCacheHit(kind string, hit bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cacheLookups = append(o.cacheLookups, cacheLookup{kind: kind, hit: hit})
+}
+
+func TestObserveVerification(t *testing.T) {
+	o := &recordingObserver{}
+	v := NewVerifier().
+		SetObserver(o).
+		SetDomainAllowlist([]string{"trusted.test"}, reachableYes).
+		SetDomainBlocklist([]string{"blocked.test"})
+
+	_, _ = v.Verify("invalid-address")
+	_, _ = v.Verify("user@blocked.test")
+	_, _ = v.Verify("user@trusted.test")
+	_, _ = v.Verify("user@zzjbfwqi.shop")
+	_, _ = v.Verify("user@example.invalid")
+
+	assert.Equal(t, []verification{
+		{domain: "", outcome: OutcomeInvalid},
+		{domain: "blocked.test", outcome: OutcomeSkipped},
+		{domain: "trusted.test", outcome: OutcomeSkipped},
+		{domain: "zzjbfwqi.shop", outcome: reachableUnknown},
+		{domain: "example.invalid", outcome: OutcomeError},
+	}, o.verifications)
+}
+
+func TestObserveVerification_GroupedBatch(t *testing.T) {
+	o := &recordingObserver{}
+	v := NewVerifier().SetObserver(o).SetDomainBlocklist([]string{"blocked.test"})
+
+	v.VerifyBatch([]string{"invalid-address", "a@blocked.test", "b@example.invalid", "c@example.invalid"}, BatchOptions{GroupByDomain: true})
+
+	assert.ElementsMatch(t, []verification{
+		{domain: "", outcome: OutcomeInvalid},
+		{domain: "blocked.test", outcome: OutcomeSkipped},
+		{domain: "example.invalid", outcome: OutcomeError},
+		{domain: "example.invalid", outcome: OutcomeError},
+	}, o.verifications)
+}
+
+func TestObserveVerification_NilObserver(t *testing.T) {
+	v := NewVerifier().SetObserver(nil)
+	ret, err := v.Verify("user@zzjbfwqi.shop")
+	assert.NoError(t, err)
+	assert.True(t, ret.Disposable)
+}
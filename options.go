@@ -0,0 +1,638 @@
+package emailverifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Option configures a Verifier, see NewVerifierWithOptions.
+// An option stores its value even if the value is invalid, so the setters built on the options keep accepting
+// any value as before, whereas NewVerifierWithOptions returns the error.
+type Option func(c *config) error
+
+// NewVerifierWithOptions creates a new email verifier configured by opts,
+// it returns an error if the value of any option is invalid
+func NewVerifierWithOptions(opts ...Option) (*Verifier, error) {
+	v := NewVerifier()
+	for _, opt := range opts {
+		if err := opt(&v.config); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// apply applies opt to the configuration of v and ignores an invalid value like the setters always did
+func (v *Verifier) apply(opt Option) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	_ = opt(&v.config)
+	return v
+}
+
+// WithSMTPCheck enables check email by smtp, like EnableSMTPCheck
+func WithSMTPCheck() Option {
+	return func(c *config) error {
+		c.smtpCheckEnabled = true
+		return nil
+	}
+}
+
+// WithoutSMTPCheck disables check email by smtp, like DisableSMTPCheck
+func WithoutSMTPCheck() Option {
+	return func(c *config) error {
+		c.smtpCheckEnabled = false
+		return nil
+	}
+}
+
+// WithSMTPSoftFail records an error of the smtp check in the result instead of returning it, like EnableSMTPSoftFail
+func WithSMTPSoftFail() Option {
+	return func(c *config) error {
+		c.smtpSoftFailEnabled = true
+		return nil
+	}
+}
+
+// WithoutSMTPSoftFail makes Verify return an error of the smtp check, like DisableSMTPSoftFail
+func WithoutSMTPSoftFail() Option {
+	return func(c *config) error {
+		c.smtpSoftFailEnabled = false
+		return nil
+	}
+}
+
+// WithSMTPDryRun makes the smtp check only report the commands it would send, like EnableSMTPDryRun
+func WithSMTPDryRun() Option {
+	return func(c *config) error {
+		c.smtpDryRun = true
+		return nil
+	}
+}
+
+// WithoutSMTPDryRun makes the smtp check connect to the servers, like DisableSMTPDryRun
+func WithoutSMTPDryRun() Option {
+	return func(c *config) error {
+		c.smtpDryRun = false
+		return nil
+	}
+}
+
+// WithExactDomainMatching matches the domain lists and providers against the domain only, like EnableExactDomainMatching
+func WithExactDomainMatching() Option {
+	return func(c *config) error {
+		c.exactDomainMatching = true
+		return nil
+	}
+}
+
+// WithoutExactDomainMatching matches the registrable domain of a domain too, like DisableExactDomainMatching
+func WithoutExactDomainMatching() Option {
+	return func(c *config) error {
+		c.exactDomainMatching = false
+		return nil
+	}
+}
+
+// WithDomainAuthCheck enables the domain auth check, like EnableDomainAuthCheck
+func WithDomainAuthCheck() Option {
+	return func(c *config) error {
+		c.domainAuthCheckEnabled = true
+		return nil
+	}
+}
+
+// WithoutDomainAuthCheck disables the domain auth check, like DisableDomainAuthCheck
+func WithoutDomainAuthCheck() Option {
+	return func(c *config) error {
+		c.domainAuthCheckEnabled = false
+		return nil
+	}
+}
+
+// WithDKIMSelectors sets the DKIM selectors probed by the domain auth check, like SetDKIMSelectors.
+// Each selector must be a sequence of DNS labels.
+func WithDKIMSelectors(selectors ...string) Option {
+	return func(c *config) error {
+		c.dkimSelectors = nil
+		for _, selector := range selectors {
+			c.dkimSelectors = append(c.dkimSelectors, strings.ToLower(selector))
+		}
+		for _, selector := range c.dkimSelectors {
+			if err := validateDKIMSelector(selector); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithSequentialChecks runs the network checks of a verification one after another, like EnableSequentialChecks
+func WithSequentialChecks() Option {
+	return func(c *config) error {
+		c.sequentialChecks = true
+		return nil
+	}
+}
+
+// WithoutSequentialChecks runs the independent network checks of a verification concurrently, like DisableSequentialChecks
+func WithoutSequentialChecks() Option {
+	return func(c *config) error {
+		c.sequentialChecks = false
+		return nil
+	}
+}
+
+// WithSMTPRelay makes the smtp checks go through a relay, like SetSMTPRelay.
+// An empty host disables the relay, otherwise the port must be between 1 and 65535.
+func WithSMTPRelay(host string, port int, auth smtp.Auth, useTLS bool) Option {
+	return func(c *config) error {
+		if host == "" {
+			c.relay = nil
+			return nil
+		}
+		c.relay = &smtpRelay{host: host, port: port, auth: auth, useTLS: useTLS}
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid relay port %d", port)
+		}
+		return nil
+	}
+}
+
+// WithDomainSMTPOverride adds an SMTP server dialed instead of the MX hosts of domain, like SetDomainSMTPOverride.
+// The domain and the host mustn't be empty, and the port must be between 0 and 65535.
+func WithDomainSMTPOverride(domain, host string, port int) Option {
+	return func(c *config) error {
+		overrides := c.copySMTPOverrides()
+		key := overrideKey(domain)
+		overrides[key] = append(append([]smtpEndpoint(nil), overrides[key]...), smtpEndpoint{host: host, port: port})
+		c.smtpOverrides = overrides
+		switch {
+		case key == "" || host == "":
+			return errors.New("smtp override without domain or host")
+		case port < 0 || port > 65535:
+			return fmt.Errorf("invalid smtp override port %d", port)
+		}
+		return nil
+	}
+}
+
+// WithAdaptiveMXSelection enables the adaptive MX selection, like EnableAdaptiveMXSelection
+func WithAdaptiveMXSelection() Option {
+	return func(c *config) error {
+		if c.mxHealth == nil {
+			c.mxHealth = newMXHealth()
+		}
+		return nil
+	}
+}
+
+// WithoutAdaptiveMXSelection disables the adaptive MX selection, like DisableAdaptiveMXSelection
+func WithoutAdaptiveMXSelection() Option {
+	return func(c *config) error {
+		c.mxHealth = nil
+		return nil
+	}
+}
+
+// WithSequentialMXDialing makes the smtp check dial the MX hosts of a domain one at a time in preference order,
+// like EnableSequentialMXDialing
+func WithSequentialMXDialing() Option {
+	return func(c *config) error {
+		c.sequentialMX = true
+		return nil
+	}
+}
+
+// WithoutSequentialMXDialing makes the smtp check dial the MX hosts of a domain at once, like DisableSequentialMXDialing
+func WithoutSequentialMXDialing() Option {
+	return func(c *config) error {
+		c.sequentialMX = false
+		return nil
+	}
+}
+
+// WithMaxMXAttempts sets how many MX hosts of a domain the smtp check dials at most, like SetMaxMXAttempts
+func WithMaxMXAttempts(max int) Option {
+	return func(c *config) error {
+		c.maxMXAttempts = max
+
+		if max < 0 {
+			return fmt.Errorf("invalid max MX attempts %d", max)
+		}
+		return nil
+	}
+}
+
+// WithSMTPPool makes the smtp checks reuse the connections to the mail servers of a domain, like EnableSMTPPool
+func WithSMTPPool(idleTimeout time.Duration, maxProbes int) Option {
+	return func(c *config) error {
+		if c.smtpPool != nil {
+			c.smtpPool.close()
+		}
+		c.smtpPool = newSMTPPool(idleTimeout, maxProbes)
+
+		if idleTimeout < 0 {
+			return fmt.Errorf("invalid smtp pool idle timeout %s", idleTimeout)
+		}
+		if maxProbes < 0 {
+			return fmt.Errorf("invalid smtp pool max probes %d", maxProbes)
+		}
+		return nil
+	}
+}
+
+// WithoutSMTPPool closes the pooled connections and disables the pool, like DisableSMTPPool
+func WithoutSMTPPool() Option {
+	return func(c *config) error {
+		if c.smtpPool != nil {
+			c.smtpPool.close()
+			c.smtpPool = nil
+		}
+		return nil
+	}
+}
+
+// WithCache sets the cache of the MX records and the catch-all checks of the domains, like SetCache
+func WithCache(cache Cache) Option {
+	return func(c *config) error {
+		c.cache = cache
+		if cache == nil {
+			return errors.New("nil cache")
+		}
+		return nil
+	}
+}
+
+// WithCatchAllCacheTTL sets the time the catch-all check of a domain is reused by the checks of its addresses,
+// in the cache set by WithCache or in memory without one, an hour by default. Zero disables caching them.
+func WithCatchAllCacheTTL(ttl time.Duration) Option {
+	return func(c *config) error {
+		c.catchAllCacheTTL = ttl
+		if ttl < 0 {
+			return fmt.Errorf("invalid catch-all cache TTL %s", ttl)
+		}
+		return nil
+	}
+}
+
+// WithResultCacheTTL caches the verifications of Verify and VerifyBatch for ttl in the cache set by WithCache,
+// zero, the default, disables caching them
+func WithResultCacheTTL(ttl time.Duration) Option {
+	return func(c *config) error {
+		c.resultCacheTTL = ttl
+		if ttl < 0 {
+			return fmt.Errorf("invalid result cache TTL %s", ttl)
+		}
+		return nil
+	}
+}
+
+// WithProxy sets a SOCKS5 or HTTP CONNECT proxy to verify the email, like Proxy.
+// The proxy URI must have the socks5, socks4, socks4a, http or https scheme and a host.
+func WithProxy(proxyURI string) Option {
+	return func(c *config) error {
+		c.proxies = nil
+		if proxyURI != "" {
+			c.proxies = newProxyPool(proxyURI)
+		}
+		return validateProxyURI(proxyURI)
+	}
+}
+
+// WithProxies sets the pool of SOCKS proxies the connections to the mail servers go through, like AddProxy
+func WithProxies(proxyURIs ...string) Option {
+	return func(c *config) error {
+		c.proxies = newProxyPool()
+		for _, proxyURI := range proxyURIs {
+			c.proxies.add(proxyURI)
+		}
+
+		if len(proxyURIs) == 0 {
+			return errors.New("no proxy")
+		}
+		for _, proxyURI := range proxyURIs {
+			if err := validateProxyURI(proxyURI); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithProxySelection sets which proxy of the pool a connection goes through, like SetProxySelection
+func WithProxySelection(selection ProxySelection) Option {
+	return func(c *config) error {
+		c.proxySelection = selection
+
+		if selection != ProxyRoundRobin && selection != ProxyRandom {
+			return fmt.Errorf("invalid proxy selection %d", selection)
+		}
+		return nil
+	}
+}
+
+// WithHelloName sets the name to use in the `EHLO:` SMTP command, like HelloName.
+// The name must be a host name or an address literal like "[192.0.2.1]".
+func WithHelloName(name string) Option {
+	return func(c *config) error {
+		c.helloName = name
+
+		if strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]") {
+			literal := strings.TrimPrefix(name[1:len(name)-1], "IPv6:")
+			if net.ParseIP(literal) == nil {
+				return fmt.Errorf("invalid hello name %q", name)
+			}
+			return nil
+		}
+		if !isHostName(name) {
+			return fmt.Errorf("invalid hello name %q", name)
+		}
+		return nil
+	}
+}
+
+// WithFromEmail sets the email to use in the `MAIL FROM:` SMTP command, like FromEmail.
+// The email must be a valid address.
+func WithFromEmail(email string) Option {
+	return func(c *config) error {
+		c.fromEmail = email
+
+		if !NewVerifier().ParseAddress(email).Valid || strings.ContainsAny(email, "<>\r\n") {
+			return fmt.Errorf("invalid from email %q", email)
+		}
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of connecting to a mail server, defaults to 30 seconds, like WithSMTPDialTimeout
+func WithTimeout(d time.Duration) Option {
+	return WithSMTPDialTimeout(d)
+}
+
+// WithSMTPDialTimeout sets the timeout of connecting to a mail server, like SetSMTPDialTimeout
+func WithSMTPDialTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		c.smtpTimeout = d
+
+		if d <= 0 {
+			return fmt.Errorf("invalid timeout %s", d)
+		}
+		return nil
+	}
+}
+
+// WithSMTPCommandTimeout sets the timeout of each SMTP command once connected, like SetSMTPCommandTimeout
+func WithSMTPCommandTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		c.smtpCommandTimeout = d
+
+		if d < 0 {
+			return fmt.Errorf("invalid command timeout %s", d)
+		}
+		return nil
+	}
+}
+
+// WithConnectionRetry sets the reconnections to a mail server which drops the connection before its banner,
+// like SetConnectionRetry. It defaults to 1 reconnection after 250ms.
+func WithConnectionRetry(attempts int, baseDelay time.Duration) Option {
+	return func(c *config) error {
+		c.connectionRetries = attempts
+		c.connectionRetryDelay = baseDelay
+
+		if attempts < 0 {
+			return fmt.Errorf("invalid connection retry attempts %d", attempts)
+		}
+		if baseDelay < 0 {
+			return fmt.Errorf("invalid connection retry delay %s", baseDelay)
+		}
+		return nil
+	}
+}
+
+// WithGreylistRetry sets the retries of a RCPT greylisted by the mail server, like SetGreylistRetry.
+// It defaults to no retry.
+func WithGreylistRetry(attempts int, delay time.Duration) Option {
+	return func(c *config) error {
+		c.greylistRetries = attempts
+		c.greylistRetryDelay = delay
+
+		if attempts < 0 {
+			return fmt.Errorf("invalid greylist retry attempts %d", attempts)
+		}
+		if delay < 0 {
+			return fmt.Errorf("invalid greylist retry delay %s", delay)
+		}
+		return nil
+	}
+}
+
+// WithLenientGreeting tolerates the nonstandard banners of the mail servers, like EnableLenientGreeting
+func WithLenientGreeting(bannerTimeout time.Duration) Option {
+	return func(c *config) error {
+		c.greeting = &lenientGreeting{timeout: bannerTimeout}
+
+		if bannerTimeout < 0 {
+			return fmt.Errorf("invalid banner timeout %s", bannerTimeout)
+		}
+		return nil
+	}
+}
+
+// WithoutLenientGreeting reads the banners strictly, like DisableLenientGreeting
+func WithoutLenientGreeting() Option {
+	return func(c *config) error {
+		c.greeting = nil
+		return nil
+	}
+}
+
+// WithVerifyTimeout sets the budget of each verification of Verify, like SetVerifyTimeout. Zero, the default, means none.
+func WithVerifyTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		c.verifyTimeout = d
+
+		if d < 0 {
+			return fmt.Errorf("invalid verify timeout %s", d)
+		}
+		return nil
+	}
+}
+
+// WithResolver sets the resolver of the DNS lookups, defaults to net.DefaultResolver
+func WithResolver(r *net.Resolver) Option {
+	return func(c *config) error {
+		c.resolver = r
+
+		if r == nil {
+			return errors.New("nil resolver")
+		}
+		return nil
+	}
+}
+
+// WithNameservers makes the DNS lookups query the nameservers at addrs in turn instead of those of the system,
+// e.g. "8.8.8.8:53" or "1.1.1.1", whose port defaults to 53. It replaces the resolver set by WithResolver.
+func WithNameservers(addrs ...string) Option {
+	return func(c *config) error {
+		r, err := nameserverResolver(addrs)
+		if err != nil {
+			return err
+		}
+		c.resolver = r
+		return nil
+	}
+}
+
+// WithDNSOverTLS makes the DNS lookups query the DNS-over-TLS server at addr instead of the nameservers
+// of the system, e.g. "1.1.1.1:853", whose port defaults to 853. The certificate of the server is verified
+// with tlsConfig, the default config if nil, whose server name defaults to the host of addr.
+// It replaces the resolver set by WithResolver.
+func WithDNSOverTLS(addr string, tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		r, err := dnsOverTLSResolver(addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+		c.resolver = r
+		return nil
+	}
+}
+
+// WithDNSOverHTTPS makes the DNS lookups post their queries to the DNS-over-HTTPS endpoint instead of querying
+// the nameservers of the system, e.g. "https://cloudflare-dns.com/dns-query", with client, http.DefaultClient if nil.
+// It replaces the resolver set by WithResolver.
+func WithDNSOverHTTPS(endpoint string, client *http.Client) Option {
+	return func(c *config) error {
+		r, err := dnsOverHTTPSResolver(endpoint, client)
+		if err != nil {
+			return err
+		}
+		c.resolver = r
+		return nil
+	}
+}
+
+// WithDialer sets the function dialing the mail servers, unless a proxy is set, defaults to a net.Dialer.
+// The address passed to dial is the host of an MX record with the SMTP port, e.g. to redirect
+// the connections to a test server like smtptest.Server.
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(c *config) error {
+		c.dialer = dial
+
+		if dial == nil {
+			return errors.New("nil dialer")
+		}
+		return nil
+	}
+}
+
+// WithSMTPPort sets the port of the mail servers, defaults to 25.
+// The connections to port 465 use implicit TLS, see WithImplicitTLS.
+func WithSMTPPort(port int) Option {
+	return WithSMTPPorts(port)
+}
+
+// WithSMTPPorts sets the ports of the mail servers, which are tried in order: a mail server is dialed
+// on the next port when the connection to the previous one fails. The connections to port 465 use implicit TLS.
+func WithSMTPPorts(ports ...int) Option {
+	return func(c *config) error {
+		c.smtpPorts = append([]int(nil), ports...)
+
+		if len(ports) == 0 {
+			return errors.New("no smtp port")
+		}
+		for _, port := range ports {
+			if port <= 0 || port > 65535 {
+				return fmt.Errorf("invalid smtp port %d", port)
+			}
+		}
+		return nil
+	}
+}
+
+// WithImplicitTLS makes the connections to the mail servers use implicit TLS on any port,
+// the SMTP session starts after the TLS handshake like on port 465
+func WithImplicitTLS() Option {
+	return func(c *config) error {
+		c.implicitTLS = true
+		return nil
+	}
+}
+
+// WithSTARTTLS secures the connections to the mail servers by STARTTLS after EHLO when they advertise it,
+// so MAIL and RCPT are encrypted, like EnableSTARTTLS. The TLS config is the one of WithTLSConfig, whose server name
+// defaults to the MX host. A failed handshake doesn't fail the smtp check, which redials the mail server and goes on
+// in plaintext, recording why in SMTP.STARTTLSError.
+func WithSTARTTLS() Option {
+	return func(c *config) error {
+		c.startTLS = true
+		return nil
+	}
+}
+
+// WithoutSTARTTLS sends MAIL and RCPT in plaintext, like DisableSTARTTLS
+func WithoutSTARTTLS() Option {
+	return func(c *config) error {
+		c.startTLS = false
+		return nil
+	}
+}
+
+// WithSMTPPortFallback dials an MX host on port 465 with implicit TLS, then on port 587, when the connection
+// to its port 25 times out, like on the networks of cloud providers which block the outgoing port 25.
+// It's like EnableSMTPPortFallback, and has no effect on another port set by WithSMTPPort or with WithImplicitTLS.
+func WithSMTPPortFallback() Option {
+	return func(c *config) error {
+		c.portFallback = true
+		return nil
+	}
+}
+
+// WithoutSMTPPortFallback only dials the MX hosts on their port, like DisableSMTPPortFallback
+func WithoutSMTPPortFallback() Option {
+	return func(c *config) error {
+		c.portFallback = false
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS config of the connections to the mail servers, defaults to the default config.
+// The server name defaults to the MX host.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		c.tlsConfig = tlsConfig
+
+		if tlsConfig == nil {
+			return errors.New("nil tls config")
+		}
+		return nil
+	}
+}
+
+// isHostName checks if name consists of dot separated labels of letters, digits and hyphens
+func isHostName(name string) bool {
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
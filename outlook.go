@@ -0,0 +1,113 @@
+package emailverifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// outlookDomains are the domains of Microsoft consumer mailboxes
+var outlookDomains = map[string]bool{
+	"outlook.com": true,
+	"hotmail.com": true,
+	"live.com":    true,
+}
+
+// outlookVerifier checks the existence of Microsoft accounts through the credential type endpoint of the login page,
+// since the Microsoft consumer MX servers answer RCPT like catch-all servers
+type outlookVerifier struct {
+	client            *http.Client
+	limiter           *rateLimiter
+	credentialTypeURL string // endpoint returning the credential type of a username
+}
+
+// newOutlookVerifier returns an outlookVerifier of the Microsoft endpoint
+func newOutlookVerifier() *outlookVerifier {
+	return &outlookVerifier{
+		client:            &http.Client{Timeout: apiVerifierTimeout},
+		limiter:           &rateLimiter{interval: apiVerifierInterval},
+		credentialTypeURL: outlookCredentialTypeURL,
+	}
+}
+
+func (o *outlookVerifier) isSupported(domain string) bool {
+	return outlookDomains[strings.ToLower(domain)]
+}
+
+// outlookCredentialTypeRequest is the request of the credential type endpoint
+type outlookCredentialTypeRequest struct {
+	Username            string `json:"username"`
+	IsOtherIdpSupported bool   `json:"isOtherIdpSupported"`
+}
+
+// outlookCredentialTypeResponse is the response of the credential type endpoint
+type outlookCredentialTypeResponse struct {
+	IfExistsResult *int `json:"IfExistsResult"` // whether the account exists, see the outlookIfExists constants
+	ThrottleStatus int  `json:"ThrottleStatus"` // 1 when the requests are throttled
+}
+
+// Values of IfExistsResult
+const (
+	outlookIfExistsExists          = 0
+	outlookIfExistsNotExists       = 1
+	outlookIfExistsThrottled       = 2
+	outlookIfExistsOtherIdp        = 5
+	outlookIfExistsExistsOtherType = 6
+)
+
+func (o *outlookVerifier) check(ctx context.Context, domain, username string) (*SMTP, error) {
+	if err := o.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(outlookCredentialTypeRequest{
+		Username:            fmt.Sprintf("%s@%s", username, domain),
+		IsOtherIdpSupported: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", o.credentialTypeURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("User-Agent", apiVerifierUserAgent)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, newLookupError(ErrServerUnavailable, err.Error())
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, newLookupError(ErrBlocked, fmt.Sprintf("outlook status_code: %d", resp.StatusCode))
+	default:
+		return nil, newLookupError(ErrServerUnavailable, fmt.Sprintf("outlook status_code: %d", resp.StatusCode))
+	}
+
+	var credentialType outlookCredentialTypeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&credentialType); err != nil || credentialType.IfExistsResult == nil {
+		return nil, newLookupError(ErrBlocked, "unexpected outlook credential type response")
+	}
+	if credentialType.ThrottleStatus == 1 {
+		return nil, newLookupError(ErrTryAgainLater, "outlook credential type throttled")
+	}
+
+	switch *credentialType.IfExistsResult {
+	case outlookIfExistsExists, outlookIfExistsOtherIdp, outlookIfExistsExistsOtherType:
+		return &SMTP{HostExists: true, Deliverable: true}, nil
+	case outlookIfExistsNotExists:
+		return &SMTP{HostExists: true}, nil
+	case outlookIfExistsThrottled:
+		return nil, newLookupError(ErrTryAgainLater, "outlook credential type throttled")
+	default:
+		return nil, newLookupError(ErrServerUnavailable, fmt.Sprintf("outlook IfExistsResult: %d", *credentialType.IfExistsResult))
+	}
+}
@@ -0,0 +1,73 @@
+package emailverifier
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newOutlookTestServer returns an outlookVerifier of a server answering with the recorded fixture of each username
+func newOutlookTestServer(t *testing.T, fixtures map[string]string) *outlookVerifier {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req outlookCredentialTypeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fixture, ok := fixtures[req.Username]
+		if !ok {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body, err := ioutil.ReadFile(filepath.Join("testdata", "outlook", fixture))
+		if err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	o := newOutlookVerifier()
+	o.limiter.interval = 0
+	o.credentialTypeURL = server.URL
+	return o
+}
+
+func TestOutlookVerifier_IsSupported(t *testing.T) {
+	o := newOutlookVerifier()
+
+	assert.True(t, o.isSupported("outlook.com"))
+	assert.True(t, o.isSupported("Hotmail.com"))
+	assert.True(t, o.isSupported("live.com"))
+	assert.False(t, o.isSupported("gmail.com"))
+}
+
+func TestOutlookVerifier_Check(t *testing.T) {
+	o := newOutlookTestServer(t, map[string]string{
+		"alice@outlook.com":  "exists.json",
+		"nobody@outlook.com": "not_exists.json",
+		"busy@outlook.com":   "throttled.json",
+	})
+
+	smtp, err := o.check(context.Background(), "outlook.com", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true}, smtp)
+
+	smtp, err = o.check(context.Background(), "outlook.com", "nobody")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true}, smtp)
+
+	smtp, err = o.check(context.Background(), "outlook.com", "busy")
+	assert.Nil(t, smtp)
+	assert.Equal(t, ErrTryAgainLater, err.(*LookupError).Message)
+
+	smtp, err = o.check(context.Background(), "outlook.com", "blocked")
+	assert.Nil(t, smtp)
+	assert.Equal(t, ErrBlocked, err.(*LookupError).Message)
+}
@@ -0,0 +1,84 @@
+package emailverifier
+
+import (
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// smtpEndpoint is an SMTP server dialed instead of the MX hosts of a domain, see SetDomainSMTPOverride
+type smtpEndpoint struct {
+	host string // host of the server
+	port int    // port of the server, the port of the mail servers if zero
+}
+
+// SetDomainSMTPOverride adds the SMTP server at host and port to the servers the smtp checks of the addresses at domain
+// dial instead of looking up its MX hosts, e.g. an internal mail server which the public MX records don't reflect.
+// The servers of a domain are tried in the order they were added, a zero port is the port of the mail servers.
+// The overrides take precedence over the relay, and the domain allowlist and blocklist over the overrides.
+func (v *Verifier) SetDomainSMTPOverride(domain, host string, port int) *Verifier {
+	return v.apply(WithDomainSMTPOverride(domain, host, port))
+}
+
+// ClearDomainSMTPOverrides removes the SMTP servers set by SetDomainSMTPOverride for domain,
+// whose MX hosts are dialed again
+func (v *Verifier) ClearDomainSMTPOverrides(domain string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	overrides := v.copySMTPOverrides()
+	delete(overrides, overrideKey(domain))
+	v.smtpOverrides = overrides
+	return v
+}
+
+// copySMTPOverrides returns a copy of the smtp overrides, which are replaced rather than modified since snapshots share them
+func (c *config) copySMTPOverrides() map[string][]smtpEndpoint {
+	overrides := make(map[string][]smtpEndpoint, len(c.smtpOverrides)+1)
+	for domain, endpoints := range c.smtpOverrides {
+		overrides[domain] = endpoints
+	}
+	return overrides
+}
+
+// overrideKey returns the key of domain in the smtp overrides
+func overrideKey(domain string) string {
+	return strings.ToLower(domainToASCII(strings.TrimSuffix(domain, ".")))
+}
+
+// smtpOverride returns the SMTP servers which replace the MX hosts of domain, nil if there are none
+func (v *Verifier) smtpOverride(domain string) []smtpEndpoint {
+	if len(v.smtpOverrides) == 0 {
+		return nil
+	}
+	return v.smtpOverrides[overrideKey(domain)]
+}
+
+// dialOverride connects to the first of endpoints which accepts a connection, they are tried in order.
+// It returns the reconnections to the endpoints which reset the connection before their banner as well.
+func (v *Verifier) dialOverride(domain string, endpoints []smtpEndpoint) (*smtp.Client, string, int, error) {
+	var firstErr error
+	var retries int
+	for _, endpoint := range endpoints {
+		port := endpoint.port
+		if port == 0 {
+			port = v.port()
+		}
+		v.debug(domain, "dialing smtp override", "host", endpoint.host, "port", port, "proxy", v.proxies != nil)
+		client, n, err := v.dialHost(v.context(), domain, endpoint.host, net.JoinHostPort(endpoint.host, strconv.Itoa(port)), v.implicitTLSConfigOf(port))
+		retries += n
+		if err == nil {
+			return client, endpoint.host, retries, nil
+		}
+		v.debug(domain, "dial failed", "host", endpoint.host, "error", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = errors.New("no smtp override")
+	}
+	return nil, "", retries, firstErr
+}
@@ -0,0 +1,76 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestVerify_DomainSMTPOverride(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"})
+
+	// The servers dialed are recorded, the first override is down
+	var mu sync.Mutex
+	var dialed []string
+	dial := v.dialer
+	v.dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, addr)
+		mu.Unlock()
+		if strings.HasPrefix(addr, "down.") {
+			return nil, errors.New("connection refused")
+		}
+		return dial(ctx, network, addr)
+	}
+	v.SetDomainSMTPOverride("Internal.test", "down.internal.test", 2525).
+		SetDomainSMTPOverride("internal.test", "smtp.internal.test", 0)
+
+	// internal.test has no public MX records
+	ret, err := v.Verify("user@internal.test")
+	assert.NoError(t, err)
+	assert.False(t, ret.HasMxRecords)
+	if assert.NotNil(t, ret.SMTP) {
+		assert.True(t, ret.SMTP.HostExists)
+		assert.True(t, ret.SMTP.MXOverride)
+	}
+	assert.Equal(t, reachableNo, ret.Reachable)
+	assert.Equal(t, []string{
+		"down.internal.test:2525", "smtp.internal.test:25",
+		"down.internal.test:2525", "smtp.internal.test:25",
+	}, dialed)
+
+	ret, err = v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.SMTP.MXOverride)
+
+	// The allowlist takes precedence over the overrides
+	v.SetDomainAllowlist([]string{"internal.test"}, reachableYes)
+	ret, err = v.Verify("user@internal.test")
+	assert.NoError(t, err)
+	assert.Equal(t, SkipReasonAllowlisted, ret.SkipReason)
+	v.SetDomainAllowlist(nil, reachableYes)
+
+	v.ClearDomainSMTPOverrides("internal.test")
+	_, err = v.Verify("user@internal.test")
+	assert.Error(t, err)
+}
+
+func TestWithDomainSMTPOverride(t *testing.T) {
+	v, err := NewVerifierWithOptions(WithDomainSMTPOverride("example.com", "mx.internal", 587))
+	assert.NoError(t, err)
+	assert.Equal(t, []smtpEndpoint{{host: "mx.internal", port: 587}}, v.smtpOverride("EXAMPLE.com."))
+	assert.Nil(t, v.smtpOverride("example.org"))
+
+	_, err = NewVerifierWithOptions(WithDomainSMTPOverride("", "mx.internal", 25))
+	assert.Error(t, err)
+	_, err = NewVerifierWithOptions(WithDomainSMTPOverride("example.com", "", 25))
+	assert.Error(t, err)
+	_, err = NewVerifierWithOptions(WithDomainSMTPOverride("example.com", "mx.internal", 70000))
+	assert.Error(t, err)
+}
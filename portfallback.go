@@ -0,0 +1,62 @@
+package emailverifier
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// EnableSMTPPortFallback makes the smtp check dial an MX host on port 465 with implicit TLS, then on port 587,
+// when the connection to its port 25 times out, see WithSMTPPortFallback
+func (v *Verifier) EnableSMTPPortFallback() *Verifier {
+	return v.apply(WithSMTPPortFallback())
+}
+
+// DisableSMTPPortFallback makes the smtp check only dial the MX hosts on their port, which is the default
+func (v *Verifier) DisableSMTPPortFallback() *Verifier {
+	return v.apply(WithoutSMTPPortFallback())
+}
+
+// dialMXHost connects to host, an MX host of domain, on the first port of the mail servers which accepts
+// the connection. With the port fallback, a connection to port 25 which times out is made to port 465
+// with implicit TLS, then to port 587, instead. It returns the reconnections to host which reset the connection
+// before its banner, and the failure to connect to the first port if the other ports fail too.
+// ctx interrupts the dial, e.g. once another MX host answered.
+func (v *Verifier) dialMXHost(ctx context.Context, domain, host string) (*smtp.Client, int, error) {
+	start := time.Now()
+	client, retries, err := v.dialMXHostPorts(ctx, domain, host)
+	v.recordMXDial(ctx, host, time.Since(start), err)
+	return client, retries, err
+}
+
+// dialMXHostPorts performs dialMXHost without recording the health of host
+func (v *Verifier) dialMXHostPorts(ctx context.Context, domain, host string) (*smtp.Client, int, error) {
+	ports := v.ports()
+	fallback := v.portFallback && len(ports) == 1 && ports[0] == smtpPort && !v.implicitTLS
+	if fallback {
+		ports = []int{smtpPort, tlsSMTPPort, submissionPort}
+	}
+
+	var firstErr error
+	var retries int
+	for i, port := range ports {
+		client, n, err := v.dialHost(ctx, domain, host, net.JoinHostPort(host, strconv.Itoa(port)), v.implicitTLSConfigOf(port))
+		retries += n
+		if err == nil {
+			return client, retries, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		// the fallback only replaces a port 25 which times out
+		if fallback && hostUnreachableReason(firstErr) != UnreachableConnectTimeout || ctx.Err() != nil {
+			break
+		}
+		if i < len(ports)-1 {
+			v.debug(domain, "dial failed, dialing the next port", "host", host, "port", port, "next_port", ports[i+1], "error", err)
+		}
+	}
+	return nil, retries, firstErr
+}
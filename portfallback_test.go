@@ -0,0 +1,124 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// blockedPortDialer dials srv except on port 25, whose connections time out like on a network blocking it,
+// and on the refused ports. It records the dialed addresses.
+type blockedPortDialer struct {
+	srv     *smtptest.Server
+	refused []string
+
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (d *blockedPortDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	d.addrs = append(d.addrs, addr)
+	d.mu.Unlock()
+	_, port, _ := net.SplitHostPort(addr)
+	if port == "25" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	for _, refused := range d.refused {
+		if port == refused {
+			return nil, errors.New("connection refused")
+		}
+	}
+	return d.srv.Dial(ctx, network, addr)
+}
+
+func (d *blockedPortDialer) dialed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.addrs...)
+}
+
+func TestCheckSMTP_PortFallbackImplicitTLS(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{ImplicitTLS: true}, "example.com")
+	defer srv.Close()
+	d := &blockedPortDialer{srv: srv}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, nil, WithDialer(d.dial), WithResolver(srv.Resolver()),
+		WithSMTPDialTimeout(50*time.Millisecond), WithTLSConfig(trustCertificate(srv)), WithSMTPPortFallback())
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	assert.True(t, smtp.ImplicitTLS)
+	assert.Equal(t, []string{"mx.example.com.:25", "mx.example.com.:465"}, d.dialed()[:2])
+}
+
+func TestCheckSMTP_PortFallbackSubmission(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	d := &blockedPortDialer{srv: srv, refused: []string{"465"}}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, nil, WithDialer(d.dial), WithResolver(srv.Resolver()),
+		WithSMTPDialTimeout(50*time.Millisecond), WithSMTPPortFallback())
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	assert.False(t, smtp.ImplicitTLS)
+	assert.Equal(t, []string{"mx.example.com.:25", "mx.example.com.:465", "mx.example.com.:587"}, d.dialed()[:3])
+}
+
+func TestCheckSMTP_PortFallbackFailed(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	d := &blockedPortDialer{srv: srv, refused: []string{"465", "587"}}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, nil, WithDialer(d.dial), WithResolver(srv.Resolver()),
+		WithSMTPDialTimeout(50*time.Millisecond), WithSMTPPortFallback())
+
+	// the failure is the one of port 25
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, UnreachableConnectTimeout, smtp.HostUnreachableReason)
+}
+
+func TestCheckSMTP_PortFallbackDisabled(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	d := &blockedPortDialer{srv: srv}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, nil, WithDialer(d.dial), WithResolver(srv.Resolver()),
+		WithSMTPDialTimeout(50*time.Millisecond), WithSMTPPortFallback())
+	v.DisableSMTPPortFallback()
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, UnreachableConnectTimeout, smtp.HostUnreachableReason)
+	for _, addr := range d.dialed() {
+		assert.True(t, strings.HasSuffix(addr, ":25"), addr)
+	}
+}
+
+func TestCheckSMTP_SMTPPorts(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer srv.Close()
+	d := &blockedPortDialer{srv: srv, refused: []string{"2525"}}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, nil, WithDialer(d.dial), WithResolver(srv.Resolver()),
+		WithSMTPPorts(2525, 2526))
+
+	// the next port is dialed when the previous one refuses the connection
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	assert.Equal(t, []string{"mx.example.com.:2525", "mx.example.com.:2526"}, d.dialed())
+
+	v.apply(WithSMTPDryRun())
+	smtp, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mx.example.com.:2525", "mx.example.com.:2526"}, smtp.Plan.Hosts)
+}
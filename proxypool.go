@@ -0,0 +1,272 @@
+package emailverifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/smtp"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProxySelection controls which proxy of the pool a connection to a mail server goes through
+type ProxySelection int
+
+const (
+	// ProxyRoundRobin uses the proxies of the pool in turn (default)
+	ProxyRoundRobin ProxySelection = iota
+	// ProxyRandom uses a random proxy of the pool for each connection
+	ProxyRandom
+)
+
+// ProxyStats describes a proxy of the pool, see Verifier.ProxyStats
+type ProxyStats struct {
+	URI          string    // proxy URI, without its password
+	Connections  uint64    // connections to mail servers made through the proxy
+	Failures     uint64    // connections which failed because the proxy couldn't be reached
+	EvictedUntil time.Time // time until which the proxy is only used once the others failed, zero if it isn't evicted
+}
+
+// proxyPool is the pool of the SOCKS proxies the connections to the mail servers go through,
+// shared by the verifiers using it
+type proxyPool struct {
+	mu      sync.Mutex
+	proxies []*poolProxy
+	next    int // index of the next proxy of the round-robin selection
+	now     func() time.Time
+}
+
+// poolProxy is a proxy of the pool
+type poolProxy struct {
+	uri          string
+	connections  uint64
+	failures     uint64
+	consecutive  int       // failures since the last connection the proxy made or its last eviction
+	evictedUntil time.Time // time until which the proxy is evicted
+}
+
+func newProxyPool(uris ...string) *proxyPool {
+	p := &proxyPool{now: time.Now}
+	for _, uri := range uris {
+		p.proxies = append(p.proxies, &poolProxy{uri: uri})
+	}
+	return p
+}
+
+// add adds the proxy at uri to the pool, unless it's in the pool already
+func (p *proxyPool) add(uri string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, proxy := range p.proxies {
+		if proxy.uri == uri {
+			return
+		}
+	}
+	p.proxies = append(p.proxies, &poolProxy{uri: uri})
+}
+
+// uris returns the URIs of the proxies of the pool
+func (p *proxyPool) uris() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	uris := make([]string, len(p.proxies))
+	for i, proxy := range p.proxies {
+		uris[i] = proxy.uri
+	}
+	return uris
+}
+
+// pick returns the URI of the proxy a connection goes through by selection, except the tried ones.
+// The evicted proxies are only picked once every other proxy was tried, the one evicted the longest ago first.
+// It returns false once every proxy was tried.
+func (p *proxyPool) pick(selection ProxySelection, tried map[string]bool) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var available []*poolProxy
+	var evicted *poolProxy
+	for _, proxy := range p.proxies {
+		switch {
+		case tried[proxy.uri]:
+		case now.Before(proxy.evictedUntil):
+			if evicted == nil || proxy.evictedUntil.Before(evicted.evictedUntil) {
+				evicted = proxy
+			}
+		default:
+			available = append(available, proxy)
+		}
+	}
+	if len(available) == 0 {
+		if evicted == nil {
+			return "", false
+		}
+		return evicted.uri, true
+	}
+
+	if selection == ProxyRandom {
+		return available[rand.Intn(len(available))].uri, true
+	}
+	proxy := available[p.next%len(available)]
+	p.next++
+	return proxy.uri, true
+}
+
+// record records a connection through the proxy at uri which failed with err, unless it's nil.
+// A proxy which can't be reached proxyMaxFailures times in a row is evicted for proxyEvictionTime.
+func (p *proxyPool) record(uri string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, proxy := range p.proxies {
+		if proxy.uri != uri {
+			continue
+		}
+		switch {
+		case err == nil:
+			proxy.connections++
+			proxy.consecutive = 0
+			proxy.evictedUntil = time.Time{}
+		case isProxyDown(err):
+			proxy.failures++
+			proxy.consecutive++
+			if proxy.consecutive >= proxyMaxFailures {
+				proxy.consecutive = 0
+				proxy.evictedUntil = p.now().Add(proxyEvictionTime)
+			}
+		}
+		return
+	}
+}
+
+// stats returns the stats of the proxies of the pool
+func (p *proxyPool) stats() []ProxyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	stats := make([]ProxyStats, len(p.proxies))
+	for i, proxy := range p.proxies {
+		stats[i] = ProxyStats{URI: redactURI(proxy.uri), Connections: proxy.connections, Failures: proxy.failures}
+		if now.Before(proxy.evictedUntil) {
+			stats[i].EvictedUntil = proxy.evictedUntil
+		}
+	}
+	return stats
+}
+
+// isProxyDown reports whether err is the failure to reach the proxy itself,
+// as opposed to the failure of the proxy to reach the mail server
+func isProxyDown(err error) bool {
+	if hostUnreachableReason(err) != UnreachableProxyError {
+		return false
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// redactURI returns uri without the password of its user
+func redactURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.User == nil {
+		return uri
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
+// validateProxyURI checks that the proxy URI has the socks5, socks4, socks4a, http or https scheme and a host
+func validateProxyURI(proxyURI string) error {
+	u, err := url.Parse(proxyURI)
+	if err != nil {
+		return errors.New("invalid proxy URI")
+	}
+	switch u.Scheme {
+	case "socks5", "socks4", "socks4a", "http", "https":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("proxy URI without host")
+	}
+	// the SOCKS5 authentication takes a user and a password of at most 255 bytes
+	if u.User != nil && u.Scheme == "socks5" {
+		password, _ := u.User.Password()
+		if u.User.Username() == "" || password == "" || len(u.User.Username()) > 255 || len(password) > 255 {
+			return errors.New("invalid proxy user name or password")
+		}
+	}
+	return nil
+}
+
+// AddProxy adds a SOCKS proxy to the pool the connections to the mail servers go through, in the format of Proxy.
+// The connections use the proxies of the pool in turn, see SetProxySelection, and a connection whose proxy
+// can't be reached is made through another proxy. A proxy which can't be reached 3 times in a row is evicted
+// for a minute, during which it's only used once the other proxies failed.
+func (v *Verifier) AddProxy(proxyURI string) error {
+	if err := validateProxyURI(proxyURI); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.proxies == nil {
+		v.proxies = newProxyPool()
+	}
+	v.proxies.add(proxyURI)
+	return nil
+}
+
+// SetProxySelection sets which proxy of the pool a connection goes through, defaults to ProxyRoundRobin
+func (v *Verifier) SetProxySelection(selection ProxySelection) *Verifier {
+	return v.apply(WithProxySelection(selection))
+}
+
+// ProxyStats returns the stats of the proxies of the pool, nil without a proxy
+func (v *Verifier) ProxyStats() []ProxyStats {
+	v = v.snapshot()
+	if v.proxies == nil {
+		return nil
+	}
+	return v.proxies.stats()
+}
+
+// dialProxied dials the mail server of domain at addr like dialSMTP, through a proxy of the pool if any.
+// A connection whose proxy can't be reached is made through the next proxy, until every proxy was tried.
+// ctx, derived from the context of v, interrupts the dial.
+func (v *Verifier) dialProxied(ctx context.Context, domain, addr string, tlsConfig *tls.Config) (*smtp.Client, error) {
+	if v.proxies == nil {
+		return dialSMTP(v.connContext(), ctx, addr, "", v.smtpTimeout, v.smtpCommandTimeout, v.mailDialer(), tlsConfig, v.greeting)
+	}
+
+	tried := make(map[string]bool)
+	var firstErr error
+	for {
+		proxyURI, ok := v.proxies.pick(v.proxySelection, tried)
+		if !ok {
+			if firstErr == nil {
+				firstErr = unreachable(UnreachableProxyError, errors.New("no proxy"))
+			}
+			return nil, firstErr
+		}
+		client, err := dialSMTP(v.connContext(), ctx, addr, proxyURI, v.smtpTimeout, v.smtpCommandTimeout, v.dialer, tlsConfig, v.greeting)
+		v.proxies.record(proxyURI, err)
+		if err == nil || !isProxyDown(err) || ctx.Err() != nil {
+			return client, err
+		}
+		v.debug(domain, "proxy unreachable, dialing through the next proxy", "error", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+		tried[proxyURI] = true
+	}
+}
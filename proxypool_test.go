@@ -0,0 +1,220 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// newSOCKS5Proxy starts a SOCKS5 proxy connecting every request to srv, and returns its URI.
+// The proxy requires the user and password unless user is empty.
+func newSOCKS5Proxy(t *testing.T, srv *smtptest.Server, user, password string) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, srv, user, password)
+		}
+	}()
+	return "socks5://" + ln.Addr().String()
+}
+
+func serveSOCKS5(conn net.Conn, srv *smtptest.Server, user, password string) {
+	defer conn.Close()
+	// the method selection, the authentication with a user and a password,
+	// then the request of a domain name, whose length is the 5th byte
+	greeting := make([]byte, 3)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, greeting[2]}); err != nil {
+		return
+	}
+	if user != "" {
+		u, p, err := readSOCKS5Auth(conn)
+		if err != nil || greeting[2] != 2 || u != user || p != password {
+			conn.Write([]byte{1, 1})
+			return
+		}
+		if _, err := conn.Write([]byte{1, 0}); err != nil {
+			return
+		}
+	}
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	addr := make([]byte, int(header[4])+2)
+	if _, err := io.ReadFull(conn, addr); err != nil {
+		return
+	}
+	target, err := srv.Dial(context.Background(), "tcp", "")
+	if err != nil {
+		return
+	}
+	defer target.Close()
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+	// the client reads the reply in a single read, which mustn't get the banner of the server too
+	time.Sleep(20 * time.Millisecond)
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+// readSOCKS5Auth reads the version 1 user and password authentication of a SOCKS5 client
+func readSOCKS5Auth(conn net.Conn) (string, string, error) {
+	var fields [2]string
+	header := make([]byte, 1)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", "", err
+	}
+	if header[0] != 1 {
+		return "", "", errors.New("unsupported authentication version")
+	}
+	for i := range fields {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return "", "", err
+		}
+		b := make([]byte, int(header[0]))
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return "", "", err
+		}
+		fields[i] = string(b)
+	}
+	return fields[0], fields[1], nil
+}
+
+func TestProxyPool_Selection(t *testing.T) {
+	p := newProxyPool("socks5://a:1080", "socks5://b:1080")
+
+	var picked []string
+	for i := 0; i < 3; i++ {
+		uri, ok := p.pick(ProxyRoundRobin, nil)
+		assert.True(t, ok)
+		picked = append(picked, uri)
+	}
+	assert.Equal(t, []string{"socks5://a:1080", "socks5://b:1080", "socks5://a:1080"}, picked)
+
+	uri, ok := p.pick(ProxyRandom, map[string]bool{"socks5://a:1080": true})
+	assert.True(t, ok)
+	assert.Equal(t, "socks5://b:1080", uri)
+
+	_, ok = p.pick(ProxyRoundRobin, map[string]bool{"socks5://a:1080": true, "socks5://b:1080": true})
+	assert.False(t, ok)
+}
+
+func TestProxyPool_Eviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newProxyPool("socks5://user:secret@a:1080", "socks5://b:1080")
+	p.now = func() time.Time { return now }
+	down := unreachable(UnreachableProxyError, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+
+	// the failures of the proxy to reach the mail server don't count
+	p.record("socks5://user:secret@a:1080", unreachable(UnreachableProxyError, errors.New("can't complete SOCKS5 connection")))
+	for i := 0; i < proxyMaxFailures; i++ {
+		p.record("socks5://user:secret@a:1080", down)
+	}
+	for i := 0; i < 2; i++ {
+		uri, _ := p.pick(ProxyRoundRobin, nil)
+		assert.Equal(t, "socks5://b:1080", uri)
+	}
+	// an evicted proxy is used once the others failed
+	uri, ok := p.pick(ProxyRoundRobin, map[string]bool{"socks5://b:1080": true})
+	assert.True(t, ok)
+	assert.Equal(t, "socks5://user:secret@a:1080", uri)
+
+	stats := p.stats()
+	assert.Equal(t, "socks5://user:xxxxx@a:1080", stats[0].URI)
+	assert.Equal(t, uint64(proxyMaxFailures), stats[0].Failures)
+	assert.Equal(t, now.Add(proxyEvictionTime), stats[0].EvictedUntil)
+
+	// the proxy is back once its eviction ends
+	now = now.Add(proxyEvictionTime)
+	assert.True(t, p.stats()[0].EvictedUntil.IsZero())
+}
+
+func TestCheckSMTP_ProxyFailover(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadURI := "socks5://" + dead.Addr().String()
+	dead.Close()
+	v.apply(WithProxies(deadURI, newSOCKS5Proxy(t, srv, "", "")))
+
+	// the connection through the unreachable proxy is made through the other one
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+
+	stats := v.ProxyStats()
+	assert.Equal(t, uint64(1), stats[0].Failures)
+	assert.Equal(t, uint64(1), stats[1].Connections)
+
+	assert.NoError(t, v.AddProxy("socks5://127.0.0.1:1081"))
+	assert.Error(t, v.AddProxy("ftp://127.0.0.1:8080"))
+	assert.Len(t, v.ProxyStats(), 3)
+}
+
+func TestCheckSMTP_SOCKS5Authentication(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithCatchAllCacheTTL(0))
+	uri := newSOCKS5Proxy(t, srv, "user", "p@ss")
+
+	// the credentials of the URI are percent-encoded
+	v.Proxy(strings.Replace(uri, "socks5://", "socks5://user:p%40ss@", 1))
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+
+	v.Proxy(strings.Replace(uri, "socks5://", "socks5://user:wrong@", 1))
+	smtp, err = v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, UnreachableProxyError, smtp.HostUnreachableReason)
+	assert.Equal(t, 1, srv.Connections())
+}
+
+func TestEstablishProxyConnection_SOCKSCanceled(t *testing.T) {
+	// The proxy accepts the connections but never answers the method selection
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		select {
+		case conn := <-accepted:
+			conn.Close()
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = establishProxyConnection(ctx, "mx.example.com:25", "socks5://"+ln.Addr().String())
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -0,0 +1,252 @@
+// Package rediscache implements the emailverifier.Cache interface with Redis, so the lookups and the verifications
+// of a Verifier are shared by the processes using the same Redis server. It speaks the Redis protocol itself,
+// without a dependency on a client library, and only uses the GET, SET, DEL, AUTH and SELECT commands.
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of the Options
+const (
+	DefaultDialTimeout = 5 * time.Second
+	DefaultPoolSize    = 10
+)
+
+// Options configure the connections of a Cache
+type Options struct {
+	Password    string        // password of the AUTH command, none if empty
+	DB          int           // database selected by the SELECT command, the default one if zero
+	DialTimeout time.Duration // timeout of connecting to the server, DefaultDialTimeout if zero
+	PoolSize    int           // idle connections kept for later commands, DefaultPoolSize if zero
+}
+
+// Error is an error reply of the Redis server
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// errClosed is returned by the commands of a closed cache
+var errClosed = errors.New("redis: cache closed")
+
+// Cache is an emailverifier.Cache stored in Redis. Its methods are called concurrently,
+// each command uses a connection of its own, taken from a pool of idle connections.
+type Cache struct {
+	addr string
+	opts Options
+
+	mu     sync.Mutex
+	idle   []*conn // idle connections, the most recently used last
+	closed bool
+}
+
+// conn is a connection to the Redis server
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// New returns a cache stored in the Redis server at addr, like "localhost:6379".
+// It connects on the first command, so an unreachable server only fails the commands.
+func New(addr string, opts Options) *Cache {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DefaultPoolSize
+	}
+	return &Cache{addr: addr, opts: opts}
+}
+
+// Get returns the value of key and whether it was found, Redis expires the values itself
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.do(ctx, "GET", []byte(key))
+	if err != nil {
+		return nil, false, err
+	}
+	if value == nil {
+		return nil, false, nil
+	}
+	return value.([]byte), true, nil
+}
+
+// Set stores the value of key for ttl rounded up to the millisecond, without expiry if ttl isn't positive
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := [][]byte{[]byte(key), value}
+	if ttl > 0 {
+		ms := (ttl + time.Millisecond - 1) / time.Millisecond
+		args = append(args, []byte("PX"), []byte(strconv.FormatInt(int64(ms), 10)))
+	}
+	_, err := c.do(ctx, "SET", args...)
+	return err
+}
+
+// Delete removes key
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, "DEL", []byte(key))
+	return err
+}
+
+// Close closes the idle connections, the commands of a closed cache fail
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	var err error
+	for _, cn := range idle {
+		if e := cn.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// do sends the command cmd with args and returns its reply: nil, an int64, a string or a []byte.
+// An error reply is an Error, after which the connection is reused, any other failure closes it.
+func (c *Cache) do(ctx context.Context, cmd string, args ...[]byte) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(ctx, cmd, args...)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// get returns an idle connection, or a new one authenticated and on the database of the options
+func (c *Cache) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	d := net.Dialer{Timeout: c.opts.DialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	if c.opts.Password != "" {
+		if _, err := cn.do(ctx, "AUTH", []byte(c.opts.Password)); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	if c.opts.DB != 0 {
+		if _, err := cn.do(ctx, "SELECT", []byte(strconv.Itoa(c.opts.DB))); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// put returns cn to the idle connections, or closes it when the pool is full or the cache closed
+func (c *Cache) put(cn *conn) {
+	c.mu.Lock()
+	if c.closed || len(c.idle) >= c.opts.PoolSize {
+		c.mu.Unlock()
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+	c.mu.Unlock()
+}
+
+// do writes the command cmd with args and reads its reply, until the deadline of ctx if any
+func (cn *conn) do(ctx context.Context, cmd string, args ...[]byte) (interface{}, error) {
+	deadline, _ := ctx.Deadline()
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// the command is an array of bulk strings
+	b := make([]byte, 0, 64)
+	b = append(b, '*')
+	b = strconv.AppendInt(b, int64(len(args)+1), 10)
+	b = append(b, '\r', '\n')
+	for _, arg := range append([][]byte{[]byte(cmd)}, args...) {
+		b = append(b, '$')
+		b = strconv.AppendInt(b, int64(len(arg)), 10)
+		b = append(b, '\r', '\n')
+		b = append(b, arg...)
+		b = append(b, '\r', '\n')
+	}
+	if _, err := cn.Write(b); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+// readReply reads a reply of the Redis protocol from r. The arrays, which the commands of the cache
+// don't return, are unsupported.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk string length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		value := make([]byte, n+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		return value[:n], nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// readLine reads a line of a reply from r, without its CRLF
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: invalid reply line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
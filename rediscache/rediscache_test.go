@@ -0,0 +1,270 @@
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	emailVerifier "github.com/vikt0r0/email-verifier"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+var _ emailVerifier.Cache = (*Cache)(nil)
+
+// fakeServer is a Redis server of the commands of the cache, storing the values in memory
+type fakeServer struct {
+	ln       net.Listener
+	password string
+
+	mu          sync.Mutex
+	values      map[string]string
+	expires     map[string]time.Time
+	commands    []string
+	connections int
+}
+
+// newFakeServer starts a server requiring password, none if empty
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln, password: password, values: map[string]string{}, expires: map[string]time.Time{}}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.connections++
+			s.mu.Unlock()
+			go s.serve(nc)
+		}
+	}()
+	return s
+}
+
+// received returns the commands the server received and its number of connections
+func (s *fakeServer) received() ([]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), s.connections
+}
+
+func (s *fakeServer) serve(nc net.Conn) {
+	defer nc.Close()
+	r := bufio.NewReader(nc)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			authenticated = args[1] == s.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			reply = "+OK\r\n"
+		case args[0] == "GET":
+			value, ok := s.values[args[1]]
+			if expires, ok := s.expires[args[1]]; ok && time.Now().After(expires) {
+				value = ""
+				delete(s.values, args[1])
+				delete(s.expires, args[1])
+			}
+			reply = "$-1\r\n"
+			if ok && value != "" {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			}
+		case args[0] == "SET":
+			s.values[args[1]] = args[2]
+			delete(s.expires, args[1])
+			if len(args) == 5 && args[3] == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			reply = "+OK\r\n"
+		case args[0] == "DEL":
+			_, ok := s.values[args[1]]
+			delete(s.values, args[1])
+			reply = ":0\r\n"
+			if ok {
+				reply = ":1\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		if _, err := io.WriteString(nc, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(line, "*"))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		arg, err := readReply(r)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = string(arg.([]byte))
+	}
+	return args, nil
+}
+
+func TestCache_GetSetDelete(t *testing.T) {
+	srv := newFakeServer(t, "")
+	c := New(srv.ln.Addr().String(), Options{})
+	defer c.Close()
+	ctx := context.Background()
+
+	_, ok, err := c.Get(ctx, "emailverifier:mx:example.com")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the values are binary safe
+	value := []byte("line\r\nother line")
+	assert.NoError(t, c.Set(ctx, "emailverifier:mx:example.com", value, time.Hour))
+	got, ok, err := c.Get(ctx, "emailverifier:mx:example.com")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, value, got)
+
+	assert.NoError(t, c.Delete(ctx, "emailverifier:mx:example.com"))
+	_, ok, err = c.Get(ctx, "emailverifier:mx:example.com")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	commands, connections := srv.received()
+	assert.Equal(t, []string{
+		"GET emailverifier:mx:example.com",
+		"SET emailverifier:mx:example.com line\r\nother line PX 3600000",
+		"GET emailverifier:mx:example.com",
+		"DEL emailverifier:mx:example.com",
+		"GET emailverifier:mx:example.com",
+	}, commands)
+	// the connection is reused
+	assert.Equal(t, 1, connections)
+}
+
+func TestCache_Expiry(t *testing.T) {
+	srv := newFakeServer(t, "")
+	c := New(srv.ln.Addr().String(), Options{})
+	defer c.Close()
+	ctx := context.Background()
+
+	// the TTL is rounded up to the millisecond
+	assert.NoError(t, c.Set(ctx, "key", []byte("value"), time.Microsecond))
+	commands, _ := srv.received()
+	assert.Equal(t, "SET key value PX 1", commands[0])
+	time.Sleep(5 * time.Millisecond)
+	_, ok, err := c.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Set(ctx, "key", []byte("value"), 0))
+	commands, _ = srv.received()
+	assert.Equal(t, "SET key value", commands[2])
+}
+
+func TestCache_AuthAndDB(t *testing.T) {
+	srv := newFakeServer(t, "secret")
+	c := New(srv.ln.Addr().String(), Options{Password: "secret", DB: 2})
+	defer c.Close()
+
+	assert.NoError(t, c.Set(context.Background(), "key", []byte("value"), time.Minute))
+	commands, _ := srv.received()
+	assert.Equal(t, []string{"AUTH secret", "SELECT 2", "SET key value PX 60000"}, commands)
+
+	c = New(srv.ln.Addr().String(), Options{Password: "wrong"})
+	defer c.Close()
+	_, _, err := c.Get(context.Background(), "key")
+	assert.Equal(t, Error("WRONGPASS invalid password"), err)
+}
+
+func TestCache_Errors(t *testing.T) {
+	srv := newFakeServer(t, "secret")
+	c := New(srv.ln.Addr().String(), Options{})
+
+	// an error reply keeps the connection
+	_, _, err := c.Get(context.Background(), "key")
+	assert.EqualError(t, err, "redis: NOAUTH Authentication required.")
+	_, _, err = c.Get(context.Background(), "key")
+	assert.Error(t, err)
+	_, connections := srv.received()
+	assert.Equal(t, 1, connections)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = c.Get(ctx, "key")
+	assert.Equal(t, context.Canceled, err)
+
+	assert.NoError(t, c.Close())
+	_, _, err = c.Get(context.Background(), "key")
+	assert.Equal(t, errClosed, err)
+
+	// an unreachable server fails the commands
+	srv.ln.Close()
+	c = New(srv.ln.Addr().String(), Options{DialTimeout: time.Second})
+	_, _, err = c.Get(context.Background(), "key")
+	assert.Error(t, err)
+}
+
+func TestCache_Verifier(t *testing.T) {
+	srv := newFakeServer(t, "")
+	c := New(srv.ln.Addr().String(), Options{})
+	defer c.Close()
+	smtp := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	defer smtp.Close()
+
+	newVerifier := func() *emailVerifier.Verifier {
+		v, err := emailVerifier.NewVerifierWithOptions(emailVerifier.WithSMTPCheck(), emailVerifier.WithDialer(smtp.Dial),
+			emailVerifier.WithResolver(smtp.Resolver()), emailVerifier.WithCache(c), emailVerifier.WithResultCacheTTL(time.Hour))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return v
+	}
+
+	ret, err := newVerifier().Verify("user@example.com")
+	assert.NoError(t, err)
+	connections := smtp.Connections()
+
+	// another verifier, like one of another process, gets the verification from the cache
+	v := newVerifier()
+	cached, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, ret.Reachable, cached.Reachable)
+	assert.Equal(t, connections, smtp.Connections())
+	assert.Equal(t, uint64(1), v.Stats().Caches[emailVerifier.CacheKindResult].Hits)
+}
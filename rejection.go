@@ -0,0 +1,119 @@
+package emailverifier
+
+import (
+	"errors"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reasons of the rejection of the RCPT of an address, see SMTP.RejectReason
+const (
+	RejectUserUnknown    = "user_unknown"    // the recipient doesn't exist or its mailbox is disabled
+	RejectMailboxFull    = "mailbox_full"    // the mailbox of the recipient is over its quota
+	RejectPolicyBlock    = "policy_block"    // the server refuses the probe by policy, e.g. a block list of the client IP
+	RejectSenderRejected = "sender_rejected" // the server refuses the sender, e.g. the domain of the from email
+	RejectUnknown        = "unknown"         // the server rejected the recipient for another reason
+)
+
+// enhancedStatusCode matches the enhanced status code of an SMTP reply, like "5.1.1" (RFC 3463)
+var enhancedStatusCode = regexp.MustCompile(`\b([245])\.(\d{1,3})\.(\d{1,3})\b`)
+
+// classifyRejection returns the reason of the rejection of a RCPT by the reply err and whether it reports
+// the mailbox of the recipient as disabled. The reason is empty if err isn't a rejection:
+// an error which isn't a reply, or a transient reply other than a full mailbox, like greylisting.
+func classifyRejection(err error) (reason string, disabled bool) {
+	code, message := replyCode(err)
+	if code < 400 {
+		return "", false
+	}
+
+	// The enhanced status code of the mailbox and the sender is the most reliable
+	enhanced := "x.0.0"
+	if match := enhancedStatusCode.FindStringSubmatch(message); match != nil {
+		enhanced = "x." + match[2] + "." + match[3]
+	}
+	switch {
+	case enhanced == "x.2.2" || code == 552 || insContains(message,
+		"mailbox full",
+		"mailbox is full",
+		"inbox full",
+		"inbox is full",
+		"account is full",
+		"over quota",
+		"quota exceeded",
+		"insufficient storage",
+		"out of storage"):
+		return RejectMailboxFull, false
+	case code < 500:
+		return "", false
+	case enhanced == "x.1.7" || enhanced == "x.1.8":
+		return RejectSenderRejected, false
+	case enhanced == "x.2.1":
+		return RejectUserUnknown, true
+	case enhanced == "x.1.1" || enhanced == "x.1.10":
+		return RejectUserUnknown, false
+	}
+
+	switch {
+	case insContains(message, "sender", "mail from", "return path"):
+		return RejectSenderRejected, false
+	case insContains(message, "disabled", "deactivated", "inactive", "suspended"):
+		return RejectUserUnknown, true
+	case insContains(message,
+		"undeliverable",
+		"does not exist",
+		"doesn't exist",
+		"doesn't have",
+		"may not exist",
+		"user unknown",
+		"unknown user",
+		"user not found",
+		"no such user",
+		"invalid address",
+		"invalid recipient",
+		"recipient invalid",
+		"recipient rejected",
+		"address rejected",
+		"mailbox unavailable",
+		"mailbox not found",
+		"no mailbox"):
+		return RejectUserUnknown, false
+	case strings.HasPrefix(enhanced, "x.7.") || insContains(message,
+		"spamhaus",
+		"proofpoint",
+		"cloudmark",
+		"banned",
+		"blacklisted",
+		"blocked",
+		"block list",
+		"blocklist",
+		"denied",
+		"policy",
+		"spam",
+		"reputation"):
+		return RejectPolicyBlock, false
+	}
+	return RejectUnknown, false
+}
+
+// replyCode returns the code and the message of the SMTP reply err, a zero code if err isn't a reply
+func replyCode(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code, reply.Msg
+	}
+	message := err.Error()
+	if len(message) < 3 {
+		return 0, message
+	}
+	code, convErr := strconv.Atoi(message[:3])
+	if convErr != nil {
+		return 0, message
+	}
+	return code, message[3:]
+}
@@ -0,0 +1,57 @@
+package emailverifier
+
+import (
+	"errors"
+	"io"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyRejection(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     int
+		msg      string
+		reason   string
+		disabled bool
+	}{
+		{"gmail user unknown", 550, "5.1.1 The email account that you tried to reach does not exist. Please try\n5.1.1 double-checking the recipient's email address for typos or\n5.1.1 unnecessary spaces. https://support.google.com/mail/?p=NoSuchUser", RejectUserUnknown, false},
+		{"gmail disabled", 550, "5.2.1 The email account that you tried to reach is disabled. https://support.google.com/mail/?p=DisabledUser", RejectUserUnknown, true},
+		{"gmail over quota", 452, "4.2.2 The email account that you tried to reach is over quota. Please direct\n4.2.2 the recipient to https://support.google.com/mail/?p=OverQuotaTemp", RejectMailboxFull, false},
+		{"gmail unauthenticated sender", 550, "5.7.26 This mail has been blocked because the sender is unauthenticated.", RejectSenderRejected, false},
+		{"gmail spam", 550, "5.7.1 Our system has detected that this message is likely unsolicited mail. To reduce the amount of spam sent to Gmail, this message has been blocked.", RejectPolicyBlock, false},
+		{"outlook mailbox unavailable", 550, "5.5.0 Requested action not taken: mailbox unavailable (S2017062302). [BN8NAM12FT063.eop-nam12.prod.protection.outlook.com]", RejectUserUnknown, false},
+		{"office 365 directory based edge blocking", 550, "5.4.1 Recipient address rejected: Access denied. AS(201806281) [DM6NAM11FT064.eop-nam11.prod.protection.outlook.com]", RejectUserUnknown, false},
+		{"outlook blocked ip", 550, "5.7.1 Unfortunately, messages from [192.0.2.1] weren't sent. Please contact your Internet service provider since part of their network is on our block list (S3150).", RejectPolicyBlock, false},
+		{"yahoo user unknown", 554, "delivery error: dd This user doesn't have a yahoo.com account (user@yahoo.com) [0] - mta1234.mail.ne1.yahoo.com", RejectUserUnknown, false},
+		{"yahoo spamhaus", 553, "5.7.1 [BL21] Connections will not be accepted from 192.0.2.1, because the ip is in Spamhaus's list; see https://postmaster.yahooinc.com/error-codes", RejectPolicyBlock, false},
+		{"icloud user unknown", 550, "5.1.1 <user@icloud.com>: user does not exist", RejectUserUnknown, false},
+		{"postfix user unknown", 550, "5.1.1 <user@example.com>: Recipient address rejected: User unknown in virtual mailbox table", RejectUserUnknown, false},
+		{"postfix sender domain", 450, "4.1.8 <probe@example.org>: Sender address rejected: Domain not found", "", false},
+		{"postfix sender domain permanent", 553, "5.1.8 <probe@example.org>: Sender address rejected: Domain not found", RejectSenderRejected, false},
+		{"sender blocked", 550, "Sender blocked", RejectSenderRejected, false},
+		{"spamhaus client host", 554, "5.7.1 Service unavailable; Client host [192.0.2.1] blocked using zen.spamhaus.org", RejectPolicyBlock, false},
+		{"mimecast policy", 550, "Rejected by header based Anti-Spoofing policy: user@example.com", RejectPolicyBlock, false},
+		{"mailbox full", 552, "5.2.2 Mailbox full", RejectMailboxFull, false},
+		{"mailbox full without enhanced code", 550, "Mailbox is full", RejectMailboxFull, false},
+		{"greylisted", 451, "4.7.1 Greylisted, try again later", "", false},
+		{"unknown", 550, "Requested action not taken", RejectUnknown, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reason, disabled := classifyRejection(&textproto.Error{Code: c.code, Msg: c.msg})
+			assert.Equal(t, c.reason, reason)
+			assert.Equal(t, c.disabled, disabled)
+		})
+	}
+}
+
+func TestClassifyRejection_NotAReply(t *testing.T) {
+	reason, _ := classifyRejection(io.EOF)
+	assert.Equal(t, "", reason)
+
+	reason, _ = classifyRejection(errors.New("550 5.1.1 User unknown"))
+	assert.Equal(t, RejectUserUnknown, reason)
+}
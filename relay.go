@@ -0,0 +1,87 @@
+package emailverifier
+
+import (
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// smtpRelay is the smart host the smtp checks go through instead of the mail servers, see SetSMTPRelay
+type smtpRelay struct {
+	host   string    // host of the relay
+	port   int       // port of the relay
+	auth   smtp.Auth // authentication of the session, none if nil
+	useTLS bool      // whether the connection is secured, with implicit TLS on port 465 and STARTTLS otherwise
+}
+
+// SetSMTPRelay makes the smtp checks go through the authenticated relay at host and port instead of connecting
+// to the mail servers of the domains, for networks which block outgoing SMTP connections except to a smart host.
+// The relay is asked MAIL and RCPT for the checked addresses, so its recipient verification, often a callout
+// to the mail server of the domain, is the deliverability signal, and the catch-all check probes a random
+// address through it alike. With useTLS the connection uses implicit TLS on port 465 and STARTTLS otherwise,
+// auth, which may be nil, authenticates the session, e.g. smtp.PlainAuth. An empty host disables the relay.
+func (v *Verifier) SetSMTPRelay(host string, port int, auth smtp.Auth, useTLS bool) *Verifier {
+	return v.apply(WithSMTPRelay(host, port, auth, useTLS))
+}
+
+// implicitTLS reports whether the connection to the relay uses implicit TLS
+func (r *smtpRelay) implicitTLS() bool {
+	return r.useTLS && r.port == tlsSMTPPort
+}
+
+// relayTLSConfig returns the TLS config of the connection to the relay, whose server name is the host of the relay
+func (v *Verifier) relayTLSConfig() *tls.Config {
+	config := &tls.Config{}
+	if v.tlsConfig != nil {
+		config = v.tlsConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = v.relay.host
+	}
+	return config
+}
+
+// dialRelay connects to the relay, with implicit TLS if it's configured so,
+// and returns the reconnections to the relay which reset the connection before its banner
+func (v *Verifier) dialRelay(domain string) (*smtp.Client, int, error) {
+	var tlsConfig *tls.Config
+	if v.relay.implicitTLS() {
+		tlsConfig = v.relayTLSConfig()
+	}
+
+	v.debug(domain, "dialing smtp relay", "host", v.relay.host, "proxy", v.proxies != nil)
+	addr := net.JoinHostPort(v.relay.host, strconv.Itoa(v.relay.port))
+	client, retries, err := v.dialHost(v.context(), domain, v.relay.host, addr, tlsConfig)
+	if err != nil {
+		v.debug(domain, "dial failed", "host", v.relay.host, "error", err)
+	}
+	return client, retries, err
+}
+
+// startRelaySession secures the session of client with the relay by STARTTLS, unless it uses implicit TLS,
+// and authenticates it. client has sent EHLO.
+func (v *Verifier) startRelaySession(domain string, client *smtp.Client) error {
+	if v.relay.useTLS && !v.relay.implicitTLS() {
+		if err := client.StartTLS(v.relayTLSConfig()); err != nil {
+			v.debug(domain, "relay starttls failed", "host", v.relay.host, "error", err)
+			return err
+		}
+		v.startedTLS.Store(client, true)
+	}
+	if v.relay.auth != nil {
+		if err := client.Auth(v.relay.auth); err != nil {
+			v.debug(domain, "relay authentication failed", "host", v.relay.host, "reply", replyText(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// relayFor returns the relay of the smtp checks of domain, nil without a relay or with smtp overrides of domain
+func (v *Verifier) relayFor(domain string) *smtpRelay {
+	if v.relay == nil || v.smtpOverride(domain) != nil {
+		return nil
+	}
+	return v.relay
+}
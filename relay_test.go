@@ -0,0 +1,77 @@
+package emailverifier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// newRelayTestVerifier returns a verifier whose smtp checks go through the relay mx.relay.test, with the password,
+// which does the recipient verification of example.com as scripted by b
+func newRelayTestVerifier(t *testing.T, b smtptest.Behavior, password string) (*Verifier, *smtptest.Server) {
+	b.StartTLS = true
+	b.Users = map[string]string{"relay": "secret"}
+	v, srv := newSMTPTestVerifier(t, b, []string{"example.com", "relay.test"})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	v.apply(WithTLSConfig(&tls.Config{RootCAs: roots}))
+	v.SetSMTPRelay("mx.relay.test", 587, smtp.PlainAuth("", "relay", password, "mx.relay.test"), true)
+	return v, srv
+}
+
+func TestVerify_SMTPRelay(t *testing.T) {
+	v, srv := newRelayTestVerifier(t, smtptest.Behavior{
+		Rcpt:        map[string]string{"user@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 Recipient address rejected: verification failed",
+	}, "secret")
+
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableYes, ret.Reachable)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true, STARTTLS: true, Relay: "mx.relay.test", ProbeReliability: ProbeReliabilityUnknown}, ret.SMTP)
+
+	ret, err = v.Verify("nobody@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableNo, ret.Reachable)
+	assert.Equal(t, RejectUserUnknown, ret.SMTP.RejectReason)
+	assert.Equal(t, "mx.relay.test", ret.SMTP.Relay)
+
+	// The session is secured and authenticated before MAIL, and the catch-all check goes through the relay
+	var verbs []string
+	var catchAll bool
+	for _, command := range srv.Commands() {
+		verbs = append(verbs, strings.Fields(command)[0])
+		catchAll = catchAll || strings.HasPrefix(command, "RCPT") && !strings.Contains(command, "user@") &&
+			!strings.Contains(command, "nobody@") && strings.HasSuffix(command, "@example.com>")
+	}
+	assert.Equal(t, []string{"EHLO", "STARTTLS", "EHLO", "AUTH", "MAIL", "RCPT"}, verbs[:6])
+	assert.True(t, catchAll)
+}
+
+func TestCheckSMTP_SMTPRelayAuthFailed(t *testing.T) {
+	v, _ := newRelayTestVerifier(t, smtptest.Behavior{}, "wrong")
+
+	_, err := v.CheckSMTP("example.com", "user")
+	assert.Error(t, err)
+}
+
+func TestWithSMTPRelay(t *testing.T) {
+	v, err := NewVerifierWithOptions(WithSMTPRelay("smtp.corp.test", 465, nil, true))
+	assert.NoError(t, err)
+	if assert.NotNil(t, v.relay) {
+		assert.True(t, v.relay.implicitTLS())
+		assert.Equal(t, "smtp.corp.test", v.relayFor("example.com").host)
+	}
+
+	v.SetSMTPRelay("", 0, nil, false)
+	assert.Nil(t, v.relay)
+
+	_, err = NewVerifierWithOptions(WithSMTPRelay("smtp.corp.test", 0, nil, false))
+	assert.Error(t, err)
+}
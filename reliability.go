@@ -0,0 +1,69 @@
+package emailverifier
+
+import "fmt"
+
+// Reliabilities of the RCPT probe of a mailbox provider, see SMTP.ProbeReliability
+const (
+	ProbeReliabilityHigh    = "high"    // the provider rejects RCPT for nonexistent users
+	ProbeReliabilityLow     = "low"     // the provider accepts RCPT for nonexistent users, a 250 tells nothing
+	ProbeReliabilityUnknown = "unknown" // the provider isn't in the reliability table
+)
+
+// providerProbeReliability is the reliability of the RCPT probe of well-known mailbox providers,
+// besides the providers with an API verifier, whose probe is of low reliability
+var providerProbeReliability = map[string]string{
+	"gmail.com":   ProbeReliabilityHigh,
+	"aol.com":     ProbeReliabilityLow,
+	"msn.com":     ProbeReliabilityLow,
+	"yahoo.co.uk": ProbeReliabilityLow,
+}
+
+// WithDomainProbeReliability sets the reliability of the RCPT probe of the mailbox provider of domain,
+// like SetDomainProbeReliability
+func WithDomainProbeReliability(domain, reliability string) Option {
+	return func(c *config) error {
+		// the map is replaced rather than modified, since snapshots share it
+		probeReliability := make(map[string]string, len(c.domainProbeReliability)+1)
+		for d, r := range c.domainProbeReliability {
+			probeReliability[d] = r
+		}
+		probeReliability[aliasKey(domain)] = reliability
+		c.domainProbeReliability = probeReliability
+
+		switch reliability {
+		case ProbeReliabilityHigh, ProbeReliabilityLow, ProbeReliabilityUnknown:
+			return nil
+		}
+		return fmt.Errorf("invalid probe reliability %q", reliability)
+	}
+}
+
+// SetDomainProbeReliability sets the reliability of the RCPT probe of the mailbox provider of domain,
+// e.g. ProbeReliabilityLow for a provider accepting RCPT for nonexistent users.
+// It takes precedence over the built-in provider table.
+func (v *Verifier) SetDomainProbeReliability(domain, reliability string) *Verifier {
+	return v.apply(WithDomainProbeReliability(domain, reliability))
+}
+
+// probeReliability returns the reliability of the RCPT probe of the mailbox provider of domain,
+// looked up on domain, its canonical domain and their registrable domains
+func (v *Verifier) probeReliability(domain string) string {
+	var keys []string
+	for _, d := range []string{domain, v.canonicalDomain(domain)} {
+		for _, m := range v.matchedDomains(d) {
+			keys = append(keys, aliasKey(m))
+		}
+	}
+	for _, key := range keys {
+		if reliability, ok := v.domainProbeReliability[key]; ok {
+			return reliability
+		}
+		if reliability, ok := providerProbeReliability[key]; ok {
+			return reliability
+		}
+		if _, ok := yahooDomains[key]; ok || outlookDomains[key] {
+			return ProbeReliabilityLow
+		}
+	}
+	return ProbeReliabilityUnknown
+}
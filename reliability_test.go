@@ -0,0 +1,66 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestProbeReliability(t *testing.T) {
+	v := NewVerifier().
+		AddDomainAlias("ymail.example", "yahoo.com").
+		SetDomainProbeReliability("Example.com", ProbeReliabilityLow).
+		SetDomainProbeReliability("hotmail.com", ProbeReliabilityHigh)
+
+	for domain, reliability := range map[string]string{
+		"gmail.com":      ProbeReliabilityHigh,
+		"googlemail.com": ProbeReliabilityHigh,
+		"Yahoo.com":      ProbeReliabilityLow,
+		"rocketmail.com": ProbeReliabilityLow,
+		"outlook.com":    ProbeReliabilityLow,
+		"aol.com":        ProbeReliabilityLow,
+		"ymail.example":  ProbeReliabilityLow,
+		"example.com":    ProbeReliabilityLow,
+		"hotmail.com":    ProbeReliabilityHigh,
+		"example.org":    ProbeReliabilityUnknown,
+		"mx.example.com": ProbeReliabilityLow,
+		"mail.gmail.com": ProbeReliabilityHigh,
+	} {
+		assert.Equal(t, reliability, v.probeReliability(domain), domain)
+	}
+
+	v.EnableExactDomainMatching()
+	assert.Equal(t, ProbeReliabilityUnknown, v.probeReliability("mx.example.com"))
+	assert.Equal(t, ProbeReliabilityLow, v.probeReliability("example.com"))
+}
+
+func TestCalculateReachable_ProbeReliability(t *testing.T) {
+	v := NewVerifier().EnableSMTPCheck()
+
+	assert.Equal(t, reachableYes, v.calculateReachable(&SMTP{HostExists: true, Deliverable: true, ProbeReliability: ProbeReliabilityHigh}))
+	assert.Equal(t, reachableYes, v.calculateReachable(&SMTP{HostExists: true, Deliverable: true, ProbeReliability: ProbeReliabilityUnknown}))
+	assert.Equal(t, reachableUnknown, v.calculateReachable(&SMTP{HostExists: true, Deliverable: true, ProbeReliability: ProbeReliabilityLow}))
+	assert.Equal(t, reachableNo, v.calculateReachable(&SMTP{HostExists: true, RejectReason: RejectUserUnknown, ProbeReliability: ProbeReliabilityLow}))
+}
+
+func TestVerify_LowProbeReliability(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{
+		Rcpt:        map[string]string{"user@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 User unknown",
+	}, []string{"example.com"}, WithDomainProbeReliability("example.com", ProbeReliabilityLow))
+
+	// The 250 is reported but doesn't make the address reachable
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.Deliverable)
+	assert.Equal(t, ProbeReliabilityLow, ret.SMTP.ProbeReliability)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+
+	results := v.VerifyBatch([]string{"user@example.com"}, BatchOptions{GroupByDomain: true})
+	if assert.Len(t, results, 1) && assert.NoError(t, results[0].Err) {
+		assert.Equal(t, ProbeReliabilityLow, results[0].Result.SMTP.ProbeReliability)
+		assert.Equal(t, reachableUnknown, results[0].Result.Reachable)
+	}
+}
@@ -0,0 +1,57 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+)
+
+// SetResolver sets the resolver of the DNS lookups, nil restores net.DefaultResolver
+func (v *Verifier) SetResolver(r *net.Resolver) *Verifier {
+	return v.apply(WithResolver(r))
+}
+
+// SetNameservers makes the DNS lookups query the nameservers at addrs instead of those of the system,
+// like WithNameservers. It returns an error if an address isn't an IP address, with an optional port.
+func (v *Verifier) SetNameservers(addrs ...string) error {
+	r, err := nameserverResolver(addrs)
+	if err != nil {
+		return err
+	}
+	v.SetResolver(r)
+	return nil
+}
+
+// nameserverResolver returns a Go resolver querying the nameservers at addrs in turn,
+// e.g. "8.8.8.8:53" or "2001:4860:4860::8888", whose port defaults to 53
+func nameserverResolver(addrs []string) (*net.Resolver, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("no nameserver")
+	}
+	nameservers := make([]string, len(addrs))
+	for i, addr := range addrs {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			host, port = addr, "53"
+		}
+		// a nameserver by name would be resolved by the resolver of the system this bypasses
+		if net.ParseIP(host) == nil || port == "" {
+			return nil, fmt.Errorf("invalid nameserver %q", addr)
+		}
+		nameservers[i] = net.JoinHostPort(host, port)
+	}
+
+	var next uint32
+	return &net.Resolver{
+		PreferGo: true,
+		// the Go resolver dials for each query attempt, which goes to the next nameserver
+		// so a nameserver which doesn't answer is retried on another one
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			i := atomic.AddUint32(&next, 1) - 1
+			var d net.Dialer
+			return d.DialContext(ctx, network, nameservers[int(i)%len(nameservers)])
+		},
+	}, nil
+}
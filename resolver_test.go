@@ -0,0 +1,107 @@
+package emailverifier
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// newNameserver starts a DNS server over UDP on the loopback interface answering like the resolver of srv,
+// it returns its address
+func newNameserver(t *testing.T, srv *smtptest.Server) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			// the resolver of srv frames the messages like DNS over TCP
+			conn, err := srv.Resolver().Dial(context.Background(), "tcp", "")
+			if err != nil {
+				return
+			}
+			query := make([]byte, 2+n)
+			binary.BigEndian.PutUint16(query, uint16(n))
+			copy(query[2:], buf[:n])
+			var length [2]byte
+			if _, err := conn.Write(query); err == nil {
+				if _, err := io.ReadFull(conn, length[:]); err == nil {
+					response := make([]byte, binary.BigEndian.Uint16(length[:]))
+					if _, err := io.ReadFull(conn, response); err == nil {
+						_, _ = pc.WriteTo(response, addr)
+					}
+				}
+			}
+			conn.Close()
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func TestVerify_Nameservers(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	t.Cleanup(srv.Close)
+
+	v, err := NewVerifierWithOptions(WithNameservers(newNameserver(t, srv)))
+	assert.NoError(t, err)
+
+	mx, err := v.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+}
+
+func TestSetNameservers(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	t.Cleanup(srv.Close)
+
+	v := NewVerifier()
+	assert.Error(t, v.SetNameservers("localhost:53"))
+	assert.NoError(t, v.SetNameservers(newNameserver(t, srv)))
+
+	mx, err := v.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+}
+
+func TestNameserverResolver(t *testing.T) {
+	tests := []struct {
+		addr  string
+		valid bool
+	}{
+		{"8.8.8.8:53", true},
+		{"8.8.8.8", true},
+		{"[2001:4860:4860::8888]:53", true},
+		{"2001:4860:4860::8888", true},
+		{"dns.google:53", false},
+		{"8.8.8.8:", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			_, err := nameserverResolver([]string{tt.addr})
+			assert.Equal(t, tt.valid, err == nil)
+		})
+	}
+}
+
+func TestMailDialer(t *testing.T) {
+	v := NewVerifier()
+	assert.Nil(t, v.mailDialer())
+
+	// the mail servers are resolved by the resolver of the DNS lookups
+	assert.NoError(t, v.SetNameservers("127.0.0.1"))
+	assert.NotNil(t, v.snapshot().mailDialer())
+}
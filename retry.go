@@ -0,0 +1,64 @@
+package emailverifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand"
+	"net/smtp"
+	"syscall"
+	"time"
+)
+
+// SetConnectionRetry sets how many times a mail server which resets the connection before its banner is dialed again,
+// after baseDelay jittered up to twice as long. Zero attempts disables the retries, the default is 1 after 250ms.
+// The reconnections of a check are reported in SMTP.ConnectionRetries.
+func (v *Verifier) SetConnectionRetry(attempts int, baseDelay time.Duration) *Verifier {
+	return v.apply(WithConnectionRetry(attempts, baseDelay))
+}
+
+// isConnectionReset reports whether the connection to a mail server failed with err because the server
+// reset or closed it before sending any reply
+func isConnectionReset(err error) bool {
+	if hostUnreachableReason(err) != UnreachableBannerError {
+		return false
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// retryDelay returns the delay of a reconnection, the base delay jittered up to twice as long
+func (v *Verifier) retryDelay() time.Duration {
+	if v.connectionRetryDelay <= 0 {
+		return 0
+	}
+	return v.connectionRetryDelay + time.Duration(rand.Int63n(int64(v.connectionRetryDelay)+1))
+}
+
+// dialHost connects to host at addr, a mail server of domain, with implicit TLS unless tlsConfig is nil.
+// A server which resets the connection before its banner is dialed again up to the connection retry attempts,
+// dialHost returns how many times it was. ctx, derived from the context of v, interrupts the dial.
+func (v *Verifier) dialHost(ctx context.Context, domain, host, addr string, tlsConfig *tls.Config) (*smtp.Client, int, error) {
+	for retries := 0; ; retries++ {
+		start := time.Now()
+		client, err := v.dialProxied(ctx, domain, addr, tlsConfig)
+		// a dial canceled because another host answered didn't fail
+		if err == nil || ctx.Err() != context.Canceled || v.context().Err() != nil {
+			v.stats.countSMTPDial(err)
+			if v.observer != nil {
+				v.observer.ObserveSMTPDial(host, err, time.Since(start))
+			}
+		}
+		if err == nil || retries >= v.connectionRetries || !isConnectionReset(err) {
+			return client, retries, err
+		}
+
+		delay := v.retryDelay()
+		v.debug(domain, "connection reset before the banner, retrying", "host", host, "retry", retries+1, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, retries, err
+		}
+	}
+}
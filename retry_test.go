@@ -0,0 +1,95 @@
+package emailverifier
+
+import (
+	"errors"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestCheckSMTP_ConnectionRetry(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DropBanner: 1}, []string{"example.com"},
+		WithConnectionRetry(1, time.Millisecond))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, CatchAll: true, ConnectionRetries: 1, ProbeReliability: ProbeReliabilityUnknown}, smtp)
+	assert.Equal(t, 2, srv.Connections())
+}
+
+func TestCheckSMTP_ConnectionRetryExhausted(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DropBanner: 3}, []string{"example.com"},
+		WithConnectionRetry(1, time.Millisecond))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, &SMTP{HostUnreachableReason: UnreachableBannerError, ConnectionRetries: 1, ProbeReliability: ProbeReliabilityUnknown, FailedStage: SMTPStageBanner}, smtp)
+	assert.Equal(t, 2, srv.Connections())
+}
+
+func TestCheckSMTP_ConnectionRetryDisabled(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DropBanner: 1}, []string{"example.com"})
+	v.SetConnectionRetry(0, 0)
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, &SMTP{HostUnreachableReason: UnreachableBannerError, ProbeReliability: ProbeReliabilityUnknown, FailedStage: SMTPStageBanner}, smtp)
+	assert.Equal(t, 1, srv.Connections())
+}
+
+func TestCheckSMTP_NoRetryOnReply(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{Banner: "554 5.7.1 No service"}, []string{"example.com"},
+		WithConnectionRetry(2, time.Millisecond))
+
+	_, err := v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, 1, srv.Connections())
+}
+
+func TestVerify_ConnectionRetries(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DropBanner: 1, DefaultRcpt: "550 5.1.1 User unknown",
+		Rcpt: map[string]string{"user@example.com": "250 2.1.5 OK"}}, []string{"example.com"},
+		WithConnectionRetry(1, time.Millisecond))
+
+	// the reconnection of the catch-all check is reported along with the deliverability check
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.Deliverable)
+	assert.Equal(t, 1, ret.SMTP.ConnectionRetries)
+}
+
+func TestVerifyBatch_ConnectionRetries(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DropBanner: 1, DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithConnectionRetry(1, time.Millisecond))
+
+	// the reconnections of the connections of a domain are reported for each of its addresses
+	results := v.VerifyBatch([]string{"alice@example.com", "bob@example.com"}, BatchOptions{GroupByDomain: true})
+	for _, ret := range results {
+		assert.NoError(t, ret.Err)
+		assert.True(t, ret.Result.SMTP.HostExists)
+		assert.Equal(t, 1, ret.Result.SMTP.ConnectionRetries)
+	}
+}
+
+func TestIsConnectionReset(t *testing.T) {
+	assert.True(t, isConnectionReset(unreachable(UnreachableBannerError, io.EOF)))
+	assert.True(t, isConnectionReset(unreachable(UnreachableBannerError, io.ErrUnexpectedEOF)))
+	assert.True(t, isConnectionReset(unreachable(UnreachableBannerError, syscall.ECONNRESET)))
+	assert.False(t, isConnectionReset(unreachable(UnreachableBannerError, errors.New("554 5.7.1 No service"))))
+	assert.False(t, isConnectionReset(unreachable(UnreachableConnectRefused, syscall.ECONNRESET)))
+	assert.False(t, isConnectionReset(io.EOF))
+}
+
+func TestRetryDelay(t *testing.T) {
+	v := NewVerifier().SetConnectionRetry(1, 10*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		d := v.retryDelay()
+		assert.True(t, d >= 10*time.Millisecond && d <= 20*time.Millisecond, d)
+	}
+	assert.Equal(t, time.Duration(0), NewVerifier().SetConnectionRetry(1, 0).retryDelay())
+}
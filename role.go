@@ -0,0 +1,123 @@
+package emailverifier
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// rolePrefixSuffix marks a role account entry matching every local part starting with the text before it
+const rolePrefixSuffix = "*"
+
+// roleSet is an immutable set of role accounts, it's replaced as a whole on every change
+type roleSet struct {
+	accounts map[string]bool // role accounts matched exactly
+	prefixes []string        // role account prefixes, e.g. "noreply" for the entry "noreply*"
+}
+
+var (
+	roleMu           sync.Mutex   // serializes the writers of the role accounts
+	roleSyncAccounts atomic.Value // current *roleSet of role accounts, read without locking
+)
+
+// init loads role_account meta data to roleSyncAccounts which are safe for concurrent use
+func init() {
+	accounts := make([]string, 0, len(roleAccounts))
+	for account := range roleAccounts {
+		accounts = append(accounts, account)
+	}
+	roleSyncAccounts.Store(newRoleSet(accounts))
+}
+
+// newRoleSet returns a roleSet of accounts
+func newRoleSet(accounts []string) *roleSet {
+	set := &roleSet{accounts: make(map[string]bool, len(accounts))}
+	for _, account := range accounts {
+		account = strings.ToLower(account)
+		if strings.HasSuffix(account, rolePrefixSuffix) {
+			set.prefixes = append(set.prefixes, strings.TrimSuffix(account, rolePrefixSuffix))
+			continue
+		}
+		set.accounts[account] = true
+	}
+	return set
+}
+
+// contains checks if mailbox matches a role account of s
+func (s *roleSet) contains(mailbox string) bool {
+	if s.accounts[mailbox] {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(mailbox, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// entries returns the role accounts of s, prefix entries included
+func (s *roleSet) entries() []string {
+	entries := make([]string, 0, len(s.accounts)+len(s.prefixes))
+	for account := range s.accounts {
+		entries = append(entries, account)
+	}
+	for _, prefix := range s.prefixes {
+		entries = append(entries, prefix+rolePrefixSuffix)
+	}
+	return entries
+}
+
+// IsRoleAccount checks if username is a role-based account,
+// using the role accounts shared by all verifiers
+func IsRoleAccount(username string) bool {
+	return defaultVerifier.IsRoleAccount(username)
+}
+
+// AddRoleAccounts adds role-based accounts, an entry ending with "*" like "noreply*"
+// matches every local part starting with the text before it
+func (v *Verifier) AddRoleAccounts(accounts []string) *Verifier {
+	updateRoleAccounts(func(current *roleSet) []string {
+		return append(current.entries(), accounts...)
+	})
+	return v
+}
+
+// RemoveRoleAccounts removes role-based accounts, prefix entries are removed by their "*" form
+func (v *Verifier) RemoveRoleAccounts(accounts []string) *Verifier {
+	removed := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		removed[strings.ToLower(account)] = true
+	}
+
+	updateRoleAccounts(func(current *roleSet) []string {
+		var entries []string
+		for _, entry := range current.entries() {
+			if !removed[entry] {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	})
+	return v
+}
+
+// isRoleAccount checks if the mailbox of username, i.e. without its sub-address tag, is a role-based account
+func (v *Verifier) isRoleAccount(username, domain string) bool {
+	mailbox, _, _ := v.splitSubAddress(username, domain)
+	return currentRoleAccounts().contains(strings.ToLower(mailbox))
+}
+
+// updateRoleAccounts atomically replaces the current role accounts with the entries returned by update
+func updateRoleAccounts(update func(*roleSet) []string) {
+	roleMu.Lock()
+	defer roleMu.Unlock()
+
+	roleSyncAccounts.Store(newRoleSet(update(currentRoleAccounts())))
+	roleListUpdate.touch()
+}
+
+// currentRoleAccounts returns the current role accounts
+func currentRoleAccounts() *roleSet {
+	return roleSyncAccounts.Load().(*roleSet)
+}
@@ -0,0 +1,94 @@
+package emailverifier
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreRoleAccounts restores the role accounts current at the time of calling
+func restoreRoleAccounts(t *testing.T) {
+	current := currentRoleAccounts()
+	t.Cleanup(func() { roleSyncAccounts.Store(current) })
+}
+
+func TestIsRoleAccount_Package(t *testing.T) {
+	assert.True(t, IsRoleAccount("admin"))
+	assert.False(t, IsRoleAccount("john.smith"))
+}
+
+func TestIsRoleAccount_NonEnglish(t *testing.T) {
+	for _, username := range []string{"support", "vertrieb", "ventes", "soporte", "kundenservice", "Contatto"} {
+		assert.True(t, verifier.IsRoleAccount(username), username)
+	}
+}
+
+func TestIsRoleAccount_SubAddress(t *testing.T) {
+	assert.True(t, verifier.IsRoleAccount("sales+q3"))
+	assert.True(t, verifier.IsRoleAccount("SALES+Q3"))
+	assert.False(t, verifier.IsRoleAccount("john+sales"))
+	assert.False(t, verifier.IsRoleAccount("+sales"))
+}
+
+func TestIsRoleAccount_Address(t *testing.T) {
+	v := NewVerifier()
+	for _, address := range []string{"info@example.com", "Admin@example.com", "support+tickets@example.com", "noreply@example.com"} {
+		assert.True(t, v.IsRoleAccount(address), address)
+	}
+	assert.False(t, v.IsRoleAccount("jane.doe@example.com"))
+}
+
+func TestIsRoleAccount_Prefix(t *testing.T) {
+	assert.True(t, verifier.IsRoleAccount("noreply"))
+	assert.True(t, verifier.IsRoleAccount("noreply-billing"))
+	assert.True(t, verifier.IsRoleAccount("no-reply.notifications"))
+	assert.True(t, verifier.IsRoleAccount("donotreply42"))
+	assert.False(t, verifier.IsRoleAccount("reply"))
+}
+
+func TestAddRoleAccounts(t *testing.T) {
+	restoreRoleAccounts(t)
+
+	v := NewVerifier().AddRoleAccounts([]string{"Auftragsannahme", "buildbot-*"})
+	assert.True(t, v.IsRoleAccount("auftragsannahme"))
+	assert.True(t, IsRoleAccount("auftragsannahme"))
+	assert.True(t, v.IsRoleAccount("buildbot-prod"))
+	assert.False(t, v.IsRoleAccount("buildbot"))
+	assert.True(t, v.IsRoleAccount("admin"))
+}
+
+func TestRemoveRoleAccounts(t *testing.T) {
+	restoreRoleAccounts(t)
+
+	v := NewVerifier().RemoveRoleAccounts([]string{"Marketing", "noreply*"})
+	assert.False(t, v.IsRoleAccount("marketing"))
+	assert.False(t, v.IsRoleAccount("noreply-billing"))
+	assert.True(t, v.IsRoleAccount("no-reply"))
+	assert.True(t, v.IsRoleAccount("admin"))
+}
+
+func TestIsRoleAccount_DomainSubAddressSeparator(t *testing.T) {
+	v := NewVerifier()
+	assert.True(t, v.isRoleAccount("sales-q3", "fastmail.com"))
+	assert.False(t, v.isRoleAccount("sales-q3", "example.com"))
+}
+
+func TestRoleAccounts_Concurrent(t *testing.T) {
+	restoreRoleAccounts(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			verifier.AddRoleAccounts([]string{"concurrent-role"})
+		}()
+		go func() {
+			defer wg.Done()
+			assert.True(t, verifier.IsRoleAccount("admin"))
+		}()
+	}
+	wg.Wait()
+	assert.True(t, verifier.IsRoleAccount("concurrent-role"))
+}
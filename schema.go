@@ -0,0 +1,17 @@
+package emailverifier
+
+// SchemaVersion is the version of the fields of Result, reported as its schema_version.
+// It's incremented whenever a field of Result or of one of its sections is added, removed or renamed,
+// so stored results of different versions can be told apart.
+const SchemaVersion = 11
+
+// NewResult returns the result of the verification of email before any check ran. Every field of it
+// and of its sections is present in its JSON encoding, the sections of the checks which don't run are null.
+func NewResult(email string) *Result {
+	return &Result{
+		SchemaVersion: SchemaVersion,
+		Email:         email,
+		Reachable:     reachableUnknown,
+		Quality:       QualityUnknown,
+	}
+}
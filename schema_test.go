@@ -0,0 +1,121 @@
+package emailverifier
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// updateGolden rewrites the golden files with the current encoding, run with -update after a deliberate change
+var updateGolden = flag.Bool("update", false, "update the golden files of the result schema")
+
+// assertGolden asserts that the JSON encoding of ret is the one of testdata/schema/name
+func assertGolden(t *testing.T, name string, ret *Result) {
+	data, err := json.MarshalIndent(ret, "", "  ")
+	if !assert.NoError(t, err) {
+		return
+	}
+	data = append(data, '\n')
+	path := filepath.Join("testdata", "schema", name)
+	if *updateGolden {
+		assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+		return
+	}
+	golden, err := ioutil.ReadFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(golden), string(data), "the fields of Result changed, increment SchemaVersion and run the tests with -update")
+}
+
+func TestNewResult(t *testing.T) {
+	ret := NewResult("user@example.com")
+	assert.Equal(t, SchemaVersion, ret.SchemaVersion)
+	assert.Equal(t, "user@example.com", ret.Email)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+
+	assertGolden(t, "new.json", ret)
+}
+
+func TestResultSchema(t *testing.T) {
+	ret := NewResult(`"John" <john.smith+news@example.com>`)
+	ret.CanonicalEmail = "john.smith@example.com"
+	ret.Name = "John"
+	ret.Reachable = reachableYes
+	ret.Quality = QualityGood
+	ret.Score = 90
+	ret.Syntax = Syntax{
+		Username:      "john.smith+news",
+		Domain:        "example.com",
+		DomainASCII:   "example.com",
+		DomainUnicode: "example.com",
+		Valid:         true,
+		HasSubAddress: true,
+		Tag:           "news",
+		Reasons:       []string{},
+	}
+	ret.SMTP = &SMTP{
+		HostExists:            true,
+		Deliverable:           true,
+		RejectReason:          RejectUserUnknown,
+		HostUnreachableReason: UnreachableConnectTimeout,
+		Error:                 newLookupError(ErrTimeout, "i/o timeout"),
+		Relay:                 "relay.example.net",
+		ConnectionRetries:     1,
+		ProbeReliability:      ProbeReliabilityHigh,
+		FailedStage:           SMTPStageRcpt,
+		ReplyCode:             550,
+		STARTTLS:              true,
+		Plan: &SMTPPlan{
+			Hosts:               []string{"mx.example.com.:25"},
+			CatchAllAddress:     "random@example.com",
+			CatchAllCommands:    []string{"EHLO mail.example.org", "MAIL FROM:<user@example.org>", "RCPT TO:<random@example.com>"},
+			DeliverableCommands: []string{"EHLO mail.example.org", "MAIL FROM:<user@example.org>", "RCPT TO:<john.smith+news@example.com>"},
+		},
+	}
+	ret.SMTPChecked = true
+	ret.Gravatar = &Gravatar{HasGravatar: true, GravatarUrl: "https://www.gravatar.com/avatar/hash", Hash: "hash", AvatarUrl: "https://www.gravatar.com/avatar/hash"}
+	ret.Avatar = &Avatar{Provider: "gravatar", HasAvatar: true, Hash: "hash", Url: "https://www.gravatar.com/avatar/hash"}
+	ret.DomainAuth = &DomainAuth{MTASTS: &MTASTS{Published: true, ID: "20240101", Mode: MTASTSModeEnforce, MX: []string{"*.example.com"}, MaxAge: 86400},
+		SPF:   &SPF{Published: true, Record: "v=spf1 mx -all", Policy: SPFPolicyFail},
+		DKIM:  &DKIM{Published: true, Selectors: []string{"google"}},
+		DMARC: &DMARC{Published: true, Record: "v=DMARC1; p=reject; rua=mailto:dmarc@example.com", Policy: DMARCPolicyReject, SubdomainPolicy: DMARCPolicyReject, Percent: 100, RUA: []string{"mailto:dmarc@example.com"}}}
+	ret.GravatarChecked = true
+	ret.Free = true
+	ret.HasMxRecords = true
+	ret.Timings = Timings{Syntax: time.Microsecond, MX: time.Millisecond, CatchAll: 2 * time.Millisecond, Deliverable: 3 * time.Millisecond, Total: 6 * time.Millisecond}
+	ret.TimedOut = true
+	ret.TimedOutStages = []string{StageAvatar}
+
+	assertGolden(t, "full.json", ret)
+}
+
+func TestResultSchema_Verify(t *testing.T) {
+	ret, err := NewVerifier().Verify("invalid")
+	assert.NoError(t, err)
+	ret.Timings = Timings{}
+
+	assertGolden(t, "invalid.json", ret)
+}
+
+func TestCachedResult_SchemaVersion(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	v, err := NewVerifierWithOptions(WithCache(cache), WithResultCacheTTL(time.Hour))
+	assert.NoError(t, err)
+
+	stale := NewResult("user@example.com")
+	stale.SchemaVersion = SchemaVersion - 1
+	v.cacheSet(CacheKindResult, resultCacheKey("user@example.com"), stale, time.Hour)
+	_, ok := v.cachedResult("user@example.com")
+	assert.False(t, ok)
+
+	v.cacheSet(CacheKindResult, resultCacheKey("user@example.com"), NewResult("user@example.com"), time.Hour)
+	_, ok = v.cachedResult("user@example.com")
+	assert.True(t, ok)
+}
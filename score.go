@@ -0,0 +1,71 @@
+package emailverifier
+
+// Qualities summarizing a verification, see Result.Quality
+const (
+	QualityGood    = "good"    // the address exists and accepts email
+	QualityRisky   = "risky"   // the address may exist, but its domain accepts any address or it's a role account
+	QualityBad     = "bad"     // the address is invalid, doesn't exist, can't receive email or is disposable
+	QualityUnknown = "unknown" // the checks couldn't tell, e.g. without the smtp check or when it failed
+)
+
+// assess records in r the score and the quality summarizing the signals of its checks,
+// err is the error of the verification
+func (r *Result) assess(err error) {
+	r.Quality = r.quality(err)
+	r.Score = r.score()
+}
+
+// quality returns the verdict on the address of r, whose verification failed with err.
+// An address without MX records is bad, unless the MX lookup failed without telling, e.g. on a DNS timeout.
+func (r *Result) quality(err error) string {
+	switch {
+	case !r.Syntax.Valid,
+		r.SkipReason == SkipReasonBlocklisted,
+		r.Disposable,
+		r.Reachable == reachableNo,
+		!r.Skipped && !r.HasMxRecords && !r.TimedOut && r.SMTP == nil && (err == nil || isNoSuchHost(err)),
+		r.SMTP != nil && (r.SMTP.Disabled || r.SMTP.FullInbox):
+		return QualityBad
+	case r.Reachable == reachableYes && !r.RoleAccount:
+		return QualityGood
+	case r.Reachable == reachableYes,
+		r.SMTP != nil && r.SMTP.CatchAll && r.SMTP.Error == nil:
+		return QualityRisky
+	}
+	return QualityUnknown
+}
+
+// score returns the likelihood, from 0 to 100, that an email sent to the address of r is delivered to a person,
+// within the range of its quality
+func (r *Result) score() int {
+	score := 0
+	switch r.Quality {
+	case QualityGood:
+		score = 90
+		if r.Gravatar != nil && r.Gravatar.HasGravatar || r.Avatar != nil && r.Avatar.HasAvatar {
+			score += 10
+		}
+	case QualityRisky:
+		score = 60
+		if r.SMTP != nil && r.SMTP.CatchAll {
+			score = 50
+		}
+		if r.RoleAccount {
+			score -= 10
+		}
+	case QualityUnknown:
+		score = 30
+		if r.HasMxRecords {
+			score += 10
+		}
+		if r.SMTP != nil && r.SMTP.HostExists {
+			score += 10
+		}
+	case QualityBad:
+		// a full or disabled mailbox exists, unlike an address rejected by its server or one of an unknown domain
+		if r.SMTP != nil && (r.SMTP.FullInbox || r.SMTP.Disabled) {
+			score = 10
+		}
+	}
+	return score
+}
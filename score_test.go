@@ -0,0 +1,102 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestResultAssess(t *testing.T) {
+	valid := Syntax{Username: "username", Domain: "example.com", Valid: true}
+	cases := []struct {
+		name    string
+		ret     Result
+		err     error
+		quality string
+		score   int
+	}{
+		{"invalid syntax", Result{Syntax: Syntax{}}, nil, QualityBad, 0},
+		{"blocklisted", Result{Syntax: valid, Skipped: true, SkipReason: SkipReasonBlocklisted}, nil, QualityBad, 0},
+		{"no mx", Result{Syntax: valid, Reachable: reachableUnknown}, nil, QualityBad, 0},
+		{"no such host", Result{Syntax: valid, Reachable: reachableUnknown}, newLookupError(ErrNoSuchHost, ""), QualityBad, 0},
+		{"mx lookup failed", Result{Syntax: valid, Reachable: reachableUnknown}, newLookupError(ErrServerUnavailable, ""), QualityUnknown, 30},
+		{"disposable", Result{Syntax: valid, HasMxRecords: true, Disposable: true, Reachable: reachableUnknown}, nil, QualityBad, 0},
+		{"rejected", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableNo, SMTP: &SMTP{HostExists: true}}, nil, QualityBad, 0},
+		{"full inbox", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableUnknown, SMTP: &SMTP{HostExists: true, FullInbox: true}}, nil, QualityBad, 10},
+		{"deliverable", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableYes, SMTP: &SMTP{HostExists: true, Deliverable: true}}, nil, QualityGood, 90},
+		{"deliverable with gravatar", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableYes, SMTP: &SMTP{HostExists: true, Deliverable: true}, Gravatar: &Gravatar{HasGravatar: true}}, nil, QualityGood, 100},
+		{"role account", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableYes, RoleAccount: true, SMTP: &SMTP{HostExists: true, Deliverable: true}}, nil, QualityRisky, 50},
+		{"catch-all", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableUnknown, SMTP: &SMTP{HostExists: true, CatchAll: true}}, nil, QualityRisky, 50},
+		{"catch-all role account", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableUnknown, RoleAccount: true, SMTP: &SMTP{HostExists: true, CatchAll: true}}, nil, QualityRisky, 40},
+		{"without smtp check", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableUnknown}, nil, QualityUnknown, 40},
+		{"smtp check failed", Result{Syntax: valid, HasMxRecords: true, Reachable: reachableUnknown, SMTP: &SMTP{HostExists: true, Error: newLookupError(ErrTimeout, "")}}, nil, QualityUnknown, 50},
+		{"timed out", Result{Syntax: valid, Reachable: reachableUnknown, TimedOut: true}, nil, QualityUnknown, 30},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ret := c.ret
+			ret.assess(c.err)
+			assert.Equal(t, c.quality, ret.Quality)
+			assert.Equal(t, c.score, ret.Score)
+		})
+	}
+}
+
+func TestVerify_Quality(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{
+		Rcpt:        map[string]string{"username@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 User unknown",
+	}, []string{"example.com"})
+
+	ret, err := v.Verify("username@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, QualityGood, ret.Quality)
+	assert.Equal(t, 90, ret.Score)
+
+	ret, err = v.Verify("nobody@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, QualityBad, ret.Quality)
+	assert.Equal(t, 0, ret.Score)
+
+	ret, err = v.Verify("username")
+	assert.NoError(t, err)
+	assert.Equal(t, QualityBad, ret.Quality)
+}
+
+func TestVerifyBatch_Quality(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "250 2.1.5 OK"}, []string{"example.com"})
+
+	results := v.VerifyBatch([]string{"username@example.com", "username"}, BatchOptions{})
+	assert.Equal(t, QualityRisky, results[0].Result.Quality)
+	assert.Equal(t, 50, results[0].Result.Score)
+	assert.Equal(t, QualityBad, results[1].Result.Quality)
+}
+
+func TestVerify_QualityMXLookupFailed(t *testing.T) {
+	failingResolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("dns server unavailable")
+		},
+	}
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithResolver(failingResolver))
+
+	ret, err := v.Verify("username@example.com")
+	assert.Error(t, err)
+	assert.False(t, ret.HasMxRecords)
+	assert.Equal(t, QualityUnknown, ret.Quality)
+	assert.Equal(t, 30, ret.Score)
+
+	results := v.VerifyBatch([]string{"username@example.com"}, BatchOptions{})
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, QualityUnknown, results[0].Result.Quality)
+
+	v, _ = newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+	ret, _ = v.Verify("username@example.org")
+	assert.Equal(t, QualityBad, ret.Quality)
+}
@@ -0,0 +1,200 @@
+package emailverifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	dnsOverTLSPort   = "853"
+	dnsMessageType   = "application/dns-message"
+	maxDNSMessageLen = 65535
+)
+
+// SetDNSOverTLS makes the DNS lookups query the DNS-over-TLS server at addr, like WithDNSOverTLS
+func (v *Verifier) SetDNSOverTLS(addr string, tlsConfig *tls.Config) error {
+	r, err := dnsOverTLSResolver(addr, tlsConfig)
+	if err != nil {
+		return err
+	}
+	v.SetResolver(r)
+	return nil
+}
+
+// SetDNSOverHTTPS makes the DNS lookups query the DNS-over-HTTPS endpoint, like WithDNSOverHTTPS
+func (v *Verifier) SetDNSOverHTTPS(endpoint string, client *http.Client) error {
+	r, err := dnsOverHTTPSResolver(endpoint, client)
+	if err != nil {
+		return err
+	}
+	v.SetResolver(r)
+	return nil
+}
+
+// dnsOverTLSResolver returns a Go resolver sending the queries to the DNS-over-TLS server (RFC 7858) at addr,
+// e.g. "1.1.1.1:853" or "dns.google", whose port defaults to 853. The certificate of the server is verified
+// with tlsConfig, whose server name defaults to the host of addr, or the default config if nil.
+func dnsOverTLSResolver(addr string, tlsConfig *tls.Config) (*net.Resolver, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, dnsOverTLSPort
+	}
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("invalid DNS-over-TLS server %q", addr)
+	}
+	addr = net.JoinHostPort(host, port)
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	config := tlsConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		// the Go resolver frames the queries over a connection which isn't a net.PacketConn like DNS over TCP,
+		// which is the framing of DNS over TLS
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialTLS(ctx, addr, config)
+		},
+	}, nil
+}
+
+// dialTLS connects to addr and completes the TLS handshake with config by the deadline of ctx, if any
+func dialTLS(ctx context.Context, addr string, config *tls.Config) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, config)
+	if deadline, ok := ctx.Deadline(); ok {
+		tlsConn.SetDeadline(deadline)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// dnsOverHTTPSResolver returns a Go resolver sending the queries to the DNS-over-HTTPS endpoint (RFC 8484),
+// e.g. "https://cloudflare-dns.com/dns-query", with client, http.DefaultClient if nil
+func dnsOverHTTPSResolver(endpoint string, client *http.Client) (*net.Resolver, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid DNS-over-HTTPS endpoint %q", endpoint)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, client: client, endpoint: endpoint}, nil
+		},
+	}, nil
+}
+
+// dohConn is a connection of the Go resolver whose queries, framed like DNS over TCP,
+// are each posted to a DNS-over-HTTPS endpoint, and whose reads return the framed responses
+type dohConn struct {
+	ctx      context.Context
+	client   *http.Client
+	endpoint string
+	deadline time.Time
+	queries  bytes.Buffer // written bytes of the query being framed
+	answers  bytes.Buffer // framed responses which weren't read yet
+}
+
+// Write posts each query framed by b once it's complete
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.queries.Write(b)
+	for c.queries.Len() >= 2 {
+		n := int(binary.BigEndian.Uint16(c.queries.Bytes()))
+		if c.queries.Len() < 2+n {
+			break
+		}
+		c.queries.Next(2)
+		response, err := c.exchange(c.queries.Next(n))
+		if err != nil {
+			return 0, err
+		}
+		var length [2]byte
+		binary.BigEndian.PutUint16(length[:], uint16(len(response)))
+		c.answers.Write(length[:])
+		c.answers.Write(response)
+	}
+	return len(b), nil
+}
+
+// exchange posts the query to the endpoint and returns its response
+func (c *dohConn) exchange(query []byte) ([]byte, error) {
+	ctx := c.ctx
+	if !c.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dnsMessageType)
+	req.Header.Set("Accept", dnsMessageType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS-over-HTTPS endpoint answered %s", resp.Status)
+	}
+	response, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDNSMessageLen+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(response) > maxDNSMessageLen {
+		return nil, errors.New("DNS-over-HTTPS response too long")
+	}
+	return response, nil
+}
+
+// Read reads the framed responses, io.EOF once they're all read
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.answers.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.answers.Read(b)
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return dohAddr(c.endpoint) }
+func (c *dohConn) RemoteAddr() net.Addr               { return dohAddr(c.endpoint) }
+func (c *dohConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(t time.Time) error { return c.SetDeadline(t) }
+
+// SetDeadline sets the deadline of the queries posted by the next writes
+func (c *dohConn) SetDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+// dohAddr is the address of a DNS-over-HTTPS endpoint
+type dohAddr string
+
+func (a dohAddr) Network() string { return "https" }
+func (a dohAddr) String() string  { return string(a) }
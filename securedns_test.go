@@ -0,0 +1,146 @@
+package emailverifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// exchangeDNS sends query to the resolver of srv and returns its response
+func exchangeDNS(srv *smtptest.Server, query []byte) ([]byte, error) {
+	// the resolver of srv frames the messages like DNS over TCP
+	conn, err := srv.Resolver().Dial(context.Background(), "tcp", "")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, err
+	}
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	response := make([]byte, binary.BigEndian.Uint16(length[:]))
+	_, err = io.ReadFull(conn, response)
+	return response, err
+}
+
+func TestVerify_DNSOverTLS(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	t.Cleanup(srv.Close)
+
+	// the DNS-over-TLS server relays the framed queries to the resolver of srv
+	// only the certificate of the httptest server, valid for 127.0.0.1, is used
+	dot := httptest.NewTLSServer(nil)
+	dot.Close()
+	cert := dot.TLS.Certificates[0]
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			upstream, _ := srv.Resolver().Dial(context.Background(), "tcp", "")
+			go func() {
+				_, _ = io.Copy(upstream, conn)
+				upstream.Close()
+			}()
+			go func() {
+				_, _ = io.Copy(conn, upstream)
+				conn.Close()
+			}()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(dot.Certificate())
+	v, err := NewVerifierWithOptions(WithDNSOverTLS(ln.Addr().String(), &tls.Config{RootCAs: roots}))
+	assert.NoError(t, err)
+
+	mx, err := v.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+
+	// the certificate isn't trusted by the default config
+	assert.NoError(t, v.SetDNSOverTLS(ln.Addr().String(), nil))
+	_, err = v.CheckMX("example.org")
+	assert.Error(t, err)
+}
+
+func TestDialTLS_HandshakeDeadline(t *testing.T) {
+	// The server accepts the connection but never answers the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	t.Cleanup(func() {
+		select {
+		case conn := <-accepted:
+			conn.Close()
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = dialTLS(ctx, ln.Addr().String(), &tls.Config{ServerName: "example.com"})
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestVerify_DNSOverHTTPS(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Behavior{}, "example.com")
+	t.Cleanup(srv.Close)
+
+	var contentType string
+	doh := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		query, _ := ioutil.ReadAll(r.Body)
+		response, err := exchangeDNS(srv, query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(doh.Close)
+
+	v, err := NewVerifierWithOptions(WithDNSOverHTTPS(doh.URL+"/dns-query", doh.Client()))
+	assert.NoError(t, err)
+
+	mx, err := v.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+	assert.Equal(t, "application/dns-message", contentType)
+
+	assert.Error(t, v.SetDNSOverHTTPS("dns.example.com", nil))
+}
@@ -0,0 +1,71 @@
+package emailverifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Checks of the sender identity, see SenderIdentityProblem.Check
+const (
+	SenderCheckFromEmailSyntax = "from_email_syntax" // the email of `MAIL FROM:` must be a valid address
+	SenderCheckFromDomainMX    = "from_domain_mx"    // the domain of the email of `MAIL FROM:` must accept mail
+	SenderCheckHelloNameFQDN   = "hello_name_fqdn"   // the name of `EHLO:` must be a fully qualified domain name
+	SenderCheckHelloNameDNS    = "hello_name_dns"    // the name of `EHLO:` must resolve
+)
+
+// SenderIdentityProblem is a problem of the identity the smtp check presents to the mail servers
+type SenderIdentityProblem struct {
+	Check   string // failed check, see the SenderCheck constants
+	Message string // detail of the problem
+}
+
+// SenderIdentityError is the report of ValidateSenderIdentity, listing the problems it found
+type SenderIdentityError struct {
+	Problems []SenderIdentityProblem
+}
+
+func (e *SenderIdentityError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Message
+	}
+	return "invalid sender identity: " + strings.Join(messages, "; ")
+}
+
+// ValidateSenderIdentity checks the identity the smtp check presents to the mail servers, until ctx is done:
+// the email of `MAIL FROM:` must be a valid address whose domain has MX records, and the name of `EHLO:`
+// must be a fully qualified domain name which resolves. Many servers reject a sender which fails these checks,
+// making every address look undeliverable. It returns a *SenderIdentityError listing the problems,
+// or the error of ctx if it's done before the checks complete.
+func (v *Verifier) ValidateSenderIdentity(ctx context.Context) error {
+	v = v.withContext(ctx)
+	var problems []SenderIdentityProblem
+	report := func(check, format string, a ...interface{}) {
+		problems = append(problems, SenderIdentityProblem{Check: check, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if syntax := v.ParseAddress(v.fromEmail); !syntax.Valid {
+		report(SenderCheckFromEmailSyntax, "from email %q isn't a valid address", v.fromEmail)
+	} else if mx, err := v.lookupResolver().LookupMX(ctx, syntax.DomainASCII); err != nil {
+		report(SenderCheckFromDomainMX, "from email domain %s has no MX records: %v", syntax.Domain, err)
+	} else if len(mx) == 0 || isNullMX(mx) {
+		report(SenderCheckFromDomainMX, "from email domain %s doesn't accept mail", syntax.Domain)
+	}
+
+	helloName := strings.TrimSuffix(v.helloName, ".")
+	if !strings.Contains(helloName, ".") {
+		report(SenderCheckHelloNameFQDN, "hello name %q isn't a fully qualified domain name", v.helloName)
+	} else if _, err := v.lookupResolver().LookupHost(ctx, domainToASCII(helloName)); err != nil {
+		report(SenderCheckHelloNameDNS, "hello name %s doesn't resolve: %v", v.helloName, err)
+	}
+
+	// the lookups interrupted by ctx tell nothing about the identity
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &SenderIdentityError{Problems: problems}
+	}
+	return nil
+}
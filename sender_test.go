@@ -0,0 +1,57 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestValidateSenderIdentity(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithFromEmail("verify@example.com"), WithHelloName("mx.example.com"))
+
+	assert.NoError(t, v.ValidateSenderIdentity(context.Background()))
+}
+
+func TestValidateSenderIdentity_Problems(t *testing.T) {
+	cases := []struct {
+		fromEmail string
+		helloName string
+		checks    []string
+	}{
+		{"verify@example.com", "localhost", []string{SenderCheckHelloNameFQDN}},
+		{"verify@example.com", "mx.example.org", []string{SenderCheckHelloNameDNS}},
+		{"verify@example.org", "mx.example.com", []string{SenderCheckFromDomainMX}},
+		{"verify", "mx.example.org", []string{SenderCheckFromEmailSyntax, SenderCheckHelloNameDNS}},
+	}
+	for _, c := range cases {
+		t.Run(c.fromEmail+" "+c.helloName, func(t *testing.T) {
+			v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithHelloName(c.helloName))
+			v.FromEmail(c.fromEmail)
+
+			err := v.ValidateSenderIdentity(context.Background())
+			var e *SenderIdentityError
+			if assert.True(t, errors.As(err, &e)) {
+				var checks []string
+				for _, problem := range e.Problems {
+					checks = append(checks, problem.Check)
+					assert.Contains(t, err.Error(), problem.Message)
+				}
+				assert.Equal(t, c.checks, checks)
+			}
+		})
+	}
+}
+
+func TestValidateSenderIdentity_Canceled(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithFromEmail("verify@example.com"), WithHelloName("mx.example.com"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, v.ValidateSenderIdentity(ctx))
+}
@@ -1,12 +1,18 @@
 package emailverifier
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"net/smtp"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"h12.io/socks"
@@ -19,52 +25,141 @@ type SMTP struct {
 	CatchAll    bool `json:"catch_all"`   // does the domain have a catch-all email address?
 	Deliverable bool `json:"deliverable"` // can send an email to the email server?
 	Disabled    bool `json:"disabled"`    // is the email blocked or disabled by the provider?
+
+	SMTPUTF8Unsupported bool   `json:"smtputf8_unsupported"` // the server can't take the non-ASCII local part
+	ImplicitTLS         bool   `json:"implicit_tls"`         // the connection to the server used implicit TLS
+	STARTTLS            bool   `json:"starttls"`             // the connection to the server was secured by STARTTLS, see WithSTARTTLS
+	STARTTLSError       string `json:"starttls_error"`       // why STARTTLS failed, after which the check went on in plaintext, empty if it didn't
+
+	Blocked      bool   `json:"blocked"`       // did the server refuse to check the address by policy?
+	RejectReason string `json:"reject_reason"` // why the server rejected the address, e.g. RejectUserUnknown
+
+	HostUnreachableReason string `json:"host_unreachable_reason"` // why no mail server could be reached, e.g. UnreachableNXDomain, empty if one was
+
+	Error *LookupError `json:"error"` // error of the smtp check in the soft-fail mode, see EnableSMTPSoftFail
+
+	Relay      string `json:"relay"`       // host of the relay the check went through, see SetSMTPRelay, empty if none
+	MXOverride bool   `json:"mx_override"` // whether the check dialed the servers set by SetDomainSMTPOverride instead of the MX hosts
+
+	ConnectionRetries int `json:"connection_retries"` // reconnections to mail servers which reset the connection before their banner, see SetConnectionRetry
+
+	ProbeReliability string `json:"probe_reliability"` // whether the provider's answer to RCPT can be trusted, see the ProbeReliability constants
+
+	Plan *SMTPPlan `json:"plan"` // commands the check would send in the dry-run mode, see EnableSMTPDryRun, nil otherwise
+
+	FailedStage string `json:"failed_stage"` // stage of the SMTP conversation which failed, e.g. SMTPStageMail, empty if none did
+	ReplyCode   int    `json:"reply_code"`   // code of the reply of the failed stage, zero if none
 }
 
 // Create a new client which is connected to the SMTP server awaiting RCPT
 func (v *Verifier) GetClient(domain string) (*smtp.Client, error) {
+	v = v.snapshot()
+	client, _, err := v.getClient(domain)
+	if err != nil {
+		return client, ParseSMTPError(err)
+	}
+	return client, nil
+}
+
+// getClient performs GetClient, the failure to reach the mail servers is returned as an unreachableError,
+// and a rejected command as a stageError. It returns the reconnections to the mail server which reset
+// the connection before its banner as well.
+func (v *Verifier) getClient(domain string) (*smtp.Client, int, error) {
+	client, retries, err := v.connectClient(domain, v.startTLS)
+	var tlsErr *startTLSError
+	if errors.As(err, &tlsErr) && !v.expired() {
+		// Opportunistic STARTTLS mustn't fail a check which would succeed without it. The client is unusable
+		// after the failed handshake, so the mail server is redialed and the check goes on in plaintext.
+		v.discardClient(client)
+		var n int
+		client, n, err = v.connectClient(domain, false)
+		retries += n
+		if err == nil {
+			v.failedTLS.Store(client, ParseSMTPError(tlsErr).Message)
+		}
+	}
+	return client, retries, err
+}
+
+// connectClient dials a mail server of domain and sends EHLO, secured by STARTTLS if startTLS, and MAIL FROM.
+// A failed STARTTLS handshake is returned as a startTLSError.
+func (v *Verifier) connectClient(domain string, startTLS bool) (*smtp.Client, int, error) {
 	// Dial any SMTP server that will accept a connection
-	client, err := newSMTPClient(domain, v.proxyURI)
+	client, host, retries, err := v.newSMTPClient(domain)
 
 	if err != nil {
-		return client, ParseSMTPError(err)
+		return client, retries, err
+	}
+	// A pooled connection outlives the check, whose context bounds its commands until it's released
+	if v.smtpPool != nil {
+		v.smtpPool.hold(v.context(), v.poolKey(domain), client)
 	}
 
 	// Sets the HELO/EHLO hostname
 	if err := client.Hello(v.helloName); err != nil {
-		return client, ParseSMTPError(err)
+		v.debug(domain, "ehlo rejected", "reply", replyText(err))
+		return client, retries, failedAt(SMTPStageEHLO, err)
 	}
+	if v.relayFor(domain) != nil {
+		if err := v.startRelaySession(domain, client); err != nil {
+			return client, retries, failedAt(SMTPStageEHLO, err)
+		}
+	} else if startTLS {
+		if err := v.upgradeTLS(domain, host, client); err != nil {
+			return client, retries, failedAt(SMTPStageEHLO, err)
+		}
+	}
+	smtputf8, _ := client.Extension("SMTPUTF8")
+	v.debug(domain, "ehlo accepted", "hello_name", v.helloName, "smtputf8", smtputf8)
 
 	// Sets the from email
 	if err := client.Mail(v.fromEmail); err != nil {
-		return client, ParseSMTPError(err)
+		v.debug(domain, "mail from rejected", "from", v.fromEmail, "reply", replyText(err))
+		return client, retries, failedAt(SMTPStageMail, err)
 	}
 
-	return client, nil
+	return client, retries, nil
 }
 
 // Checks the deliver ability of a randomly generated address in
 // order to verify the existence of a catch-all and etc.
 func (v *Verifier) CheckCatchAll(domain string, ret *SMTP) error {
-
-	randomEmail := GenerateRandomEmail(domain)
-
-	client, err := v.GetClient(domain)
+	v = v.snapshot()
+	client, retries, err := v.acquireClient(domain)
+	ret.ConnectionRetries += retries
 
 	if err != nil {
+		ret.HostUnreachableReason = hostUnreachableReason(err)
+		recordFailedStage(ret, err)
 		return ParseSMTPError(err)
 	}
 
+	// Defer quit the SMTP connection, or return it to the pool
+	defer v.releaseClient(client)
+
+	v.checkCatchAll(client, domain, ret)
+	// The reply to a RCPT the verify timeout interrupted is unknown
+	if v.expired() {
+		return ParseSMTPError(v.ctx.Err())
+	}
+	return nil
+}
+
+// checkCatchAll checks the deliver ability of a randomly generated address of domain
+// over client, which awaits RCPT
+func (v *Verifier) checkCatchAll(client *smtp.Client, domain string, ret *SMTP) {
+	randomEmail := GenerateRandomEmail(domain)
+
 	// Default sets catch-all to true
 	ret.CatchAll = true
 
 	// Host exists if we've successfully formed a connection
-	ret.HostExists = true
+	v.recordConnection(client, domain, ret)
 
-	// Defer quit the SMTP connection
-	defer client.Close()
-
-	if err := client.Rcpt(randomEmail); err != nil {
+	err := v.rcpt(client, domain, randomEmail)
+	v.debug(domain, "catch-all rcpt reply", "rcpt", randomEmail, "reply", replyText(err))
+	if err != nil {
+		recordFailedStage(ret, failedAt(SMTPStageRcpt, err))
 		if e := ParseSMTPError(err); e != nil {
 			switch e.Message {
 			case ErrFullInbox:
@@ -79,30 +174,105 @@ func (v *Verifier) CheckCatchAll(domain string, ret *SMTP) error {
 			}
 		}
 	}
-
-	return nil
 }
 
 func (v *Verifier) CheckSMTPPresence(domain, username string, ret *SMTP) error {
+	v = v.snapshot()
 
-	client, err := v.GetClient(domain)
+	client, retries, err := v.acquireClient(domain)
+	ret.ConnectionRetries += retries
 
 	if err != nil {
+		if !ret.HostExists {
+			ret.HostUnreachableReason = hostUnreachableReason(err)
+		}
+		recordFailedStage(ret, err)
 		return ParseSMTPError(err)
 	}
 
-	// Host exists if we've successfully formed a connection
+	// Defer quit the SMTP connection, or return it to the pool
+	defer v.releaseClient(client)
+
+	v.checkPresence(client, domain, username, ret)
+	if v.expired() {
+		return ParseSMTPError(v.ctx.Err())
+	}
+	return nil
+}
+
+// recordConnection records in ret how the smtp check of domain connected over client,
+// whose conversation got through MAIL FROM
+func (v *Verifier) recordConnection(client *smtp.Client, domain string, ret *SMTP) {
 	ret.HostExists = true
+	ret.FailedStage, ret.ReplyCode = "", 0
+	// A secure connection used implicit TLS, unless it was secured by STARTTLS
+	_, secure := client.TLSConnectionState()
+	_, ret.STARTTLS = v.startedTLS.Load(client)
+	ret.STARTTLSError = ""
+	if reason, ok := v.failedTLS.Load(client); ok {
+		ret.STARTTLSError = reason.(string)
+	}
+	ret.ImplicitTLS = secure && !ret.STARTTLS
+	ret.MXOverride = v.smtpOverride(domain) != nil
+	if relay := v.relayFor(domain); relay != nil {
+		ret.Relay = relay.host
+	}
+}
 
-	// Defer quit the SMTP connection
-	defer client.Close()
+// checkPresence checks the deliver ability of the address of username at domain
+// over client, which awaits RCPT
+func (v *Verifier) checkPresence(client *smtp.Client, domain, username string, ret *SMTP) {
+	// Host exists if we've successfully formed a connection
+	v.recordConnection(client, domain, ret)
+
+	// A non-ASCII local part can only be sent to servers supporting SMTPUTF8,
+	// net/smtp adds the SMTPUTF8 parameter to MAIL FROM when the server advertises it.
+	// Otherwise the address can't be checked, which doesn't make it undeliverable.
+	if !supportsLocalPart(client, username) {
+		v.debug(domain, "rcpt skipped, smtputf8 unsupported", "username", username)
+		ret.SMTPUTF8Unsupported = true
+		return
+	}
 
-	email := fmt.Sprintf("%s@%s", username, domain)
-	if err := client.Rcpt(email); err == nil {
+	email := fmt.Sprintf("%s@%s", quoteLocalPart(username), domainToASCII(domain))
+	err := v.rcpt(client, domain, email)
+	v.debug(domain, "rcpt reply", "rcpt", email, "reply", replyText(err))
+	if err == nil {
 		ret.Deliverable = true
+		return
 	}
 
-	return nil
+	recordFailedStage(ret, failedAt(SMTPStageRcpt, err))
+	// The reply tells apart an unknown user from a full mailbox or a probe blocked by policy
+	reason, disabled := classifyRejection(err)
+	ret.RejectReason = reason
+	switch reason {
+	case RejectMailboxFull:
+		ret.FullInbox = true
+	case RejectPolicyBlock:
+		ret.Blocked = true
+	}
+	if disabled {
+		ret.Disabled = true
+	}
+}
+
+// resetClient aborts the current mail transaction of client and starts a new one,
+// so client awaits RCPT again
+func (v *Verifier) resetClient(client *smtp.Client) error {
+	if err := client.Reset(); err != nil {
+		return err
+	}
+	return client.Mail(v.fromEmail)
+}
+
+// supportsLocalPart checks if the server of client is able to take the local part
+func supportsLocalPart(client *smtp.Client, username string) bool {
+	if isASCII(username) {
+		return true
+	}
+	ok, _ := client.Extension("SMTPUTF8")
+	return ok
 }
 
 // CheckSMTP performs an email verification on the passed domain via SMTP
@@ -111,15 +281,65 @@ func (v *Verifier) CheckSMTPPresence(domain, username string, ret *SMTP) error {
 //
 // if server is catch-all server, username will not be checked
 func (v *Verifier) CheckSMTP(domain, username string) (*SMTP, error) {
+	v = v.snapshot()
+	return v.checkSMTP(domain, username, nil)
+}
+
+// CheckSMTPWithContext performs CheckSMTP until ctx is done, which interrupts the pending DNS lookups,
+// connections and SMTP commands. A check ctx interrupted returns the error of ctx.
+func (v *Verifier) CheckSMTPWithContext(ctx context.Context, domain, username string) (*SMTP, error) {
+	ret, err := v.withContext(ctx).checkSMTP(domain, username, nil)
+	if err != nil && contextExpired(ctx) {
+		return ret, contextError(ctx)
+	}
+	return ret, err
+}
+
+// checkSMTP performs CheckSMTP and records the durations of the catch-all and deliverability checks in timings,
+// unless timings is nil. The checks the verify timeout interrupts fail with a stageTimeoutError.
+func (v *Verifier) checkSMTP(domain, username string, timings *Timings) (*SMTP, error) {
 	if !v.smtpCheckEnabled {
 		return nil, nil
 	}
+	if v.smtpDryRun {
+		return v.planSMTP(domain, username)
+	}
+
+	// The addresses of providers with an API verifier are checked through their API
+	if api, ok := v.apiVerifierFor(domain); ok && username != "" {
+		start := time.Now()
+		ret, err := api.check(v.context(), domain, username)
+		if ret != nil {
+			// the API answers for the account itself
+			ret.ProbeReliability = ProbeReliabilityHigh
+		}
+		if timings != nil {
+			timings.Deliverable = time.Since(start)
+		}
+		if err != nil && v.expired() {
+			return nil, &stageTimeoutError{stages: []string{StageDeliverable}, err: err}
+		}
+		return ret, err
+	}
 
 	var ret SMTP
 
-	var err = v.CheckCatchAll(domain, &ret)
+	// The catch-all check of the domain is reused while it's cached
+	start := time.Now()
+	err := v.catchAll(domain, &ret)
+	if timings != nil {
+		timings.CatchAll = time.Since(start)
+	}
+	ret.ProbeReliability = v.probeReliability(domain)
 
 	if err != nil {
+		if v.expired() {
+			stages := []string{StageCatchAll}
+			if username != "" {
+				stages = append(stages, StageDeliverable)
+			}
+			return nil, &stageTimeoutError{stages: stages, err: err}
+		}
 		return &ret, err
 	}
 
@@ -135,106 +355,211 @@ func (v *Verifier) CheckSMTP(domain, username string) (*SMTP, error) {
 	// 452 4.5.3 Recipients belong to multiple regions ATTR38
 	// [DM3NAM02FT039.eop-nam02.prod.protection.outlook.com]
 	// This is particularly the case for Microsoft Mail Servers!
+	start = time.Now()
 	err = v.CheckSMTPPresence(domain, username, &ret)
+	if timings != nil {
+		timings.Deliverable = time.Since(start)
+	}
 
 	// VRFY doesn't really work, so check by actually sending a mail, or maybe that's a bad approach too.
 
 	if err != nil {
+		if v.expired() {
+			return &ret, &stageTimeoutError{stages: []string{StageDeliverable}, err: err}
+		}
 		return &ret, err
 	}
 
 	return &ret, nil
 }
 
-// newSMTPClient generates a new available SMTP client
-func newSMTPClient(domain, proxyURI string) (*smtp.Client, error) {
+// newSMTPClient generates a new available SMTP client, and returns the host it's connected to
+// with the reconnections to the mail server which reset the connection before its banner
+func (v *Verifier) newSMTPClient(domain string) (*smtp.Client, string, int, error) {
 	domain = domainToASCII(domain)
-	mxRecords, err := net.LookupMX(domain)
+	if endpoints := v.smtpOverride(domain); endpoints != nil {
+		return v.dialOverride(domain, endpoints)
+	}
+	if v.relayFor(domain) != nil {
+		client, retries, err := v.dialRelay(domain)
+		return client, v.relay.host, retries, err
+	}
+	hosts, err := v.mxHosts(domain)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	groups := [][]string{hosts}
+	// The unhealthy hosts are dialed only once the healthy ones failed
+	if v.mxHealth != nil {
+		if healthy, unhealthy := v.mxHealth.partition(hosts); len(healthy) > 0 && len(unhealthy) > 0 {
+			v.debug(domain, "deprioritizing unhealthy mx hosts", "hosts", unhealthy)
+			groups = [][]string{healthy, unhealthy}
+		}
+	}
+
+	var errs []error
+	var retries int
+	for _, group := range v.capMXHosts(groups) {
+		client, host, n, groupErrs := v.dialMXGroup(domain, group)
+		retries += n
+		if client != nil {
+			return client, host, retries, nil
+		}
+		errs = append(errs, groupErrs...)
+		if v.context().Err() != nil {
+			break
+		}
+	}
+	return nil, "", retries, mostTransient(errs)
+}
+
+// mxHosts returns the MX hosts of domain, the failure to look them up is returned as an unreachableError
+func (v *Verifier) mxHosts(domain string) ([]string, error) {
+	mxRecords, err := v.lookupMX(domain)
 	if err != nil {
-		return nil, err
+		v.debug(domain, "mx lookup failed", "error", err)
+		return nil, v.classifyMXLookupError(domain, err)
 	}
 
 	if len(mxRecords) == 0 {
-		return nil, errors.New("no MX records found")
+		v.debug(domain, "no mx records found")
+		return nil, unreachable(UnreachableNoMX, errors.New("no MX records found"))
 	}
-	// Create a channel for receiving response from
-	ch := make(chan interface{}, 1)
+	if isNullMX(mxRecords) {
+		v.debug(domain, "null mx record found")
+		return nil, unreachable(UnreachableNullMX, errors.New("null MX record, the domain accepts no email"))
+	}
+
+	hosts := make([]string, len(mxRecords))
+	for i, r := range mxRecords {
+		hosts[i] = r.Host
+	}
+	return hosts, nil
+}
 
-	// Done indicates if we're still waiting on dial responses
-	var done bool
+// dialMXHosts connects to the first of the MX hosts of domain to answer, dialing them concurrently.
+// The dials still running once a host answered are canceled, and the hosts which answer in the meantime closed.
+// It returns the client and its host with the reconnections to the hosts dialed until then, or the errors of every host.
+func (v *Verifier) dialMXHosts(domain string, hosts []string) (*smtp.Client, string, int, []error) {
+	// dialed is the outcome of dialing an MX host
+	type dialed struct {
+		client  *smtp.Client
+		host    string
+		retries int
+		err     error
+	}
 
-	// mutex for data race
-	var mutex sync.Mutex
+	ctx, cancel := context.WithCancel(v.context())
+	defer cancel()
 
-	// Attempt to connect to all SMTP servers concurrently
-	for _, r := range mxRecords {
-		addr := r.Host + smtpPort
+	// Attempt to connect to all SMTP servers concurrently, each of them sends its outcome
+	ch := make(chan dialed, len(hosts))
+	for _, host := range hosts {
+		host := host
 
 		go func() {
-			c, err := dialSMTP(addr, proxyURI)
-			if err != nil {
-				if !done {
-					ch <- err
-				}
-				return
-			}
-
-			// Place the client on the channel or close it
-			mutex.Lock()
-			switch {
-			case !done:
-				done = true
-				ch <- c
-			default:
-				c.Close()
+			v.debug(domain, "dialing smtp server", "host", host, "proxy", v.proxies != nil)
+			c, retries, err := v.dialMXHost(ctx, domain, host)
+			if err != nil && ctx.Err() != context.Canceled {
+				v.debug(domain, "dial failed", "host", host, "error", err)
 			}
-			mutex.Unlock()
+			ch <- dialed{client: c, host: host, retries: retries, err: err}
 		}()
 	}
 
-	// Collect errors or return a client
+	// Collect errors or return a client, with the reconnections to the hosts dialed until then
 	var errs []error
-	for {
+	var retries int
+	for pending := len(hosts); pending > 0; pending-- {
 		res := <-ch
-		switch r := res.(type) {
-		case *smtp.Client:
-			return r, nil
-		case error:
-			errs = append(errs, r)
-			if len(errs) == len(mxRecords) {
-				return nil, errs[0]
-			}
-		default:
-			return nil, errors.New("unexpected response dialing SMTP server")
+		retries += res.retries
+		if res.err == nil {
+			v.debug(domain, "smtp server chosen", "host", res.host)
+			// The hosts which answer before their dial is canceled are closed
+			go func(pending int) {
+				for ; pending > 0; pending-- {
+					if late := <-ch; late.client != nil {
+						late.client.Close()
+					}
+				}
+			}(pending - 1)
+			return res.client, res.host, retries, nil
 		}
+		errs = append(errs, res.err)
 	}
-
+	return nil, "", retries, errs
 }
 
 // dialSMTP is a timeout wrapper for smtp.Dial. It attempts to dial an
 // SMTP server (socks5 proxy supported) and fails with a timeout if timeout is reached while
-// attempting to establish a new connection
-func dialSMTP(addr, proxyURI string) (*smtp.Client, error) {
+// attempting to establish a new connection. Without a proxy the connection is made by dial, net.Dialer if nil.
+// The connection uses implicit TLS configured by tlsConfig unless it's nil, the server name defaults to the host of addr.
+// A failure is an unreachableError classified by the stage which failed. The deadline of parent, if any,
+// interrupts the dial and is the deadline of the commands of the connection, which fail once parent is canceled.
+// dialCtx, derived from parent, only interrupts the dial, up to the banner.
+// Once connected, each command times out after commandTimeout unless it's zero.
+// The banner is read leniently with greeting unless it's nil or the connection uses implicit TLS.
+func dialSMTP(parent, dialCtx context.Context, addr, proxyURI string, timeout, commandTimeout time.Duration, dial func(ctx context.Context, network, addr string) (net.Conn, error), tlsConfig *tls.Config, greeting *lenientGreeting) (*smtp.Client, error) {
 	// Channel holding the new smtp.Client or error
 	ch := make(chan interface{}, 1)
 
+	if greeting != nil && tlsConfig != nil {
+		greeting = nil
+	}
+	total := timeout
+	if greeting != nil {
+		total += greeting.bannerTimeout(timeout)
+	}
+	ctx, cancel := context.WithTimeout(dialCtx, total)
+	defer cancel()
+
+	// stage is the reason of a timeout in the current stage of the connection
+	var stage atomic.Value
+	stage.Store(UnreachableConnectTimeout)
+	timeoutErr := func() error {
+		return unreachable(stage.Load().(string), errors.New("timeout connecting to mail-exchanger"))
+	}
+
 	// Dial the new smtp connection
 	go func() {
 		var conn net.Conn
 		var err error
 
 		if proxyURI != "" {
-			conn, err = establishProxyConnection(addr, proxyURI)
-		} else {
-			conn, err = establishConnection(addr)
+			if conn, err = establishProxyConnection(ctx, addr, proxyURI); err != nil {
+				err = unreachable(UnreachableProxyError, err)
+			}
+		} else if conn, err = establishConnection(ctx, addr, dial); err != nil {
+			err = classifyDialError(err)
 		}
 		if err != nil {
 			ch <- err
 			return
 		}
-
-		host, _, _ := net.SplitHostPort(addr)
-		client, err := smtp.NewClient(conn, host)
+		commandDeadline, _ := parent.Deadline()
+		if !commandDeadline.IsZero() {
+			_ = conn.SetDeadline(commandDeadline)
+		}
+		// The TLS handshake and the banner are interrupted like the connect, so the dial doesn't outlive ctx
+		var lenientGreet func(net.Conn) (net.Conn, error)
+		if greeting != nil {
+			lenientGreet = func(conn net.Conn) (net.Conn, error) {
+				bannerDeadline, _ := ctx.Deadline()
+				if d := time.Now().Add(greeting.bannerTimeout(timeout)); d.Before(bannerDeadline) {
+					bannerDeadline = d
+				}
+				return greet(conn, bannerDeadline, commandDeadline)
+			}
+		}
+		interrupted := closeOnDone(ctx, conn)
+		client, err := handshakeSMTP(newSMTPConn(parent, conn, commandTimeout), addr, tlsConfig, &stage, lenientGreet)
+		if interrupted() {
+			if client != nil {
+				client.Close()
+			}
+			ch <- timeoutErr()
+			return
+		}
 		if err != nil {
 			ch <- err
 			return
@@ -253,8 +578,72 @@ func dialSMTP(addr, proxyURI string) (*smtp.Client, error) {
 		default:
 			return nil, errors.New("unexpected response dialing SMTP server")
 		}
-	case <-time.After(smtpTimeout):
-		return nil, errors.New("timeout connecting to mail-exchanger")
+	case <-ctx.Done():
+		// A client connected after the timeout is closed
+		go func() {
+			if client, ok := (<-ch).(*smtp.Client); ok {
+				client.Close()
+			}
+		}()
+		return nil, timeoutErr()
+	}
+}
+
+// handshakeSMTP performs the TLS handshake over conn, a connection to the mail server at addr, unless tlsConfig is nil,
+// and reads its banner, leniently with greet unless it's nil, recording the current stage of the connection in stage.
+// conn is closed if it fails.
+func handshakeSMTP(conn net.Conn, addr string, tlsConfig *tls.Config, stage *atomic.Value, greet func(net.Conn) (net.Conn, error)) (*smtp.Client, error) {
+	host, _, _ := net.SplitHostPort(addr)
+	if tlsConfig != nil {
+		stage.Store(UnreachableTLSError)
+		config := tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = strings.TrimSuffix(host, ".")
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, unreachable(UnreachableTLSError, err)
+		}
+		conn = tlsConn
+	}
+	stage.Store(UnreachableBannerError)
+	if greet != nil {
+		greeted, err := greet(conn)
+		if err != nil {
+			conn.Close()
+			if hostUnreachableReason(err) == "" {
+				err = unreachable(UnreachableBannerError, err)
+			}
+			return nil, err
+		}
+		conn = greeted
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, unreachable(UnreachableBannerError, err)
+	}
+	return client, nil
+}
+
+// closeOnDone closes conn, a connection or a client, once ctx is done, until the returned interrupted is called,
+// which reports whether conn was closed
+func closeOnDone(ctx context.Context, conn io.Closer) (interrupted func() bool) {
+	stop := make(chan struct{})
+	closed := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			closed <- true
+		case <-stop:
+			closed <- false
+		}
+	}()
+	return func() bool {
+		close(stop)
+		return <-closed
 	}
 }
 
@@ -269,13 +658,100 @@ func GenerateRandomEmail(domain string) string {
 
 }
 
-// establishConnection connects to the address on the named network address.
-func establishConnection(addr string) (net.Conn, error) {
-	return net.Dial("tcp", addr)
+// establishConnection connects to the address on the named network address with dial, net.Dialer if nil.
+func establishConnection(ctx context.Context, addr string, dial func(ctx context.Context, network, addr string) (net.Conn, error)) (net.Conn, error) {
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	return dial(ctx, "tcp", addr)
+}
+
+// smtpConn is a connection to a mail server whose commands time out after commandTimeout, unless it's zero,
+// and whose pending and later reads and writes fail once its context is done
+type smtpConn struct {
+	net.Conn
+	commandTimeout time.Duration
+	deadline       time.Time // deadline of the context, zero if none
+
+	mu        sync.Mutex // guards done and the deadline of Conn once the commands are timed
+	done      bool       // whether the context is done
+	closeOnce sync.Once
+	closed    chan struct{} // closed by Close, which stops watching the context
+}
+
+// newSMTPConn returns conn, whose commands time out after commandTimeout, unless it's zero,
+// and whose pending and later reads and writes fail once ctx is done, e.g. to abort the commands
+// a hung server never replies to
+func newSMTPConn(ctx context.Context, conn net.Conn, commandTimeout time.Duration) net.Conn {
+	if ctx.Done() == nil && commandTimeout == 0 {
+		return conn
+	}
+	c := &smtpConn{Conn: conn, commandTimeout: commandTimeout, closed: make(chan struct{})}
+	c.deadline, _ = ctx.Deadline()
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				c.mu.Lock()
+				c.done = true
+				// a deadline in the past fails the reads and writes with a timeout
+				_ = c.Conn.SetDeadline(time.Unix(1, 0))
+				c.mu.Unlock()
+			case <-c.closed:
+			}
+		}()
+	}
+	return c
+}
+
+// Write writes a command, whose reply must come within the command timeout
+func (c *smtpConn) Write(b []byte) (int, error) {
+	if c.commandTimeout > 0 {
+		c.mu.Lock()
+		if !c.done {
+			deadline := time.Now().Add(c.commandTimeout)
+			if !c.deadline.IsZero() && c.deadline.Before(deadline) {
+				deadline = c.deadline
+			}
+			_ = c.Conn.SetDeadline(deadline)
+		}
+		c.mu.Unlock()
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *smtpConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
 }
 
 // establishProxyConnection connects to the address on the named network address
-// via proxy protocol
-func establishProxyConnection(addr, proxyURI string) (net.Conn, error) {
-	return socks.Dial(proxyURI)("tcp", addr)
+// via proxy protocol, SOCKS or HTTP CONNECT by the scheme of proxyURI, until ctx is done
+func establishProxyConnection(ctx context.Context, addr, proxyURI string) (net.Conn, error) {
+	if u, err := url.Parse(proxyURI); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return dialHTTPProxy(ctx, u, addr)
+	}
+
+	type dialed struct {
+		conn net.Conn
+		err  error
+	}
+	ch := make(chan dialed, 1)
+	go func() {
+		conn, err := socks.Dial(proxyURI)("tcp", addr)
+		ch <- dialed{conn, err}
+	}()
+	select {
+	case d := <-ch:
+		return d.conn, d.err
+	case <-ctx.Done():
+		// The SOCKS dial can't be interrupted, its connection is closed once established
+		go func() {
+			if d := <-ch; d.conn != nil {
+				d.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
 }
@@ -0,0 +1,290 @@
+package emailverifier
+
+import (
+	"context"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// EnableSMTPPool makes the smtp checks reuse the connections to the mail servers of a domain: a connection is kept
+// after a check, and the next check of the domain starts a new mail transaction over it, with RSET and MAIL FROM,
+// instead of reconnecting. A connection is closed once it's idle for idleTimeout, 30 seconds if zero,
+// or once it sent maxProbes RCPT commands, 20 if zero. It's disabled by default.
+func (v *Verifier) EnableSMTPPool(idleTimeout time.Duration, maxProbes int) *Verifier {
+	return v.apply(WithSMTPPool(idleTimeout, maxProbes))
+}
+
+// DisableSMTPPool closes the pooled connections, the smtp checks then connect for each check as by default
+func (v *Verifier) DisableSMTPPool() *Verifier {
+	return v.apply(WithoutSMTPPool())
+}
+
+// smtpPool keeps the idle connections to the mail servers by domain, shared by the verifiers using it
+type smtpPool struct {
+	idleTimeout time.Duration
+	maxProbes   int
+
+	mu    sync.Mutex
+	idle  map[string][]*pooledClient     // idle connections by key, the most recently used last
+	conns map[*smtp.Client]*pooledClient // connections of the pool, idle or held by a check
+	timer *time.Timer                    // closes the connections idle for too long, nil if none is idle
+	now   func() time.Time
+}
+
+// pooledClient is a connection of the pool
+type pooledClient struct {
+	client    *smtp.Client
+	key       string
+	probes    int       // RCPT commands sent over the connection
+	startTLS  bool      // whether the connection was secured by STARTTLS
+	tlsError  string    // why STARTTLS failed before the connection went on in plaintext, empty if it didn't
+	idleSince time.Time // time it was released, zero while a check holds it
+
+	interrupted func() bool // stops watching the context of the check holding the connection
+}
+
+func newSMTPPool(idleTimeout time.Duration, maxProbes int) *smtpPool {
+	if idleTimeout == 0 {
+		idleTimeout = defaultSMTPPoolIdleTimeout
+	}
+	if maxProbes == 0 {
+		maxProbes = defaultSMTPPoolMaxProbes
+	}
+	return &smtpPool{
+		idleTimeout: idleTimeout,
+		maxProbes:   maxProbes,
+		idle:        make(map[string][]*pooledClient),
+		conns:       make(map[*smtp.Client]*pooledClient),
+		now:         time.Now,
+	}
+}
+
+// hold records that a check holds client, a new connection of key, until ctx is done
+func (p *smtpPool) hold(ctx context.Context, key string, client *smtp.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.conns[client] = &pooledClient{client: client, key: key, interrupted: closeOnDone(ctx, client)}
+}
+
+// take returns the most recently used idle connection of key, which a check holds until ctx is done,
+// or nil if none is idle
+func (p *smtpPool) take(ctx context.Context, key string) *pooledClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.expire()
+	idle := p.idle[key]
+	if len(idle) == 0 {
+		return nil
+	}
+	pc := idle[len(idle)-1]
+	p.idle[key] = idle[:len(idle)-1]
+	if len(p.idle[key]) == 0 {
+		delete(p.idle, key)
+	}
+	pc.idleSince = time.Time{}
+	pc.interrupted = closeOnDone(ctx, pc.client)
+	return pc
+}
+
+// probe counts a RCPT command sent over client
+func (p *smtpPool) probe(client *smtp.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pc, ok := p.conns[client]; ok {
+		pc.probes++
+	}
+}
+
+// exhausted reports whether client sent the RCPT commands a pooled connection sends at most
+func (p *smtpPool) exhausted(client *smtp.Client) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.conns[client]
+	return ok && pc.probes >= p.maxProbes
+}
+
+// release returns client, secured by STARTTLS if startTLS or in plaintext after the STARTTLS which failed for tlsError,
+// to the idle connections unless the context of its check interrupted it, it's exhausted or its domain has enough
+// idle connections already, in which case it's closed
+func (p *smtpPool) release(client *smtp.Client, startTLS bool, tlsError string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.conns[client]
+	if !ok {
+		client.Close()
+		return
+	}
+	if pc.interrupted() || pc.probes >= p.maxProbes || len(p.idle[pc.key]) >= smtpPoolMaxIdle {
+		p.forget(pc)
+		return
+	}
+	pc.startTLS = startTLS
+	pc.tlsError = tlsError
+	pc.idleSince = p.now()
+	p.idle[pc.key] = append(p.idle[pc.key], pc)
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.idleTimeout, p.sweep)
+	}
+}
+
+// discard closes client, whose connection is broken
+func (p *smtpPool) discard(client *smtp.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pc, ok := p.conns[client]; ok {
+		pc.interrupted()
+		p.forget(pc)
+		return
+	}
+	client.Close()
+}
+
+// forget closes the connection pc and removes it from the pool
+func (p *smtpPool) forget(pc *pooledClient) {
+	delete(p.conns, pc.client)
+	pc.client.Close()
+}
+
+// sweep closes the connections idle for too long, and schedules the next sweep while connections are idle
+func (p *smtpPool) sweep() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.timer = nil
+	p.expire()
+	if len(p.idle) > 0 {
+		p.timer = time.AfterFunc(p.idleTimeout, p.sweep)
+	}
+}
+
+// expire closes the connections idle for too long
+func (p *smtpPool) expire() {
+	now := p.now()
+	for key, idle := range p.idle {
+		kept := idle[:0]
+		for _, pc := range idle {
+			if now.Sub(pc.idleSince) >= p.idleTimeout {
+				p.forget(pc)
+				continue
+			}
+			kept = append(kept, pc)
+		}
+		if len(kept) == 0 {
+			delete(p.idle, key)
+		} else {
+			p.idle[key] = kept
+		}
+	}
+}
+
+// close closes the idle connections, the connections held by checks are closed once released
+func (p *smtpPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, idle := range p.idle {
+		for _, pc := range idle {
+			p.forget(pc)
+		}
+	}
+	p.idle = make(map[string][]*pooledClient)
+	p.maxProbes = 0
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+// poolKey returns the key of the pooled connections to the mail servers of domain
+func (v *Verifier) poolKey(domain string) string {
+	return domainToASCII(domain) + " " + v.helloName
+}
+
+// connContext returns the context the connections to the mail servers are bound to,
+// which outlives the check of a pooled connection
+func (v *Verifier) connContext() context.Context {
+	if v.smtpPool != nil {
+		return context.Background()
+	}
+	return v.context()
+}
+
+// acquireClient returns a client of the mail servers of domain awaiting RCPT like getClient,
+// an idle pooled connection if any, and the reconnections made to connect it.
+// The client is released by releaseClient, or discardClient if it's broken.
+func (v *Verifier) acquireClient(domain string) (*smtp.Client, int, error) {
+	if v.smtpPool == nil {
+		client, retries, err := v.getClient(domain)
+		if err != nil && client != nil {
+			client.Close()
+			client = nil
+		}
+		return client, retries, err
+	}
+
+	key := v.poolKey(domain)
+	for {
+		pc := v.smtpPool.take(v.context(), key)
+		if pc == nil {
+			break
+		}
+		if err := v.resetClient(pc.client); err != nil {
+			v.debug(domain, "pooled connection broken", "error", err)
+			v.smtpPool.discard(pc.client)
+			continue
+		}
+		v.debug(domain, "reusing pooled connection", "probes", pc.probes)
+		v.stats.countSMTPReuse()
+		if pc.startTLS {
+			v.startedTLS.Store(pc.client, true)
+		}
+		if pc.tlsError != "" {
+			v.failedTLS.Store(pc.client, pc.tlsError)
+		}
+		return pc.client, 0, nil
+	}
+
+	client, retries, err := v.getClient(domain)
+	if err != nil && client != nil {
+		v.smtpPool.discard(client)
+		client = nil
+	}
+	return client, retries, err
+}
+
+// releaseClient releases client, acquired by acquireClient, which is pooled for the next checks of its domain
+// or closed
+func (v *Verifier) releaseClient(client *smtp.Client) {
+	if v.smtpPool == nil {
+		client.Close()
+		return
+	}
+	_, startTLS := v.startedTLS.Load(client)
+	var tlsError string
+	if reason, ok := v.failedTLS.Load(client); ok {
+		tlsError = reason.(string)
+	}
+	v.smtpPool.release(client, startTLS, tlsError)
+}
+
+// discardClient closes client, acquired by acquireClient, whose connection is broken
+func (v *Verifier) discardClient(client *smtp.Client) {
+	if v.smtpPool == nil {
+		client.Close()
+		return
+	}
+	v.smtpPool.discard(client)
+}
+
+// exhaustedClient reports whether client, acquired by acquireClient, sent the RCPT commands
+// a pooled connection sends at most
+func (v *Verifier) exhaustedClient(client *smtp.Client) bool {
+	return v.smtpPool != nil && v.smtpPool.exhausted(client)
+}
@@ -0,0 +1,106 @@
+package emailverifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestCheckSMTP_Pool(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{
+		Rcpt:        map[string]string{"alice@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 User unknown",
+	}, []string{"example.com"}, WithSMTPPool(0, 0))
+	defer v.DisableSMTPPool()
+
+	// The deliverability checks reuse the connection of the catch-all check
+	for _, username := range []string{"alice", "bob", "alice"} {
+		smtp, err := v.CheckSMTP("example.com", username)
+		assert.NoError(t, err)
+		assert.True(t, smtp.HostExists)
+		assert.Equal(t, username == "alice", smtp.Deliverable)
+	}
+	assert.Equal(t, 1, srv.Connections())
+	assert.Equal(t, []string{"EHLO", "MAIL", "RCPT", "RSET", "MAIL", "RCPT", "RSET", "MAIL", "RCPT", "RSET", "MAIL", "RCPT"}, commandVerbs(srv))
+	assert.Equal(t, uint64(3), v.Stats().SMTP.ConnectionsReused)
+}
+
+func TestCheckSMTP_PoolMaxProbes(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithSMTPPool(0, 2))
+	defer v.DisableSMTPPool()
+
+	// A connection is closed once it sent 2 RCPT commands
+	for i := 0; i < 3; i++ {
+		_, err := v.CheckSMTP("example.com", "bob")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, srv.Connections())
+}
+
+func TestCheckSMTP_PoolIdleTimeout(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithSMTPPool(20*time.Millisecond, 0), WithCatchAllCacheTTL(0))
+	defer v.DisableSMTPPool()
+
+	_, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	// The idle connection is closed after the idle timeout
+	assert.Eventually(t, func() bool {
+		v.smtpPool.mu.Lock()
+		defer v.smtpPool.mu.Unlock()
+		return len(v.smtpPool.conns) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, srv.Connections())
+}
+
+func TestCheckSMTP_PoolBrokenConnection(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DropOn: "RSET"}, []string{"example.com"},
+		WithSMTPPool(0, 0), WithCatchAllCacheTTL(0))
+	defer v.DisableSMTPPool()
+
+	// The pooled connection the server drops on RSET is replaced by a new one
+	for i := 0; i < 2; i++ {
+		smtp, err := v.CheckSMTP("example.com", "")
+		assert.NoError(t, err)
+		assert.True(t, smtp.HostExists)
+	}
+	assert.Equal(t, 2, srv.Connections())
+}
+
+func TestVerifyBatch_Pool(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithSMTPPool(0, 0))
+	defer v.DisableSMTPPool()
+
+	// The second batch reuses the connection of the first one
+	for i := 0; i < 2; i++ {
+		results := v.VerifyBatch([]string{"alice@example.com", "bob@example.com"}, BatchOptions{GroupByDomain: true})
+		for _, result := range results {
+			assert.NoError(t, result.Err)
+		}
+	}
+	assert.Equal(t, 1, srv.Connections())
+}
+
+func TestDisableSMTPPool(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"},
+		WithSMTPPool(0, 0), WithCatchAllCacheTTL(0))
+
+	_, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	pool := v.smtpPool
+	v.DisableSMTPPool()
+	assert.Nil(t, v.smtpPool)
+	assert.Empty(t, pool.conns)
+
+	_, err = v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, srv.Connections())
+}
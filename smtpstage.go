@@ -0,0 +1,62 @@
+package emailverifier
+
+import (
+	"errors"
+	"net/textproto"
+)
+
+// Stages of the SMTP conversation at which the smtp check failed, see SMTP.FailedStage
+const (
+	SMTPStageBanner = "banner" // the server didn't greet with a 220 reply, e.g. it rejected the client
+	SMTPStageEHLO   = "ehlo"   // the server rejected EHLO, or the STARTTLS or AUTH of the session with a relay failed
+	SMTPStageMail   = "mail"   // the server rejected MAIL FROM, e.g. it blocks the sender domain
+	SMTPStageRcpt   = "rcpt"   // the server rejected RCPT TO, e.g. the recipient is unknown
+)
+
+// stageError is a failure of the SMTP conversation at its stage.
+// Its message is the one of err, so ParseSMTPError parses it the same.
+type stageError struct {
+	stage string
+	err   error
+}
+
+func (e *stageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stageError) Unwrap() error {
+	return e.err
+}
+
+// failedAt returns err as a failure of the SMTP conversation at stage
+func failedAt(stage string, err error) error {
+	return &stageError{stage: stage, err: err}
+}
+
+// failedStage returns the stage of the SMTP conversation at which err occurred and the code of the reply,
+// empty and zero if err isn't a failure of the conversation or had no reply
+func failedStage(err error) (string, int) {
+	var stage string
+	var e *stageError
+	switch {
+	case errors.As(err, &e):
+		stage = e.stage
+	case hostUnreachableReason(err) == UnreachableBannerError || hostUnreachableReason(err) == UnreachableTempFail:
+		stage = SMTPStageBanner
+	default:
+		return "", 0
+	}
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return stage, reply.Code
+	}
+	return stage, 0
+}
+
+// recordFailedStage records in ret the stage of the SMTP conversation at which err occurred, unless it's unknown
+func recordFailedStage(ret *SMTP, err error) {
+	if stage, code := failedStage(err); stage != "" {
+		ret.FailedStage = stage
+		ret.ReplyCode = code
+	}
+}
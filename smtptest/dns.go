@@ -0,0 +1,170 @@
+package smtptest
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Resolver returns a resolver answering the DNS queries in-process: the MX host of each domain of the server
+// is "mx." followed by the domain, along with "mx2." for the domains of Behavior.BackupMX,
+// and the domains and their MX hosts resolve to 127.0.0.1.
+// The names of Behavior.TXT have their TXT records, any other name doesn't exist.
+func (s *Server) Resolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go s.serveDNS(server)
+			return client, nil
+		},
+	}
+}
+
+// serveDNS answers the queries of conn, framed like DNS over TCP as conn isn't a net.PacketConn
+func (s *Server) serveDNS(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		response, err := s.answer(query)
+		if err != nil {
+			return
+		}
+		if s.behavior.DNSDelay > 0 {
+			select {
+			case <-time.After(s.behavior.DNSDelay):
+			case <-s.closed:
+				return
+			}
+		}
+		binary.BigEndian.PutUint16(length[:], uint16(len(response)))
+		if _, err := conn.Write(append(length[:], response...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer returns the response to the DNS query
+func (s *Server) answer(query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	question, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(question.Name.String(), "."))
+	isDomain := s.domains[name]
+	isMXHost := strings.HasPrefix(name, "mx.") && s.domains[strings.TrimPrefix(name, "mx.")] ||
+		strings.HasPrefix(name, "mx2.") && s.backupMX(strings.TrimPrefix(name, "mx2."))
+	txt, hasTXT := s.txt(name)
+
+	responseHeader := dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		Authoritative:      true,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: true,
+	}
+	if !isDomain && !isMXHost && !hasTXT {
+		responseHeader.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, responseHeader)
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(question); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	resource := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60}
+	switch {
+	case question.Type == dnsmessage.TypeMX && isDomain:
+		host := "mx." + name + "."
+		if s.nullMX(name) {
+			host = "."
+		}
+		mx, err := dnsmessage.NewName(host)
+		if err != nil {
+			return nil, err
+		}
+		err = b.MXResource(resource, dnsmessage.MXResource{Pref: 10, MX: mx})
+		if err != nil {
+			return nil, err
+		}
+		if s.backupMX(name) {
+			backup, err := dnsmessage.NewName("mx2." + name + ".")
+			if err != nil {
+				return nil, err
+			}
+			if err := b.MXResource(resource, dnsmessage.MXResource{Pref: 20, MX: backup}); err != nil {
+				return nil, err
+			}
+		}
+	case question.Type == dnsmessage.TypeA && (isDomain || isMXHost):
+		if err := b.AResource(resource, dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}}); err != nil {
+			return nil, err
+		}
+	case question.Type == dnsmessage.TypeTXT:
+		for _, record := range txt {
+			if err := b.TXTResource(resource, dnsmessage.TXTResource{TXT: []string{record}}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Finish()
+}
+
+// nullMX reports whether the domain name has a null MX record
+func (s *Server) nullMX(name string) bool {
+	for _, domain := range s.behavior.NullMX {
+		if strings.EqualFold(strings.TrimSuffix(domain, "."), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// backupMX reports whether the domain name of the server has the backup MX host
+func (s *Server) backupMX(name string) bool {
+	if !s.domains[name] {
+		return false
+	}
+	for _, domain := range s.behavior.BackupMX {
+		if strings.EqualFold(strings.TrimSuffix(domain, "."), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// txt returns the TXT records of name and whether it has any
+func (s *Server) txt(name string) ([]string, bool) {
+	for txtName, records := range s.behavior.TXT {
+		if strings.EqualFold(strings.TrimSuffix(txtName, "."), name) {
+			return records, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,93 @@
+package smtptest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_LookupMX(t *testing.T) {
+	srv := NewServer(Behavior{}, "example.com")
+	defer srv.Close()
+
+	mx, err := srv.Resolver().LookupMX(context.Background(), "Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*net.MX{{Host: "mx.example.com.", Pref: 10}}, mx)
+}
+
+func TestResolver_LookupHost(t *testing.T) {
+	srv := NewServer(Behavior{}, "example.com")
+	defer srv.Close()
+
+	for _, host := range []string{"example.com", "mx.example.com"} {
+		addrs, err := srv.Resolver().LookupHost(context.Background(), host)
+		assert.NoError(t, err, host)
+		assert.Equal(t, []string{"127.0.0.1"}, addrs, host)
+	}
+}
+
+func TestResolver_NoSuchHost(t *testing.T) {
+	srv := NewServer(Behavior{}, "example.com")
+	defer srv.Close()
+
+	_, err := srv.Resolver().LookupMX(context.Background(), "example.org")
+	var dnsErr *net.DNSError
+	if assert.ErrorAs(t, err, &dnsErr) {
+		assert.True(t, dnsErr.IsNotFound)
+	}
+}
+
+func TestResolver_LookupTXT(t *testing.T) {
+	srv := NewServer(Behavior{TXT: map[string][]string{"_mta-sts.example.com": {"v=STSv1; id=1"}}}, "example.com")
+	defer srv.Close()
+
+	txt, err := srv.Resolver().LookupTXT(context.Background(), "_MTA-STS.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v=STSv1; id=1"}, txt)
+
+	txt, err = srv.Resolver().LookupTXT(context.Background(), "example.com")
+	assert.Error(t, err)
+	assert.Empty(t, txt)
+}
+
+func TestResolver_DNSDelay(t *testing.T) {
+	srv := NewServer(Behavior{DNSDelay: 50 * time.Millisecond}, "example.com")
+	defer srv.Close()
+
+	start := time.Now()
+	_, err := srv.Resolver().LookupMX(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestResolver_NullMX(t *testing.T) {
+	srv := NewServer(Behavior{NullMX: []string{"Example.com"}}, "example.com")
+	defer srv.Close()
+
+	mx, err := srv.Resolver().LookupMX(context.Background(), "example.com")
+	assert.NoError(t, err)
+	if assert.Len(t, mx, 1) {
+		assert.Equal(t, ".", mx[0].Host)
+	}
+}
+
+func TestResolver_BackupMX(t *testing.T) {
+	srv := NewServer(Behavior{BackupMX: []string{"Example.com"}}, "example.com", "example.org")
+	defer srv.Close()
+
+	mx, err := srv.Resolver().LookupMX(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*net.MX{{Host: "mx.example.com.", Pref: 10}, {Host: "mx2.example.com.", Pref: 20}}, mx)
+	addrs, err := srv.Resolver().LookupHost(context.Background(), "mx2.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, addrs)
+
+	mx, err = srv.Resolver().LookupMX(context.Background(), "example.org")
+	assert.NoError(t, err)
+	assert.Len(t, mx, 1)
+	_, err = srv.Resolver().LookupHost(context.Background(), "mx2.example.org")
+	assert.Error(t, err)
+}
@@ -0,0 +1,397 @@
+// Package smtptest provides an in-process SMTP server for the integration tests of the smtp check.
+// The server listens on a random local port and replies as scripted by a Behavior, its Dial and
+// Resolver point a verifier at it:
+//
+//	srv := smtptest.NewServer(smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, "example.com")
+//	defer srv.Close()
+//
+//	verifier, err := emailverifier.NewVerifierWithOptions(
+//		emailverifier.WithSMTPCheck(),
+//		emailverifier.WithDialer(srv.Dial),
+//		emailverifier.WithResolver(srv.Resolver()),
+//	)
+package smtptest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default replies of a Server
+const (
+	defaultBanner = "220 smtptest ESMTP"
+	defaultRcpt   = "250 2.1.5 OK"
+	greylistReply = "451 4.7.1 Greylisted, try again later"
+)
+
+// Behavior scripts the replies of a Server, the zero value accepts every command
+type Behavior struct {
+	// Banner is the greeting of the server, "220 smtptest ESMTP" if empty.
+	// A greeting which isn't a 220 reply makes the client fail to connect.
+	Banner string
+	// BannerDelay delays the greeting, e.g. beyond the timeout of the client
+	BannerDelay time.Duration
+	// Extensions are advertised in the reply to EHLO, e.g. "SMTPUTF8"
+	Extensions []string
+	// Mail is the reply to MAIL, "250 2.1.0 OK" if empty, e.g. "550 5.7.1 Sender domain blocked"
+	Mail string
+	// Rcpt maps recipients, case insensitive, to the reply to their RCPT command, e.g. "550 5.1.1 User unknown"
+	Rcpt map[string]string
+	// DefaultRcpt is the reply to the RCPT of the recipients missing in Rcpt, "250 2.1.5 OK" if empty,
+	// so the server is catch-all unless it rejects the unknown recipients
+	DefaultRcpt string
+	// Greylist rejects the first RCPT of each recipient with a 451 reply, the later ones get their reply
+	Greylist bool
+	// DropOn closes the connection without a reply on the first command with this verb, e.g. "RCPT"
+	DropOn string
+	// StartTLS advertises STARTTLS, which upgrades the connection with the certificate of the server
+	StartTLS bool
+	// ImplicitTLS starts the connections with a TLS handshake with the certificate of the server, like on port 465
+	ImplicitTLS bool
+	// Users maps the usernames to the passwords of AUTH PLAIN, which is advertised and required before MAIL
+	// unless Users is empty
+	Users map[string]string
+	// TXT maps names, case insensitive, to the TXT records the Resolver answers, e.g. "_mta-sts.example.com"
+	TXT map[string][]string
+	// NullMX are the domains of the server, case insensitive, whose MX record is the null MX "." (RFC 7505)
+	NullMX []string
+	// BackupMX are the domains of the server, case insensitive, which have a second MX host "mx2." followed by
+	// the domain, of a lower preference than the first
+	BackupMX []string
+	// DNSDelay delays each answer of the Resolver, like a slow DNS server
+	DNSDelay time.Duration
+	// DropBanner closes the first DropBanner connections before the greeting,
+	// like a server resetting the clients it dislikes
+	DropBanner int
+}
+
+// Server is an SMTP server listening on a random port of the loopback interface
+type Server struct {
+	Addr string // address of the listener, host:port
+
+	behavior Behavior
+	domains  map[string]bool
+	listener net.Listener
+	tls      *tls.Config
+	cert     *x509.Certificate
+	closed   chan struct{}
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	commands []string
+	rcpts    map[string]bool // recipients of a RCPT, for the greylisting
+	accepted int             // number of accepted connections
+}
+
+// NewServer starts a server replying as scripted by b, whose Resolver resolves the domains to it.
+// It panics if the server can't listen, like httptest.NewServer; Close stops it.
+func NewServer(b Behavior, domains ...string) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("smtptest: failed to listen: %v", err))
+	}
+
+	s := &Server{
+		Addr:     listener.Addr().String(),
+		behavior: b,
+		domains:  make(map[string]bool),
+		listener: listener,
+		closed:   make(chan struct{}),
+		conns:    make(map[net.Conn]bool),
+		rcpts:    make(map[string]bool),
+	}
+	for _, domain := range domains {
+		s.domains[strings.ToLower(strings.TrimSuffix(domain, "."))] = true
+	}
+	if b.StartTLS || b.ImplicitTLS {
+		if s.tls, s.cert, err = newTLSConfig(s.MXHosts()); err != nil {
+			listener.Close()
+			panic(fmt.Sprintf("smtptest: failed to create the certificate: %v", err))
+		}
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+// Close stops the server and closes its connections
+func (s *Server) Close() {
+	select {
+	case <-s.closed:
+		return
+	default:
+	}
+	close(s.closed)
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Dial connects to the server whatever the address, so a verifier dialing the MX hosts of any domain
+// reaches the server. It has the signature of net.Dialer.DialContext.
+func (s *Server) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", s.Addr)
+}
+
+// MXHosts returns the MX hosts of the domains of the server, "mx." followed by each domain
+func (s *Server) MXHosts() []string {
+	hosts := make([]string, 0, len(s.domains))
+	for domain := range s.domains {
+		hosts = append(hosts, "mx."+domain)
+	}
+	return hosts
+}
+
+// Certificate returns the certificate of the server, nil unless the behavior enables STARTTLS or implicit TLS
+func (s *Server) Certificate() *x509.Certificate {
+	return s.cert
+}
+
+// Commands returns the commands received by the server, in the order of their arrival
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Connections returns the number of connections the server accepted
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// accept serves the connections to the listener until Close
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		select {
+		case <-s.closed:
+			s.mu.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		s.conns[conn] = true
+		s.accepted++
+		drop := s.accepted <= s.behavior.DropBanner
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			if drop {
+				conn.Close()
+			} else {
+				s.serve(conn)
+			}
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// serve runs the SMTP session of conn
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	secure, authenticated := false, len(s.behavior.Users) == 0
+	if s.behavior.ImplicitTLS {
+		tlsConn := tls.Server(conn, s.tls)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn, secure = tlsConn, true
+		defer conn.Close()
+	}
+	if s.behavior.BannerDelay > 0 {
+		select {
+		case <-time.After(s.behavior.BannerDelay):
+		case <-s.closed:
+			return
+		}
+	}
+	text := textproto.NewConn(conn)
+	banner := s.behavior.Banner
+	if banner == "" {
+		banner = defaultBanner
+	}
+	if err := text.PrintfLine("%s", banner); err != nil {
+		return
+	}
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		s.record(line)
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], line[i+1:]
+		}
+		verb = strings.ToUpper(verb)
+		if s.behavior.DropOn != "" && verb == strings.ToUpper(s.behavior.DropOn) {
+			return
+		}
+
+		switch verb {
+		case "EHLO":
+			err = s.replyEHLO(text, secure)
+		case "HELO":
+			err = text.PrintfLine("250 smtptest")
+		case "AUTH":
+			if authenticated = s.authenticate(arg); authenticated {
+				err = text.PrintfLine("235 2.7.0 Authentication successful")
+			} else {
+				err = text.PrintfLine("535 5.7.8 Authentication credentials invalid")
+			}
+		case "MAIL":
+			if !authenticated {
+				err = text.PrintfLine("530 5.7.0 Authentication required")
+				break
+			}
+			if s.behavior.Mail != "" {
+				err = text.PrintfLine("%s", s.behavior.Mail)
+				break
+			}
+			err = text.PrintfLine("250 2.1.0 OK")
+		case "RCPT":
+			err = text.PrintfLine("%s", s.rcptReply(arg))
+		case "DATA":
+			if err = text.PrintfLine("354 Start mail input; end with <CRLF>.<CRLF>"); err == nil {
+				if _, err = ioutil.ReadAll(text.DotReader()); err == nil {
+					err = text.PrintfLine("250 2.0.0 OK")
+				}
+			}
+		case "RSET", "NOOP":
+			err = text.PrintfLine("250 2.0.0 OK")
+		case "VRFY":
+			err = text.PrintfLine("252 2.5.2 Cannot VRFY user")
+		case "STARTTLS":
+			if s.tls == nil || secure {
+				err = text.PrintfLine("502 5.5.1 STARTTLS not available")
+				break
+			}
+			if err = text.PrintfLine("220 2.0.0 Ready to start TLS"); err != nil {
+				return
+			}
+			tlsConn := tls.Server(conn, s.tls)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, text, secure = tlsConn, textproto.NewConn(tlsConn), true
+			defer conn.Close()
+		case "QUIT":
+			_ = text.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			err = text.PrintfLine("502 5.5.2 Command not recognized")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// replyEHLO replies to EHLO with the extensions of the behavior, and STARTTLS unless the connection is secure
+func (s *Server) replyEHLO(text *textproto.Conn, secure bool) error {
+	lines := append([]string{"smtptest"}, s.behavior.Extensions...)
+	if len(s.behavior.Users) > 0 {
+		lines = append(lines, "AUTH PLAIN")
+	}
+	if s.tls != nil && !secure {
+		lines = append(lines, "STARTTLS")
+	}
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		if err := text.PrintfLine("250%s%s", separator, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authenticate checks the argument arg of AUTH, "PLAIN" and the base64 of "\x00username\x00password"
+func (s *Server) authenticate(arg string) bool {
+	fields := strings.Fields(arg)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "PLAIN") {
+		return false
+	}
+	credentials, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(string(credentials), "\x00")
+	if len(parts) != 3 {
+		return false
+	}
+	password, ok := s.behavior.Users[parts[1]]
+	return ok && password == parts[2]
+}
+
+// rcptReply returns the reply to the RCPT command with the argument arg, e.g. "TO:<user@example.com>"
+func (s *Server) rcptReply(arg string) string {
+	address := arg
+	if i := strings.IndexByte(address, ':'); i >= 0 {
+		address = address[i+1:]
+	}
+	if i := strings.IndexByte(address, '>'); i >= 0 {
+		address = address[:i]
+	}
+	address = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(address), "<"))
+
+	if s.behavior.Greylist {
+		s.mu.Lock()
+		seen := s.rcpts[address]
+		s.rcpts[address] = true
+		s.mu.Unlock()
+		if !seen {
+			return greylistReply
+		}
+	}
+
+	for recipient, reply := range s.behavior.Rcpt {
+		if strings.EqualFold(recipient, address) {
+			return reply
+		}
+	}
+	if s.behavior.DefaultRcpt != "" {
+		return s.behavior.DefaultRcpt
+	}
+	return defaultRcpt
+}
+
+// record records a command received by the server
+func (s *Server) record(line string) {
+	s.mu.Lock()
+	s.commands = append(s.commands, line)
+	s.mu.Unlock()
+}
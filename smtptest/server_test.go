@@ -0,0 +1,251 @@
+package smtptest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dial returns a client of srv which greeted it
+func dial(t *testing.T, srv *Server) *smtp.Client {
+	conn, err := srv.Dial(context.Background(), "tcp", "mx.example.com:25")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	client, err := smtp.NewClient(conn, "mx.example.com")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, client.Hello("localhost"))
+	return client
+}
+
+// assertReply asserts err is the SMTP reply
+func assertReply(t *testing.T, reply string, err error) {
+	var e *textproto.Error
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, reply, fmt.Sprintf("%d %s", e.Code, e.Msg))
+	}
+}
+
+func TestServer_Rcpt(t *testing.T) {
+	srv := NewServer(Behavior{
+		Rcpt:        map[string]string{"user@example.com": "250 2.1.5 OK"},
+		DefaultRcpt: "550 5.1.1 User unknown",
+	}, "example.com")
+	defer srv.Close()
+
+	client := dial(t, srv)
+	defer client.Close()
+	assert.NoError(t, client.Mail("probe@example.org"))
+	assert.NoError(t, client.Rcpt("User@Example.com"))
+	assertReply(t, "550 5.1.1 User unknown", client.Rcpt("nobody@example.com"))
+	assert.NoError(t, client.Quit())
+
+	assert.Equal(t, []string{
+		"EHLO localhost",
+		"MAIL FROM:<probe@example.org>",
+		"RCPT TO:<User@Example.com>",
+		"RCPT TO:<nobody@example.com>",
+		"QUIT",
+	}, srv.Commands())
+}
+
+func TestServer_Mail(t *testing.T) {
+	srv := NewServer(Behavior{Mail: "550 5.7.1 Sender domain blocked"})
+	defer srv.Close()
+
+	client := dial(t, srv)
+	defer client.Close()
+	assertReply(t, "550 5.7.1 Sender domain blocked", client.Mail("probe@example.org"))
+}
+
+func TestServer_DefaultRcptAccepts(t *testing.T) {
+	srv := NewServer(Behavior{})
+	defer srv.Close()
+
+	client := dial(t, srv)
+	defer client.Close()
+	assert.NoError(t, client.Mail("probe@example.org"))
+	assert.NoError(t, client.Rcpt("anyone@example.com"))
+}
+
+func TestServer_Greylist(t *testing.T) {
+	srv := NewServer(Behavior{Greylist: true})
+	defer srv.Close()
+
+	client := dial(t, srv)
+	assert.NoError(t, client.Mail("probe@example.org"))
+	assertReply(t, greylistReply, client.Rcpt("user@example.com"))
+	client.Close()
+
+	// The retry on a new connection passes the greylisting
+	client = dial(t, srv)
+	defer client.Close()
+	assert.NoError(t, client.Mail("probe@example.org"))
+	assert.NoError(t, client.Rcpt("user@example.com"))
+	assertReply(t, greylistReply, client.Rcpt("other@example.com"))
+}
+
+func TestServer_BannerDelay(t *testing.T) {
+	srv := NewServer(Behavior{BannerDelay: time.Second})
+	defer srv.Close()
+
+	conn, err := srv.Dial(context.Background(), "tcp", "")
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err = conn.Read(make([]byte, 1))
+	var netErr net.Error
+	if assert.ErrorAs(t, err, &netErr) {
+		assert.True(t, netErr.Timeout())
+	}
+}
+
+func TestServer_Banner(t *testing.T) {
+	srv := NewServer(Behavior{Banner: "554 5.7.1 No service"})
+	defer srv.Close()
+
+	conn, err := srv.Dial(context.Background(), "tcp", "")
+	assert.NoError(t, err)
+	_, err = smtp.NewClient(conn, "localhost")
+	assertReply(t, "554 5.7.1 No service", err)
+}
+
+func TestServer_DropBanner(t *testing.T) {
+	srv := NewServer(Behavior{DropBanner: 1})
+	defer srv.Close()
+
+	conn, err := srv.Dial(context.Background(), "tcp", "")
+	assert.NoError(t, err)
+	_, err = smtp.NewClient(conn, "localhost")
+	assert.Error(t, err)
+
+	client := dial(t, srv)
+	defer client.Close()
+	assert.Equal(t, 2, srv.Connections())
+}
+
+func TestServer_DropOn(t *testing.T) {
+	srv := NewServer(Behavior{DropOn: "rcpt"})
+	defer srv.Close()
+
+	client := dial(t, srv)
+	defer client.Close()
+	assert.NoError(t, client.Mail("probe@example.org"))
+	assert.Error(t, client.Rcpt("user@example.com"))
+}
+
+func TestServer_StartTLS(t *testing.T) {
+	srv := NewServer(Behavior{StartTLS: true, Extensions: []string{"SMTPUTF8"}}, "example.com")
+	defer srv.Close()
+
+	client := dial(t, srv)
+	defer client.Close()
+	ok, _ := client.Extension("STARTTLS")
+	assert.True(t, ok)
+	ok, _ = client.Extension("SMTPUTF8")
+	assert.True(t, ok)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	assert.NoError(t, client.StartTLS(&tls.Config{ServerName: "mx.example.com", RootCAs: roots}))
+	state, ok := client.TLSConnectionState()
+	assert.True(t, ok)
+	assert.True(t, state.HandshakeComplete)
+
+	// STARTTLS isn't advertised on a secure connection
+	ok, _ = client.Extension("STARTTLS")
+	assert.False(t, ok)
+	assert.NoError(t, client.Mail("probe@example.org"))
+	assert.NoError(t, client.Rcpt("user@example.com"))
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := NewServer(Behavior{StartTLS: true, Users: map[string]string{"relay": "secret"}}, "example.com")
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	for _, password := range []string{"wrong", "secret"} {
+		client := dial(t, srv)
+		assertReply(t, "530 5.7.0 Authentication required", client.Mail("probe@example.org"))
+		assert.NoError(t, client.StartTLS(&tls.Config{ServerName: "mx.example.com", RootCAs: roots}))
+		ok, _ := client.Extension("AUTH")
+		assert.True(t, ok)
+
+		err := client.Auth(smtp.PlainAuth("", "relay", password, "mx.example.com"))
+		if password == "wrong" {
+			assertReply(t, "535 5.7.8 Authentication credentials invalid", err)
+		} else {
+			assert.NoError(t, err)
+			assert.NoError(t, client.Mail("probe@example.org"))
+		}
+		client.Close()
+	}
+}
+
+func TestServer_StartTLSNotAvailable(t *testing.T) {
+	srv := NewServer(Behavior{})
+	defer srv.Close()
+	assert.Nil(t, srv.Certificate())
+
+	client := dial(t, srv)
+	defer client.Close()
+	ok, _ := client.Extension("STARTTLS")
+	assert.False(t, ok)
+	assert.Error(t, client.StartTLS(&tls.Config{InsecureSkipVerify: true}))
+}
+
+func TestServer_Close(t *testing.T) {
+	srv := NewServer(Behavior{BannerDelay: time.Minute})
+	conn, err := srv.Dial(context.Background(), "tcp", "")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		srv.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close waits for the delayed banner")
+	}
+	srv.Close()
+
+	_, err = srv.Dial(context.Background(), "tcp", "")
+	assert.Error(t, err)
+}
+
+func TestServer_ImplicitTLS(t *testing.T) {
+	srv := NewServer(Behavior{ImplicitTLS: true}, "example.com")
+	defer srv.Close()
+
+	conn, err := srv.Dial(context.Background(), "tcp", "mx.example.com:465")
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: "mx.example.com", RootCAs: roots})
+
+	client, err := smtp.NewClient(tlsConn, "mx.example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer client.Close()
+	_, ok := client.TLSConnectionState()
+	assert.True(t, ok)
+	assert.NoError(t, client.Hello("localhost"))
+	ok, _ = client.Extension("STARTTLS")
+	assert.False(t, ok)
+	assert.NoError(t, client.Mail("probe@example.org"))
+}
@@ -0,0 +1,103 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// Policies of an SPF record for the senders it doesn't authorize, see SPF.Policy
+const (
+	SPFPolicyFail     = "fail"     // "-all", mail of any other sender is rejected
+	SPFPolicySoftFail = "softfail" // "~all", mail of any other sender is suspicious but accepted
+	SPFPolicyNeutral  = "neutral"  // "?all" or no "all" mechanism, the domain doesn't tell
+	SPFPolicyPass     = "pass"     // "+all" or "all", any sender is authorized
+)
+
+// SPF is detail about the SPF record of a domain (RFC 7208).
+// A domain without an SPF record likely doesn't send mail, and a domain with several of them is misconfigured.
+type SPF struct {
+	Published     bool   `json:"published"`     // whether the domain publishes an SPF TXT record
+	Record        string `json:"record"`        // SPF record, like "v=spf1 include:_spf.example.com -all"
+	Policy        string `json:"policy"`        // policy for the other senders, see the SPFPolicy constants, empty with a redirect
+	Redirect      string `json:"redirect"`      // domain whose record the "redirect" modifier defers to, when there is no "all" mechanism
+	Misconfigured bool   `json:"misconfigured"` // whether the domain publishes several SPF records
+	Error         string `json:"error"`         // why the record is misconfigured
+}
+
+// CheckSPF returns the SPF record of domain and its policy for the senders it doesn't authorize.
+// A failed DNS lookup yields an error, while a domain without a record has an unpublished SPF.
+func (v *Verifier) CheckSPF(domain string) (*SPF, error) {
+	v = v.snapshot()
+	domain = domainToASCII(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	ctx, cancel := context.WithTimeout(v.context(), domainAuthTimeout)
+	defer cancel()
+
+	return v.checkSPF(ctx, domain)
+}
+
+// checkSPF looks up the TXT records of domain and parses its SPF record
+func (v *Verifier) checkSPF(ctx context.Context, domain string) (*SPF, error) {
+	records, err := v.lookupResolver().LookupTXT(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return &SPF{}, nil
+		}
+		return nil, ParseSMTPError(err)
+	}
+
+	// Only the records of the version spf1 count, and several of them are an error (RFC 7208 section 4.5)
+	var spf []string
+	for _, record := range records {
+		if isSPFRecord(record) {
+			spf = append(spf, record)
+		}
+	}
+	if len(spf) == 0 {
+		return &SPF{}, nil
+	}
+
+	ret := &SPF{Published: true, Record: spf[0]}
+	if len(spf) > 1 {
+		ret.Misconfigured = true
+		ret.Error = "several SPF TXT records"
+		return ret, nil
+	}
+	ret.Policy, ret.Redirect = parseSPFRecord(spf[0])
+	return ret, nil
+}
+
+// isSPFRecord returns whether the TXT record is an SPF record, which starts with "v=spf1" then a space or ends
+func isSPFRecord(record string) bool {
+	const version = "v=spf1"
+	return len(record) >= len(version) && strings.EqualFold(record[:len(version)], version) &&
+		(len(record) == len(version) || record[len(version)] == ' ')
+}
+
+// parseSPFRecord returns the policy of the "all" mechanism of the SPF record, or the domain of its
+// "redirect" modifier without one, which RFC 7208 section 6.1 ignores when there is an "all" mechanism
+func parseSPFRecord(record string) (policy, redirect string) {
+	for _, term := range strings.Fields(record)[1:] {
+		term = strings.ToLower(term)
+		if strings.HasPrefix(term, "redirect=") {
+			redirect = strings.TrimPrefix(term, "redirect=")
+			continue
+		}
+		switch term {
+		case "-all":
+			return SPFPolicyFail, ""
+		case "~all":
+			return SPFPolicySoftFail, ""
+		case "?all":
+			return SPFPolicyNeutral, ""
+		case "+all", "all":
+			return SPFPolicyPass, ""
+		}
+	}
+	if redirect != "" {
+		return "", redirect
+	}
+	return SPFPolicyNeutral, ""
+}
@@ -0,0 +1,82 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// newTXTTestVerifier returns a verifier resolving the domains with the TXT records txt
+func newTXTTestVerifier(t *testing.T, txt map[string][]string) *Verifier {
+	srv := smtptest.NewServer(smtptest.Behavior{TXT: txt}, "example.com")
+	t.Cleanup(srv.Close)
+	return NewVerifier().SetResolver(srv.Resolver())
+}
+
+func TestCheckSPF(t *testing.T) {
+	cases := []struct {
+		name     string
+		txt      []string
+		expected *SPF
+	}{
+		{"not published", nil, &SPF{}},
+		{"other records", []string{"google-site-verification=abc", "v=spf10 -all"}, &SPF{}},
+		{"fail", []string{"v=spf1 include:_spf.example.com -all"}, &SPF{Published: true, Record: "v=spf1 include:_spf.example.com -all", Policy: SPFPolicyFail}},
+		{"redirect", []string{"v=spf1 redirect=_spf.Example.net"}, &SPF{Published: true, Record: "v=spf1 redirect=_spf.Example.net", Redirect: "_spf.example.net"}},
+		{
+			name:     "several records",
+			txt:      []string{"v=spf1 -all", "V=SPF1 ~all"},
+			expected: &SPF{Published: true, Record: "v=spf1 -all", Misconfigured: true, Error: "several SPF TXT records"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := newTXTTestVerifier(t, map[string][]string{"example.com": c.txt})
+
+			spf, err := v.CheckSPF("Example.com.")
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, spf)
+		})
+	}
+}
+
+func TestCheckSPF_IDN(t *testing.T) {
+	v := newTXTTestVerifier(t, map[string][]string{"xn--mnchen-3ya.de": {"v=spf1 -all"}})
+
+	spf, err := v.CheckSPF("München.de")
+	assert.NoError(t, err)
+	assert.Equal(t, &SPF{Published: true, Record: "v=spf1 -all", Policy: SPFPolicyFail}, spf)
+}
+
+func TestParseSPFRecord(t *testing.T) {
+	cases := []struct {
+		record   string
+		policy   string
+		redirect string
+	}{
+		{"v=spf1 -all", SPFPolicyFail, ""},
+		{"v=spf1 ip4:192.0.2.0/24 ~ALL", SPFPolicySoftFail, ""},
+		{"v=spf1 ?all", SPFPolicyNeutral, ""},
+		{"v=spf1 +all", SPFPolicyPass, ""},
+		{"v=spf1 mx all", SPFPolicyPass, ""},
+		{"v=spf1 mx", SPFPolicyNeutral, ""},
+		{"v=spf1", SPFPolicyNeutral, ""},
+		{"v=spf1 redirect=example.net", "", "example.net"},
+		{"v=spf1 redirect=example.net -all", SPFPolicyFail, ""},
+	}
+	for _, c := range cases {
+		policy, redirect := parseSPFRecord(c.record)
+		assert.Equal(t, c.policy, policy, c.record)
+		assert.Equal(t, c.redirect, redirect, c.record)
+	}
+}
+
+func TestIsSPFRecord(t *testing.T) {
+	assert.True(t, isSPFRecord("v=spf1 -all"))
+	assert.True(t, isSPFRecord("V=SPF1"))
+	assert.False(t, isSPFRecord("v=spf10 -all"))
+	assert.False(t, isSPFRecord("v=STSv1; id=1"))
+	assert.False(t, isSPFRecord(""))
+}
@@ -0,0 +1,64 @@
+package emailverifier
+
+import (
+	"crypto/tls"
+	"net/smtp"
+	"strings"
+)
+
+// EnableSTARTTLS makes the smtp check secure the connections to the mail servers by STARTTLS
+// when they advertise it, before MAIL and RCPT, see WithSTARTTLS
+func (v *Verifier) EnableSTARTTLS() *Verifier {
+	return v.apply(WithSTARTTLS())
+}
+
+// DisableSTARTTLS makes the smtp check send MAIL and RCPT in plaintext, which is the default
+func (v *Verifier) DisableSTARTTLS() *Verifier {
+	return v.apply(WithoutSTARTTLS())
+}
+
+// startTLSConfig returns the TLS config of the STARTTLS of a connection to host,
+// whose server name defaults to host
+func (v *Verifier) startTLSConfig(host string) *tls.Config {
+	config := &tls.Config{}
+	if v.tlsConfig != nil {
+		config = v.tlsConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = strings.TrimSuffix(host, ".")
+	}
+	return config
+}
+
+// startTLSError is the failure of the STARTTLS handshake with a mail server, after which its client is unusable
+type startTLSError struct {
+	err error
+}
+
+func (e *startTLSError) Error() string {
+	return e.err.Error()
+}
+
+func (e *startTLSError) Unwrap() error {
+	return e.err
+}
+
+// upgradeTLS secures the session of client with host, a mail server of domain, by STARTTLS unless
+// the connection uses implicit TLS or the server doesn't advertise it. client has sent EHLO.
+// A failed handshake is returned as a startTLSError.
+func (v *Verifier) upgradeTLS(domain, host string, client *smtp.Client) error {
+	if _, secure := client.TLSConnectionState(); secure {
+		return nil
+	}
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		v.debug(domain, "starttls not advertised", "host", host)
+		return nil
+	}
+	if err := client.StartTLS(v.startTLSConfig(host)); err != nil {
+		v.debug(domain, "starttls failed", "host", host, "error", err)
+		return &startTLSError{err: err}
+	}
+	v.startedTLS.Store(client, true)
+	v.debug(domain, "starttls succeeded", "host", host)
+	return nil
+}
@@ -0,0 +1,81 @@
+package emailverifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// commandVerbs returns the verbs of the commands srv received
+func commandVerbs(srv *smtptest.Server) []string {
+	var verbs []string
+	for _, command := range srv.Commands() {
+		verbs = append(verbs, strings.Fields(command)[0])
+	}
+	return verbs
+}
+
+func TestCheckSMTP_STARTTLS(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{StartTLS: true}, []string{"example.com"}, WithSTARTTLS())
+	v.apply(WithTLSConfig(trustCertificate(srv)))
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, CatchAll: true, STARTTLS: true, ProbeReliability: ProbeReliabilityUnknown}, smtp)
+	// MAIL and RCPT are sent over the secured session, after a new EHLO
+	assert.Equal(t, []string{"EHLO", "STARTTLS", "EHLO", "MAIL", "RCPT"}, commandVerbs(srv)[:5])
+}
+
+func TestCheckSMTP_STARTTLSNotAdvertised(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithSTARTTLS())
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.False(t, smtp.STARTTLS)
+	assert.NotContains(t, commandVerbs(srv), "STARTTLS")
+}
+
+func TestCheckSMTP_STARTTLSDisabled(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{StartTLS: true}, []string{"example.com"}, WithSTARTTLS())
+	v.DisableSTARTTLS()
+
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.False(t, smtp.STARTTLS)
+	assert.NotContains(t, commandVerbs(srv), "STARTTLS")
+}
+
+func TestCheckSMTP_STARTTLSUntrustedCertificate(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{StartTLS: true}, []string{"example.com"})
+	v.EnableSTARTTLS()
+
+	// The self-signed certificate fails the verification, the check goes on over a new plaintext connection
+	smtp, err := v.CheckSMTP("example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{
+		HostExists:       true,
+		CatchAll:         true,
+		STARTTLSError:    ErrTLSCertificate,
+		ProbeReliability: ProbeReliabilityUnknown,
+	}, smtp)
+	assert.Equal(t, 2, srv.Connections())
+	assert.Equal(t, []string{"EHLO", "STARTTLS", "EHLO", "MAIL", "RCPT"}, commandVerbs(srv)[:5])
+}
+
+func TestCheckSMTP_STARTTLSFailedPooled(t *testing.T) {
+	v, srv := newSMTPTestVerifier(t, smtptest.Behavior{StartTLS: true}, []string{"example.com"},
+		WithSTARTTLS(), WithSMTPPool(0, 0))
+	defer v.DisableSMTPPool()
+
+	// A pooled plaintext connection keeps the failure of its STARTTLS
+	for i := 0; i < 2; i++ {
+		smtp, err := v.CheckSMTP("example.com", "bob")
+		assert.NoError(t, err)
+		assert.False(t, smtp.STARTTLS)
+		assert.Equal(t, ErrTLSCertificate, smtp.STARTTLSError)
+	}
+	assert.Equal(t, 2, srv.Connections())
+}
@@ -0,0 +1,175 @@
+package emailverifier
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Names of the domain lists in Stats.Lists
+const (
+	ListDisposable = "disposable" // disposable domains
+	ListFree       = "free"       // free mailbox provider domains
+	ListRole       = "role"       // role accounts
+)
+
+// Stats are the counters of a Verifier and the sizes of its caches and lists, e.g. for capacity planning
+type Stats struct {
+	Caches         map[string]CacheStats `json:"caches"`          // lookups of the cache by kind, see CacheKindMX
+	CacheEntries   int                   `json:"cache_entries"`   // values in the cache, -1 if the cache doesn't count them
+	CacheEvictions uint64                `json:"cache_evictions"` // values the cache evicted to make room for others
+	Lists          map[string]ListStats  `json:"lists"`           // domain lists by name, see ListDisposable
+	SMTP           SMTPStats             `json:"smtp"`            // connections to the mail servers
+}
+
+// CacheStats are the lookups of a kind of cached values
+type CacheStats struct {
+	Entries  int     `json:"entries"`   // values of the kind in the cache, -1 if the cache doesn't count them
+	Hits     uint64  `json:"hits"`      // lookups which found a value
+	Misses   uint64  `json:"misses"`    // lookups which didn't find a value
+	HitRatio float64 `json:"hit_ratio"` // share of the lookups which found a value, zero without lookups
+}
+
+// ListStats describes a domain list
+type ListStats struct {
+	Entries    int       `json:"entries"`     // number of entries
+	LastUpdate time.Time `json:"last_update"` // when the list was last changed, zero if it's the built-in one
+}
+
+// SMTPStats counts the connections to the mail servers
+type SMTPStats struct {
+	ConnectionsOpened uint64 `json:"connections_opened"` // connections which got through the banner and EHLO
+	ConnectionsReused uint64 `json:"connections_reused"` // checks over a connection opened for a previous check
+	ConnectionsFailed uint64 `json:"connections_failed"` // connection attempts which failed, retries included
+}
+
+// CacheCounter is implemented by the caches which count their values, like MemoryCache,
+// whose counts Stats reports then
+type CacheCounter interface {
+	// Count returns the number of values whose key starts with prefix
+	Count(prefix string) int
+	// Evictions returns the number of values evicted to make room for others
+	Evictions() uint64
+}
+
+// verifierStats are the counters of a Verifier, shared by its snapshots and updated atomically
+type verifierStats struct {
+	smtpOpened uint64 // first, so the counters are 64-bit aligned for the atomic operations
+	smtpReused uint64
+	smtpFailed uint64
+
+	caches map[string]*cacheCounters // lookups by cache kind, never modified after newVerifierStats
+}
+
+// cacheCounters counts the lookups of a kind of cached values
+type cacheCounters struct {
+	hits   uint64
+	misses uint64
+}
+
+// newVerifierStats returns zero counters
+func newVerifierStats() *verifierStats {
+	return &verifierStats{caches: map[string]*cacheCounters{
+		CacheKindMX:       {},
+		CacheKindCatchAll: {},
+		CacheKindResult:   {},
+	}}
+}
+
+// countCacheLookup counts a lookup of a value of kind, hit reports whether it was found
+func (s *verifierStats) countCacheLookup(kind string, hit bool) {
+	if s == nil || s.caches[kind] == nil {
+		return
+	}
+	if hit {
+		atomic.AddUint64(&s.caches[kind].hits, 1)
+	} else {
+		atomic.AddUint64(&s.caches[kind].misses, 1)
+	}
+}
+
+// countSMTPDial counts a connection attempt to a mail server, err is its failure
+func (s *verifierStats) countSMTPDial(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		atomic.AddUint64(&s.smtpFailed, 1)
+	} else {
+		atomic.AddUint64(&s.smtpOpened, 1)
+	}
+}
+
+// countSMTPReuse counts a check over a connection opened for a previous check
+func (s *verifierStats) countSMTPReuse() {
+	if s != nil {
+		atomic.AddUint64(&s.smtpReused, 1)
+	}
+}
+
+// listUpdate records when a domain list was last changed, it's read without locking
+type listUpdate struct {
+	unixNano int64
+}
+
+var (
+	disposableListUpdate listUpdate // last change of the disposable domains
+	freeListUpdate       listUpdate // last change of the free domains
+	roleListUpdate       listUpdate // last change of the role accounts
+)
+
+// touch records a change of the list now
+func (u *listUpdate) touch() {
+	atomic.StoreInt64(&u.unixNano, time.Now().UnixNano())
+}
+
+// time returns when the list was last changed, zero if it never was
+func (u *listUpdate) time() time.Time {
+	n := atomic.LoadInt64(&u.unixNano)
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// Stats returns the counters of the verifier since its creation, shared by all its snapshots,
+// with the current sizes of its cache and of the domain lists
+func (v *Verifier) Stats() Stats {
+	v = v.snapshot()
+	s := v.stats
+	if s == nil {
+		s = newVerifierStats()
+	}
+
+	stats := Stats{
+		Caches:       make(map[string]CacheStats, len(s.caches)),
+		CacheEntries: -1,
+		Lists: map[string]ListStats{
+			ListDisposable: {Entries: len(currentDisposableDomains()), LastUpdate: disposableListUpdate.time()},
+			ListFree:       {Entries: len(currentFreeDomains()), LastUpdate: freeListUpdate.time()},
+			ListRole:       {Entries: len(currentRoleAccounts().entries()), LastUpdate: roleListUpdate.time()},
+		},
+		SMTP: SMTPStats{
+			ConnectionsOpened: atomic.LoadUint64(&s.smtpOpened),
+			ConnectionsReused: atomic.LoadUint64(&s.smtpReused),
+			ConnectionsFailed: atomic.LoadUint64(&s.smtpFailed),
+		},
+	}
+
+	counter, _ := v.cache.(CacheCounter)
+	if counter != nil {
+		stats.CacheEntries = counter.Count(cacheKeyPrefix)
+		stats.CacheEvictions = counter.Evictions()
+	}
+	for kind, c := range s.caches {
+		cs := CacheStats{Entries: -1, Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+		// without a cache, the MX records are in the own cache of the verifier
+		if kindCounter, ok := v.cacheOf(kind).(CacheCounter); ok {
+			cs.Entries = kindCounter.Count(cacheKeyPrefix + kind + ":")
+		}
+		if lookups := cs.Hits + cs.Misses; lookups > 0 {
+			cs.HitRatio = float64(cs.Hits) / float64(lookups)
+		}
+		stats.Caches[kind] = cs
+	}
+	return stats
+}
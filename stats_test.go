@@ -0,0 +1,61 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+func TestStats(t *testing.T) {
+	cache, err := NewMemoryCache(100)
+	assert.NoError(t, err)
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{DefaultRcpt: "550 5.1.1 User unknown"}, []string{"example.com"},
+		WithCache(cache))
+
+	stats := v.Stats()
+	assert.Equal(t, CacheStats{}, stats.Caches[CacheKindMX])
+	assert.Equal(t, 0, stats.CacheEntries)
+	assert.Equal(t, SMTPStats{}, stats.SMTP)
+	assert.Equal(t, len(currentDisposableDomains()), stats.Lists[ListDisposable].Entries)
+	assert.Equal(t, len(currentFreeDomains()), stats.Lists[ListFree].Entries)
+	assert.Equal(t, len(currentRoleAccounts().entries()), stats.Lists[ListRole].Entries)
+
+	// The addresses of a domain are checked over the connection of the catch-all check
+	results := v.VerifyBatch([]string{"a@example.com", "b@example.com", "c@example.com"}, BatchOptions{GroupByDomain: true, DomainConnections: 1})
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+	_, err = v.Verify("d@example.com")
+	assert.NoError(t, err)
+
+	stats = v.Stats()
+	assert.Equal(t, SMTPStats{ConnectionsOpened: 3, ConnectionsReused: 3}, stats.SMTP)
+	mx := stats.Caches[CacheKindMX]
+	assert.Equal(t, 1, mx.Entries)
+	assert.Equal(t, uint64(1), mx.Misses)
+	assert.Equal(t, float64(mx.Hits)/float64(mx.Hits+1), mx.HitRatio)
+	assert.Equal(t, CacheStats{Entries: 1, Misses: 1}, stats.Caches[CacheKindCatchAll])
+	assert.Equal(t, 0, stats.Caches[CacheKindResult].Entries)
+	assert.Equal(t, 2, stats.CacheEntries)
+
+	// The unreachable mail servers count as failed connections
+	v, _ = newSMTPTestVerifier(t, smtptest.Behavior{Banner: "554 5.7.1 Access denied"}, []string{"example.com"})
+	_, err = v.CheckSMTP("example.com", "")
+	assert.Error(t, err)
+	assert.Equal(t, SMTPStats{ConnectionsFailed: 1}, v.Stats().SMTP)
+	assert.Equal(t, -1, v.Stats().CacheEntries)
+}
+
+func TestStats_ListUpdate(t *testing.T) {
+	v := NewVerifier()
+	before := v.Stats().Lists[ListFree]
+
+	t.Cleanup(func() { v.RemoveFreeDomains([]string{"stats.example.com"}) })
+	v.AddFreeDomains([]string{"stats.example.com"})
+	after := v.Stats().Lists[ListFree]
+	assert.Equal(t, before.Entries+1, after.Entries)
+	assert.False(t, after.LastUpdate.IsZero())
+	assert.False(t, after.LastUpdate.Before(before.LastUpdate))
+}
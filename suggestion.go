@@ -6,9 +6,76 @@ import (
 	"github.com/hbollon/go-edlib"
 )
 
+// SetSuggestionDomains replaces the candidate domains used to suggest a misspelled domain,
+// the free domains list is used by default
+func (v *Verifier) SetSuggestionDomains(domains []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.suggestionDomains = make(map[string]bool, len(domains))
+	for _, d := range domains {
+		v.suggestionDomains[strings.ToLower(d)] = true
+	}
+	return v
+}
+
+// AddSuggestionDomains adds candidate domains used to suggest a misspelled domain
+func (v *Verifier) AddSuggestionDomains(domains []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	candidates := make(map[string]bool, len(v.candidateDomains())+len(domains))
+	for d := range v.candidateDomains() {
+		candidates[d] = true
+	}
+	for _, d := range domains {
+		candidates[strings.ToLower(d)] = true
+	}
+	v.suggestionDomains = candidates
+	return v
+}
+
+// SetSuggestionMaxDistance sets the maximum edit distance between a misspelled domain and its suggestion,
+// a distance <= 0 disables the bound and only the similarity thresholds apply
+func (v *Verifier) SetSuggestionMaxDistance(distance int) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.suggestionMaxDistance = distance
+	return v
+}
+
+// candidateDomains returns the candidate domains used to suggest a misspelled domain
+func (v *Verifier) candidateDomains() map[string]bool {
+	if v.suggestionDomains != nil {
+		return v.suggestionDomains
+	}
+	return currentFreeDomains()
+}
+
+// suggestEmail returns the address with its misspelled domain corrected,
+// returns an empty string if domain suggestion is disabled or nothing similar was found
+func (v *Verifier) suggestEmail(username, domain string) string {
+	suggestion := v.suggestDomain(domain)
+	if suggestion == "" {
+		return ""
+	}
+	return username + "@" + suggestion
+}
+
+// suggestDomain returns the correction of a misspelled domain,
+// returns an empty string if domain suggestion is disabled or nothing similar was found
+func (v *Verifier) suggestDomain(domain string) string {
+	if !v.domainSuggestEnabled || domain == "" {
+		return ""
+	}
+	return v.SuggestDomain(domain)
+}
+
 // SuggestDomain checks if domain has a typo and suggests a similar correct domain from metadata,
 // returns a suggestion
 func (v *Verifier) SuggestDomain(domain string) string {
+	v = v.snapshot()
 	if domain == "" {
 		return ""
 	}
@@ -23,7 +90,7 @@ func (v *Verifier) SuggestDomain(domain string) string {
 
 	}
 
-	closestDomain := findClosestDomain(domain, freeDomains, domainThreshold)
+	closestDomain := findClosestDomain(domain, v.candidateDomains(), domainThreshold, v.suggestionMaxDistance)
 	if closestDomain != "" {
 		if closestDomain == domain {
 			// The domain exactly matches one of the suggestion domains, no suggestion provided.
@@ -36,8 +103,8 @@ func (v *Verifier) SuggestDomain(domain string) string {
 	var localTypo bool
 	closestDomain = domain
 
-	closestSecondLevelDomain := findClosestDomain(sld, suggestionSecondLevelDomains, secondLevelThreshold)
-	closestTopLevelDomain := findClosestDomain(tld, suggestionTopLevelDomains, topLevelThreshold)
+	closestSecondLevelDomain := findClosestDomain(sld, suggestionSecondLevelDomains, secondLevelThreshold, v.suggestionMaxDistance)
+	closestTopLevelDomain := findClosestDomain(tld, suggestionTopLevelDomains, topLevelThreshold, v.suggestionMaxDistance)
 
 	if closestSecondLevelDomain != "" && closestSecondLevelDomain != sld {
 		localTypo = true
@@ -56,8 +123,26 @@ func (v *Verifier) SuggestDomain(domain string) string {
 	return ""
 }
 
-// findClosestDomain finds the string most similar to the domain via Levenshtein algorithms.
-func findClosestDomain(domain string, domains map[string]bool, threshold float32) string {
+// SuggestEmail checks if the domain of email has a typo like SuggestDomain, and returns the address
+// with the suggested domain, e.g. "user@gmail.com" for "user@gmial.com". It returns an empty string
+// if email isn't an address or nothing similar was found.
+func (v *Verifier) SuggestEmail(email string) string {
+	email = strings.TrimSpace(email)
+	index := strings.LastIndex(email, "@")
+	if index <= 0 {
+		return ""
+	}
+	suggestion := v.SuggestDomain(email[index+1:])
+	if suggestion == "" {
+		return ""
+	}
+	return email[:index] + "@" + suggestion
+}
+
+// findClosestDomain finds the string most similar to the domain via the optimal string alignment
+// Damerau-Levenshtein algorithm, so a transposition (gmial.com) costs a single edit.
+// Candidates further away than maxDistance edits are ignored when maxDistance > 0.
+func findClosestDomain(domain string, domains map[string]bool, threshold float32, maxDistance int) string {
 	var maxDist = float32(-1)
 	var closestDomain string
 
@@ -70,8 +155,13 @@ func findClosestDomain(domain string, domains map[string]bool, threshold float32
 			return domain
 		}
 
-		dist, _ := edlib.StringsSimilarity(domain, d, edlib.Levenshtein)
-		if dist > maxDist {
+		if maxDistance > 0 && edlib.OSADamerauLevenshteinDistance(domain, d) > maxDistance {
+			continue
+		}
+
+		dist, _ := edlib.StringsSimilarity(domain, d, edlib.OSADamerauLevenshtein)
+		// the ties are broken by the order of the domains, so the suggestion doesn't depend on the map order
+		if dist > maxDist || dist == maxDist && d < closestDomain {
 			maxDist = dist
 			closestDomain = d
 		}
@@ -47,3 +47,67 @@ func TestSuggestDomainOK_SLD(t *testing.T) {
 	ret := verifier.SuggestDomain(domain)
 	assert.Equal(t, "hotmail.aftership", ret)
 }
+
+func TestSuggestDomainOK_Transposition(t *testing.T) {
+	assert.Equal(t, "gmail.com", verifier.SuggestDomain("gmial.com"))
+	assert.Equal(t, "hotmail.com", verifier.SuggestDomain("hotnail.com"))
+	assert.Equal(t, "yahoo.com", verifier.SuggestDomain("yahooo.com"))
+}
+
+func TestSuggestDomain_SetSuggestionDomains(t *testing.T) {
+	v := NewVerifier().SetSuggestionDomains([]string{"Example.com"})
+
+	assert.Equal(t, "example.com", v.SuggestDomain("exmaple.com"))
+	assert.Equal(t, "", v.SuggestDomain("gmaii.com"))
+}
+
+func TestSuggestDomain_AddSuggestionDomains(t *testing.T) {
+	v := NewVerifier().AddSuggestionDomains([]string{"aftership.com"})
+
+	assert.Equal(t, "aftership.com", v.SuggestDomain("aftershp.com"))
+	assert.Equal(t, "gmail.com", v.SuggestDomain("gmaii.com"))
+}
+
+func TestSuggestDomain_SetSuggestionMaxDistance(t *testing.T) {
+	v := NewVerifier().SetSuggestionDomains([]string{"verylongdomainname.com"})
+	assert.Equal(t, "", v.SuggestDomain("vrylngdomainame.com"))
+
+	v.SetSuggestionMaxDistance(3)
+	assert.Equal(t, "verylongdomainname.com", v.SuggestDomain("vrylngdomainame.com"))
+}
+
+func TestSuggestEmail(t *testing.T) {
+	v := NewVerifier()
+	assert.Equal(t, "", v.suggestEmail("user", "gmial.com"))
+
+	v.EnableDomainSuggest()
+	assert.Equal(t, "user@gmail.com", v.suggestEmail("user", "gmial.com"))
+	assert.Equal(t, "", v.suggestEmail("user", "gmail.com"))
+	assert.Equal(t, "", v.suggestEmail("user", ""))
+}
+
+func TestSuggestEmail_Address(t *testing.T) {
+	// the suggestion doesn't require EnableDomainSuggest, like SuggestDomain
+	v := NewVerifier()
+	assert.Equal(t, "user@gmail.com", v.SuggestEmail("user@gmial.com"))
+	assert.Equal(t, "first.last@hotmail.com", v.SuggestEmail(" first.last@hotnail.com "))
+	assert.Equal(t, "", v.SuggestEmail("user@gmail.com"))
+	assert.Equal(t, "", v.SuggestEmail("gmial.com"))
+	assert.Equal(t, "", v.SuggestEmail("@gmial.com"))
+}
+
+func TestFindClosestDomain_Tie(t *testing.T) {
+	domains := map[string]bool{"abd.com": true, "abc.com": true, "abe.com": true}
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "abc.com", findClosestDomain("abx.com", domains, 0.5, 0))
+	}
+}
+
+func TestCheckEmail_SuggestionOnInvalidSyntax(t *testing.T) {
+	v := NewVerifier().EnableDomainSuggest()
+
+	ret, err := v.Verify("user@gmailcom")
+	assert.NoError(t, err)
+	assert.False(t, ret.Syntax.Valid)
+	assert.Equal(t, "user@gmail.com", ret.Suggestion)
+}
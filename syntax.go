@@ -0,0 +1,271 @@
+package emailverifier
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxMode controls how strictly the address syntax is validated
+type SyntaxMode int
+
+const (
+	// SyntaxLenient accepts every address matching the address regex (default)
+	SyntaxLenient SyntaxMode = iota
+	// SyntaxStrict additionally enforces the RFC 5321 length limits and the dot rules of the domain
+	SyntaxStrict
+)
+
+// Reasons explaining why an address failed the syntax check
+const (
+	ReasonEmpty            = "empty"
+	ReasonMissingAt        = "missing_at"
+	ReasonEmptyLocalPart   = "empty_local_part"
+	ReasonEmptyDomain      = "empty_domain"
+	ReasonLocalPartTooLong = "local_part_too_long"
+	ReasonDomainTooLong    = "domain_too_long"
+	ReasonLabelTooLong     = "label_too_long"
+	ReasonLeadingDot       = "leading_dot"
+	ReasonTrailingDot      = "trailing_dot"
+	ReasonConsecutiveDots  = "consecutive_dots"
+	ReasonMissingTLD       = "missing_tld"
+	ReasonInvalidCharacter = "invalid_character"
+	ReasonInvalidQuote     = "invalid_quoted_string"
+	ReasonInvalidIDN       = "invalid_idn"
+	ReasonInvalidFormat    = "invalid_format"
+)
+
+// RFC 5321 size limits, in octets
+const (
+	maxLocalPartLength = 64
+	maxDomainLength    = 255
+	maxLabelLength     = 63
+)
+
+// SetSyntaxMode sets how strictly the address syntax is validated, defaults to SyntaxLenient
+func (v *Verifier) SetSyntaxMode(mode SyntaxMode) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.syntaxMode = mode
+	return v
+}
+
+// EnableUTF8LocalPart accepts any UTF-8 characters in the local part (RFC 6531), e.g. 用户@例え.jp,
+// such addresses can only be checked via SMTP against servers supporting the SMTPUTF8 extension
+func (v *Verifier) EnableUTF8LocalPart() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.utf8LocalPartEnabled = true
+	return v
+}
+
+// DisableUTF8LocalPart only accepts the non-ASCII characters of the address regex in the local part
+func (v *Verifier) DisableUTF8LocalPart() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.utf8LocalPartEnabled = false
+	return v
+}
+
+// checkSyntax validates email in the given mode and returns the reasons why it is invalid,
+// utf8LocalPart accepts any UTF-8 characters in the local part
+func checkSyntax(email string, mode SyntaxMode, utf8LocalPart bool) (bool, []string) {
+	var reasons []string
+
+	if !utf8.ValidString(email) {
+		return false, []string{ReasonInvalidCharacter}
+	}
+	if utf8LocalPart {
+		email = asciiLocalPart(email)
+	}
+
+	valid := IsAddressValid(email)
+	if !valid {
+		reasons = formatReasons(email)
+	} else if reason := structureReason(email); reason != "" {
+		valid = false
+		reasons = []string{reason}
+	}
+	if mode == SyntaxStrict {
+		for _, reason := range strictReasons(email) {
+			reasons = appendReason(reasons, reason)
+		}
+	}
+	if !valid && len(reasons) == 0 {
+		reasons = []string{ReasonInvalidFormat}
+	}
+
+	return valid && len(reasons) == 0, reasons
+}
+
+// structureReason reports a defect of an address matching the address regex which the regex can't detect
+func structureReason(email string) string {
+	index := strings.LastIndex(email, "@")
+	local, domain := email[:index], email[index+1:]
+
+	// the address regex doesn't require quotes inside a quoted string to be escaped
+	if strings.HasPrefix(local, `"`) && !isValidQuotedString(local) {
+		return ReasonInvalidQuote
+	}
+	if _, _, ok := idnForms(strings.ToLower(domain)); !ok {
+		return ReasonInvalidIDN
+	}
+	return ""
+}
+
+// asciiLocalPart replaces the non-ASCII characters of the local part with an ASCII letter,
+// so the address regex validates the structure of an internationalized local part
+func asciiLocalPart(email string) string {
+	index := strings.LastIndex(email, "@")
+	if index < 0 {
+		return email
+	}
+
+	local := strings.Map(func(r rune) rune {
+		if r >= utf8.RuneSelf {
+			return 'u'
+		}
+		return r
+	}, email[:index])
+	return local + email[index:]
+}
+
+// isASCII checks if s only contains ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// formatReasons diagnoses why email doesn't match the address regex
+func formatReasons(email string) []string {
+	if email == "" {
+		return []string{ReasonEmpty}
+	}
+
+	index := strings.LastIndex(email, "@")
+	if index < 0 {
+		return []string{ReasonMissingAt}
+	}
+
+	var reasons []string
+	local, domain := email[:index], email[index+1:]
+
+	if local == "" {
+		reasons = append(reasons, ReasonEmptyLocalPart)
+	} else if strings.HasPrefix(local, `"`) {
+		if !isValidQuotedString(local) {
+			reasons = append(reasons, ReasonInvalidQuote)
+		}
+	} else {
+		reasons = append(reasons, dotReasons(local)...)
+		if strings.IndexFunc(local, func(r rune) bool { return !isAtext(r) && r != '.' }) >= 0 {
+			reasons = appendReason(reasons, ReasonInvalidCharacter)
+		}
+	}
+
+	if domain == "" {
+		return append(reasons, ReasonEmptyDomain)
+	}
+	for _, reason := range dotReasons(strings.TrimSuffix(domain, ".")) {
+		reasons = appendReason(reasons, reason)
+	}
+	if strings.IndexFunc(domain, func(r rune) bool { return !isDomainChar(r) }) >= 0 {
+		reasons = appendReason(reasons, ReasonInvalidCharacter)
+	}
+	if !strings.Contains(strings.Trim(domain, "."), ".") {
+		reasons = appendReason(reasons, ReasonMissingTLD)
+	}
+
+	return reasons
+}
+
+// strictReasons reports the RFC 5321 violations which the address regex tolerates
+func strictReasons(email string) []string {
+	index := strings.LastIndex(email, "@")
+	if index < 0 {
+		return nil
+	}
+
+	var reasons []string
+	local, domain := email[:index], email[index+1:]
+
+	if len(local) > maxLocalPartLength {
+		reasons = append(reasons, ReasonLocalPartTooLong)
+	}
+	if len(domain) > maxDomainLength {
+		reasons = append(reasons, ReasonDomainTooLong)
+	}
+	reasons = append(reasons, dotReasons(domain)...)
+	for _, label := range strings.Split(domain, ".") {
+		if len(label) > maxLabelLength {
+			reasons = append(reasons, ReasonLabelTooLong)
+			break
+		}
+	}
+
+	return reasons
+}
+
+// dotReasons reports misplaced dots in a dot-separated local part or domain
+func dotReasons(s string) []string {
+	var reasons []string
+	if strings.HasPrefix(s, ".") {
+		reasons = append(reasons, ReasonLeadingDot)
+	}
+	if strings.HasSuffix(s, ".") {
+		reasons = append(reasons, ReasonTrailingDot)
+	}
+	if strings.Contains(s, "..") {
+		reasons = append(reasons, ReasonConsecutiveDots)
+	}
+	return reasons
+}
+
+// appendReason appends reason to reasons unless it is already present
+func appendReason(reasons []string, reason string) []string {
+	for _, r := range reasons {
+		if r == reason {
+			return reasons
+		}
+	}
+	return append(reasons, reason)
+}
+
+// isQuoted checks if the local part is a quoted string
+func isQuoted(local string) bool {
+	return len(local) >= 2 && strings.HasPrefix(local, `"`) && strings.HasSuffix(local, `"`)
+}
+
+// isAtext checks if r is allowed in an unquoted local part, mirroring the address regex
+func isAtext(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	case strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r):
+		return true
+	}
+	return isUnicodeChar(r)
+}
+
+// isDomainChar checks if r is allowed in a domain, mirroring the address regex
+func isDomainChar(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	case r == '-' || r == '.' || r == '~':
+		return true
+	}
+	return isUnicodeChar(r)
+}
+
+// isUnicodeChar checks if r is within the unicode ranges accepted by the address regex
+func isUnicodeChar(r rune) bool {
+	return r != utf8.RuneError &&
+		(0x00A0 <= r && r <= 0xD7FF || 0xF900 <= r && r <= 0xFDCF || 0xFDF0 <= r && r <= 0xFFEF)
+}
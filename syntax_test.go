@@ -0,0 +1,118 @@
+package emailverifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	longLocal := strings.Repeat("a", 65)
+	maxLocal := strings.Repeat("a", 64)
+	longLabel := strings.Repeat("b", 64)
+	longDomain := strings.Repeat(strings.Repeat("c", 60)+".", 5) + "com"
+
+	cases := []struct {
+		email   string
+		mode    SyntaxMode
+		valid   bool
+		reasons []string
+	}{
+		{email: "user@example.com", mode: SyntaxLenient, valid: true},
+		{email: "user@example.com", mode: SyntaxStrict, valid: true},
+		{email: "first.last@example.com", mode: SyntaxStrict, valid: true},
+		{email: "user+tag@example.co.uk", mode: SyntaxStrict, valid: true},
+		{email: "a@b.co", mode: SyntaxStrict, valid: true},
+		{email: "o'neil@example.com", mode: SyntaxStrict, valid: true},
+		{email: "user@sub-domain.example.com", mode: SyntaxStrict, valid: true},
+		{email: maxLocal + "@example.com", mode: SyntaxStrict, valid: true},
+		{email: "", mode: SyntaxLenient, reasons: []string{ReasonEmpty}},
+		{email: "", mode: SyntaxStrict, reasons: []string{ReasonEmpty}},
+		{email: "userexample.com", mode: SyntaxLenient, reasons: []string{ReasonMissingAt}},
+		{email: "userexample.com", mode: SyntaxStrict, reasons: []string{ReasonMissingAt}},
+		{email: "@example.com", mode: SyntaxLenient, reasons: []string{ReasonEmptyLocalPart}},
+		{email: "user@", mode: SyntaxLenient, reasons: []string{ReasonEmptyDomain}},
+		{email: "@", mode: SyntaxLenient, reasons: []string{ReasonEmptyLocalPart, ReasonEmptyDomain}},
+		{email: ".user@example.com", mode: SyntaxLenient, reasons: []string{ReasonLeadingDot}},
+		{email: "user.@example.com", mode: SyntaxLenient, reasons: []string{ReasonTrailingDot}},
+		{email: "us..er@example.com", mode: SyntaxLenient, reasons: []string{ReasonConsecutiveDots}},
+		{email: "user@example", mode: SyntaxLenient, reasons: []string{ReasonMissingTLD}},
+		{email: "user@example", mode: SyntaxStrict, reasons: []string{ReasonMissingTLD}},
+		{email: "us er@example.com", mode: SyntaxLenient, reasons: []string{ReasonInvalidCharacter}},
+		{email: "user@exa mple.com", mode: SyntaxLenient, reasons: []string{ReasonInvalidCharacter}},
+		{email: "us@er@example.com", mode: SyntaxLenient, reasons: []string{ReasonInvalidCharacter}},
+		{email: "user@.example.com", mode: SyntaxLenient, reasons: []string{ReasonLeadingDot}},
+		{email: "user@example.123", mode: SyntaxLenient, reasons: []string{ReasonInvalidFormat}},
+		{email: "user@-example.com", mode: SyntaxLenient, reasons: []string{ReasonInvalidFormat}},
+		{email: longLocal + "@example.com", mode: SyntaxLenient, valid: true},
+		{email: longLocal + "@example.com", mode: SyntaxStrict, reasons: []string{ReasonLocalPartTooLong}},
+		{email: "user@" + longDomain, mode: SyntaxLenient, valid: true},
+		{email: "user@" + longDomain, mode: SyntaxStrict, reasons: []string{ReasonDomainTooLong}},
+		{email: "user@" + longLabel + ".com", mode: SyntaxLenient, valid: true},
+		{email: "user@" + longLabel + ".com", mode: SyntaxStrict, reasons: []string{ReasonLabelTooLong}},
+		{email: "user@example..com", mode: SyntaxLenient, reasons: []string{ReasonConsecutiveDots}},
+		{email: "user@ab..cd.com", mode: SyntaxLenient, valid: true},
+		{email: "user@ab..cd.com", mode: SyntaxStrict, reasons: []string{ReasonConsecutiveDots}},
+		{email: "user@example.com.", mode: SyntaxLenient, valid: true},
+		{email: "user@example.com.", mode: SyntaxStrict, reasons: []string{ReasonTrailingDot}},
+		{email: `"user name"@example.com`, mode: SyntaxStrict, valid: true},
+		{email: "abc@доменное.com", mode: SyntaxStrict, valid: true},
+		{email: `"john"doe"@example.com`, mode: SyntaxLenient, reasons: []string{ReasonInvalidQuote}},
+		{email: `"john\"@example.com`, mode: SyntaxLenient, reasons: []string{ReasonInvalidQuote}},
+		{
+			email:   longLocal + "@a" + longLabel + "..b.com.",
+			mode:    SyntaxStrict,
+			reasons: []string{ReasonLocalPartTooLong, ReasonTrailingDot, ReasonConsecutiveDots, ReasonLabelTooLong},
+		},
+	}
+
+	for _, c := range cases {
+		valid, reasons := checkSyntax(c.email, c.mode, false)
+		assert.Equal(t, c.valid, valid, c.email)
+		assert.Equal(t, c.reasons, reasons, c.email)
+	}
+}
+
+func TestParseAddress_StrictMode(t *testing.T) {
+	v := NewVerifier().SetSyntaxMode(SyntaxStrict)
+
+	address := v.ParseAddress("user@ab..cd.com")
+	assert.False(t, address.Valid)
+	assert.Equal(t, []string{ReasonConsecutiveDots}, address.Reasons)
+
+	address = verifier.ParseAddress("user@ab..cd.com")
+	assert.True(t, address.Valid)
+	assert.Empty(t, address.Reasons)
+}
+
+func TestCheckSyntax_UTF8LocalPart(t *testing.T) {
+	valid, _ := checkSyntax("😀@gmail.com", SyntaxLenient, false)
+	assert.False(t, valid)
+
+	valid, reasons := checkSyntax("😀@gmail.com", SyntaxLenient, true)
+	assert.True(t, valid)
+	assert.Nil(t, reasons)
+
+	valid, _ = checkSyntax("用户@例え.jp", SyntaxStrict, true)
+	assert.True(t, valid)
+
+	valid, reasons = checkSyntax("用..户@例え.jp", SyntaxLenient, true)
+	assert.False(t, valid)
+	assert.Equal(t, []string{ReasonConsecutiveDots}, reasons)
+
+	valid, reasons = checkSyntax("us\xffer@example.com", SyntaxLenient, true)
+	assert.False(t, valid)
+	assert.Equal(t, []string{ReasonInvalidCharacter}, reasons)
+}
+
+func TestParseAddress_UTF8LocalPart(t *testing.T) {
+	v := NewVerifier().EnableUTF8LocalPart()
+
+	address := v.ParseAddress("😀@gmail.com")
+	assert.True(t, address.Valid)
+	assert.Equal(t, "😀", address.Username)
+
+	v.DisableUTF8LocalPart()
+	assert.False(t, v.ParseAddress("😀@gmail.com").Valid)
+}
@@ -0,0 +1,107 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Reasons why the mail servers of a domain couldn't be reached, see SMTP.HostUnreachableReason
+const (
+	UnreachableNXDomain       = "nxdomain"        // the domain doesn't exist
+	UnreachableNoMX           = "no_mx"           // the domain exists but has no MX records
+	UnreachableNullMX         = "null_mx"         // the domain publishes a null MX record, i.e. accepts no email (RFC 7505)
+	UnreachableDNSError       = "dns_error"       // a DNS lookup failed, e.g. the DNS server timed out, a retry may succeed
+	UnreachableConnectRefused = "connect_refused" // the mail servers refused the connection
+	UnreachableConnectTimeout = "connect_timeout" // connecting to the mail servers timed out, a retry may succeed
+	UnreachableConnectError   = "connect_error"   // connecting to the mail servers failed for another reason, e.g. no route
+	UnreachableTLSError       = "tls_error"       // the TLS handshake of implicit TLS failed
+	UnreachableBannerError    = "banner_error"    // the mail servers didn't greet with a 220 reply in time
+	UnreachableTempFail       = "temp_fail"       // the mail servers greeted with a 4xx reply, a retry may succeed, see EnableLenientGreeting
+	UnreachableProxyError     = "proxy_error"     // the connection through the SOCKS5 proxy failed
+)
+
+// unreachablePreference orders the reasons of the failures to connect to the MX hosts of a domain,
+// the reason of the failure most worth a retry first
+var unreachablePreference = []string{
+	UnreachableConnectTimeout,
+	UnreachableTempFail,
+	UnreachableBannerError,
+	UnreachableTLSError,
+	UnreachableProxyError,
+	UnreachableDNSError,
+	UnreachableConnectError,
+	UnreachableConnectRefused,
+}
+
+// unreachableError is the failure to reach the mail servers of a domain, classified by its reason.
+// Its message is the one of err, so ParseSMTPError parses it the same.
+type unreachableError struct {
+	reason string
+	err    error
+}
+
+func (e *unreachableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unreachableError) Unwrap() error {
+	return e.err
+}
+
+// unreachable returns err classified by reason
+func unreachable(reason string, err error) error {
+	return &unreachableError{reason: reason, err: err}
+}
+
+// hostUnreachableReason returns the reason of the failure err to reach the mail servers, empty if err isn't one
+func hostUnreachableReason(err error) string {
+	var e *unreachableError
+	if errors.As(err, &e) {
+		return e.reason
+	}
+	return ""
+}
+
+// classifyDialError classifies the failure err to connect to a mail server without a proxy
+func classifyDialError(err error) error {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout():
+		return unreachable(UnreachableConnectTimeout, err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return unreachable(UnreachableConnectRefused, err)
+	case errors.As(err, &dnsErr):
+		return unreachable(UnreachableDNSError, err)
+	default:
+		return unreachable(UnreachableConnectError, err)
+	}
+}
+
+// classifyMXLookupError classifies the failure err to look up the MX records of the ASCII domain,
+// a domain which isn't found but resolves to an address has no MX records
+func (v *Verifier) classifyMXLookupError(domain string, err error) error {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		return unreachable(UnreachableDNSError, err)
+	}
+	if _, hostErr := v.lookupResolver().LookupHost(v.context(), domain); hostErr == nil {
+		return unreachable(UnreachableNoMX, err)
+	}
+	return unreachable(UnreachableNXDomain, err)
+}
+
+// mostTransient returns the error of errs, the failures to connect to the MX hosts of a domain,
+// whose reason is the most worth a retry, the first of them if several have the same reason
+func mostTransient(errs []error) error {
+	for _, reason := range unreachablePreference {
+		for _, err := range errs {
+			if hostUnreachableReason(err) == reason {
+				return err
+			}
+		}
+	}
+	return errs[0]
+}
@@ -0,0 +1,105 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vikt0r0/email-verifier/smtptest"
+)
+
+// failingDialer returns a dialer failing with err
+func failingDialer(err error) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, err
+	}
+}
+
+func TestCheckSMTP_HostUnreachableReason(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	blocking := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	failingResolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("dns server unavailable")
+		},
+	}
+
+	cases := []struct {
+		name     string
+		behavior smtptest.Behavior
+		domain   string
+		opts     []Option
+		want     string
+	}{
+		{name: "nxdomain", domain: "example.org", want: UnreachableNXDomain},
+		{name: "no mx", domain: "mx.example.com", want: UnreachableNoMX},
+		{name: "null mx", behavior: smtptest.Behavior{NullMX: []string{"example.com"}}, domain: "example.com", want: UnreachableNullMX},
+		{name: "dns error", domain: "example.com", opts: []Option{WithResolver(failingResolver)}, want: UnreachableDNSError},
+		{name: "connect refused", domain: "example.com", opts: []Option{WithDialer(failingDialer(refused))}, want: UnreachableConnectRefused},
+		{name: "connect timeout", domain: "example.com", opts: []Option{WithDialer(blocking), WithTimeout(50 * time.Millisecond)}, want: UnreachableConnectTimeout},
+		{name: "connect error", domain: "example.com", opts: []Option{WithDialer(failingDialer(errors.New("network is unreachable")))}, want: UnreachableConnectError},
+		{name: "tls error", domain: "example.com", opts: []Option{WithImplicitTLS()}, want: UnreachableTLSError},
+		{name: "banner error", behavior: smtptest.Behavior{Banner: "554 go away"}, domain: "example.com", want: UnreachableBannerError},
+		{name: "banner timeout", behavior: smtptest.Behavior{BannerDelay: time.Second}, domain: "example.com", opts: []Option{WithTimeout(50 * time.Millisecond)}, want: UnreachableBannerError},
+		{name: "proxy error", domain: "example.com", opts: []Option{WithProxy("socks5://127.0.0.1:1")}, want: UnreachableProxyError},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, _ := newSMTPTestVerifier(t, c.behavior, []string{"example.com"}, c.opts...)
+
+			ret, err := v.CheckSMTP(c.domain, "")
+			assert.Error(t, err)
+			if assert.NotNil(t, ret) {
+				assert.False(t, ret.HostExists)
+				assert.Equal(t, c.want, ret.HostUnreachableReason)
+			}
+		})
+	}
+}
+
+func TestCheckSMTP_HostUnreachableReasonReached(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"})
+
+	ret, err := v.CheckSMTP("example.com", "user")
+	assert.NoError(t, err)
+	assert.True(t, ret.HostExists)
+	assert.Empty(t, ret.HostUnreachableReason)
+}
+
+func TestVerify_HostUnreachableReasonSoftFail(t *testing.T) {
+	v, _ := newSMTPTestVerifier(t, smtptest.Behavior{}, []string{"example.com"}, WithSMTPSoftFail(),
+		WithDialer(failingDialer(os.NewSyscallError("connect", syscall.ECONNREFUSED))))
+
+	ret, err := v.Verify("user@example.com")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ret.SMTP) {
+		assert.Equal(t, UnreachableConnectRefused, ret.SMTP.HostUnreachableReason)
+		assert.NotNil(t, ret.SMTP.Error)
+	}
+}
+
+func TestMostTransient(t *testing.T) {
+	refused := unreachable(UnreachableConnectRefused, errors.New("connection refused"))
+	timeout := unreachable(UnreachableConnectTimeout, errors.New("i/o timeout"))
+	other := errors.New("unexpected")
+
+	assert.Equal(t, timeout, mostTransient([]error{refused, timeout}))
+	assert.Equal(t, refused, mostTransient([]error{refused, other}))
+	assert.Equal(t, other, mostTransient([]error{other}))
+}
+
+func TestUnreachableError_ParsedLikeItsCause(t *testing.T) {
+	err := errors.New("dial tcp: i/o timeout")
+	assert.Equal(t, ParseSMTPError(err), ParseSMTPError(unreachable(UnreachableConnectTimeout, err)))
+	assert.Equal(t, UnreachableConnectTimeout, hostUnreachableReason(unreachable(UnreachableConnectTimeout, err)))
+	assert.Empty(t, hostUnreachableReason(err))
+}
@@ -3,10 +3,12 @@ package emailverifier
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"fmt"
 	"reflect"
 	"strings"
 
 	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
 )
 
 // parsedDomain parses and returns second level domain
@@ -19,6 +21,31 @@ func parsedDomain(domain string) string {
 	return lowercaseDomain
 }
 
+// RegistrableDomain returns the registrable domain (eTLD+1) of domain according to the public suffix list,
+// e.g. "example.co.uk" for "mail.example.co.uk", in the form of domain: ASCII (punycode) or Unicode.
+// It fails for a single-label domain and for a domain which is a public suffix itself, like "co.uk".
+func RegistrableDomain(domain string) (string, error) {
+	lowercaseDomain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	asciiDomain, _, ok := idnForms(lowercaseDomain)
+	if !ok {
+		return "", fmt.Errorf("invalid domain %q", domain)
+	}
+	if !strings.Contains(asciiDomain, ".") {
+		return "", fmt.Errorf("single-label domain %q has no registrable domain", domain)
+	}
+	if suffix, _ := publicsuffix.PublicSuffix(asciiDomain); suffix == asciiDomain {
+		return "", fmt.Errorf("domain %q is a public suffix", domain)
+	}
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(asciiDomain)
+	if err != nil {
+		return "", err
+	}
+	if asciiDomain == lowercaseDomain {
+		return registrable, nil
+	}
+	return idna.Lookup.ToUnicode(registrable)
+}
+
 // splitDomain splits domain and returns sld and tld
 func splitDomain(domain string) (string, string) {
 	parts := strings.Split(domain, ".")
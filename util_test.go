@@ -100,3 +100,28 @@ func TestSplitDomainSubDomain(t *testing.T) {
 	assert.Equal(t, sld, "aftership")
 	assert.Equal(t, tld, "com")
 }
+
+func TestRegistrableDomain(t *testing.T) {
+	cases := map[string]string{
+		"example.com":            "example.com",
+		"mail.example.com":       "example.com",
+		"a.b.mail.example.com":   "example.com",
+		"mail.example.co.uk":     "example.co.uk",
+		"Example.CO.UK.":         "example.co.uk",
+		"user.blogspot.com":      "user.blogspot.com",
+		"deep.user.blogspot.com": "user.blogspot.com",
+		"mail.münchen.de":        "münchen.de",
+		"mail.xn--mnchen-3ya.de": "xn--mnchen-3ya.de",
+		"mail.例え.jp":             "例え.jp",
+	}
+	for domain, expected := range cases {
+		registrable, err := RegistrableDomain(domain)
+		assert.NoError(t, err, domain)
+		assert.Equal(t, expected, registrable, domain)
+	}
+
+	for _, domain := range []string{"localhost", "com", "co.uk", "blogspot.com", "xn--p1ai", "", "a..b"} {
+		_, err := RegistrableDomain(domain)
+		assert.Error(t, err, domain)
+	}
+}
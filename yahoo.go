@@ -0,0 +1,165 @@
+package emailverifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// yahooDomains are the domains of Yahoo mailboxes by their signup domain field
+var yahooDomains = map[string]string{
+	"yahoo.com":      "yahoo",
+	"ymail.com":      "ymail",
+	"rocketmail.com": "rocketmail",
+}
+
+// yahooAcrumbPattern matches the acrumb within the AS cookie set by the signup page
+var yahooAcrumbPattern = regexp.MustCompile(`s=([^;&]+)`)
+
+// yahooVerifier checks the existence of Yahoo accounts through the username validation of the signup page,
+// since the Yahoo MX servers accept RCPT for nonexistent users
+type yahooVerifier struct {
+	client      *http.Client
+	limiter     *rateLimiter
+	signupURL   string // page setting the cookies of the validation
+	validateURL string // endpoint validating the username
+}
+
+// newYahooVerifier returns a yahooVerifier of the Yahoo endpoints
+func newYahooVerifier() *yahooVerifier {
+	return &yahooVerifier{
+		client:      &http.Client{Timeout: apiVerifierTimeout},
+		limiter:     &rateLimiter{interval: apiVerifierInterval},
+		signupURL:   yahooSignupURL,
+		validateURL: yahooValidateURL,
+	}
+}
+
+func (y *yahooVerifier) isSupported(domain string) bool {
+	_, ok := yahooDomains[strings.ToLower(domain)]
+	return ok
+}
+
+// yahooValidateResponse is the response of the username validation
+type yahooValidateResponse struct {
+	Errors []struct {
+		Name  string `json:"name"`
+		Error string `json:"error"`
+	} `json:"errors"`
+}
+
+// exists reports whether the username is taken, i.e. the account exists
+func (r yahooValidateResponse) exists() bool {
+	for _, e := range r.Errors {
+		if e.Name == "userId" && (e.Error == "IDENTIFIER_EXISTS" || e.Error == "IDENTIFIER_NOT_AVAILABLE") {
+			return true
+		}
+	}
+	return false
+}
+
+func (y *yahooVerifier) check(ctx context.Context, domain, username string) (*SMTP, error) {
+	if err := y.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	acrumb, cookies, err := y.signup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"specId":        {"yidregsimplified"},
+		"acrumb":        {acrumb},
+		"done":          {"https://www.yahoo.com/"},
+		"attrSetIndex":  {"0"},
+		"userid-domain": {yahooDomains[strings.ToLower(domain)]},
+		"userId":        {username},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", y.validateURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Origin", "https://login.yahoo.com")
+	req.Header.Set("Referer", y.signupURL)
+	req.Header.Set("User-Agent", apiVerifierUserAgent)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return nil, newLookupError(ErrServerUnavailable, err.Error())
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if err := yahooStatusError(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newLookupError(ErrServerUnavailable, err.Error())
+	}
+	// A captcha or block page isn't JSON
+	var validation yahooValidateResponse
+	if err := json.Unmarshal(body, &validation); err != nil {
+		return nil, newLookupError(ErrBlocked, "unexpected yahoo validation response")
+	}
+
+	return &SMTP{
+		HostExists:  true,
+		Deliverable: validation.exists(),
+	}, nil
+}
+
+// signup requests the signup page and returns its acrumb and cookies
+func (y *yahooVerifier) signup(ctx context.Context) (string, []*http.Cookie, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", y.signupURL, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("User-Agent", apiVerifierUserAgent)
+
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return "", nil, newLookupError(ErrServerUnavailable, err.Error())
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if err := yahooStatusError(resp); err != nil {
+		return "", nil, err
+	}
+
+	cookies := resp.Cookies()
+	for _, c := range cookies {
+		if c.Name != "AS" {
+			continue
+		}
+		if match := yahooAcrumbPattern.FindStringSubmatch(c.Value); match != nil {
+			return match[1], cookies, nil
+		}
+	}
+	return "", nil, newLookupError(ErrBlocked, "yahoo signup page without acrumb")
+}
+
+// yahooStatusError returns the error of an unsuccessful response status
+func yahooStatusError(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return newLookupError(ErrBlocked, fmt.Sprintf("yahoo status_code: %d", resp.StatusCode))
+	default:
+		return newLookupError(ErrServerUnavailable, fmt.Sprintf("yahoo status_code: %d", resp.StatusCode))
+	}
+}
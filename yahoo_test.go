@@ -0,0 +1,84 @@
+package emailverifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newYahooTestServer returns a server of the Yahoo signup endpoints, validate handles the username validation
+func newYahooTestServer(t *testing.T, validate http.HandlerFunc) *yahooVerifier {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/account/create", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "AS", Value: "v=1&s=testcrumb&d=A"})
+	})
+	mux.HandleFunc("/account/module/create", validate)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	y := newYahooVerifier()
+	y.limiter.interval = 0
+	y.signupURL = server.URL + "/account/create"
+	y.validateURL = server.URL + "/account/module/create?validateField=userId"
+	return y
+}
+
+// yahooUsers returns a validation handler of the existing users
+func yahooUsers(users ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("acrumb") != "testcrumb" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		for _, user := range users {
+			if r.FormValue("userId") == user {
+				_, _ = w.Write([]byte(`{"errors":[{"name":"userId","error":"IDENTIFIER_EXISTS"}]}`))
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{"errors":[{"name":"firstName","error":"FIELD_EMPTY"}]}`))
+	}
+}
+
+func TestYahooVerifier_IsSupported(t *testing.T) {
+	y := newYahooVerifier()
+
+	assert.True(t, y.isSupported("yahoo.com"))
+	assert.True(t, y.isSupported("YMail.com"))
+	assert.True(t, y.isSupported("rocketmail.com"))
+	assert.False(t, y.isSupported("gmail.com"))
+}
+
+func TestYahooVerifier_Check(t *testing.T) {
+	y := newYahooTestServer(t, yahooUsers("alice"))
+
+	smtp, err := y.check(context.Background(), "yahoo.com", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true}, smtp)
+
+	smtp, err = y.check(context.Background(), "yahoo.com", "nobody")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true}, smtp)
+}
+
+func TestYahooVerifier_CheckCaptcha(t *testing.T) {
+	y := newYahooTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>captcha</html>"))
+	})
+
+	smtp, err := y.check(context.Background(), "yahoo.com", "alice")
+	assert.Nil(t, smtp)
+	assert.Equal(t, ErrBlocked, err.(*LookupError).Message)
+}
+
+func TestYahooVerifier_CheckBlocked(t *testing.T) {
+	y := newYahooTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := y.check(context.Background(), "yahoo.com", "alice")
+	assert.Equal(t, ErrBlocked, err.(*LookupError).Message)
+}